@@ -19,6 +19,8 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
 )
 
 // SecretStoreRef defines which SecretStore to fetch the ExternalSecret data.
@@ -257,6 +259,10 @@ type ExternalSecretDataRemoteRef struct {
 	// Used to select a specific property of the Provider value (if a map), if supported
 	Property string `json:"property,omitempty"`
 
+	// +optional
+	// Used to select a subset of the properties of the Provider value (if a map), if supported
+	Properties []string `json:"properties,omitempty"`
+
 	// +optional
 	// Used to select a specific version of the Provider value, if supported
 	Version string `json:"version,omitempty"`
@@ -270,6 +276,46 @@ type ExternalSecretDataRemoteRef struct {
 	// Used to define a decoding Strategy
 	// +kubebuilder:default="None"
 	DecodingStrategy ExternalSecretDecodingStrategy `json:"decodingStrategy,omitempty"`
+
+	// +optional
+	// Used to convert a certificate Provider value to another format, if supported
+	FormatConversion *ExternalSecretFormatConversion `json:"formatConversion,omitempty"`
+
+	// +optional
+	// Used as the value when the Provider value does not exist, if supported and allowed by the SecretStore
+	DefaultValue *string `json:"defaultValue,omitempty"`
+
+	// +optional
+	// Used to select the scope of the Provider value, e.g. a group the secret is shared with, if supported
+	Scope string `json:"scope,omitempty"`
+}
+
+// ExternalSecretFormat is the format of a certificate and its private key.
+// +kubebuilder:validation:Enum=PEM;PKCS12;JKS
+type ExternalSecretFormat string
+
+const (
+	// ExternalSecretFormatPEM is a PEM bundle of a private key and its certificate chain.
+	ExternalSecretFormatPEM ExternalSecretFormat = "PEM"
+	// ExternalSecretFormatPKCS12 is a PKCS#12 keystore.
+	ExternalSecretFormatPKCS12 ExternalSecretFormat = "PKCS12"
+	// ExternalSecretFormatJKS is a Java KeyStore.
+	ExternalSecretFormatJKS ExternalSecretFormat = "JKS"
+)
+
+// ExternalSecretFormatConversion converts a certificate Provider value from one format to another.
+type ExternalSecretFormatConversion struct {
+	// From is the format of the Provider value. JKS is only supported as a target.
+	From ExternalSecretFormat `json:"from"`
+
+	// To is the format the Provider value is converted to.
+	To ExternalSecretFormat `json:"to"`
+
+	// PassphraseSecretRef is the passphrase of the encrypted formats: the PKCS12 value converted from,
+	// and the PKCS12 or JKS keystore converted to. It is required to convert to JKS.
+	// The Secret is read from the namespace of the ExternalSecret.
+	// +optional
+	PassphraseSecretRef *esmeta.SecretKeySelector `json:"passphraseSecretRef,omitempty"`
 }
 
 // ExternalSecretMetadataPolicy defines policies for fetching metadata from provider secrets.
@@ -549,7 +595,7 @@ type GeneratorRef struct {
 	APIVersion string `json:"apiVersion,omitempty"`
 
 	// Specify the Kind of the generator resource
-	// +kubebuilder:validation:Enum=ACRAccessToken;ClusterGenerator;CloudsmithAccessToken;ECRAuthorizationToken;Fake;GCRAccessToken;GithubAccessToken;QuayAccessToken;Password;SSHKey;STSSessionToken;UUID;VaultDynamicSecret;Webhook;Grafana;MFA
+	// +kubebuilder:validation:Enum=ACRAccessToken;ClusterGenerator;CloudsmithAccessToken;ECRAuthorizationToken;Fake;GCRAccessToken;GithubAccessToken;QuayAccessToken;Password;SSHKey;STSSessionToken;UUID;VaultDynamicSecret;Webhook;Grafana;MFA;SmopDynamicSecret
 	Kind string `json:"kind"`
 
 	// Specify the name of the generator resource
@@ -624,6 +670,16 @@ type ExternalSecretStatus struct {
 
 	// Binding represents a servicebinding.io Provisioned Service reference to the secret
 	Binding corev1.LocalObjectReference `json:"binding,omitempty"`
+
+	// ProviderCursors are the pagination cursors of providers spreading a dataFrom.find
+	// over several reconciles, keyed by the provider. They are reset when the ExternalSecret changes.
+	// +optional
+	ProviderCursors map[string]string `json:"providerCursors,omitempty"`
+
+	// DefaultedKeys are the remote keys whose remoteRef defaultValue was synced by the last sync
+	// in place of a missing secret, as reported by the providers.
+	// +optional
+	DefaultedKeys []string `json:"defaultedKeys,omitempty"`
 }
 
 // ExternalSecret is the Schema for the external-secrets API.
@@ -653,6 +709,9 @@ type ExternalSecret struct {
 const (
 	// AnnotationDataHash all secrets managed by an ExternalSecret have this annotation with the hash of their data.
 	AnnotationDataHash = "reconcile.external-secrets.io/data-hash"
+	// AnnotationProviderVersion secrets synced from providers reporting the versions of their remote secrets
+	// (see SecretVersionHinter) have this annotation with the hash of those versions.
+	AnnotationProviderVersion = "reconcile.external-secrets.io/provider-version"
 	// AnnotationForceSync all ExternalSecrets managed by a ClusterExternalSecret mirror the state and value of this annotation.
 	AnnotationForceSync = "external-secrets.io/force-sync"
 
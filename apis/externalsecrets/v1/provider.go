@@ -18,6 +18,7 @@ package v1
 
 import (
 	"context"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -74,6 +75,7 @@ type SecretsClient interface {
 	// GetSecret returns a single secret from the provider
 	// if GetSecret returns an error with type NoSecretError
 	// then the secret entry will be deleted depending on the deletionPolicy.
+	// If it returns an error with type SkipSecretError the secret entry is left out.
 	GetSecret(ctx context.Context, ref ExternalSecretDataRemoteRef) ([]byte, error)
 
 	// PushSecret will write a single secret into the provider
@@ -99,6 +101,149 @@ type SecretsClient interface {
 	Close(ctx context.Context) error
 }
 
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// SecretTypeHinter is an optional interface a SecretsClient may implement to suggest
+// the type of the Kubernetes Secret created from a dataFrom.extract remote reference.
+// A hint is only applied when the Secret is created and spec.target.template.type is not set.
+type SecretTypeHinter interface {
+	// SecretTypeHint returns the suggested Secret type for the remote reference,
+	// or an empty type if the provider has no suggestion.
+	SecretTypeHint(ctx context.Context, ref ExternalSecretDataRemoteRef) (corev1.SecretType, error)
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// RefreshJitterHinter is an optional interface a SecretsClient may implement to spread
+// the refreshes of ExternalSecrets sharing the same refresh interval.
+// The controller delays the requeue of each ExternalSecret by a deterministic offset
+// derived from its UID, up to the returned maximum.
+type RefreshJitterHinter interface {
+	// RefreshJitter returns the maximum delay that may be added to the requeue
+	// of an ExternalSecret refreshed every refreshInterval, or zero for no jitter.
+	RefreshJitter(refreshInterval time.Duration) time.Duration
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// SecretVersionHinter is an optional interface a SecretsClient may implement to report the versions
+// of the remote secrets it returned. The controller skips updating a Secret whose remote secrets
+// are at the versions of the last sync, as long as the ExternalSecret has not changed either.
+type SecretVersionHinter interface {
+	// SecretVersion returns an opaque version of the remote secrets read with the client so far,
+	// which changes whenever one of them changes. It returns an empty string if any version is unknown.
+	SecretVersion() string
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// FindCursorHinter is an optional interface a SecretsClient may implement to spread a dataFrom.find
+// over several reconciles. The controller keeps the returned cursors in the ExternalSecret status
+// and passes them to the next reconcile, see esutils.FindCursor. While cursors are pending, the
+// secrets found are merged into the data of the target Secret instead of replacing it.
+// An ExternalSecret with a target template refuses cursors and fails to sync: its template must
+// render every secret found at once.
+type FindCursorHinter interface {
+	// FindCursors returns the cursors to resume the unfinished listings of the client from,
+	// or nil once every listing completed.
+	FindCursors() map[string]string
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// SecretAnnotationsHinter is an optional interface a SecretsClient may implement to describe
+// the remote secrets it returned with annotations on the Kubernetes Secret, e.g. when they expire.
+// The annotations are set on every sync which may change the Secret.
+type SecretAnnotationsHinter interface {
+	// SecretAnnotations returns the annotations for the remote secrets read with the client so far,
+	// or nil if the provider has none to suggest.
+	SecretAnnotations() map[string]string
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// DefaultedKeysHinter is an optional interface a SecretsClient may implement to report the remote keys
+// whose remoteRef defaultValue it returned in place of a missing secret.
+// The controller lists them in the ExternalSecret status.
+type DefaultedKeysHinter interface {
+	// DefaultedKeys returns the remote keys defaulted with the client so far, or nil if there are none.
+	DefaultedKeys() []string
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// SourceDeletionHandler is an optional interface a Provider may implement to drop the state it keeps
+// about the ExternalSecrets reading from it, e.g. metric series, once an ExternalSecret is deleted.
+type SourceDeletionHandler interface {
+	// SourceDeleted is called once the ExternalSecret `name` in `namespace` is deleted.
+	SourceDeleted(namespace, name string)
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// RetryAfterError is an optional interface an error returned by a SecretsClient may implement
+// when the provider is temporarily unavailable, e.g. during maintenance.
+// The controller requeues the ExternalSecret after the returned delay instead of backing off as usual.
+type RetryAfterError interface {
+	error
+	// RetryAfter returns how long to wait before syncing again.
+	RetryAfter() time.Duration
+}
+
+// ProviderFailureReason is the reason a SecretsClient failed, see FailureReasonError.
+type ProviderFailureReason string
+
+const (
+	// ProviderFailureReasonAuth reports credentials the provider rejected.
+	ProviderFailureReasonAuth ProviderFailureReason = "ProviderAuthFailed"
+	// ProviderFailureReasonNotFound reports a secret missing in the provider.
+	ProviderFailureReasonNotFound ProviderFailureReason = "ProviderSecretNotFound"
+	// ProviderFailureReasonRateLimited reports requests the provider refused to serve until later.
+	ProviderFailureReasonRateLimited ProviderFailureReason = "ProviderRateLimited"
+	// ProviderFailureReasonServerError reports a provider which failed or could not be reached.
+	ProviderFailureReasonServerError ProviderFailureReason = "ProviderServerError"
+	// ProviderFailureReasonApprovalPending reports a secret whose access awaits an approval.
+	ProviderFailureReasonApprovalPending ProviderFailureReason = "ProviderApprovalPending"
+)
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// FailureReasonError is an optional interface an error returned by a SecretsClient may implement
+// to tell why it failed. The controller records the Warning Event of the failure with the returned
+// reason instead of UpdateFailed, so operators can tell failures apart, e.g. with field selectors.
+type FailureReasonError interface {
+	error
+	// FailureReason returns the reason of the failure, or an empty reason if it is unknown.
+	FailureReason() ProviderFailureReason
+}
+
 // NoSecretErr is a sentinel error for when a secret is not found.
 var NoSecretErr = NoSecretError{}
 
@@ -110,6 +255,19 @@ func (NoSecretError) Error() string {
 	return "Secret does not exist"
 }
 
+// SkipSecretErr is a sentinel error for when a secret is not found and
+// shall be left out of the target secret instead of failing the sync.
+var SkipSecretErr = SkipSecretError{}
+
+// SkipSecretError shall be returned when a GetSecret can not find the desired
+// secret and the provider is configured to ignore missing secrets.
+// The controller drops the entry regardless of the deletionPolicy.
+type SkipSecretError struct{}
+
+func (SkipSecretError) Error() string {
+	return "Secret does not exist and is skipped"
+}
+
 // NotModifiedErr is a sentinel error to signal that the webhook received no changes,
 // and it should just return without doing anything.
 var NotModifiedErr = NotModifiedError{}
@@ -71,6 +71,17 @@ func GetProviderByName(name string) (Provider, bool) {
 	return f, ok
 }
 
+// GetProviders returns the registered provider implementations.
+func GetProviders() []Provider {
+	buildlock.RLock()
+	defer buildlock.RUnlock()
+	providers := make([]Provider, 0, len(builder))
+	for _, p := range builder {
+		providers = append(providers, p)
+	}
+	return providers
+}
+
 // GetProvider returns the provider from the generic store.
 func GetProvider(s GenericStore) (Provider, error) {
 	if s == nil {
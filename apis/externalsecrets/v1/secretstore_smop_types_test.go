@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+)
+
+func TestSmopProviderDeepCopy(t *testing.T) {
+	in := &SmopProvider{
+		Auth: &SmopAuth{
+			APIKey: &SmopAuthSecretRef{
+				SmopToken: esmeta.SecretKeySelector{Name: "smop-api-token", Key: "token"},
+			},
+		},
+		Server: &SmopServer{
+			APIURL: "https://api.beyondtrust.io/site",
+			SiteId: "site-id",
+		},
+		Timeouts: &SmopTimeouts{
+			Get: &metav1.Duration{Duration: time.Second},
+		},
+		TLS: &SmopTLS{
+			CABundle:   []byte("ca"),
+			CAProvider: &CAProvider{Type: CAProviderTypeSecret, Name: "ca"},
+			ClientCert: &esmeta.SecretKeySelector{Name: "smop-tls", Key: "tls.crt"},
+			ClientKey:  &esmeta.SecretKeySelector{Name: "smop-tls", Key: "tls.key"},
+		},
+		KeyFilter: &SmopKeyFilter{
+			Allow: []string{"db-*"},
+			Deny:  []string{"root-*"},
+		},
+		FolderPath:           "demo/K8s",
+		EmptyListStatusCodes: []int{204},
+	}
+
+	out := in.DeepCopy()
+	assert.Equal(t, in, out)
+
+	// mutating the copy must not leak into the original
+	out.Auth.APIKey.SmopToken.Name = "changed"
+	out.Server.APIURL = "https://changed"
+	out.Timeouts.Get.Duration = time.Hour
+	out.TLS.CABundle[0] = 'x'
+	out.TLS.CAProvider.Name = "changed"
+	out.TLS.ClientCert.Name = "changed"
+	out.KeyFilter.Allow[0] = "changed"
+	out.KeyFilter.Deny = append(out.KeyFilter.Deny, "changed")
+	out.EmptyListStatusCodes[0] = 209
+
+	assert.Equal(t, "smop-api-token", in.Auth.APIKey.SmopToken.Name)
+	assert.Equal(t, "https://api.beyondtrust.io/site", in.Server.APIURL)
+	assert.Equal(t, time.Second, in.Timeouts.Get.Duration)
+	assert.Equal(t, []byte("ca"), in.TLS.CABundle)
+	assert.Equal(t, "ca", in.TLS.CAProvider.Name)
+	assert.Equal(t, "smop-tls", in.TLS.ClientCert.Name)
+	assert.Equal(t, []string{"db-*"}, in.KeyFilter.Allow)
+	assert.Equal(t, []string{"root-*"}, in.KeyFilter.Deny)
+	assert.Equal(t, []int{204}, in.EmptyListStatusCodes)
+
+	var nilProvider *SmopProvider
+	assert.Nil(t, nilProvider.DeepCopy())
+}
@@ -1362,7 +1362,7 @@ func (in *ExternalSecret) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalSecretData) DeepCopyInto(out *ExternalSecretData) {
 	*out = *in
-	out.RemoteRef = in.RemoteRef
+	in.RemoteRef.DeepCopyInto(&out.RemoteRef)
 	if in.SourceRef != nil {
 		in, out := &in.SourceRef, &out.SourceRef
 		*out = new(StoreSourceRef)
@@ -1386,7 +1386,7 @@ func (in *ExternalSecretDataFromRemoteRef) DeepCopyInto(out *ExternalSecretDataF
 	if in.Extract != nil {
 		in, out := &in.Extract, &out.Extract
 		*out = new(ExternalSecretDataRemoteRef)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Find != nil {
 		in, out := &in.Find, &out.Find
@@ -1420,6 +1420,21 @@ func (in *ExternalSecretDataFromRemoteRef) DeepCopy() *ExternalSecretDataFromRem
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalSecretDataRemoteRef) DeepCopyInto(out *ExternalSecretDataRemoteRef) {
 	*out = *in
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FormatConversion != nil {
+		in, out := &in.FormatConversion, &out.FormatConversion
+		*out = new(ExternalSecretFormatConversion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultValue != nil {
+		in, out := &in.DefaultValue, &out.DefaultValue
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretDataRemoteRef.
@@ -1464,6 +1479,26 @@ func (in *ExternalSecretFind) DeepCopy() *ExternalSecretFind {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretFormatConversion) DeepCopyInto(out *ExternalSecretFormatConversion) {
+	*out = *in
+	if in.PassphraseSecretRef != nil {
+		in, out := &in.PassphraseSecretRef, &out.PassphraseSecretRef
+		*out = new(apismetav1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretFormatConversion.
+func (in *ExternalSecretFormatConversion) DeepCopy() *ExternalSecretFormatConversion {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretFormatConversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalSecretList) DeepCopyInto(out *ExternalSecretList) {
 	*out = *in
@@ -1653,6 +1688,18 @@ func (in *ExternalSecretStatus) DeepCopyInto(out *ExternalSecretStatus) {
 		}
 	}
 	out.Binding = in.Binding
+	if in.ProviderCursors != nil {
+		in, out := &in.ProviderCursors, &out.ProviderCursors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DefaultedKeys != nil {
+		in, out := &in.DefaultedKeys, &out.DefaultedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretStatus.
@@ -3560,10 +3607,51 @@ func (in *SenhaseguraProvider) DeepCopy() *SenhaseguraProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkipSecretError) DeepCopyInto(out *SkipSecretError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkipSecretError.
+func (in *SkipSecretError) DeepCopy() *SkipSecretError {
+	if in == nil {
+		return nil
+	}
+	out := new(SkipSecretError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopApprovalPolling) DeepCopyInto(out *SmopApprovalPolling) {
+	*out = *in
+	out.Interval = in.Interval
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopApprovalPolling.
+func (in *SmopApprovalPolling) DeepCopy() *SmopApprovalPolling {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopApprovalPolling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SmopAuth) DeepCopyInto(out *SmopAuth) {
 	*out = *in
-	in.APIKey.DeepCopyInto(&out.APIKey)
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(SmopAuthSecretRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(SmopWorkloadIdentityAuth)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopAuth.
@@ -3592,6 +3680,86 @@ func (in *SmopAuthSecretRef) DeepCopy() *SmopAuthSecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopCheckout) DeepCopyInto(out *SmopCheckout) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopCheckout.
+func (in *SmopCheckout) DeepCopy() *SmopCheckout {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopCheckout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopFlatten) DeepCopyInto(out *SmopFlatten) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopFlatten.
+func (in *SmopFlatten) DeepCopy() *SmopFlatten {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopFlatten)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopImpersonation) DeepCopyInto(out *SmopImpersonation) {
+	*out = *in
+	if in.ServiceAccountRef != nil {
+		in, out := &in.ServiceAccountRef, &out.ServiceAccountRef
+		*out = new(apismetav1.ServiceAccountSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopImpersonation.
+func (in *SmopImpersonation) DeepCopy() *SmopImpersonation {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopImpersonation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopKeyFilter) DeepCopyInto(out *SmopKeyFilter) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopKeyFilter.
+func (in *SmopKeyFilter) DeepCopy() *SmopKeyFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopKeyFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SmopProvider) DeepCopyInto(out *SmopProvider) {
 	*out = *in
@@ -3605,6 +3773,106 @@ func (in *SmopProvider) DeepCopyInto(out *SmopProvider) {
 		*out = new(SmopServer)
 		**out = **in
 	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(SmopTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequeueDelays != nil {
+		in, out := &in.RequeueDelays, &out.RequeueDelays
+		*out = new(SmopRequeueDelays)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(SmopTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(SmopTransport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Impersonation != nil {
+		in, out := &in.Impersonation, &out.Impersonation
+		*out = new(SmopImpersonation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PushValidation != nil {
+		in, out := &in.PushValidation, &out.PushValidation
+		*out = new(SmopPushValidation)
+		**out = **in
+	}
+	if in.PushMetadata != nil {
+		in, out := &in.PushMetadata, &out.PushMetadata
+		*out = new(SmopPushMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedEnvironments != nil {
+		in, out := &in.AllowedEnvironments, &out.AllowedEnvironments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FindUpdatedWithin != nil {
+		in, out := &in.FindUpdatedWithin, &out.FindUpdatedWithin
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.FindPageLimit != nil {
+		in, out := &in.FindPageLimit, &out.FindPageLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Checkout != nil {
+		in, out := &in.Checkout, &out.Checkout
+		*out = new(SmopCheckout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RotateOnSync != nil {
+		in, out := &in.RotateOnSync, &out.RotateOnSync
+		*out = new(SmopRotateOnSync)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ApprovalPolling != nil {
+		in, out := &in.ApprovalPolling, &out.ApprovalPolling
+		*out = new(SmopApprovalPolling)
+		**out = **in
+	}
+	if in.Flatten != nil {
+		in, out := &in.Flatten, &out.Flatten
+		*out = new(SmopFlatten)
+		**out = **in
+	}
+	if in.KeyFilter != nil {
+		in, out := &in.KeyFilter, &out.KeyFilter
+		*out = new(SmopKeyFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WalkLimits != nil {
+		in, out := &in.WalkLimits, &out.WalkLimits
+		*out = new(SmopWalkLimits)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(SmopRetries)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EmptyListStatusCodes != nil {
+		in, out := &in.EmptyListStatusCodes, &out.EmptyListStatusCodes
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultResponseContentType != nil {
+		in, out := &in.DefaultResponseContentType, &out.DefaultResponseContentType
+		*out = new(string)
+		**out = **in
+	}
+	if in.RefreshJitterPercent != nil {
+		in, out := &in.RefreshJitterPercent, &out.RefreshJitterPercent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopProvider.
@@ -3617,6 +3885,118 @@ func (in *SmopProvider) DeepCopy() *SmopProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopPushMetadata) DeepCopyInto(out *SmopPushMetadata) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopPushMetadata.
+func (in *SmopPushMetadata) DeepCopy() *SmopPushMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopPushMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopPushValidation) DeepCopyInto(out *SmopPushValidation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopPushValidation.
+func (in *SmopPushValidation) DeepCopy() *SmopPushValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopPushValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopRequeueDelays) DeepCopyInto(out *SmopRequeueDelays) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Transient != nil {
+		in, out := &in.Transient, &out.Transient
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopRequeueDelays.
+func (in *SmopRequeueDelays) DeepCopy() *SmopRequeueDelays {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopRequeueDelays)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopRetries) DeepCopyInto(out *SmopRetries) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopRetries.
+func (in *SmopRetries) DeepCopy() *SmopRetries {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopRetries)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopRotateOnSync) DeepCopyInto(out *SmopRotateOnSync) {
+	*out = *in
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopRotateOnSync.
+func (in *SmopRotateOnSync) DeepCopy() *SmopRotateOnSync {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopRotateOnSync)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SmopServer) DeepCopyInto(out *SmopServer) {
 	*out = *in
@@ -3632,6 +4012,142 @@ func (in *SmopServer) DeepCopy() *SmopServer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopTLS) DeepCopyInto(out *SmopTLS) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.CAProvider != nil {
+		in, out := &in.CAProvider, &out.CAProvider
+		*out = new(CAProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCert != nil {
+		in, out := &in.ClientCert, &out.ClientCert
+		*out = new(apismetav1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientKey != nil {
+		in, out := &in.ClientKey, &out.ClientKey
+		*out = new(apismetav1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopTLS.
+func (in *SmopTLS) DeepCopy() *SmopTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopTimeouts) DeepCopyInto(out *SmopTimeouts) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Get != nil {
+		in, out := &in.Get, &out.Get
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.List != nil {
+		in, out := &in.List, &out.List
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DefaultDeadline != nil {
+		in, out := &in.DefaultDeadline, &out.DefaultDeadline
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopTimeouts.
+func (in *SmopTimeouts) DeepCopy() *SmopTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopTransport) DeepCopyInto(out *SmopTransport) {
+	*out = *in
+	if in.IdleConnTimeout != nil {
+		in, out := &in.IdleConnTimeout, &out.IdleConnTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TLSHandshakeTimeout != nil {
+		in, out := &in.TLSHandshakeTimeout, &out.TLSHandshakeTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxRedirects != nil {
+		in, out := &in.MaxRedirects, &out.MaxRedirects
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopTransport.
+func (in *SmopTransport) DeepCopy() *SmopTransport {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopTransport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopWalkLimits) DeepCopyInto(out *SmopWalkLimits) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopWalkLimits.
+func (in *SmopWalkLimits) DeepCopy() *SmopWalkLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopWalkLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopWorkloadIdentityAuth) DeepCopyInto(out *SmopWorkloadIdentityAuth) {
+	*out = *in
+	in.ServiceAccountRef.DeepCopyInto(&out.ServiceAccountRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopWorkloadIdentityAuth.
+func (in *SmopWorkloadIdentityAuth) DeepCopy() *SmopWorkloadIdentityAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopWorkloadIdentityAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StoreGeneratorSourceRef) DeepCopyInto(out *StoreGeneratorSourceRef) {
 	*out = *in
@@ -19,6 +19,8 @@ package v1beta1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
 )
 
 // SecretStoreRef defines which SecretStore to fetch the ExternalSecret data.
@@ -256,6 +258,10 @@ type ExternalSecretDataRemoteRef struct {
 	// Used to select a specific property of the Provider value (if a map), if supported
 	Property string `json:"property,omitempty"`
 
+	// +optional
+	// Used to select a subset of the properties of the Provider value (if a map), if supported
+	Properties []string `json:"properties,omitempty"`
+
 	// +optional
 	// Used to select a specific version of the Provider value, if supported
 	Version string `json:"version,omitempty"`
@@ -269,6 +275,46 @@ type ExternalSecretDataRemoteRef struct {
 	// Used to define a decoding Strategy
 	// +kubebuilder:default="None"
 	DecodingStrategy ExternalSecretDecodingStrategy `json:"decodingStrategy,omitempty"`
+
+	// +optional
+	// Used to convert a certificate Provider value to another format, if supported
+	FormatConversion *ExternalSecretFormatConversion `json:"formatConversion,omitempty"`
+
+	// +optional
+	// Used as the value when the Provider value does not exist, if supported and allowed by the SecretStore
+	DefaultValue *string `json:"defaultValue,omitempty"`
+
+	// +optional
+	// Used to select the scope of the Provider value, e.g. a group the secret is shared with, if supported
+	Scope string `json:"scope,omitempty"`
+}
+
+// ExternalSecretFormat is the format of a certificate and its private key.
+// +kubebuilder:validation:Enum=PEM;PKCS12;JKS
+type ExternalSecretFormat string
+
+const (
+	// ExternalSecretFormatPEM is a PEM bundle of a private key and its certificate chain.
+	ExternalSecretFormatPEM ExternalSecretFormat = "PEM"
+	// ExternalSecretFormatPKCS12 is a PKCS#12 keystore.
+	ExternalSecretFormatPKCS12 ExternalSecretFormat = "PKCS12"
+	// ExternalSecretFormatJKS is a Java KeyStore.
+	ExternalSecretFormatJKS ExternalSecretFormat = "JKS"
+)
+
+// ExternalSecretFormatConversion converts a certificate Provider value from one format to another.
+type ExternalSecretFormatConversion struct {
+	// From is the format of the Provider value. JKS is only supported as a target.
+	From ExternalSecretFormat `json:"from"`
+
+	// To is the format the Provider value is converted to.
+	To ExternalSecretFormat `json:"to"`
+
+	// PassphraseSecretRef is the passphrase of the encrypted formats: the PKCS12 value converted from,
+	// and the PKCS12 or JKS keystore converted to. It is required to convert to JKS.
+	// The Secret is read from the namespace of the ExternalSecret.
+	// +optional
+	PassphraseSecretRef *esmeta.SecretKeySelector `json:"passphraseSecretRef,omitempty"`
 }
 
 // ExternalSecretMetadataPolicy defines the policy for fetching tags/labels from provider secrets.
@@ -554,6 +600,16 @@ type ExternalSecretStatus struct {
 
 	// Binding represents a servicebinding.io Provisioned Service reference to the secret
 	Binding corev1.LocalObjectReference `json:"binding,omitempty"`
+
+	// ProviderCursors are the pagination cursors of providers spreading a dataFrom.find
+	// over several reconciles, keyed by the provider. They are reset when the ExternalSecret changes.
+	// +optional
+	ProviderCursors map[string]string `json:"providerCursors,omitempty"`
+
+	// DefaultedKeys are the remote keys whose remoteRef defaultValue was synced by the last sync
+	// in place of a missing secret, as reported by the providers.
+	// +optional
+	DefaultedKeys []string `json:"defaultedKeys,omitempty"`
 }
 
 // ExternalSecret is the schema for the external-secrets API.
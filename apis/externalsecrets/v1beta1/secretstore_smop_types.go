@@ -17,41 +17,605 @@ limitations under the License.
 package v1beta1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
 )
 
 // SmopAuthSecretRef defines a reference to a secret containing credentials for the Smop provider.
+// Each credential of a store is resolved on its own, so the token, the TLS client certificate and
+// its key may be kept in different Secrets.
 type SmopAuthSecretRef struct {
 	// The SmopToken is used for authentication.
+	// +required
 	SmopToken esmeta.SecretKeySelector `json:"smopToken"`
 }
 
 // SmopAuth defines the authentication method for the Smop provider.
+// Exactly one authentication method must be specified.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
 type SmopAuth struct {
-	APIKey SmopAuthSecretRef `json:"apikey"`
+	// APIKey authenticates using a static Smop API token stored in a Kubernetes Secret.
+	// +optional
+	APIKey *SmopAuthSecretRef `json:"apikey,omitempty"`
+
+	// WorkloadIdentity authenticates by exchanging a Kubernetes ServiceAccount token
+	// for a short-lived Smop token, which is refreshed before it expires.
+	// +optional
+	WorkloadIdentity *SmopWorkloadIdentityAuth `json:"workloadIdentity,omitempty"`
+}
+
+// SmopWorkloadIdentityAuth trades a Kubernetes ServiceAccount token for a Smop token
+// with an OAuth 2.0 token exchange (RFC 8693).
+type SmopWorkloadIdentityAuth struct {
+	// ServiceAccountRef is the ServiceAccount whose token is exchanged.
+	// Its audiences default to "smop".
+	// +required
+	ServiceAccountRef esmeta.ServiceAccountSelector `json:"serviceAccountRef"`
+
+	// TokenExchangeURL is the token exchange endpoint.
+	// Defaults to <apiUrl>/<siteId>/auth/token.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	TokenExchangeURL string `json:"tokenExchangeUrl,omitempty"`
+
+	// Audience is requested as the audience of the exchanged Smop token.
+	// +optional
+	Audience string `json:"audience,omitempty"`
 }
 
 // SmopServer defines configuration for connecting to Smop server.
 type SmopServer struct {
+	// APIURL is the base URL of the Smop API, e.g. https://api.beyondtrust.io/site.
 	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^https?://`
 	APIURL string `json:"apiUrl"`
+	// APIVersion overrides the Smop API version sent with every request.
+	// Defaults to the version targeted by the Smop API client.
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
+	// StrictAPIVersion fails client creation when the API version cannot be determined,
+	// instead of falling back to the default API version.
+	// +optional
+	StrictAPIVersion bool `json:"strictAPIVersion,omitempty"`
+	// SiteId is the Smop site (tenant) identifier the secrets belong to.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	SiteId string `json:"siteId"`
+	// BasePathPrefix is prepended to every Smop API request path, e.g. "/smop/v1"
+	// when the Smop API is served behind a gateway under a sub-path.
+	// +optional
+	BasePathPrefix string `json:"basePathPrefix,omitempty"`
+
+	// HostOverride is the address connections to the APIURL host are made to, instead of
+	// the address the host resolves to, e.g. "10.0.0.12" or "10.0.0.12:8443" in split-horizon networks.
+	// The server certificate is still verified against the APIURL host.
+	// +optional
+	HostOverride string `json:"hostOverride,omitempty"`
+}
+
+// SmopTLS defines TLS configuration used when connecting to the Smop server.
+type SmopTLS struct {
+	// CABundle is a PEM encoded CA bundle used to validate the Smop server certificate.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// CAProvider points to a Secret or ConfigMap resource that contains
+	// a PEM encoded CA bundle used to validate the Smop server certificate.
+	// +optional
+	CAProvider *CAProvider `json:"caProvider,omitempty"`
+
+	// ClientCert is a reference to a PEM encoded client certificate used for mutual TLS.
+	// Must be specified together with ClientKey.
+	// +optional
+	ClientCert *esmeta.SecretKeySelector `json:"clientCert,omitempty"`
+
+	// ClientKey is a reference to the PEM encoded private key of ClientCert.
+	// Must be specified together with ClientCert.
+	// +optional
+	ClientKey *esmeta.SecretKeySelector `json:"clientKey,omitempty"`
+
+	// InsecureSkipVerify disables verification of the Smop server certificate.
+	// DANGER: this exposes the Smop token and all secrets to anyone able to intercept the connection.
+	// Only use it in lab environments with self-signed certificates; prefer CABundle or CAProvider.
+	// It is refused unless the controller runs with --smop-allow-insecure-skip-verify.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// MinVersion is the minimum TLS version accepted from the Smop server. Defaults to 1.2.
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// CipherSuites restricts the cipher suites offered to the Smop server, by their Go crypto/tls name,
+	// e.g. TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384. They only apply to TLS 1.2, TLS 1.3 cipher suites
+	// cannot be restricted. Insecure cipher suites are refused.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+}
+
+// SmopRequeueDelays are the delays after which a sync failing with a Smop error is retried, by class of error.
+// The controller requeues the ExternalSecret after the delay of the class instead of backing off exponentially.
+// An unset delay uses the default of its class; an explicit 0s leaves errors of its class to the exponential backoff of the controller.
+type SmopRequeueDelays struct {
+	// Auth applies to rejected credentials (HTTP 401 and 403) and failed token exchanges. Defaults to 10m.
+	// +optional
+	Auth *metav1.Duration `json:"auth,omitempty"`
+
+	// RateLimit applies to rate limited requests (HTTP 429) and exhausted retry budgets. Defaults to 1m.
+	// +optional
+	RateLimit *metav1.Duration `json:"rateLimit,omitempty"`
+
+	// Transient applies to network errors, timeouts and server errors (HTTP 5xx). Defaults to 10s.
+	// +optional
+	Transient *metav1.Duration `json:"transient,omitempty"`
+}
+
+// SmopTimeouts defines timeouts for requests made to the Smop API.
+// An operation specific timeout takes precedence over the request timeout.
+type SmopTimeouts struct {
+	// Request is the default timeout for every Smop API operation.
+	// +optional
+	Request *metav1.Duration `json:"request,omitempty"`
+
+	// Get is the timeout for fetching a single secret. Defaults to 30s.
+	// +optional
+	Get *metav1.Duration `json:"get,omitempty"`
+
+	// List is the timeout for listing a folder. Defaults to 2m.
+	// +optional
+	List *metav1.Duration `json:"list,omitempty"`
+
+	// DefaultDeadline bounds operations spanning many requests, e.g. a recursive find,
+	// when the caller set no deadline. Defaults to 10m, 0 disables it.
+	// +optional
+	DefaultDeadline *metav1.Duration `json:"defaultDeadline,omitempty"`
+}
+
+// SmopTransport tunes the HTTP connection pool used for the Smop API.
+type SmopTransport struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept open to the Smop server.
+	// Defaults to 2. Raise it when many secrets are synced concurrently.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"`
+
+	// MaxConnsPerHost bounds the number of connections to the Smop server. Unlimited by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnsPerHost int `json:"maxConnsPerHost,omitempty"`
+
+	// IdleConnTimeout is how long an idle connection to the Smop server is kept open for reuse.
+	// Keep it below the idle timeout of load balancers and proxies in front of Smop. Defaults to 50s.
+	// +optional
+	IdleConnTimeout *metav1.Duration `json:"idleConnTimeout,omitempty"`
+
+	// TLSHandshakeTimeout is how long the TLS handshake of a new connection to the Smop server may take,
+	// so a stalled handshake fails before the request timeout. Defaults to 5s.
+	// +optional
+	TLSHandshakeTimeout *metav1.Duration `json:"tlsHandshakeTimeout,omitempty"`
+
+	// MaxRedirects is the number of redirects a single Smop request follows, keeping the Smop token.
+	// Only redirects to the host of the Smop server are followed, unless AllowCrossHostRedirects is set.
+	// 0 does not follow redirects. Defaults to 5.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=20
+	// +optional
+	MaxRedirects *int32 `json:"maxRedirects,omitempty"`
+
+	// AllowCrossHostRedirects follows redirects to other hosts than the Smop server, or from HTTPS to HTTP.
+	// They are refused by default so the Smop token cannot leak to another host.
+	// +optional
+	AllowCrossHostRedirects bool `json:"allowCrossHostRedirects,omitempty"`
+}
+
+// SmopImpersonation attributes Smop access to a subject other than the token owner
+// through the On-Behalf-Of header. The Smop token must be allowed to impersonate the subject.
+// At most one of Subject and ServiceAccountRef may be set.
+type SmopImpersonation struct {
+	// Subject is the user or service access is attributed to, e.g. "ci@example.com".
+	// +kubebuilder:validation:MaxLength=256
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][A-Za-z0-9@._:+-]*$`
+	// +optional
+	Subject string `json:"subject,omitempty"`
+
+	// ServiceAccountRef attributes access to a Kubernetes ServiceAccount,
+	// as the subject "system:serviceaccount:<namespace>:<name>".
+	// +optional
+	ServiceAccountRef *esmeta.ServiceAccountSelector `json:"serviceAccountRef,omitempty"`
+
+	// AllowAnnotationOverride lets an ExternalSecret choose the subject with the
+	// "smop.external-secrets.io/on-behalf-of" annotation, taking precedence over Subject and ServiceAccountRef.
+	// +optional
+	AllowAnnotationOverride bool `json:"allowAnnotationOverride,omitempty"`
+}
+
+// SmopPushValidation checks values before they are pushed to Smop.
+// By default only empty values are rejected, so a blank value never overwrites a real secret.
+type SmopPushValidation struct {
+	// AllowEmpty allows pushing empty values.
+	// +optional
+	AllowEmpty bool `json:"allowEmpty,omitempty"`
+
+	// MaxSizeBytes rejects values larger than the given size. Unlimited by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSizeBytes int32 `json:"maxSizeBytes,omitempty"`
+
+	// MinEntropyBits flags values whose Shannon entropy is below the given number of bits,
+	// e.g. "changeme" has 22 bits. Flagged values are logged unless RejectLowEntropy is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinEntropyBits int32 `json:"minEntropyBits,omitempty"`
+
+	// RejectLowEntropy rejects the values flagged by MinEntropyBits instead of only logging them.
+	// +optional
+	RejectLowEntropy bool `json:"rejectLowEntropy,omitempty"`
+}
+
+// SmopPushMetadataMode is how the metadata of pushed secrets is stored in Smop.
+// +kubebuilder:validation:Enum=Tags;Sidecar
+type SmopPushMetadataMode string
+
+const (
+	// SmopPushMetadataTags stores the metadata as tags of the pushed KV.
+	SmopPushMetadataTags SmopPushMetadataMode = "Tags"
+	// SmopPushMetadataSidecar stores the metadata in a sidecar KV named "<remote key>.eso-metadata",
+	// for Smop servers without KV tags.
+	SmopPushMetadataSidecar SmopPushMetadataMode = "Sidecar"
+)
+
+// SmopPushMetadata tags pushed secrets with their origin, so ESO-managed KVs can be audited.
+// Pushed KVs carry the built-in tags "eso.managed-by", "eso.cluster" (if ClusterName is set),
+// "eso.kind", "eso.namespace", "eso.name" and "eso.secret", identifying the PushSecret and its source Secret.
+type SmopPushMetadata struct {
+	// Mode is how the metadata is stored. Defaults to Tags.
+	// +optional
+	Mode SmopPushMetadataMode `json:"mode,omitempty"`
+
+	// ClusterName identifies the cluster in the "eso.cluster" tag.
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// Tags are added to the built-in tags. Values are Go templates with the fields
+	// .Cluster, .Kind, .Namespace, .Name, .SecretName and .RemoteKey, e.g. "{{ .Namespace }}/{{ .Name }}".
+	// Tags of the PushSecret metadata take precedence over these. Using a built-in tag key is an error.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// SmopNotFoundPolicy is how a secret referenced by an ExternalSecret but missing in Smop is handled.
+// +kubebuilder:validation:Enum=Fail;Ignore
+type SmopNotFoundPolicy string
+
+const (
+	// SmopNotFoundPolicyFail reports the secret as missing, which fails the sync unless
+	// the deletionPolicy of the ExternalSecret allows deleting the missing key.
+	SmopNotFoundPolicyFail SmopNotFoundPolicy = "Fail"
+	// SmopNotFoundPolicyIgnore leaves the missing secret out of the target secret and syncs the other keys.
+	SmopNotFoundPolicyIgnore SmopNotFoundPolicy = "Ignore"
+)
+
+// SmopEmptyValuePolicy is how an empty secret value read by an ExternalSecret is handled.
+// +kubebuilder:validation:Enum=Return;NotFound;Reject
+type SmopEmptyValuePolicy string
+
+const (
+	// SmopEmptyValuePolicyReturn syncs the empty value as is.
+	SmopEmptyValuePolicyReturn SmopEmptyValuePolicy = "Return"
+	// SmopEmptyValuePolicyNotFound reports the secret as missing, as handled by NotFoundPolicy.
+	SmopEmptyValuePolicyNotFound SmopEmptyValuePolicy = "NotFound"
+	// SmopEmptyValuePolicyReject fails the sync.
+	SmopEmptyValuePolicyReject SmopEmptyValuePolicy = "Reject"
+)
+
+// SmopKeyFilter restricts which keys are synced from Smop.
+// Patterns use glob syntax where `*` does not match `/`. Deny takes precedence over Allow.
+type SmopKeyFilter struct {
+	// Allow only syncs keys matching at least one of these patterns. All keys are allowed when empty.
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny never syncs keys matching any of these patterns.
+	// +optional
+	Deny []string `json:"deny,omitempty"`
+}
+
+// SmopCheckout configures reading secrets through the Smop checkout workflow.
+// Each secret is checked out before it is read, so it is held exclusively while the ExternalSecret syncs.
+type SmopCheckout struct {
+	// TTL is how long a checkout is held at most. Smop checks the secret back in by itself afterwards.
+	// Defaults to the Smop default.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// Hold keeps the secrets checked out until the TTL expires, instead of checking them
+	// back in once the ExternalSecret has synced.
+	// +optional
+	Hold bool `json:"hold,omitempty"`
+}
+
+// SmopRotateOnSync configures rotating secrets in Smop before they are read, so each sync gets a fresh credential.
+// Only secrets Smop can rotate on demand can be read this way.
+type SmopRotateOnSync struct {
+	// MinInterval is the minimum time between two rotations of a secret.
+	// A secret read again within it, e.g. by another ExternalSecret, is read without being rotated.
+	// Defaults to 1h.
+	// +optional
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+}
+
+// SmopWalkLimits bounds the folder trees walked recursively.
+type SmopWalkLimits struct {
+	// MaxDepth is how many levels of sub-folders are walked at most. Defaults to 32.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxDepth int32 `json:"maxDepth,omitempty"`
+
+	// MaxItems is how many KVs and folders are listed at most. Defaults to 100000.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxItems int32 `json:"maxItems,omitempty"`
+}
+
+// SmopRetries configures how Smop requests failing with a transient error are retried.
+type SmopRetries struct {
+	// MaxRetries is how often an idempotent request failing with a network error or HTTP 429, 502, 503
+	// or 504 is retried, with an exponential backoff from 200ms to 2s. Defaults to 3, 0 disables retries.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// MaxAttempts bounds the retries of all requests of a single read or write of the provider, e.g. a
+	// dataFrom.find. Requests failing once it is spent are not retried. Defaults to no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// MaxDuration bounds the time spent retrying the requests of a single read or write of the provider,
+	// including the backoff. Defaults to no limit.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+}
+
+// SmopApprovalPolling waits for the approval of secrets whose access requires one.
+type SmopApprovalPolling struct {
+	// Interval is how often a secret pending approval is read again.
+	Interval metav1.Duration `json:"interval"`
+
+	// Timeout is how long a secret pending approval is waited for before the sync is requeued.
+	// The sync of the ExternalSecret is held up meanwhile, so keep it short.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// SmopFlatten configures flattening the nested objects of a secret read by dataFrom.extract.
+// A value {"db":{"host":"h","port":5432}} is synced as the keys "db.host" and "db.port".
+type SmopFlatten struct {
+	// Separator joins the keys of the nested objects. Defaults to ".".
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	Separator string `json:"separator,omitempty"`
+
+	// MaxDepth is how many levels of nested objects are flattened at most,
+	// objects nested deeper are synced as JSON. Defaults to no limit.
+	// +kubebuilder:validation:Minimum=0
 	// +optional
-	SiteId string `json:"siteId,omitempty"`
+	MaxDepth int32 `json:"maxDepth,omitempty"`
 }
 
 // SmopProvider configures a store to sync secrets using the Smop provider.
-// Project and Config are required if not using a Service Token.
 type SmopProvider struct {
 	// Auth configures how the Operator authenticates with the Smop API
+	// +required
 	Auth *SmopAuth `json:"auth"`
 
 	// Server configures the Smop server connection details
+	// +required
+	Server *SmopServer `json:"server"`
+
+	// Timeouts configures timeouts for requests made to the Smop API.
 	// +optional
-	Server *SmopServer `json:"server,omitempty"`
+	Timeouts *SmopTimeouts `json:"timeouts,omitempty"`
 
-	// Smop folder path to retrieve secret from
+	// RequeueDelays are the delays after which a sync failing with a Smop error is retried, by class of error.
+	// +optional
+	RequeueDelays *SmopRequeueDelays `json:"requeueDelays,omitempty"`
+
+	// TLS configures the TLS settings used when connecting to the Smop server.
+	// +optional
+	TLS *SmopTLS `json:"tls,omitempty"`
+
+	// Transport tunes the HTTP connection pool used for the Smop API.
+	// +optional
+	Transport *SmopTransport `json:"transport,omitempty"`
+
+	// Impersonation attributes access to a subject other than the token owner.
+	// +optional
+	Impersonation *SmopImpersonation `json:"impersonation,omitempty"`
+
+	// PushValidation checks values before they are pushed to Smop.
+	// +optional
+	PushValidation *SmopPushValidation `json:"pushValidation,omitempty"`
+
+	// PushMetadata tags pushed secrets with the PushSecret and cluster they originate from.
+	// +optional
+	PushMetadata *SmopPushMetadata `json:"pushMetadata,omitempty"`
+
+	// Smop folder path to retrieve secret from.
+	// Defaults to the root folder when omitted.
 	// +optional
 	FolderPath string `json:"folderPath,omitempty"`
+
+	// Environment selects the Smop environment (stage) to read secrets from, e.g. "prod".
+	// It is the leading path segment, so secrets are read from <environment>/<folderPath>/<key>.
+	// Defaults to no environment, i.e. <folderPath>/<key>.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][A-Za-z0-9_.-]*$`
+	// +optional
+	Environment string `json:"environment,omitempty"`
+
+	// AllowedEnvironments restricts the environments this store may read from.
+	// When set, Environment must be one of them. All environments are allowed when empty.
+	// +optional
+	AllowedEnvironments []string `json:"allowedEnvironments,omitempty"`
+
+	// FindRecursive makes dataFrom.find descend into the sub-folders of FolderPath.
+	// A dataFrom.find by tags always does, matching secrets which carry every one of the tags.
+	// Secrets in sub-folders are keyed by their path relative to FolderPath, e.g. "db/password".
+	// +optional
+	FindRecursive bool `json:"findRecursive,omitempty"`
+
+	// FindUpdatedWithin limits dataFrom.find to the secrets changed within this window,
+	// e.g. "15m" for incremental syncs. Secrets changed earlier are left out of the result.
+	// All secrets are returned, with a warning, when Smop does not report when they were changed.
+	// +optional
+	FindUpdatedWithin *metav1.Duration `json:"findUpdatedWithin,omitempty"`
+
+	// FindPageLimit spreads dataFrom.find over several reconciles, listing at most this many pages
+	// of FolderPath per reconcile. The listing resumes in the next reconcile from a cursor kept in the
+	// ExternalSecret status, and restarts when the ExternalSecret changes, e.g. its force-sync annotation.
+	// While a listing is unfinished, the secrets found are merged into the target Secret: it holds
+	// values read in different reconciles, and keys of secrets deleted from Smop are kept until a
+	// listing completes within a single reconcile. Not supported with FindRecursive, and an ExternalSecret
+	// with a target template fails to sync while its listing is unfinished.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FindPageLimit *int32 `json:"findPageLimit,omitempty"`
+
+	// RequireFolder reports a FolderPath which does not exist, instead of treating it as an empty folder.
+	// dataFrom.find then reports no secret, and the store fails validation, which surfaces path typos.
+	// It requires the Smop server to report sub-folders in folder listings.
+	// +optional
+	RequireFolder bool `json:"requireFolder,omitempty"`
+
+	// SkipMissingProperties leaves out properties requested by remoteRef.properties which are
+	// missing from a secret, instead of failing to read the secret.
+	// +optional
+	SkipMissingProperties bool `json:"skipMissingProperties,omitempty"`
+
+	// CaseInsensitiveProperties matches remoteRef.property and remoteRef.properties with the keys
+	// of a secret ignoring case, e.g. "DB_Password" finds "db_password". A key matching exactly
+	// is always preferred; several keys matching only when ignoring case fail the read as ambiguous.
+	// +optional
+	CaseInsensitiveProperties bool `json:"caseInsensitiveProperties,omitempty"`
+
+	// ServerSideProperty asks Smop to return only the property of a remoteRef instead of the whole
+	// secret value, which keeps the other fields of large secrets off the wire. Smop servers which
+	// do not select fields return the whole value, from which the property is then extracted.
+	// +optional
+	ServerSideProperty bool `json:"serverSideProperty,omitempty"`
+
+	// NotFoundPolicy is how a secret referenced by data or dataFrom.extract but missing in Smop is handled.
+	// Defaults to Fail.
+	// +optional
+	NotFoundPolicy SmopNotFoundPolicy `json:"notFoundPolicy,omitempty"`
+
+	// EmptyValuePolicy is how a secret referenced by data whose value is empty is handled,
+	// as an empty value may be a secret which was never set rather than an empty secret.
+	// A value is empty when it has no bytes, e.g. an empty string property; whitespace or a secret
+	// without any key, synced as `{}`, are not empty. Defaults to Return.
+	// +optional
+	EmptyValuePolicy SmopEmptyValuePolicy `json:"emptyValuePolicy,omitempty"`
+
+	// AllowDefaultValues returns the remoteRef defaultValue of a data entry whose secret is missing in
+	// Smop, instead of reporting the secret missing. It is off by default, so a default cannot hide a
+	// mistyped key by accident. Defaulted keys are listed in the smop.external-secrets.io/defaulted
+	// annotation of the target secret.
+	// +optional
+	AllowDefaultValues bool `json:"allowDefaultValues,omitempty"`
+
+	// Checkout reads the secrets referenced by data and dataFrom.extract by checking them out,
+	// and checks them back in after the sync. A secret checked out by someone else fails the sync.
+	// +optional
+	Checkout *SmopCheckout `json:"checkout,omitempty"`
+
+	// RotateOnSync asks Smop to rotate the secrets referenced by data and dataFrom.extract before they
+	// are read, waiting for a rotation in progress to complete. A failed rotation fails the sync.
+	// It cannot be combined with Checkout.
+	// +optional
+	RotateOnSync *SmopRotateOnSync `json:"rotateOnSync,omitempty"`
+
+	// ApprovalPolling waits for the approval of secrets whose access requires one.
+	// By default a secret pending approval requeues the sync after the delay Smop suggests,
+	// or 15m, and the approval request ID is reported in the event of the ExternalSecret.
+	// +optional
+	ApprovalPolling *SmopApprovalPolling `json:"approvalPolling,omitempty"`
+
+	// Flatten syncs the nested objects of a secret read by dataFrom.extract as dot-joined keys,
+	// instead of syncing each top-level key with its nested objects as JSON.
+	// +optional
+	Flatten *SmopFlatten `json:"flatten,omitempty"`
+
+	// KeyFilter restricts which keys are synced by dataFrom (find and extract).
+	// +optional
+	KeyFilter *SmopKeyFilter `json:"keyFilter,omitempty"`
+
+	// WalkLimits bounds the folder tree walked by dataFrom.find, failing the sync instead of
+	// walking a tree deeper or larger than expected, e.g. below a misconfigured root folder.
+	// +optional
+	WalkLimits *SmopWalkLimits `json:"walkLimits,omitempty"`
+
+	// Retries configures how requests failing with a transient error are retried.
+	// Requests are retried 3 times when unset.
+	// +optional
+	Retries *SmopRetries `json:"retries,omitempty"`
+
+	// ReadOnly refuses every change to Smop, including deleting the secrets of
+	// a PushSecret with deletionPolicy Delete.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// RecursiveDelete makes a PushSecret with deletionPolicy Delete whose remote key is a folder
+	// delete the KVs and sub-folders it holds. By default a folder which is not empty is not deleted.
+	// +optional
+	RecursiveDelete bool `json:"recursiveDelete,omitempty"`
+
+	// DisableAliasResolution returns alias KVs as-is instead of following them to the KV they reference.
+	// +optional
+	DisableAliasResolution bool `json:"disableAliasResolution,omitempty"`
+
+	// RejectExpired fails reading a secret past the expiry reported by Smop, instead of returning its value.
+	// The earliest expiry of the secrets read is set as the smop.external-secrets.io/expires-at annotation
+	// of the Kubernetes Secret either way.
+	// +optional
+	RejectExpired bool `json:"rejectExpired,omitempty"`
+
+	// SkipValidation disables the authenticated connectivity check run when validating the store.
+	// The store status is reported as Unknown instead.
+	// +optional
+	SkipValidation bool `json:"skipValidation,omitempty"`
+
+	// EmptyListStatusCodes are status codes which, when returned while listing a folder,
+	// are treated as an empty folder instead of an error, e.g. 204 returned by some gateways.
+	// Only 2xx and 4xx codes are allowed, except 401, 403, 404 and 429.
+	// +optional
+	EmptyListStatusCodes []int `json:"emptyListStatusCodes,omitempty"`
+
+	// ValidateResponseSchema checks the shape of SMoP responses before they are decoded,
+	// reporting the missing or mistyped field. Useful to diagnose gateways that transform responses.
+	// +optional
+	ValidateResponseSchema bool `json:"validateResponseSchema,omitempty"`
+
+	// DisableContentTypeSniffing only accepts SMoP responses declaring a JSON Content-Type,
+	// where a missing Content-Type counts as DefaultResponseContentType.
+	// By default a response with a missing or application/octet-stream Content-Type
+	// is accepted if its body is a JSON object or array.
+	// +optional
+	DisableContentTypeSniffing bool `json:"disableContentTypeSniffing,omitempty"`
+
+	// DefaultResponseContentType is the Content-Type assumed for SMoP responses sent without one,
+	// e.g. by gateways stripping the header. Defaults to application/json.
+	// Set it to an empty string to assume none: such responses are then only accepted
+	// when content type sniffing finds a JSON body, or never if sniffing is disabled.
+	// +optional
+	DefaultResponseContentType *string `json:"defaultResponseContentType,omitempty"`
+
+	// RefreshJitterPercent is the maximum delay, as a percentage of the refresh interval,
+	// added to the requeue of ExternalSecrets using this store so they do not all refresh at once.
+	// Each ExternalSecret gets a stable delay derived from its UID. Not set or 0 disables jitter.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=50
+	// +optional
+	RefreshJitterPercent *int32 `json:"refreshJitterPercent,omitempty"`
 }
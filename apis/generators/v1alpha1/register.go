@@ -73,6 +73,8 @@ var (
 	ClusterGeneratorKind = reflect.TypeOf(ClusterGenerator{}).Name()
 	// CloudsmithAccessTokenKind is the kind name for CloudsmithAccessToken resource.
 	CloudsmithAccessTokenKind = reflect.TypeOf(CloudsmithAccessToken{}).Name()
+	// SmopDynamicSecretKind is the kind name for SmopDynamicSecret resource.
+	SmopDynamicSecretKind = reflect.TypeOf(SmopDynamicSecret{}).Name()
 )
 
 func init() {
@@ -108,5 +110,6 @@ func init() {
 	SchemeBuilder.Register(&VaultDynamicSecret{}, &VaultDynamicSecretList{})
 	SchemeBuilder.Register(&Webhook{}, &WebhookList{})
 	SchemeBuilder.Register(&Grafana{}, &GrafanaList{})
+	SchemeBuilder.Register(&SmopDynamicSecret{}, &SmopDynamicSecretList{})
 	SchemeBuilder.Register(&MFA{}, &MFAList{})
 }
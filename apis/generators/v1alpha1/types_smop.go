@@ -0,0 +1,67 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+)
+
+// SmopDynamicSecretSpec defines the desired spec of SmopDynamicSecret.
+type SmopDynamicSecretSpec struct {
+	// Smop provider common spec
+	Provider *esv1.SmopProvider `json:"provider"`
+
+	// Path of the SMoP credential-issuance endpoint, relative to the site's credentials API
+	// (e.g. "database/readonly").
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Parameters to send with the credential request
+	// +optional
+	Parameters *apiextensions.JSON `json:"parameters,omitempty"`
+}
+
+// SmopDynamicSecretState is the state type produced by the SmopDynamicSecret generator.
+// It identifies the issued credential so it can be revoked during cleanup.
+type SmopDynamicSecretState struct {
+	LeaseID   string `json:"leaseId,omitempty"`
+	Revocable bool   `json:"revocable,omitempty"`
+}
+
+// SmopDynamicSecret represents a generator that issues ephemeral credentials from SMoP.
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:metadata:labels="external-secrets.io/component=controller"
+// +kubebuilder:resource:scope=Namespaced,categories={external-secrets, external-secrets-generators}
+type SmopDynamicSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SmopDynamicSecretSpec `json:"spec,omitempty"`
+}
+
+// SmopDynamicSecretList contains a list of SmopDynamicSecret resources.
+// +kubebuilder:object:root=true
+type SmopDynamicSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SmopDynamicSecret `json:"items"`
+}
@@ -819,6 +819,11 @@ func (in *GeneratorSpec) DeepCopyInto(out *GeneratorSpec) {
 		*out = new(MFASpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SmopDynamicSecretSpec != nil {
+		in, out := &in.SmopDynamicSecretSpec, &out.SmopDynamicSecretSpec
+		*out = new(SmopDynamicSecretSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratorSpec.
@@ -1716,6 +1721,104 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopDynamicSecret) DeepCopyInto(out *SmopDynamicSecret) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopDynamicSecret.
+func (in *SmopDynamicSecret) DeepCopy() *SmopDynamicSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopDynamicSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SmopDynamicSecret) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopDynamicSecretList) DeepCopyInto(out *SmopDynamicSecretList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SmopDynamicSecret, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopDynamicSecretList.
+func (in *SmopDynamicSecretList) DeepCopy() *SmopDynamicSecretList {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopDynamicSecretList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SmopDynamicSecretList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopDynamicSecretSpec) DeepCopyInto(out *SmopDynamicSecretSpec) {
+	*out = *in
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(externalsecretsv1.SmopProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopDynamicSecretSpec.
+func (in *SmopDynamicSecretSpec) DeepCopy() *SmopDynamicSecretSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopDynamicSecretSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmopDynamicSecretState) DeepCopyInto(out *SmopDynamicSecretState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmopDynamicSecretState.
+func (in *SmopDynamicSecretState) DeepCopy() *SmopDynamicSecretState {
+	if in == nil {
+		return nil
+	}
+	out := new(SmopDynamicSecretState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UUID) DeepCopyInto(out *UUID) {
 	*out = *in
@@ -111,6 +111,7 @@ require (
 	github.com/ngrok/ngrok-api-go/v7 v7.6.0
 	github.com/oapi-codegen/oapi-codegen/v2 v2.5.0
 	github.com/passbolt/go-passbolt v0.7.2
+	github.com/pavlo-v-chernykh/keystore-go/v4 v4.5.0
 	github.com/previder/vault-cli v0.1.3
 	github.com/pulumi/esc-sdk/sdk v0.12.2
 	github.com/scaleway/scaleway-sdk-go v1.0.0-beta.35
@@ -112,6 +112,7 @@ const (
 	errUpdateNotFound        = "unable to update secret %s: not found"
 	errDeleteCreatePolicy    = "unable to delete secret %s: creationPolicy=%s is not Owner"
 	errSecretCachesNotSynced = "controller caches for secret %s are not in sync"
+	errFindCursorsTemplate   = "dataFrom.find returned a partial listing, which cannot be rendered with spec.target.template: list the secrets in a single reconcile, e.g. without a page limit on the store"
 
 	// event messages.
 	eventCreated                  = "secret created"
@@ -120,6 +121,8 @@ const (
 	eventDeletedOrphaned          = "secret deleted because it was orphaned"
 	eventMissingProviderSecret    = "secret does not exist at provider using spec.dataFrom[%d]"
 	eventMissingProviderSecretKey = "secret does not exist at provider using spec.dataFrom[%d] (key=%s)"
+	eventSkippedProviderSecret    = "secret does not exist at provider, skipping spec.dataFrom[%d]"
+	eventSkippedProviderSecretKey = "secret does not exist at provider, skipping spec.data[%d] (key=%s)"
 )
 
 // these errors are explicitly defined so we can detect them with `errors.Is()`.
@@ -132,6 +135,9 @@ var (
 
 const indexESTargetSecretNameField = ".metadata.targetSecretName"
 
+// findCursorResumeDelay is the delay before an unfinished dataFrom.find is resumed, see esv1.FindCursorHinter.
+const findCursorResumeDelay = 5 * time.Second
+
 // Reconciler reconciles a ExternalSecret object.
 type Reconciler struct {
 	client.Client
@@ -194,6 +200,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 			log.Error(err, "failed to cleanup managed secrets")
 			return ctrl.Result{}, err
 		}
+		notifySourceDeleted(externalSecret)
 
 		// Remove finalizer if it exists
 		if updated := controllerutil.RemoveFinalizer(externalSecret, ExternalSecretFinalizer); updated {
@@ -307,7 +314,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 	//     - it has the correct "data-hash" annotation
 	if !shouldRefresh(externalSecret) && isSecretValid(existingSecret, externalSecret) {
 		log.V(1).Info("skipping refresh")
-		return r.getRequeueResult(externalSecret), nil
+		return r.getRequeueResult(externalSecret, 0), nil
 	}
 
 	// update status of the ExternalSecret when this function returns, if needed.
@@ -350,12 +357,45 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 	}()
 
 	// retrieve the provider secret data.
-	dataMap, err := r.GetProviderSecretData(ctx, externalSecret)
+	dataMap, hints, err := r.GetProviderSecretData(ctx, externalSecret)
 	if err != nil {
 		r.markAsFailed(msgErrorGetSecretData, err, externalSecret, syncCallsError.With(resourceLabels))
+		// a provider which is temporarily unavailable tells when to try again,
+		// requeue after that delay instead of backing off from the error
+		var retryAfterErr esv1.RetryAfterError
+		if errors.As(err, &retryAfterErr) && retryAfterErr.RetryAfter() > 0 {
+			log.Info("provider is temporarily unavailable, delaying requeue", "retryAfter", retryAfterErr.RetryAfter().String(), "error", err.Error())
+			return ctrl.Result{RequeueAfter: retryAfterErr.RetryAfter()}, nil
+		}
 		return ctrl.Result{}, err
 	}
 
+	if err = validateFindCursors(externalSecret, hints.findCursors); err != nil {
+		externalSecret.Status.ProviderCursors = nil
+		r.markAsFailed(msgErrorGetSecretData, err, externalSecret, syncCallsError.With(resourceLabels))
+		return ctrl.Result{}, err
+	}
+
+	// a dataFrom.find spread over several reconciles only returns the secrets of some pages,
+	// so the data of the earlier reconciles of the listing is kept until the listing completes
+	if len(pendingFindCursors(externalSecret)) > 0 || len(hints.findCursors) > 0 {
+		dataMap, err = mergeManagedData(existingSecret, externalSecret.Name, dataMap)
+		if err != nil {
+			r.markAsFailed(msgErrorGetSecretData, err, externalSecret, syncCallsError.With(resourceLabels))
+			return ctrl.Result{}, err
+		}
+	}
+	externalSecret.Status.ProviderCursors = hints.findCursors
+	externalSecret.Status.DefaultedKeys = hints.defaultedKeys
+
+	// the update is skipped if the providers report the remote secrets at the versions of the last sync,
+	// and neither the ExternalSecret nor the target secret changed since.
+	if providerVersionUnchanged(existingSecret, externalSecret, hints.version()) {
+		log.V(1).Info("provider secret versions unchanged, skipping secret update")
+		r.markAsDone(externalSecret, start, log, esv1.ConditionReasonSecretSynced, msgSynced)
+		return r.getRequeueResult(externalSecret, hints.refreshJitter), nil
+	}
+
 	// if no data was found we can delete the secret if needed.
 	if len(dataMap) == 0 {
 		switch externalSecret.Spec.Target.DeletionPolicy {
@@ -382,11 +422,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 			}
 
 			r.markAsDone(externalSecret, start, log, esv1.ConditionReasonSecretDeleted, msgDeleted)
-			return r.getRequeueResult(externalSecret), nil
+			return r.getRequeueResult(externalSecret, hints.refreshJitter), nil
 		// In case provider secrets don't exist the kubernetes secret will be kept as-is.
 		case esv1.DeletionPolicyRetain:
 			r.markAsDone(externalSecret, start, log, esv1.ConditionReasonSecretSynced, msgSyncedRetain)
-			return r.getRequeueResult(externalSecret), nil
+			return r.getRequeueResult(externalSecret, hints.refreshJitter), nil
 		// noop, handled below
 		case esv1.DeletionPolicyMerge:
 		}
@@ -462,12 +502,31 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 				delete(secret.Data, key)
 			}
 
+			// a provider secret type hint only applies to new secrets, as the type of a Secret is immutable
+			applyTypeHint := secret.GetUID() == "" && hints.secretType != ""
+			if applyTypeHint {
+				secret.Type = hints.secretType
+			}
+
 			// WARNING: this will remove any labels or annotations managed by this ExternalSecret
 			//          so any updates to labels and annotations should be done AFTER this point
 			err = r.ApplyTemplate(ctx, externalSecret, secret, dataMap)
 			if err != nil {
 				return fmt.Errorf(errApplyTemplate, err)
 			}
+
+			// fall back to an Opaque secret if the data does not have the keys required by the hinted type
+			if applyTypeHint && !secretTypeSatisfied(secret.Type, secret.Data) {
+				secret.Type = v1.SecretTypeOpaque
+			}
+
+			// provider annotations describe the synced data, they are set after the template which would remove them
+			if len(hints.annotations) > 0 {
+				if secret.Annotations == nil {
+					secret.Annotations = make(map[string]string, len(hints.annotations))
+				}
+				maps.Copy(secret.Annotations, hints.annotations)
+			}
 		}
 
 		// we also use a label to keep track of the owner of the secret
@@ -482,6 +541,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 
 		secret.Labels[esv1.LabelManaged] = esv1.LabelManagedValue
 		secret.Annotations[esv1.AnnotationDataHash] = esutils.ObjectHash(secret.Data)
+		if version := hints.version(); version != "" {
+			secret.Annotations[esv1.AnnotationProviderVersion] = version
+		} else {
+			delete(secret.Annotations, esv1.AnnotationProviderVersion)
+		}
 
 		return nil
 	}
@@ -498,7 +562,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 			// if the secret does not exist, we wait until the next refresh interval
 			// rather than returning an error which would requeue immediately
 			r.markAsDone(externalSecret, start, log, esv1.ConditionReasonSecretMissing, msgMissing)
-			return r.getRequeueResult(externalSecret), nil
+			return r.getRequeueResult(externalSecret, hints.refreshJitter), nil
 		}
 	case esv1.CreatePolicyOrphan:
 		// create the secret, if it does not exist
@@ -558,18 +622,36 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ct
 	}
 
 	r.markAsDone(externalSecret, start, log, esv1.ConditionReasonSecretSynced, msgSynced)
-	return r.getRequeueResult(externalSecret), nil
+	return r.getRequeueResult(externalSecret, hints.refreshJitter), nil
 }
 
-// getRequeueResult create a result with requeueAfter based on the ExternalSecret refresh interval.
-func (r *Reconciler) getRequeueResult(externalSecret *esv1.ExternalSecret) ctrl.Result {
+// refreshInterval returns the refresh interval of the ExternalSecret.
+func (r *Reconciler) refreshInterval(externalSecret *esv1.ExternalSecret) time.Duration {
 	// default to the global requeue interval
 	// note, this will never be used because the CRD has a default value of 1 hour
-	refreshInterval := r.RequeueInterval
 	if externalSecret.Spec.RefreshInterval != nil {
-		refreshInterval = externalSecret.Spec.RefreshInterval.Duration
+		return externalSecret.Spec.RefreshInterval.Duration
+	}
+	return r.RequeueInterval
+}
+
+// getRequeueResult create a result with requeueAfter based on the ExternalSecret refresh interval.
+//
+// refreshJitter is the maximum delay suggested by the providers (see esv1.RefreshJitterHinter),
+// each ExternalSecret is delayed by a stable fraction of it derived from its UID.
+// The jitter only postpones the requeue: shouldRefresh still decides whether a requeued
+// ExternalSecret is refreshed, and as a jittered requeue is always past the refresh interval,
+// it always refreshes. The next requeue is computed from the new refresh time, so the offset
+// between ExternalSecrets that used to refresh together carries over to the following cycles.
+// Immediate requeues, e.g. after a conflict or an overdue refresh, are never delayed.
+func (r *Reconciler) getRequeueResult(externalSecret *esv1.ExternalSecret, refreshJitter time.Duration) ctrl.Result {
+	// an unfinished dataFrom.find resumes shortly, rather than after the refresh interval
+	if len(externalSecret.Status.ProviderCursors) > 0 {
+		return ctrl.Result{RequeueAfter: findCursorResumeDelay}
 	}
 
+	refreshInterval := r.refreshInterval(externalSecret)
+
 	// if the refresh interval is <= 0, we should not requeue
 	if refreshInterval <= 0 {
 		return ctrl.Result{}
@@ -578,8 +660,9 @@ func (r *Reconciler) getRequeueResult(externalSecret *esv1.ExternalSecret) ctrl.
 	// if the last refresh time is not set, requeue after the refresh interval
 	// note, this should not happen, as we only call this function on ExternalSecrets
 	// that have been reconciled at least once
+	jitter := jitterOffset(externalSecret.UID, refreshJitter)
 	if externalSecret.Status.RefreshTime.IsZero() {
-		return ctrl.Result{RequeueAfter: refreshInterval}
+		return ctrl.Result{RequeueAfter: refreshInterval + jitter}
 	}
 
 	timeSinceLastRefresh := time.Since(externalSecret.Status.RefreshTime.Time)
@@ -593,7 +676,7 @@ func (r *Reconciler) getRequeueResult(externalSecret *esv1.ExternalSecret) ctrl.
 
 	// if there is time remaining, requeue after the remaining time
 	if timeSinceLastRefresh < refreshInterval {
-		return ctrl.Result{RequeueAfter: refreshInterval - timeSinceLastRefresh}
+		return ctrl.Result{RequeueAfter: refreshInterval - timeSinceLastRefresh + jitter}
 	}
 
 	// otherwise, requeue immediately
@@ -621,7 +704,7 @@ func (r *Reconciler) markAsDone(externalSecret *esv1.ExternalSecret, start time.
 }
 
 func (r *Reconciler) markAsFailed(msg string, err error, externalSecret *esv1.ExternalSecret, counter prometheus.Counter) {
-	r.recorder.Event(externalSecret, v1.EventTypeWarning, esv1.ReasonUpdateFailed, err.Error())
+	r.recorder.Event(externalSecret, v1.EventTypeWarning, failureEventReason(err), err.Error())
 	conditionSynced := NewExternalSecretCondition(esv1.ExternalSecretReady, v1.ConditionFalse, esv1.ConditionReasonSecretSyncedError, msg)
 	SetExternalSecretCondition(externalSecret, *conditionSynced)
 	counter.Inc()
@@ -919,6 +1002,11 @@ func shouldSkipUnmanagedStore(ctx context.Context, namespace string, r *Reconcil
 }
 
 func shouldRefresh(es *esv1.ExternalSecret) bool {
+	// an unfinished dataFrom.find resumes regardless of the refresh policy
+	if len(pendingFindCursors(es)) > 0 {
+		return true
+	}
+
 	switch es.Spec.RefreshPolicy {
 	case esv1.RefreshPolicyCreatedOnce:
 		if es.Status.SyncedResourceVersion == "" || es.Status.RefreshTime.IsZero() {
@@ -966,6 +1054,55 @@ func shouldRefreshPeriodic(es *esv1.ExternalSecret) bool {
 	return es.Status.RefreshTime.Add(es.Spec.RefreshInterval.Duration).Before(time.Now())
 }
 
+// mergeManagedData returns dataMap on top of the data keys of the existing secret managed by the ExternalSecret `esName`.
+func mergeManagedData(existingSecret *v1.Secret, esName string, dataMap map[string][]byte) (map[string][]byte, error) {
+	if existingSecret.UID == "" {
+		return dataMap, nil
+	}
+	keys, err := getManagedDataKeys(existingSecret, esName)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string][]byte, len(keys)+len(dataMap))
+	for _, key := range keys {
+		if value, ok := existingSecret.Data[key]; ok {
+			merged[key] = value
+		}
+	}
+	maps.Copy(merged, dataMap)
+	return merged, nil
+}
+
+// providerVersionUnchanged reports whether the existing secret was synced from the remote secrets at `version`,
+// for the current generation of the ExternalSecret. An empty version is unknown and never unchanged.
+// A template reading ConfigMaps or Secrets may render differently without any change of version, so it
+// is never unchanged either.
+func providerVersionUnchanged(existingSecret *v1.Secret, es *esv1.ExternalSecret, version string) bool {
+	if version == "" || existingSecret.UID == "" || hasTemplateFromObjects(es) {
+		return false
+	}
+	if existingSecret.Annotations[esv1.AnnotationProviderVersion] != version {
+		return false
+	}
+	if es.Status.SyncedResourceVersion != ctrlutil.GetResourceVersion(es.ObjectMeta) {
+		return false
+	}
+	return isSecretValid(existingSecret, es)
+}
+
+// hasTemplateFromObjects reports whether the template of the ExternalSecret reads a ConfigMap or a Secret.
+func hasTemplateFromObjects(es *esv1.ExternalSecret) bool {
+	if es.Spec.Target.Template == nil {
+		return false
+	}
+	for _, tpl := range es.Spec.Target.Template.TemplateFrom {
+		if tpl.ConfigMap != nil || tpl.Secret != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // isSecretValid checks if the secret exists, and it's data is consistent with the calculated hash.
 func isSecretValid(existingSecret *v1.Secret, es *esv1.ExternalSecret) bool {
 	// Secret is always valid with `CreationPolicy=Orphan`
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -39,7 +40,9 @@ import (
 )
 
 // GetProviderSecretData returns the provider's secret data with the provided ExternalSecret.
-func (r *Reconciler) GetProviderSecretData(ctx context.Context, externalSecret *esv1.ExternalSecret) (providerData map[string][]byte, err error) {
+// It also returns the hints of the providers, see esv1.SecretTypeHinter, esv1.RefreshJitterHinter,
+// esv1.SecretAnnotationsHinter, esv1.SecretVersionHinter, esv1.FindCursorHinter and esv1.DefaultedKeysHinter.
+func (r *Reconciler) GetProviderSecretData(ctx context.Context, externalSecret *esv1.ExternalSecret) (providerData map[string][]byte, hints providerHints, err error) {
 	// We MUST NOT create multiple instances of a provider client (mostly due to limitations with GCP)
 	// Clientmanager keeps track of the client instances
 	// that are created during the fetching process and closes clients
@@ -49,6 +52,11 @@ func (r *Reconciler) GetProviderSecretData(ctx context.Context, externalSecret *
 		_ = mgr.Close(ctx)
 	}()
 
+	// providers may read settings scoped to a single ExternalSecret from its annotations
+	ctx = esutils.ContextWithSourceAnnotations(ctx, externalSecret.Annotations)
+	ctx = esutils.ContextWithSourceRef(ctx, esutils.SourceRef{Kind: esv1.ExtSecretKind, Namespace: externalSecret.Namespace, Name: externalSecret.Name})
+	ctx = esutils.ContextWithFindCursors(ctx, pendingFindCursors(externalSecret))
+
 	// statemanager takes care of managing the state of the generators.
 	// Since ExternalSecrets can have multiple generators, we need to keep track of the state of each generator
 	// and if one fails we need to rollback all generated values from this iteration.
@@ -75,6 +83,8 @@ func (r *Reconciler) GetProviderSecretData(ctx context.Context, externalSecret *
 		}()
 	}
 	providerData = make(map[string][]byte)
+	var typeHints secretTypeHints
+	refreshInterval := r.refreshInterval(externalSecret)
 	for i, remoteRef := range externalSecret.Spec.DataFrom {
 		var secretMap map[string][]byte
 
@@ -82,25 +92,43 @@ func (r *Reconciler) GetProviderSecretData(ctx context.Context, externalSecret *
 			secretMap, err = r.handleFindAllSecrets(ctx, externalSecret, remoteRef, mgr, genState, i)
 			if err != nil {
 				err = fmt.Errorf("error processing spec.dataFrom[%d].find, err: %w", i, err)
+			} else {
+				hints.addRefreshJitter(r.getRefreshJitter(ctx, externalSecret, remoteRef.SourceRef, mgr, refreshInterval))
+				hints.addAnnotations(r.getSecretAnnotations(ctx, externalSecret, remoteRef.SourceRef, mgr))
+				hints.addDefaultedKeys(r.getDefaultedKeys(ctx, externalSecret, remoteRef.SourceRef, mgr))
+				hints.addVersion(r.getSecretVersion(ctx, externalSecret, remoteRef.SourceRef, mgr))
+				hints.addFindCursors(r.getFindCursors(ctx, externalSecret, remoteRef.SourceRef, mgr))
 			}
 		} else if remoteRef.Extract != nil {
 			secretMap, err = r.handleExtractSecrets(ctx, externalSecret, remoteRef, mgr, genState, i)
 			if err != nil {
 				err = fmt.Errorf("error processing spec.dataFrom[%d].extract, err: %w", i, err)
+			} else {
+				typeHints.add(r.getSecretTypeHint(ctx, externalSecret, remoteRef, mgr))
+				hints.addRefreshJitter(r.getRefreshJitter(ctx, externalSecret, remoteRef.SourceRef, mgr, refreshInterval))
+				hints.addAnnotations(r.getSecretAnnotations(ctx, externalSecret, remoteRef.SourceRef, mgr))
+				hints.addDefaultedKeys(r.getDefaultedKeys(ctx, externalSecret, remoteRef.SourceRef, mgr))
+				hints.addVersion(r.getSecretVersion(ctx, externalSecret, remoteRef.SourceRef, mgr))
 			}
 		} else if remoteRef.SourceRef != nil && remoteRef.SourceRef.GeneratorRef != nil {
 			secretMap, err = r.handleGenerateSecrets(ctx, externalSecret.Namespace, remoteRef, i, genState)
 			if err != nil {
 				err = fmt.Errorf("error processing spec.dataFrom[%d].sourceRef.generatorRef, err: %w", i, err)
 			}
+			// generated values have no version
+			hints.addVersion("")
 		}
 
+		if errors.Is(err, esv1.SkipSecretErr) {
+			r.recorder.Eventf(externalSecret, v1.EventTypeNormal, esv1.ReasonMissingProviderSecret, eventSkippedProviderSecret, i)
+			continue
+		}
 		if errors.Is(err, esv1.NoSecretErr) && externalSecret.Spec.Target.DeletionPolicy != esv1.DeletionPolicyRetain {
 			r.recorder.Eventf(externalSecret, v1.EventTypeNormal, esv1.ReasonMissingProviderSecret, eventMissingProviderSecret, i)
 			continue
 		}
 		if err != nil {
-			return nil, err
+			return nil, providerHints{}, err
 		}
 
 		providerData = esutils.MergeByteMap(providerData, secretMap)
@@ -108,16 +136,135 @@ func (r *Reconciler) GetProviderSecretData(ctx context.Context, externalSecret *
 
 	for i, secretRef := range externalSecret.Spec.Data {
 		err := r.handleSecretData(ctx, externalSecret, secretRef, providerData, mgr)
+		if errors.Is(err, esv1.SkipSecretErr) {
+			r.recorder.Eventf(externalSecret, v1.EventTypeNormal, esv1.ReasonMissingProviderSecret, eventSkippedProviderSecretKey, i, secretRef.RemoteRef.Key)
+			continue
+		}
 		if errors.Is(err, esv1.NoSecretErr) && externalSecret.Spec.Target.DeletionPolicy != esv1.DeletionPolicyRetain {
 			r.recorder.Eventf(externalSecret, v1.EventTypeNormal, esv1.ReasonMissingProviderSecret, eventMissingProviderSecretKey, i, secretRef.RemoteRef.Key)
 			continue
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error processing spec.data[%d] (key: %s), err: %w", i, secretRef.RemoteRef.Key, err)
+			return nil, providerHints{}, fmt.Errorf("error processing spec.data[%d] (key: %s), err: %w", i, secretRef.RemoteRef.Key, err)
 		}
+		hints.addRefreshJitter(r.getRefreshJitter(ctx, externalSecret, toStoreGenSourceRef(secretRef.SourceRef), mgr, refreshInterval))
+		hints.addAnnotations(r.getSecretAnnotations(ctx, externalSecret, toStoreGenSourceRef(secretRef.SourceRef), mgr))
+		hints.addDefaultedKeys(r.getDefaultedKeys(ctx, externalSecret, toStoreGenSourceRef(secretRef.SourceRef), mgr))
+		hints.addVersion(r.getSecretVersion(ctx, externalSecret, toStoreGenSourceRef(secretRef.SourceRef), mgr))
+	}
+
+	hints.secretType = typeHints.result()
+	return providerData, hints, nil
+}
+
+// getSecretTypeHint returns the Secret type suggested by the provider for a dataFrom.extract remote reference.
+// There is no hint if the ExternalSecret sets the Secret type in its template, or the provider has no suggestion.
+// Failures to get a hint are logged and otherwise ignored, they must not fail the sync.
+func (r *Reconciler) getSecretTypeHint(ctx context.Context, externalSecret *esv1.ExternalSecret, remoteRef esv1.ExternalSecretDataFromRemoteRef, cmgr *secretstore.Manager) v1.SecretType {
+	if externalSecret.Spec.Target.Template != nil && externalSecret.Spec.Target.Template.Type != "" {
+		return ""
+	}
+
+	client, err := cmgr.Get(ctx, externalSecret.Spec.SecretStoreRef, externalSecret.Namespace, remoteRef.SourceRef)
+	if err != nil {
+		return ""
+	}
+	hinter, ok := client.(esv1.SecretTypeHinter)
+	if !ok {
+		return ""
 	}
 
-	return providerData, nil
+	secretType, err := hinter.SecretTypeHint(ctx, *remoteRef.Extract)
+	if err != nil {
+		r.Log.V(1).Info("unable to get secret type hint", "key", remoteRef.Extract.Key, "error", err.Error())
+		return ""
+	}
+	return secretType
+}
+
+// getRefreshJitter returns the maximum requeue jitter suggested by the provider of a remote reference.
+// Generators and providers without a hint suggest no jitter.
+func (r *Reconciler) getRefreshJitter(ctx context.Context, externalSecret *esv1.ExternalSecret, sourceRef *esv1.StoreGeneratorSourceRef, cmgr *secretstore.Manager, refreshInterval time.Duration) time.Duration {
+	if refreshInterval <= 0 || (sourceRef != nil && sourceRef.GeneratorRef != nil) {
+		return 0
+	}
+	client, err := cmgr.Get(ctx, externalSecret.Spec.SecretStoreRef, externalSecret.Namespace, sourceRef)
+	if err != nil {
+		return 0
+	}
+	hinter, ok := client.(esv1.RefreshJitterHinter)
+	if !ok {
+		return 0
+	}
+	return hinter.RefreshJitter(refreshInterval)
+}
+
+// getSecretAnnotations returns the annotations suggested by the provider of a remote reference for the target Secret.
+// Generators and providers without a hint suggest none.
+func (r *Reconciler) getSecretAnnotations(ctx context.Context, externalSecret *esv1.ExternalSecret, sourceRef *esv1.StoreGeneratorSourceRef, cmgr *secretstore.Manager) map[string]string {
+	if sourceRef != nil && sourceRef.GeneratorRef != nil {
+		return nil
+	}
+	client, err := cmgr.Get(ctx, externalSecret.Spec.SecretStoreRef, externalSecret.Namespace, sourceRef)
+	if err != nil {
+		return nil
+	}
+	hinter, ok := client.(esv1.SecretAnnotationsHinter)
+	if !ok {
+		return nil
+	}
+	return hinter.SecretAnnotations()
+}
+
+// getDefaultedKeys returns the remote keys the provider of a remote reference synced from their defaultValue.
+// Generators and providers without a hint default none.
+func (r *Reconciler) getDefaultedKeys(ctx context.Context, externalSecret *esv1.ExternalSecret, sourceRef *esv1.StoreGeneratorSourceRef, cmgr *secretstore.Manager) []string {
+	if sourceRef != nil && sourceRef.GeneratorRef != nil {
+		return nil
+	}
+	client, err := cmgr.Get(ctx, externalSecret.Spec.SecretStoreRef, externalSecret.Namespace, sourceRef)
+	if err != nil {
+		return nil
+	}
+	hinter, ok := client.(esv1.DefaultedKeysHinter)
+	if !ok {
+		return nil
+	}
+	return hinter.DefaultedKeys()
+}
+
+// getSecretVersion returns the version of the remote secrets read from the provider of a remote reference.
+// Generators and providers without a hint have no version.
+func (r *Reconciler) getSecretVersion(ctx context.Context, externalSecret *esv1.ExternalSecret, sourceRef *esv1.StoreGeneratorSourceRef, cmgr *secretstore.Manager) string {
+	if sourceRef != nil && sourceRef.GeneratorRef != nil {
+		return ""
+	}
+	client, err := cmgr.Get(ctx, externalSecret.Spec.SecretStoreRef, externalSecret.Namespace, sourceRef)
+	if err != nil {
+		return ""
+	}
+	hinter, ok := client.(esv1.SecretVersionHinter)
+	if !ok {
+		return ""
+	}
+	return hinter.SecretVersion()
+}
+
+// getFindCursors returns the pagination cursors of the unfinished dataFrom.find listings of the provider of a remote reference.
+// Generators and providers without a hint always finish their listings.
+func (r *Reconciler) getFindCursors(ctx context.Context, externalSecret *esv1.ExternalSecret, sourceRef *esv1.StoreGeneratorSourceRef, cmgr *secretstore.Manager) map[string]string {
+	if sourceRef != nil && sourceRef.GeneratorRef != nil {
+		return nil
+	}
+	client, err := cmgr.Get(ctx, externalSecret.Spec.SecretStoreRef, externalSecret.Namespace, sourceRef)
+	if err != nil {
+		return nil
+	}
+	hinter, ok := client.(esv1.FindCursorHinter)
+	if !ok {
+		return nil
+	}
+	return hinter.FindCursors()
 }
 
 func (r *Reconciler) handleSecretData(ctx context.Context, externalSecret *esv1.ExternalSecret, secretRef esv1.ExternalSecretData, providerData map[string][]byte, cmgr *secretstore.Manager) error {
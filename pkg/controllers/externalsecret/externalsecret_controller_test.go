@@ -105,6 +105,19 @@ func makeExternalSecret(policy esv1.ExternalSecretCreationPolicy) *esv1.External
 
 type testTweaks func(*testCase)
 
+// retryAfterError is a provider error implementing esv1.RetryAfterError.
+type retryAfterError struct {
+	delay time.Duration
+}
+
+func (e retryAfterError) Error() string {
+	return "provider is temporarily unavailable"
+}
+
+func (e retryAfterError) RetryAfter() time.Duration {
+	return e.delay
+}
+
 var _ = Describe("Kind=secret existence logic", func() {
 	validData := map[string][]byte{
 		"foo": []byte("value1"),
@@ -1804,6 +1817,26 @@ var _ = Describe("ExternalSecret controller", Serial, func() {
 		}
 	}
 
+	// with a provider skipping a missing key
+	// the other keys should still be synced
+	skipMissingProviderSecret := func(tc *testCase) {
+		const missingKey = "missing"
+		tc.externalSecret.Spec.Data = append(tc.externalSecret.Spec.Data, esv1.ExternalSecretData{
+			SecretKey: missingKey,
+			RemoteRef: esv1.ExternalSecretDataRemoteRef{Key: missingKey},
+		})
+		fakeProvider.GetSecretFn = func(_ context.Context, ref esv1.ExternalSecretDataRemoteRef) ([]byte, error) {
+			if ref.Key == missingKey {
+				return nil, fmt.Errorf("failed to get secret: %w", esv1.SkipSecretErr)
+			}
+			return []byte(FooValue), nil
+		}
+		tc.checkSecret = func(_ *esv1.ExternalSecret, secret *v1.Secret) {
+			Expect(string(secret.Data[targetProp])).To(Equal(FooValue))
+			Expect(secret.Data).ToNot(HaveKey(missingKey))
+		}
+	}
+
 	// with dataFrom and using a template
 	// should be put into the secret
 	syncWithDataFromTemplate := func(tc *testCase) {
@@ -1836,6 +1869,25 @@ var _ = Describe("ExternalSecret controller", Serial, func() {
 
 	// when a provider errors in a GetSecret call
 	// a error condition must be set.
+	// a provider which is temporarily unavailable delays the requeue
+	// until its retry-after hint instead of retrying right away
+	providerRetryAfterCondition := func(tc *testCase) {
+		fakeProvider.WithGetSecret(nil, retryAfterError{delay: time.Hour})
+		tc.checkCondition = func(es *esv1.ExternalSecret) bool {
+			cond := GetExternalSecretCondition(es.Status, esv1.ExternalSecretReady)
+			return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1.ConditionReasonSecretSyncedError
+		}
+		tc.checkExternalSecret = func(es *esv1.ExternalSecret) {
+			fakeProvider.WithGetSecret([]byte(FooValue), nil)
+			esKey := types.NamespacedName{Name: ExternalSecretName, Namespace: ExternalSecretNamespace}
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), esKey, es)).To(Succeed())
+				cond := GetExternalSecretCondition(es.Status, esv1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse
+			}, time.Second*3, interval).Should(BeTrue())
+		}
+	}
+
 	providerErrCondition := func(tc *testCase) {
 		const secretVal = "foobar"
 		fakeProvider.WithGetSecret(nil, errors.New("boom"))
@@ -2399,10 +2451,12 @@ var _ = Describe("ExternalSecret controller", Serial, func() {
 		Entry("should rewrite secret using dataFrom", syncAndRewriteWithDataFrom),
 		Entry("should not automatically convert from extract if rewrite is used", invalidExtractKeysErrCondition),
 		Entry("should fetch secret using dataFrom.find", syncDataFromFind),
+		Entry("should skip a missing key the provider asks to skip", skipMissingProviderSecret),
 		Entry("should rewrite secret using dataFrom.find", syncAndRewriteDataFromFind),
 		Entry("should not automatically convert from find if rewrite is used", invalidFindKeysErrCondition),
 		Entry("should fetch secret using dataFrom and a template", syncWithDataFromTemplate),
 		Entry("should set error condition when provider errors", providerErrCondition),
+		Entry("should delay the requeue when the provider asks to retry later", providerRetryAfterCondition),
 		Entry("should set an error condition when store does not exist", storeMissingErrCondition),
 		Entry("should set an error condition when store provider constructor fails", storeConstructErrCondition),
 		Entry("should not process store with mismatching controller field", ignoreMismatchController),
@@ -18,13 +18,21 @@ package externalsecret
 
 import (
 	"crypto/sha3"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
 	"github.com/external-secrets/external-secrets/pkg/controllers/externalsecret/esmetrics"
+	ctrlutil "github.com/external-secrets/external-secrets/pkg/controllers/util"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
 )
 
 // NewExternalSecretCondition a set of default options for creating an External Secret Condition.
@@ -94,3 +102,169 @@ func fqdnFor(name string) string {
 	}
 	return fqdn
 }
+
+// notifySourceDeleted tells the providers implementing esv1.SourceDeletionHandler that es was deleted.
+func notifySourceDeleted(es *esv1.ExternalSecret) {
+	for _, provider := range esv1.GetProviders() {
+		if handler, ok := provider.(esv1.SourceDeletionHandler); ok {
+			handler.SourceDeleted(es.Namespace, es.Name)
+		}
+	}
+}
+
+// providerHints holds the hints of the providers used by an ExternalSecret.
+type providerHints struct {
+	// secretType is the suggested type of the target Secret.
+	secretType v1.SecretType
+	// refreshJitter is the maximum delay to add to the requeue of the ExternalSecret.
+	refreshJitter time.Duration
+	// annotations are the suggested annotations of the target Secret.
+	annotations map[string]string
+	// versions are the versions reported by the providers, see esv1.SecretVersionHinter.
+	versions       []string
+	versionUnknown bool
+	// findCursors are the pagination cursors of unfinished dataFrom.find listings, see esv1.FindCursorHinter.
+	findCursors map[string]string
+	// defaultedKeys are the remote keys synced from their defaultValue, see esv1.DefaultedKeysHinter.
+	defaultedKeys []string
+}
+
+// addDefaultedKeys adds the defaulted remote keys of a provider, keeping the keys sorted and unique.
+func (h *providerHints) addDefaultedKeys(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	h.defaultedKeys = append(h.defaultedKeys, keys...)
+	slices.Sort(h.defaultedKeys)
+	h.defaultedKeys = slices.Compact(h.defaultedKeys)
+}
+
+// addFindCursors adds the pagination cursors of a provider.
+func (h *providerHints) addFindCursors(cursors map[string]string) {
+	if len(cursors) == 0 {
+		return
+	}
+	if h.findCursors == nil {
+		h.findCursors = make(map[string]string, len(cursors))
+	}
+	maps.Copy(h.findCursors, cursors)
+}
+
+// pendingFindCursors returns the pagination cursors persisted by the last reconcile of es.
+// They are dropped when es changed since, so a changed ExternalSecret restarts its listings.
+func pendingFindCursors(es *esv1.ExternalSecret) map[string]string {
+	if es.Status.SyncedResourceVersion != ctrlutil.GetResourceVersion(es.ObjectMeta) {
+		return nil
+	}
+	return es.Status.ProviderCursors
+}
+
+// validateFindCursors refuses the pagination cursors of unfinished dataFrom.find listings for an
+// ExternalSecret with a template: spread over several reconciles, the template would only render
+// the secrets of the current pages, on top of the output of the earlier renderings.
+func validateFindCursors(es *esv1.ExternalSecret, cursors map[string]string) error {
+	if es.Spec.Target.Template != nil && len(cursors) > 0 {
+		return errors.New(errFindCursorsTemplate)
+	}
+	return nil
+}
+
+// addVersion adds the version of the remote secrets read from a provider.
+// An empty version is unknown, which leaves the combined version unknown.
+func (h *providerHints) addVersion(version string) {
+	if version == "" {
+		h.versionUnknown = true
+		return
+	}
+	h.versions = append(h.versions, version)
+}
+
+// version returns the combined version of the remote secrets of all providers,
+// or an empty string if any provider did not report a version.
+func (h *providerHints) version() string {
+	if h.versionUnknown || len(h.versions) == 0 {
+		return ""
+	}
+	return esutils.ObjectHash(h.versions)
+}
+
+// addAnnotations adds the annotations suggested by a provider.
+// A later suggestion for the same annotation replaces an earlier one.
+func (h *providerHints) addAnnotations(annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	if h.annotations == nil {
+		h.annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(h.annotations, annotations)
+}
+
+// addRefreshJitter keeps the largest jitter suggested across providers.
+func (h *providerHints) addRefreshJitter(jitter time.Duration) {
+	h.refreshJitter = max(h.refreshJitter, jitter)
+}
+
+// jitterOffset returns a delay in [0, maxJitter) derived from uid,
+// so an ExternalSecret gets the same delay on every requeue.
+func jitterOffset(uid types.UID, maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 || uid == "" {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(uid))
+	return time.Duration(h.Sum64() % uint64(maxJitter))
+}
+
+// secretTypeHints combines the Secret type hints of multiple remote references.
+// Conflicting hints cancel each other out, so no type is suggested.
+type secretTypeHints struct {
+	hint     v1.SecretType
+	conflict bool
+}
+
+func (h *secretTypeHints) add(secretType v1.SecretType) {
+	switch {
+	case secretType == "" || h.conflict || secretType == h.hint:
+	case h.hint == "":
+		h.hint = secretType
+	default:
+		h.hint = ""
+		h.conflict = true
+	}
+}
+
+func (h *secretTypeHints) result() v1.SecretType {
+	return h.hint
+}
+
+// secretTypeSatisfied reports whether data holds the keys the API server requires for a Secret of type secretType.
+func secretTypeSatisfied(secretType v1.SecretType, data map[string][]byte) bool {
+	has := func(key string) bool {
+		_, ok := data[key]
+		return ok
+	}
+	switch secretType {
+	case v1.SecretTypeTLS:
+		return has(v1.TLSCertKey) && has(v1.TLSPrivateKeyKey)
+	case v1.SecretTypeSSHAuth:
+		return has(v1.SSHAuthPrivateKey)
+	case v1.SecretTypeBasicAuth:
+		return has(v1.BasicAuthUsernameKey) || has(v1.BasicAuthPasswordKey)
+	case v1.SecretTypeDockerConfigJson:
+		return has(v1.DockerConfigJsonKey)
+	case v1.SecretTypeDockercfg:
+		return has(v1.DockerConfigKey)
+	}
+	return true
+}
+
+// failureEventReason returns the reason of the Event recorded for err:
+// the reason reported by the provider, if any, or else UpdateFailed.
+func failureEventReason(err error) string {
+	var reasonErr esv1.FailureReasonError
+	if errors.As(err, &reasonErr) && reasonErr.FailureReason() != "" {
+		return string(reasonErr.FailureReason())
+	}
+	return esv1.ReasonUpdateFailed
+}
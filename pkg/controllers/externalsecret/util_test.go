@@ -17,14 +17,19 @@ limitations under the License.
 package externalsecret
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 
 	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	ctrlutil "github.com/external-secrets/external-secrets/pkg/controllers/util"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
 )
 
 func TestGetExternalSecretCondition(t *testing.T) {
@@ -225,3 +230,324 @@ func TestSetExternalSecretCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretTypeHints(t *testing.T) {
+	tests := []struct {
+		name  string
+		hints []corev1.SecretType
+		want  corev1.SecretType
+	}{
+		{
+			name: "no hints",
+			want: "",
+		},
+		{
+			name:  "single hint",
+			hints: []corev1.SecretType{"", corev1.SecretTypeTLS},
+			want:  corev1.SecretTypeTLS,
+		},
+		{
+			name:  "agreeing hints",
+			hints: []corev1.SecretType{corev1.SecretTypeTLS, corev1.SecretTypeTLS},
+			want:  corev1.SecretTypeTLS,
+		},
+		{
+			name:  "conflicting hints",
+			hints: []corev1.SecretType{corev1.SecretTypeTLS, corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h secretTypeHints
+			for _, hint := range tt.hints {
+				h.add(hint)
+			}
+			if got := h.result(); got != tt.want {
+				t.Errorf("secretTypeHints.result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderHintsAnnotations(t *testing.T) {
+	var h providerHints
+	h.addAnnotations(nil)
+	if h.annotations != nil {
+		t.Fatalf("providerHints.annotations = %v, want nil", h.annotations)
+	}
+
+	h.addAnnotations(map[string]string{"a": "1", "b": "1"})
+	h.addAnnotations(map[string]string{"b": "2"})
+	want := map[string]string{"a": "1", "b": "2"}
+	if diff := cmp.Diff(h.annotations, want); diff != "" {
+		t.Errorf("(-got, +want)\n%s", diff)
+	}
+}
+
+func TestProviderHintsVersion(t *testing.T) {
+	var h providerHints
+	if got := h.version(); got != "" {
+		t.Fatalf("version() without hints = %q, want empty", got)
+	}
+
+	h.addVersion("a")
+	h.addVersion("b")
+	ab := h.version()
+	if ab == "" {
+		t.Fatal("version() = empty, want the combined version")
+	}
+
+	var changed providerHints
+	changed.addVersion("a")
+	changed.addVersion("c")
+	if changed.version() == ab {
+		t.Error("version() must change when a provider version changes")
+	}
+
+	h.addVersion("")
+	if got := h.version(); got != "" {
+		t.Errorf("version() with an unknown provider version = %q, want empty", got)
+	}
+}
+
+func TestProviderVersionUnchanged(t *testing.T) {
+	es := &esv1.ExternalSecret{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	es.Status.SyncedResourceVersion = ctrlutil.GetResourceVersion(es.ObjectMeta)
+	data := map[string][]byte{"password": []byte("s3cr3t")}
+	secret := func(version string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:    "uid",
+				Labels: map[string]string{esv1.LabelManaged: esv1.LabelManagedValue},
+				Annotations: map[string]string{
+					esv1.AnnotationDataHash:        esutils.ObjectHash(data),
+					esv1.AnnotationProviderVersion: version,
+				},
+			},
+			Data: data,
+		}
+	}
+	changedES := es.DeepCopy()
+	changedES.Generation = 3
+	tampered := secret("v1")
+	tampered.Data = map[string][]byte{"password": []byte("changed")}
+	literalES := es.DeepCopy()
+	literalES.Spec.Target.Template = &esv1.ExternalSecretTemplate{TemplateFrom: []esv1.TemplateFrom{{Literal: ptr.To("{{ .password }}")}}}
+	configMapES := es.DeepCopy()
+	configMapES.Spec.Target.Template = &esv1.ExternalSecretTemplate{TemplateFrom: []esv1.TemplateFrom{{ConfigMap: &esv1.TemplateRef{Name: "tpl"}}}}
+	secretES := es.DeepCopy()
+	secretES.Spec.Target.Template = &esv1.ExternalSecretTemplate{TemplateFrom: []esv1.TemplateFrom{{Literal: ptr.To("x")}, {Secret: &esv1.TemplateRef{Name: "tpl"}}}}
+
+	tests := []struct {
+		name    string
+		secret  *corev1.Secret
+		es      *esv1.ExternalSecret
+		version string
+		want    bool
+	}{
+		{name: "unchanged version", secret: secret("v1"), es: es, version: "v1", want: true},
+		{name: "changed version", secret: secret("v1"), es: es, version: "v2"},
+		{name: "unknown version", secret: secret(""), es: es},
+		{name: "missing secret", secret: &corev1.Secret{}, es: es, version: "v1"},
+		{name: "changed ExternalSecret", secret: secret("v1"), es: changedES, version: "v1"},
+		{name: "changed secret data", secret: tampered, es: es, version: "v1"},
+		{name: "literal template", secret: secret("v1"), es: literalES, version: "v1", want: true},
+		{name: "template from ConfigMap", secret: secret("v1"), es: configMapES, version: "v1"},
+		{name: "template from Secret", secret: secret("v1"), es: secretES, version: "v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := providerVersionUnchanged(tt.secret, tt.es, tt.version); got != tt.want {
+				t.Errorf("providerVersionUnchanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFindCursors(t *testing.T) {
+	es := &esv1.ExternalSecret{}
+	templated := &esv1.ExternalSecret{Spec: esv1.ExternalSecretSpec{Target: esv1.ExternalSecretTarget{Template: &esv1.ExternalSecretTemplate{}}}}
+	cursors := map[string]string{"smop:apps": "page-3"}
+
+	if err := validateFindCursors(es, cursors); err != nil {
+		t.Errorf("validateFindCursors() without template = %v, want nil", err)
+	}
+	if err := validateFindCursors(templated, nil); err != nil {
+		t.Errorf("validateFindCursors() of a complete listing = %v, want nil", err)
+	}
+	if err := validateFindCursors(templated, cursors); err == nil {
+		t.Error("validateFindCursors() with template = nil, want error")
+	}
+}
+
+func TestPendingFindCursors(t *testing.T) {
+	es := &esv1.ExternalSecret{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	es.Status.SyncedResourceVersion = ctrlutil.GetResourceVersion(es.ObjectMeta)
+	es.Status.ProviderCursors = map[string]string{"smop:apps": "page-3"}
+
+	if diff := cmp.Diff(pendingFindCursors(es), es.Status.ProviderCursors); diff != "" {
+		t.Errorf("(-got, +want)\n%s", diff)
+	}
+
+	es.Annotations = map[string]string{esv1.AnnotationForceSync: "now"}
+	if got := pendingFindCursors(es); got != nil {
+		t.Errorf("pendingFindCursors() of a changed ExternalSecret = %v, want nil", got)
+	}
+}
+
+func TestProviderHintsFindCursors(t *testing.T) {
+	var h providerHints
+	h.addFindCursors(nil)
+	if h.findCursors != nil {
+		t.Fatalf("providerHints.findCursors = %v, want nil", h.findCursors)
+	}
+
+	h.addFindCursors(map[string]string{"a": "1"})
+	h.addFindCursors(map[string]string{"b": "2"})
+	want := map[string]string{"a": "1", "b": "2"}
+	if diff := cmp.Diff(h.findCursors, want); diff != "" {
+		t.Errorf("(-got, +want)\n%s", diff)
+	}
+}
+
+func TestProviderHintsDefaultedKeys(t *testing.T) {
+	var h providerHints
+	h.addDefaultedKeys(nil)
+	if h.defaultedKeys != nil {
+		t.Fatalf("providerHints.defaultedKeys = %v, want nil", h.defaultedKeys)
+	}
+
+	h.addDefaultedKeys([]string{"redis", "db"})
+	h.addDefaultedKeys([]string{"db", "api"})
+	want := []string{"api", "db", "redis"}
+	if diff := cmp.Diff(h.defaultedKeys, want); diff != "" {
+		t.Errorf("(-got, +want)\n%s", diff)
+	}
+}
+
+func TestSecretTypeSatisfied(t *testing.T) {
+	tests := []struct {
+		name       string
+		secretType corev1.SecretType
+		data       map[string][]byte
+		want       bool
+	}{
+		{
+			name:       "tls with cert and key",
+			secretType: corev1.SecretTypeTLS,
+			data:       map[string][]byte{corev1.TLSCertKey: nil, corev1.TLSPrivateKeyKey: nil},
+			want:       true,
+		},
+		{
+			name:       "tls without key",
+			secretType: corev1.SecretTypeTLS,
+			data:       map[string][]byte{corev1.TLSCertKey: nil},
+			want:       false,
+		},
+		{
+			name:       "opaque",
+			secretType: corev1.SecretTypeOpaque,
+			data:       map[string][]byte{"foo": nil},
+			want:       true,
+		},
+		{
+			name:       "basic auth with password only",
+			secretType: corev1.SecretTypeBasicAuth,
+			data:       map[string][]byte{corev1.BasicAuthPasswordKey: nil},
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secretTypeSatisfied(tt.secretType, tt.data); got != tt.want {
+				t.Errorf("secretTypeSatisfied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJitterOffset(t *testing.T) {
+	maxJitter := 6 * time.Minute
+	if got := jitterOffset("", maxJitter); got != 0 {
+		t.Errorf("jitterOffset() without uid = %v, want 0", got)
+	}
+	if got := jitterOffset("uid", 0); got != 0 {
+		t.Errorf("jitterOffset() without jitter = %v, want 0", got)
+	}
+
+	offsets := map[time.Duration]struct{}{}
+	for i := range 100 {
+		uid := types.UID(fmt.Sprintf("uid-%d", i))
+		got := jitterOffset(uid, maxJitter)
+		if got < 0 || got >= maxJitter {
+			t.Fatalf("jitterOffset(%q) = %v, want in [0, %v)", uid, got, maxJitter)
+		}
+		if again := jitterOffset(uid, maxJitter); again != got {
+			t.Fatalf("jitterOffset(%q) is not stable: %v != %v", uid, got, again)
+		}
+		offsets[got] = struct{}{}
+	}
+	if len(offsets) < 90 {
+		t.Errorf("jitterOffset() spread 100 uids over %d offsets", len(offsets))
+	}
+}
+
+func TestGetRequeueResultJitter(t *testing.T) {
+	r := &Reconciler{}
+	es := &esv1.ExternalSecret{
+		ObjectMeta: metav1.ObjectMeta{UID: "uid"},
+		Spec: esv1.ExternalSecretSpec{
+			RefreshInterval: &metav1.Duration{Duration: time.Hour},
+		},
+		Status: esv1.ExternalSecretStatus{
+			RefreshTime: metav1.Now(),
+		},
+	}
+	maxJitter := 6 * time.Minute
+
+	want := jitterOffset(es.UID, maxJitter)
+	delta := r.getRequeueResult(es, maxJitter).RequeueAfter - r.getRequeueResult(es, 0).RequeueAfter
+	if (delta - want).Abs() > time.Second {
+		t.Errorf("getRequeueResult() jitter = %v, want %v", delta, want)
+	}
+
+	es.Status.RefreshTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	if res := r.getRequeueResult(es, maxJitter); !res.Requeue || res.RequeueAfter != 0 {
+		t.Errorf("getRequeueResult() for an overdue refresh = %+v, want an immediate requeue", res)
+	}
+}
+
+// failureReasonError is a provider error implementing esv1.FailureReasonError.
+type failureReasonError struct {
+	reason esv1.ProviderFailureReason
+}
+
+func (e failureReasonError) Error() string {
+	return "provider failed"
+}
+
+func (e failureReasonError) FailureReason() esv1.ProviderFailureReason {
+	return e.reason
+}
+
+func TestFailureEventReason(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want string
+	}{
+		"plain error":     {err: fmt.Errorf("boom"), want: esv1.ReasonUpdateFailed},
+		"provider reason": {err: failureReasonError{reason: esv1.ProviderFailureReasonAuth}, want: "ProviderAuthFailed"},
+		"wrapped reason":  {err: fmt.Errorf("failed to get secret: %w", failureReasonError{reason: esv1.ProviderFailureReasonNotFound}), want: "ProviderSecretNotFound"},
+		"empty reason":    {err: failureReasonError{}, want: esv1.ReasonUpdateFailed},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := failureEventReason(tc.err); got != tc.want {
+				t.Errorf("failureEventReason() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
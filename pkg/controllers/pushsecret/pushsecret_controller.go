@@ -393,6 +393,9 @@ func (r *Reconciler) DeleteSecretFromStore(ctx context.Context, client esv1.Secr
 // defined update policies and conversion strategies.
 func (r *Reconciler) PushSecretToProviders(ctx context.Context, stores map[esapi.PushSecretStoreRef]esv1.GenericStore, ps esapi.PushSecret, secret *v1.Secret, mgr *secretstore.Manager) (esapi.SyncedPushSecretsMap, error) {
 	out := make(esapi.SyncedPushSecretsMap)
+	// providers may tag pushed secrets with the PushSecret they originate from
+	ctx = esutils.ContextWithSourceRef(ctx, esutils.SourceRef{Kind: esapi.PushSecretKind, Namespace: ps.Namespace, Name: ps.Name})
+	ctx = esutils.ContextWithSourceAnnotations(ctx, ps.Annotations)
 	for ref, store := range stores {
 		out, err := r.handlePushSecretDataForStore(ctx, ps, secret, out, mgr, store.GetName(), ref.Kind)
 		if err != nil {
@@ -302,6 +302,17 @@ func clusterGeneratorToVirtual(gen *genv1alpha1.ClusterGenerator) (client.Object
 			},
 			Spec: *gen.Spec.Generator.MFASpec,
 		}, nil
+	case genv1alpha1.GeneratorKindSmopDynamicSecret:
+		if gen.Spec.Generator.SmopDynamicSecretSpec == nil {
+			return nil, fmt.Errorf("when kind is %s, SmopDynamicSecretSpec must be set", gen.Spec.Kind)
+		}
+		return &genv1alpha1.SmopDynamicSecret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: genv1alpha1.SchemeGroupVersion.String(),
+				Kind:       genv1alpha1.SmopDynamicSecretKind,
+			},
+			Spec: *gen.Spec.Generator.SmopDynamicSecretSpec,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unknown kind %s", gen.Spec.Kind)
 	}
@@ -203,9 +203,10 @@ func RewriteRegexp(operation esv1.ExternalSecretRewriteRegexp, in map[string][]b
 	if err != nil {
 		return nil, fmt.Errorf("regexp failed with failed to compile: %w", err)
 	}
-	for key, value := range in {
+	// keys are rewritten in sorted order, so a collision always keeps the value of the same key
+	for _, key := range slices.Sorted(maps.Keys(in)) {
 		newKey := re.ReplaceAllString(key, operation.Target)
-		out[newKey] = value
+		out[newKey] = in[key]
 	}
 	return out, nil
 }
@@ -219,12 +220,13 @@ func RewriteTransform(operation esv1.ExternalSecretRewriteTransform, in map[stri
 		return nil, fmt.Errorf("transform failed with failed to parse template: %w", err)
 	}
 
-	for key, value := range in {
+	// keys are rewritten in sorted order, so a collision always keeps the value of the same key
+	for _, key := range slices.Sorted(maps.Keys(in)) {
 		var buf bytes.Buffer
 		if err := tmpl.Execute(&buf, map[string]string{"value": key}); err != nil {
 			return nil, fmt.Errorf("transform failed with failed to execute template for key %q: %w", key, err)
 		}
-		out[buf.String()] = value
+		out[buf.String()] = in[key]
 	}
 
 	return out, nil
@@ -545,6 +547,59 @@ func NetworkValidate(endpoint string, timeout time.Duration) error {
 	return nil
 }
 
+// sourceAnnotationsKey is the context key of the annotations of the resource provider calls are made for.
+type sourceAnnotationsKey struct{}
+
+// ContextWithSourceAnnotations returns a copy of ctx carrying the annotations of the resource,
+// e.g. an ExternalSecret, that provider calls made with ctx are made for.
+func ContextWithSourceAnnotations(ctx context.Context, annotations map[string]string) context.Context {
+	return context.WithValue(ctx, sourceAnnotationsKey{}, annotations)
+}
+
+// SourceAnnotation returns the annotation `key` of the resource provider calls made with ctx are made for.
+func SourceAnnotation(ctx context.Context, key string) (string, bool) {
+	annotations, _ := ctx.Value(sourceAnnotationsKey{}).(map[string]string)
+	value, ok := annotations[key]
+	return value, ok
+}
+
+// SourceRef identifies the resource, e.g. an ExternalSecret or a PushSecret, provider calls are made for.
+type SourceRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// sourceRefKey is the context key of the SourceRef of the resource provider calls are made for.
+type sourceRefKey struct{}
+
+// ContextWithSourceRef returns a copy of ctx carrying the resource that provider calls made with ctx are made for.
+func ContextWithSourceRef(ctx context.Context, ref SourceRef) context.Context {
+	return context.WithValue(ctx, sourceRefKey{}, ref)
+}
+
+// SourceRefFromContext returns the resource provider calls made with ctx are made for.
+func SourceRefFromContext(ctx context.Context) (SourceRef, bool) {
+	ref, ok := ctx.Value(sourceRefKey{}).(SourceRef)
+	return ref, ok
+}
+
+// findCursorsKey is the context key of the pagination cursors of the resource provider calls are made for.
+type findCursorsKey struct{}
+
+// ContextWithFindCursors returns a copy of ctx carrying the pagination cursors persisted by the resource,
+// e.g. an ExternalSecret, that provider calls made with ctx are made for. See esv1.FindCursorHinter.
+func ContextWithFindCursors(ctx context.Context, cursors map[string]string) context.Context {
+	return context.WithValue(ctx, findCursorsKey{}, cursors)
+}
+
+// FindCursor returns the pagination cursor `key` persisted by the resource provider calls made with ctx are made for,
+// or an empty cursor to start from the beginning.
+func FindCursor(ctx context.Context, key string) string {
+	cursors, _ := ctx.Value(findCursorsKey{}).(map[string]string)
+	return cursors[key]
+}
+
 // Deref returns the value pointed to by v, or the zero value if v is nil.
 func Deref[V any](v *V) V {
 	if v == nil {
@@ -17,6 +17,7 @@ limitations under the License.
 package esutils
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"reflect"
@@ -492,6 +493,47 @@ func TestRewrite(t *testing.T) {
 				"merged": []byte(`{"host":"dba.example.com","pass":"yolo"}`),
 			},
 		},
+		{
+			name: "regexp collision keeps the last key in sorted order",
+			args: args{
+				operations: []esv1.ExternalSecretRewrite{
+					{
+						Regexp: &esv1.ExternalSecretRewriteRegexp{
+							Source: "^(dev|prod)-",
+							Target: "",
+						},
+					},
+				},
+				in: map[string][]byte{
+					"dev-password":  []byte("dev"),
+					"prod-password": []byte("prod"),
+					"dev-user":      []byte("app"),
+				},
+			},
+			want: map[string][]byte{
+				"password": []byte("prod"),
+				"user":     []byte("app"),
+			},
+		},
+		{
+			name: "transform collision keeps the last key in sorted order",
+			args: args{
+				operations: []esv1.ExternalSecretRewrite{
+					{
+						Transform: &esv1.ExternalSecretRewriteTransform{
+							Template: `{{ .value | lower }}`,
+						},
+					},
+				},
+				in: map[string][]byte{
+					"PASSWORD": []byte("upper"),
+					"password": []byte("lower"),
+				},
+			},
+			want: map[string][]byte{
+				"password": []byte("lower"),
+			},
+		},
 		{
 			name: "replace of a single key",
 			args: args{
@@ -1469,3 +1511,34 @@ func TestExtractJWTExpiration(t *testing.T) {
 		t.Errorf("Expected expiration to be '1700000000', got %s", exp)
 	}
 }
+
+func TestSourceAnnotation(t *testing.T) {
+	ctx := ContextWithSourceAnnotations(context.Background(), map[string]string{"example.com/key": "value"})
+
+	value, ok := SourceAnnotation(ctx, "example.com/key")
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+
+	_, ok = SourceAnnotation(ctx, "example.com/missing")
+	assert.False(t, ok)
+
+	_, ok = SourceAnnotation(context.Background(), "example.com/key")
+	assert.False(t, ok)
+}
+
+func TestFindCursor(t *testing.T) {
+	ctx := ContextWithFindCursors(context.Background(), map[string]string{"provider:apps": "page-2"})
+	assert.Equal(t, "page-2", FindCursor(ctx, "provider:apps"))
+	assert.Empty(t, FindCursor(ctx, "provider:other"))
+	assert.Empty(t, FindCursor(context.Background(), "provider:apps"))
+}
+
+func TestSourceRefFromContext(t *testing.T) {
+	want := SourceRef{Kind: "PushSecret", Namespace: "apps", Name: "db"}
+	got, ok := SourceRefFromContext(ContextWithSourceRef(context.Background(), want))
+	assert.True(t, ok)
+	assert.Equal(t, want, got)
+
+	_, ok = SourceRefFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package smop provides functionality for generating ephemeral credentials from SMoP.
+package smop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	genv1alpha1 "github.com/external-secrets/external-secrets/apis/generators/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	provider "github.com/external-secrets/external-secrets/pkg/provider/smop"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// Generator implements credential generation using SMoP's credential-issuance endpoints.
+type Generator struct{}
+
+const (
+	errNoSpec       = "no config spec provided"
+	errParseSpec    = "unable to parse spec: %w"
+	errParseState   = "unable to parse state: %w"
+	errSmopClient   = "unable to setup SMoP client: %w"
+	errIssue        = "unable to issue SMoP credential: %w"
+	errRevoke       = "unable to revoke SMoP credential: %w"
+	errNoProvider   = "no SMoP provider config in spec"
+	errEmptyResult  = "empty credential returned by SMoP"
+	errParseParams  = "unable to parse parameters: %w"
+	errConvertValue = "unable to convert credential value %q: %w"
+)
+
+// Generate requests a new credential from the SMoP credential-issuance endpoint.
+// The returned state identifies the credential's lease so it can be revoked during cleanup.
+func (g *Generator) Generate(ctx context.Context, jsonSpec *apiextensions.JSON, kube client.Client, namespace string) (map[string][]byte, genv1alpha1.GeneratorProviderState, error) {
+	spec, err := parseSpec(jsonSpec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := make(map[string]any)
+	if spec.Spec.Parameters != nil {
+		if err := json.Unmarshal(spec.Spec.Parameters.Raw, &params); err != nil {
+			return nil, nil, fmt.Errorf(errParseParams, err)
+		}
+	}
+
+	cl, err := provider.NewGeneratorClient(ctx, kube, spec.Spec.Provider, namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errSmopClient, err)
+	}
+
+	cred, err := cl.IssueCredential(ctx, spec.Spec.Path, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errIssue, err)
+	}
+
+	return prepareResponse(cred)
+}
+
+// Cleanup revokes the credential issued during Generate.
+// Credentials without a lease or which SMoP reports as not revocable are left to expire.
+func (g *Generator) Cleanup(ctx context.Context, jsonSpec *apiextensions.JSON, previousStatus genv1alpha1.GeneratorProviderState, kube client.Client, namespace string) error {
+	if previousStatus == nil {
+		return nil
+	}
+
+	var state genv1alpha1.SmopDynamicSecretState
+	if err := json.Unmarshal(previousStatus.Raw, &state); err != nil {
+		return fmt.Errorf(errParseState, err)
+	}
+	if state.LeaseID == "" || !state.Revocable {
+		return nil
+	}
+
+	spec, err := parseSpec(jsonSpec)
+	if err != nil {
+		return err
+	}
+
+	cl, err := provider.NewGeneratorClient(ctx, kube, spec.Spec.Provider, namespace)
+	if err != nil {
+		return fmt.Errorf(errSmopClient, err)
+	}
+
+	if err := cl.RevokeCredential(ctx, state.LeaseID); err != nil {
+		return fmt.Errorf(errRevoke, err)
+	}
+	return nil
+}
+
+func prepareResponse(cred *smopclient.Credential) (map[string][]byte, genv1alpha1.GeneratorProviderState, error) {
+	if cred == nil || len(cred.Data) == 0 {
+		return nil, nil, errors.New(errEmptyResult)
+	}
+
+	response := make(map[string][]byte, len(cred.Data))
+	for k := range cred.Data {
+		v, err := esutils.GetByteValueFromMap(cred.Data, k)
+		if err != nil {
+			return nil, nil, fmt.Errorf(errConvertValue, k, err)
+		}
+		response[k] = v
+	}
+
+	if cred.LeaseID == "" {
+		return response, nil, nil
+	}
+
+	stateJSON, err := json.Marshal(&genv1alpha1.SmopDynamicSecretState{
+		LeaseID:   cred.LeaseID,
+		Revocable: cred.Revocable,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return response, &apiextensions.JSON{Raw: stateJSON}, nil
+}
+
+func parseSpec(jsonSpec *apiextensions.JSON) (*genv1alpha1.SmopDynamicSecret, error) {
+	if jsonSpec == nil {
+		return nil, errors.New(errNoSpec)
+	}
+	var spec genv1alpha1.SmopDynamicSecret
+	if err := yaml.Unmarshal(jsonSpec.Raw, &spec); err != nil {
+		return nil, fmt.Errorf(errParseSpec, err)
+	}
+	if spec.Spec.Provider == nil {
+		return nil, errors.New(errNoProvider)
+	}
+	return &spec, nil
+}
+
+func init() {
+	genv1alpha1.Register(genv1alpha1.SmopDynamicSecretKind, &Generator{})
+}
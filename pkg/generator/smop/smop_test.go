@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	genv1alpha1 "github.com/external-secrets/external-secrets/apis/generators/v1alpha1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+)
+
+const testNamespace = "default"
+
+func makeSpec(t *testing.T, apiURL, credPath string) *apiextensions.JSON {
+	t.Helper()
+
+	gen := genv1alpha1.SmopDynamicSecret{
+		Spec: genv1alpha1.SmopDynamicSecretSpec{
+			Provider: &esv1.SmopProvider{
+				Auth: &esv1.SmopAuth{
+					APIKey: &esv1.SmopAuthSecretRef{
+						SmopToken: esmeta.SecretKeySelector{Name: "smop-token", Key: "token"},
+					},
+				},
+				Server: &esv1.SmopServer{APIURL: apiURL, SiteId: "site"},
+			},
+			Path:       credPath,
+			Parameters: &apiextensions.JSON{Raw: []byte(`{"ttl":"1h"}`)},
+		},
+	}
+	raw, err := json.Marshal(gen)
+	require.NoError(t, err)
+	return &apiextensions.JSON{Raw: raw}
+}
+
+func TestGenerateAndCleanup(t *testing.T) {
+	var revoked []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/site/secrets/credentials/database/readonly":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"data":{"username":"u","password":"p","port":5432},"leaseId":"lease-1","revocable":true}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/site/secrets/credentials/database/static":
+			_, _ = w.Write([]byte(`{"data":{"username":"u","password":"p"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/site/secrets/credentials/database/empty":
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		case r.Method == http.MethodDelete:
+			revoked = append(revoked, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	kube := clientfake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "smop-token", Namespace: testNamespace},
+		Data:       map[string][]byte{"token": []byte("t0k3n")},
+	}).Build()
+
+	g := &Generator{}
+	ctx := context.Background()
+
+	t.Run("revocable lease", func(t *testing.T) {
+		revoked = nil
+		spec := makeSpec(t, srv.URL, "database/readonly")
+
+		data, state, err := g.Generate(ctx, spec, kube, testNamespace)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{
+			"username": []byte("u"),
+			"password": []byte("p"),
+			"port":     []byte("5432"),
+		}, data)
+		require.NotNil(t, state)
+		assert.JSONEq(t, `{"leaseId":"lease-1","revocable":true}`, string(state.Raw))
+
+		require.NoError(t, g.Cleanup(ctx, spec, state, kube, testNamespace))
+		assert.Equal(t, []string{"/site/secrets/leases/lease-1"}, revoked)
+	})
+
+	t.Run("no lease", func(t *testing.T) {
+		revoked = nil
+		spec := makeSpec(t, srv.URL, "database/static")
+
+		data, state, err := g.Generate(ctx, spec, kube, testNamespace)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"username": []byte("u"), "password": []byte("p")}, data)
+		assert.Nil(t, state)
+
+		require.NoError(t, g.Cleanup(ctx, spec, state, kube, testNamespace))
+		assert.Empty(t, revoked)
+	})
+
+	t.Run("not revocable", func(t *testing.T) {
+		revoked = nil
+		spec := makeSpec(t, srv.URL, "database/readonly")
+		state := &apiextensions.JSON{Raw: []byte(`{"leaseId":"lease-1"}`)}
+
+		require.NoError(t, g.Cleanup(ctx, spec, state, kube, testNamespace))
+		assert.Empty(t, revoked)
+	})
+
+	t.Run("empty credential", func(t *testing.T) {
+		_, _, err := g.Generate(ctx, makeSpec(t, srv.URL, "database/empty"), kube, testNamespace)
+		assert.ErrorContains(t, err, errEmptyResult)
+	})
+
+	t.Run("missing role", func(t *testing.T) {
+		_, _, err := g.Generate(ctx, makeSpec(t, srv.URL, "database/missing"), kube, testNamespace)
+		assert.ErrorContains(t, err, "unable to issue SMoP credential")
+	})
+
+	t.Run("nil spec", func(t *testing.T) {
+		_, _, err := g.Generate(ctx, nil, kube, testNamespace)
+		assert.EqualError(t, err, errNoSpec)
+	})
+
+	t.Run("no provider", func(t *testing.T) {
+		_, _, err := g.Generate(ctx, &apiextensions.JSON{Raw: []byte(`{"spec":{"path":"x"}}`)}, kube, testNamespace)
+		assert.EqualError(t, err, errNoProvider)
+	})
+}
@@ -0,0 +1,42 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+// withAccountFields returns kv with the username and account of a privileged-account KV added to its secret,
+// see smopclient.KVMetadata.AccountFields, so an ExternalSecret can select them like its password.
+// Keys already present in the secret are kept. They are only added to secrets read for an ExternalSecret,
+// never to the KV PushSecret compares and writes back.
+func withAccountFields(kv *cg.KV, fields map[string]string) *cg.KV {
+	if kv == nil || len(fields) == 0 {
+		return kv
+	}
+
+	secret := make(cg.RedactedMap, len(kv.Secret)+len(fields))
+	for key, value := range kv.Secret {
+		secret[key] = value
+	}
+	for key, value := range fields {
+		if _, ok := secret[key]; !ok {
+			secret[key] = value
+		}
+	}
+	return &cg.KV{Path: kv.Path, Secret: secret}
+}
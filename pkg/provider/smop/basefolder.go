@@ -0,0 +1,97 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+)
+
+// BaseFolderAnnotation sets the folder the remote keys of an ExternalSecret are relative to,
+// so its data and dataFrom do not repeat the folder in every remote key.
+//
+// Paths are resolved in this order, each one relative to the previous:
+//  1. the store folder, <environment>/<folderPath>, which an ExternalSecret can never leave,
+//  2. the base folder of this annotation, if set,
+//  3. the folder of the remote key, if the key contains a '/', e.g. "db/password".
+//
+// A remote key starting with '/' ignores the base folder and is only relative to the store folder.
+// dataFrom.find searches the base folder.
+const BaseFolderAnnotation = "smop.external-secrets.io/base-folder"
+
+// ErrInvalidBaseFolder is returned for a base folder or remote key which would leave the store folder.
+var ErrInvalidBaseFolder = errors.New("invalid Smop folder: must be relative and must not contain '..' segments")
+
+// readFolderPath returns the folder the ExternalSecret reads from: the store folder,
+// followed by the BaseFolderAnnotation of the ExternalSecret.
+func (c *Client) readFolderPath(ctx context.Context) (string, error) {
+	folderPath := storeFolderPath(c.store)
+
+	base, ok := esutils.SourceAnnotation(ctx, BaseFolderAnnotation)
+	if !ok || strings.Trim(base, "/") == "" {
+		return folderPath, nil
+	}
+	if err := validateRelativeFolder(base); err != nil {
+		return "", fmt.Errorf("invalid %s annotation: %w", BaseFolderAnnotation, err)
+	}
+	return joinFolder(folderPath, base), nil
+}
+
+// resolveRemoteKey returns the name and folder of the KV a remote key of the ExternalSecret refers to.
+// See BaseFolderAnnotation for the resolution order.
+func (c *Client) resolveRemoteKey(ctx context.Context, key string) (string, string, error) {
+	folderPath := storeFolderPath(c.store)
+	if !strings.HasPrefix(key, "/") {
+		var err error
+		if folderPath, err = c.readFolderPath(ctx); err != nil {
+			return "", "", err
+		}
+	}
+
+	key = strings.Trim(key, "/")
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return key, folderPath, nil
+	}
+	if err := validateRelativeFolder(key[:i]); err != nil {
+		return "", "", fmt.Errorf("invalid remote key %q: %w", key, err)
+	}
+	return key[i+1:], joinFolder(folderPath, key[:i]), nil
+}
+
+// validateRelativeFolder refuses folders which could leave the folder they are relative to.
+func validateRelativeFolder(folder string) error {
+	for _, segment := range strings.Split(strings.Trim(folder, "/"), "/") {
+		if segment == ".." || segment == "." {
+			return ErrInvalidBaseFolder
+		}
+	}
+	return nil
+}
+
+// joinFolder appends the relative folder sub to folder.
+func joinFolder(folder, sub string) string {
+	sub = strings.Trim(sub, "/")
+	if strings.Trim(folder, "/") == "" {
+		return sub
+	}
+	return strings.TrimSuffix(folder, "/") + "/" + sub
+}
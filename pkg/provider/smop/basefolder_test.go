@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+	testingfake "github.com/external-secrets/external-secrets/pkg/provider/testing/fake"
+)
+
+func TestResolveRemoteKey(t *testing.T) {
+	tests := map[string]struct {
+		environment string
+		folderPath  string
+		baseFolder  *string
+		key         string
+		wantName    string
+		wantFolder  string
+		wantErr     error
+	}{
+		"store folder only":                 {folderPath: "/apps", key: "db", wantName: "db", wantFolder: "/apps"},
+		"key folder":                        {folderPath: "/apps", key: "payments/db", wantName: "db", wantFolder: "/apps/payments"},
+		"base folder":                       {folderPath: "/apps", baseFolder: ptr.To("payments"), key: "db", wantName: "db", wantFolder: "/apps/payments"},
+		"base folder and key folder":        {folderPath: "/apps", baseFolder: ptr.To("/payments/"), key: "prod/db", wantName: "db", wantFolder: "/apps/payments/prod"},
+		"environment, base and key folders": {environment: "prod", folderPath: "apps", baseFolder: ptr.To("payments"), key: "eu/db", wantName: "db", wantFolder: "prod/apps/payments/eu"},
+		"absolute key ignores base folder":  {folderPath: "/apps", baseFolder: ptr.To("payments"), key: "/shared/db", wantName: "db", wantFolder: "/apps/shared"},
+		"base folder without store folder":  {baseFolder: ptr.To("payments"), key: "db", wantName: "db", wantFolder: "payments"},
+		"empty base folder":                 {folderPath: "/apps", baseFolder: ptr.To(""), key: "db", wantName: "db", wantFolder: "/apps"},
+		"base folder leaving the store":     {folderPath: "/apps", baseFolder: ptr.To("../other"), key: "db", wantErr: ErrInvalidBaseFolder},
+		"key leaving the store":             {folderPath: "/apps", key: "payments/../../other/db", wantErr: ErrInvalidBaseFolder},
+		"absolute key leaving the store":    {folderPath: "/apps", key: "/../db", wantErr: ErrInvalidBaseFolder},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: &esv1.SmopProvider{Environment: tc.environment, FolderPath: tc.folderPath}}
+			ctx := context.Background()
+			if tc.baseFolder != nil {
+				ctx = esutils.ContextWithSourceAnnotations(ctx, map[string]string{BaseFolderAnnotation: *tc.baseFolder})
+			}
+
+			gotName, gotFolder, err := c.resolveRemoteKey(ctx, tc.key)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantName, gotName)
+			assert.Equal(t, tc.wantFolder, gotFolder)
+		})
+	}
+}
+
+func TestBaseFolderAnnotation(t *testing.T) {
+	var gotFolders []string
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "/apps"},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, error) {
+				gotFolders = append(gotFolders, *folderPath)
+				return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}}, nil
+			},
+			GetSecretsFn: func(_ context.Context, folderPath *string) ([]cg.KVListItem, error) {
+				gotFolders = append(gotFolders, *folderPath)
+				return nil, nil
+			},
+		},
+	}
+	ctx := esutils.ContextWithSourceAnnotations(context.Background(), map[string]string{BaseFolderAnnotation: "payments"})
+
+	_, err := c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "password"})
+	require.NoError(t, err)
+	_, err = c.GetSecretMap(ctx, esv1.ExternalSecretDataRemoteRef{Key: "prod/db"})
+	require.NoError(t, err)
+	_, err = c.GetAllSecrets(ctx, esv1.ExternalSecretFind{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/apps/payments", "/apps/payments/prod", "/apps/payments"}, gotFolders)
+}
+
+func TestBaseFolderAnnotationPushSecret(t *testing.T) {
+	var got []string
+	record := func(op, name string, folderPath *string) {
+		got = append(got, op+" "+*folderPath+" "+name)
+	}
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "/apps"},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, error) {
+				record("get", name, folderPath)
+				return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "old"}}, nil
+			},
+			GetSecretMetadataFn: func(_ context.Context, name string, folderPath *string) (*smopclient.KVMetadata, error) {
+				record("metadata", name, folderPath)
+				return &smopclient.KVMetadata{Path: name}, nil
+			},
+			SetSecretFn: func(_ context.Context, name string, folderPath *string, _ map[string]any, _ map[string]string) error {
+				record("set", name, folderPath)
+				return nil
+			},
+			DeleteSecretFn: func(_ context.Context, name string, folderPath *string) error {
+				record("delete", name, folderPath)
+				return nil
+			},
+		},
+	}
+	ctx := esutils.ContextWithSourceAnnotations(context.Background(), map[string]string{BaseFolderAnnotation: "payments"})
+	secret := &corev1.Secret{Data: map[string][]byte{"password": []byte("new")}}
+
+	// PushSecret writes where an ExternalSecret with the same annotation reads
+	require.NoError(t, c.PushSecret(ctx, secret, testingfake.PushSecretData{SecretKey: "password", RemoteKey: "prod/db"}))
+	_, err := c.SecretExists(ctx, testingfake.PushSecretData{RemoteKey: "prod/db"})
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteSecret(ctx, testingfake.PushSecretData{RemoteKey: "/shared/db"}))
+
+	assert.Equal(t, []string{
+		"get /apps/payments/prod db",
+		"set /apps/payments/prod db",
+		"metadata /apps/payments/prod db",
+		"delete /apps/shared db",
+	}, got)
+}
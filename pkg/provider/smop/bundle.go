@@ -0,0 +1,43 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"fmt"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// bundleFiles returns the files of a bundle KV keyed by file name, or only the file
+// named by property if it is set.
+func bundleFiles(secret *cg.KV, property string) (map[string][]byte, error) {
+	files, err := smopclient.BundleFiles(secret)
+	if err != nil {
+		return nil, err
+	}
+	if property == "" {
+		return files, nil
+	}
+
+	content, ok := files[property]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found in secret bundle", property)
+	}
+	return map[string][]byte{property: content}, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// checkouts tracks the secrets a Client has checked out, keyed by their full SMoP path.
+type checkouts struct {
+	mu   sync.Mutex
+	held map[string]heldCheckout
+}
+
+// heldCheckout is a checkout to check back in when the Client is closed.
+type heldCheckout struct {
+	ref      smopclient.KVRef
+	checkout *smopclient.Checkout
+}
+
+// getSecret reads the secret `name` at `folderPath`, checking it out first when the store
+// uses the checkout workflow. A secret is checked out once per Client, later reads reuse its checkout.
+func (c *Client) getSecret(ctx context.Context, name, folderPath string) (*cg.KV, error) {
+	if c.store.Checkout == nil {
+		return c.getSecretWithMetadata(ctx, name, folderPath)
+	}
+
+	// a checkout does not report the version of the secret
+	ref := smopclient.KVRef{Name: name, FolderPath: &folderPath}
+	c.versions.observe(ref.String(), 0)
+	c.checkouts.mu.Lock()
+	defer c.checkouts.mu.Unlock()
+
+	if held, ok := c.checkouts.held[ref.String()]; ok {
+		return &held.checkout.KV, nil
+	}
+
+	var ttl time.Duration
+	if c.store.Checkout.TTL != nil {
+		ttl = c.store.Checkout.TTL.Duration
+	}
+	checkout, err := c.smopClient.CheckoutSecret(ctx, name, &folderPath, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.checkouts.held == nil {
+		c.checkouts.held = map[string]heldCheckout{}
+	}
+	c.checkouts.held[ref.String()] = heldCheckout{ref: ref, checkout: checkout}
+	return &checkout.KV, nil
+}
+
+// checkinAll checks in every secret checked out by the Client, unless the store holds checkouts
+// until they expire. All failures are returned joined.
+func (c *Client) checkinAll(ctx context.Context) error {
+	c.checkouts.mu.Lock()
+	held := c.checkouts.held
+	c.checkouts.held = nil
+	c.checkouts.mu.Unlock()
+
+	if len(held) == 0 {
+		return nil
+	}
+	if c.store.Checkout != nil && c.store.Checkout.Hold {
+		log.V(1).Info("holding SMoP checkouts until they expire", "count", len(held))
+		return nil
+	}
+
+	var errs []error
+	for key, h := range held {
+		if err := c.smopClient.CheckinSecret(ctx, h.ref.Name, h.ref.FolderPath, h.checkout.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to check in secret %q: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
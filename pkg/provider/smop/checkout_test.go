@@ -0,0 +1,97 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestCheckout(t *testing.T) {
+	tests := map[string]struct {
+		hold        bool
+		wantCheckin []string
+	}{
+		"checks in on close": {wantCheckin: []string{"co-db"}},
+		"hold":               {hold: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var checkouts int
+			var checkins []string
+			smopClient := &fake.SmopClient{
+				GetSecretFn: func(context.Context, string, *string) (*cg.KV, error) {
+					t.Fatal("a checked out secret must not be read directly")
+					return nil, nil
+				},
+				CheckoutSecretFn: func(_ context.Context, name string, folderPath *string, ttl time.Duration) (*smopclient.Checkout, error) {
+					checkouts++
+					assert.Equal(t, "/apps/prod", *folderPath)
+					assert.Equal(t, 10*time.Minute, ttl)
+					if name == "locked" {
+						return nil, fmt.Errorf("%w: %q", smopclient.ErrSecretCheckedOut, name)
+					}
+					return &smopclient.Checkout{
+						ID: "co-" + name,
+						KV: cg.KV{Path: name, Secret: cg.RedactedMap{"user": "admin", "password": "s3cr3t"}},
+					}, nil
+				},
+				CheckinSecretFn: func(_ context.Context, _ string, _ *string, checkoutID string) error {
+					checkins = append(checkins, checkoutID)
+					return nil
+				},
+			}
+			c := &Client{
+				smopClient: smopClient,
+				store: &esv1.SmopProvider{
+					FolderPath: "/apps/prod",
+					Checkout:   &esv1.SmopCheckout{TTL: &metav1.Duration{Duration: 10 * time.Minute}, Hold: tc.hold},
+				},
+			}
+			ctx := context.Background()
+
+			got, err := c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "password"})
+			require.NoError(t, err)
+			assert.Equal(t, "s3cr3t", string(got))
+			gotMap, err := c.GetSecretMap(ctx, esv1.ExternalSecretDataRemoteRef{Key: "db"})
+			require.NoError(t, err)
+			assert.Empty(t, c.SecretVersion(), "a checked out secret has no version")
+			assert.Equal(t, map[string][]byte{"user": []byte("admin"), "password": []byte("s3cr3t")}, gotMap)
+			assert.Equal(t, 1, checkouts, "a secret is checked out once per client")
+
+			_, err = c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "locked"})
+			assert.ErrorIs(t, err, smopclient.ErrSecretCheckedOut)
+
+			require.NoError(t, c.Close(ctx))
+			assert.Equal(t, tc.wantCheckin, checkins)
+			require.NoError(t, c.Close(ctx))
+			assert.Equal(t, tc.wantCheckin, checkins, "checkouts are only checked in once")
+		})
+	}
+}
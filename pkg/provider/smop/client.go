@@ -3,22 +3,50 @@ package smop
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
 	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
 	"github.com/external-secrets/external-secrets/pkg/esutils"
-	corev1 "k8s.io/api/core/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const ErrMsgNotImplemented = "not implemented: %s"
+// ErrReadOnlyStore is returned when changing SMoP through a read-only store.
+var ErrReadOnlyStore = errors.New("cannot change Smop through a read-only Smop SecretStore")
+
+const (
+	ErrMsgNotImplemented = "not implemented: %s"
+
+	validateTimeout = 15 * time.Second
+)
 
 // Client implements the SecretsClient interface for SMoP.
 type Client struct {
 	smopClient SecretsClientInterface
 	store      *esv1.SmopProvider
+	keyFilter  *keyFilter
+	checkouts  checkouts
+	expiry     expiry
+	defaults   defaults
+	versions   versions
+	types      kvTypes
+	encodings  encodings
+	cursors    findCursors
+	// storeRef labels the freshness metric, see recordSync.
+	storeRef  storeRef
+	freshness freshness
+	// kube and namespace resolve the secrets referenced by an ExternalSecret, see formatPassphrase.
+	kube      kclient.Client
+	namespace string
 }
 
 // SecretsClientInterface defines the required SMoP Client methods.
@@ -26,116 +54,554 @@ type SecretsClientInterface interface {
 	BaseURL() *url.URL
 	SetBaseURL(urlStr string) error
 	GetSecret(ctx context.Context, name string, folderPath *string) (*cg.KV, error)
+	GetSecretWithMetadata(ctx context.Context, name string, folderPath *string) (*cg.KV, *smopclient.KVMetadata, error)
+	GetSecretField(ctx context.Context, name string, folderPath *string, field string) (*cg.KV, *smopclient.KVMetadata, error)
 	GetSecrets(ctx context.Context, folderPath *string) ([]cg.KVListItem, error)
+	GetSecretType(ctx context.Context, name string, folderPath *string) (string, error)
+	GetSecretMetadata(ctx context.Context, name string, folderPath *string) (*smopclient.KVMetadata, error)
+	WalkSecrets(ctx context.Context, folderPath *string) ([]smopclient.KVRef, error)
+	ListSecrets(ctx context.Context, folderPath *string) ([]smopclient.KVRef, error)
+	ListSecretsPages(ctx context.Context, folderPath *string, cursor string, maxPages int) ([]smopclient.KVRef, string, error)
+	QuerySecrets(ctx context.Context, query smopclient.Query) ([]smopclient.KVRef, error)
+	BatchGetSecrets(ctx context.Context, refs []smopclient.KVRef) (map[string]smopclient.BatchResult, error)
+	DeleteSecret(ctx context.Context, name string, folderPath *string) error
+	SetSecret(ctx context.Context, name string, folderPath *string, secret map[string]any, tags map[string]string) error
+	CheckAPIVersion(ctx context.Context) (string, error)
+	CheckoutSecret(ctx context.Context, name string, folderPath *string, ttl time.Duration) (*smopclient.Checkout, error)
+	CheckinSecret(ctx context.Context, name string, folderPath *string, checkoutID string) error
+	RotateSecret(ctx context.Context, name string, folderPath *string) (*smopclient.Rotation, error)
+	ContextWithRetryBudget(ctx context.Context) context.Context
 }
 
 // Validate checks if the client is configured correctly
 // and is able to retrieve secrets from the SMOP provider.
 // If the validation result is unknown it will be ignored.
 func (c *Client) Validate() (esv1.ValidationResult, error) {
-	timeout := 15 * time.Second
-	clientURL := c.smopClient.BaseURL().String()
+	if c.store.SkipValidation {
+		return esv1.ValidationResultUnknown, nil
+	}
 
-	if err := esutils.NetworkValidate(clientURL, timeout); err != nil {
+	clientURL := c.smopClient.BaseURL().String()
+	if err := esutils.NetworkValidate(clientURL, validateTimeout); err != nil {
 		return esv1.ValidationResultError, err
 	}
 
-	// --TODO: validate auth?
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+
+	// listing the store folder is the cheapest authenticated call available
+	folderPath := storeFolderPath(c.store)
+	if _, err := c.smopClient.GetSecrets(ctx, &folderPath); err != nil {
+		return validationResultFromError(err), fmt.Errorf("failed to validate SMoP store: %w", err)
+	}
+
+	// a drifted server API version is only warned about, older servers may not report it at all
+	if _, err := c.smopClient.CheckAPIVersion(ctx); err != nil {
+		log.V(1).Info("failed to check SMoP server API version", "error", err.Error())
+	}
 
 	return esv1.ValidationResultReady, nil
 }
 
+// validationResultFromError maps a failed validation call to a ValidationResult.
+// Network and auth failures are errors, server side failures leave the store health unknown.
+func validationResultFromError(err error) esv1.ValidationResult {
+	if errors.Is(err, smopclient.ErrFolderNotFound) {
+		return esv1.ValidationResultError
+	}
+
+	var apiErr *smopclient.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized, apiErr.StatusCode == http.StatusForbidden:
+			return esv1.ValidationResultError
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return esv1.ValidationResultUnknown
+		}
+		return esv1.ValidationResultError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return esv1.ValidationResultError
+	}
+
+	return esv1.ValidationResultUnknown
+}
+
 // GetSecret returns a single secret from the SMOP provider
 //
 //	if GetSecret returns an error with type NoSecretError
 //	then the secret entry will be deleted depending on the deletionPolicy.
+//	With the Ignore NotFoundPolicy it returns a SkipSecretError instead and the entry is left out.
+//
+// A certificate value is converted with the FormatConversion of the reference, see convertFormat.
+// A missing secret returns the DefaultValue of the reference when the store allows default values.
+// An empty value is handled according to the EmptyValuePolicy of the store, see applyEmptyValuePolicy.
+// A failed read suggests when to sync again, see withRequeueDelay.
 func (c *Client) GetSecret(ctx context.Context, ref esv1.ExternalSecretDataRemoteRef) ([]byte, error) {
-	folderPath := c.store.FolderPath
+	value, read, err := c.getSecretValue(ctx, ref)
+	if err == nil {
+		err = c.applyEmptyValuePolicy(ref, value)
+	}
+	if value, ok := c.defaultValue(ref, err); ok {
+		return value, nil
+	}
+	if err != nil {
+		return nil, c.withRequeueDelay(err)
+	}
+	if ref.FormatConversion != nil {
+		if value, err = c.convertFormat(ctx, value, ref.FormatConversion); err != nil {
+			return nil, err
+		}
+	}
+	c.recordSync(ctx, read, time.Now())
+	return value, nil
+}
 
-	secret, err := c.smopClient.GetSecret(ctx, ref.Key, &folderPath)
+// getSecretValue returns the value of a single secret, or of one of its properties,
+// and the secret it was read from, for the freshness metric (see recordSync).
+func (c *Client) getSecretValue(ctx context.Context, ref esv1.ExternalSecretDataRemoteRef) ([]byte, smopclient.KVRef, error) {
+	ctx, err := c.requestContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get secret %w", err)
+		return nil, smopclient.KVRef{}, err
+	}
+
+	if ctx, err = scopeContext(ctx, ref.Scope); err != nil {
+		return nil, smopclient.KVRef{}, err
+	}
+
+	name, folderPath, err := c.resolveRemoteKey(ctx, ref.Key)
+	if err != nil {
+		return nil, smopclient.KVRef{}, err
+	}
+
+	// a failed rotation never reports the secret missing, which could delete or default it
+	if err := c.rotateOnSync(ctx, name, folderPath, ref.Scope); err != nil {
+		return nil, smopclient.KVRef{}, err
 	}
 
-	// Debug: print the entire secret structure
-	fullSecretBytes, _ := json.MarshalIndent(secret, "", "  ")
-	fmt.Printf("Full secret response: %s\n", string(fullSecretBytes))
+	read := smopclient.KVRef{Name: name, FolderPath: &folderPath}
+	secret, err := c.getSecretProperty(ctx, name, folderPath, ref.Property)
+	if err != nil {
+		return nil, smopclient.KVRef{}, fmt.Errorf("failed to get secret %w", c.applyNotFoundPolicy(err))
+	}
 
 	// Extract value from RedactedMap
 	if secret.Secret == nil {
-		return nil, fmt.Errorf("secret value is nil")
+		return nil, smopclient.KVRef{}, fmt.Errorf("secret value is nil")
+	}
+
+	// a property of a bundle selects one of its files
+	if ref.Property != "" && smopclient.IsBundle(secret) {
+		files, err := bundleFiles(secret, ref.Property)
+		if err != nil {
+			return nil, smopclient.KVRef{}, err
+		}
+		if files, err = selectProperties(files, ref.Properties, c.store.SkipMissingProperties, c.store.CaseInsensitiveProperties); err != nil {
+			return nil, smopclient.KVRef{}, err
+		}
+		// properties skipping the file leave nothing to return, which is reported like a missing property
+		file, ok, err := lookupKey(files, ref.Property, c.store.CaseInsensitiveProperties)
+		if err != nil {
+			return nil, smopclient.KVRef{}, err
+		}
+		if !ok {
+			return nil, smopclient.KVRef{}, c.applyNotFoundPolicy(fmt.Errorf("%w: file %s of secret bundle is not selected by remoteRef.properties", esv1.NoSecretErr, ref.Property))
+		}
+		return file, read, nil
 	}
 
 	// If there's a property key in the remote reference, use it
 	if ref.Property != "" {
-		if value, ok := secret.Secret[ref.Property]; ok {
-			return []byte(fmt.Sprintf("%v", value)), nil
+		value, err := extractProperty(secret.Secret, ref.Property, c.store.CaseInsensitiveProperties)
+		if err != nil {
+			return nil, smopclient.KVRef{}, c.applyNotFoundPolicy(err)
+		}
+		decoded, err := c.decodePushedValues(ctx, name, folderPath, map[string]any{ref.Property: value})
+		if err != nil {
+			return nil, smopclient.KVRef{}, err
 		}
-		return nil, fmt.Errorf("property %s not found in secret", ref.Property)
+		valueBytes, err := esutils.GetByteValue(decoded[ref.Property])
+		if err != nil {
+			return nil, smopclient.KVRef{}, err
+		}
+		return valueBytes, read, nil
 	}
 
 	// If no property specified, return the entire secret as JSON
 	secretBytes, err := json.Marshal(secret.Secret)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal secret: %w", err)
+		return nil, smopclient.KVRef{}, fmt.Errorf("failed to marshal secret: %w", err)
 	}
 
-	return secretBytes, nil
+	return secretBytes, read, nil
 }
 
 // GetAllSecrets retrieves all secrets from SMoP that match the given criteria.
+// Sub-folders are only searched when the store sets FindRecursive, or to find secrets by tags.
+// Keys are converted with the conversion strategy of the reference, after the store key filter applies.
 func (c *Client) GetAllSecrets(ctx context.Context, ref esv1.ExternalSecretFind) (map[string][]byte, error) {
-	folderPath := c.store.FolderPath
+	secrets, err := c.getAllSecrets(ctx, ref)
+	if err != nil {
+		return nil, c.withRequeueDelay(err)
+	}
+	return secrets, nil
+}
 
-	secrets, err := c.smopClient.GetSecrets(ctx, &folderPath)
+func (c *Client) getAllSecrets(ctx context.Context, ref esv1.ExternalSecretFind) (map[string][]byte, error) {
+	ctx, err := c.requestContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list secrets: %w", err)
+		return nil, err
 	}
 
-	list := map[string][]byte{}
+	folderPath, err := c.readFolderPath(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, sec := range secrets {
-		fullSecret, err := c.smopClient.GetSecret(ctx, sec.Path, &folderPath)
-		if err != nil || fullSecret == nil {
-			return nil, fmt.Errorf("failed to get secret %s: %w", sec.Path, err)
+	refs, err := c.findSecrets(ctx, folderPath, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", mapNotFound(err))
+	}
+	refs = c.updatedWithin(refs, time.Now())
+
+	// drop excluded keys before their value is fetched
+	allowed := make([]smopclient.KVRef, 0, len(refs))
+	keys := make(map[string]string, len(refs))
+	for _, sec := range refs {
+		key := relativeKey(folderPath, sec)
+		if !c.keyFilter.allowed(key) || c.isSidecar(sec.Name) {
+			continue
 		}
+		allowed = append(allowed, sec)
+		keys[sec.String()] = key
+	}
+	filtered := len(refs) - len(allowed)
 
-		secretBytes, err := json.Marshal(fullSecret.Secret)
+	results, err := c.smopClient.BatchGetSecrets(ctx, allowed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secrets: %w", err)
+	}
+
+	list := map[string][]byte{}
+	for _, sec := range allowed {
+		key := keys[sec.String()]
+		result := results[sec.String()]
+		if result.Err != nil || result.KV == nil {
+			return nil, fmt.Errorf("failed to get secret %s: %w", key, result.Err)
+		}
+		c.expiry.observe(result.ExpiresAt)
+		c.versions.observe(sec.String(), result.Version)
+		c.recordSync(ctx, sec, time.Now())
+
+		secretBytes, err := json.Marshal(withAccountFields(result.KV, result.AccountFields).Secret)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal secret: %w", err)
 		}
 
-		list[sec.Path] = secretBytes
+		list[key] = secretBytes
+	}
+
+	if filtered > 0 {
+		log.V(1).Info("filtered secrets from folder listing", "filtered", filtered)
 	}
 
-	return list, nil
+	return esutils.ConvertKeys(ref.ConversionStrategy, list)
 }
 
-/////////////////////////
-// NOT YET IMPLEMENTED //
-/////////////////////////
+// findSecrets lists the secrets at folderPath matching the name and tags of `ref`.
+// A find by tags searches the whole subtree of folderPath, even without FindRecursive.
+// The name is matched against the name of each secret, without its folder, and every tag
+// must be set with the given value: tags are ANDed, a secret matching any of several tag
+// sets requires a dataFrom.find entry per set.
+// Smop filters the secrets with its query endpoint, see QuerySecrets; without it, the listed
+// secrets are filtered instead. A find without name or tags lists the secrets, see listSecrets.
+func (c *Client) findSecrets(ctx context.Context, folderPath string, ref esv1.ExternalSecretFind) ([]smopclient.KVRef, error) {
+	query := smopclient.Query{FolderPath: &folderPath, Recursive: c.store.FindRecursive || len(ref.Tags) > 0, Tags: ref.Tags}
+	if ref.Name != nil {
+		query.NameRegexp = ref.Name.RegExp
+	}
+	if query.NameRegexp == "" && len(query.Tags) == 0 {
+		return c.listSecrets(ctx, folderPath)
+	}
 
-// PushSecret will write a single secret into the SMOP provider.
-func (c *Client) PushSecret(ctx context.Context, secret *corev1.Secret, data esv1.PushSecretData) error {
-	return fmt.Errorf(ErrMsgNotImplemented, "PushSecret")
+	name, err := regexp.Compile(query.NameRegexp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Smop find name %q: %w", query.NameRegexp, err)
+	}
+	refs, err := c.smopClient.QuerySecrets(ctx, query)
+	if !errors.Is(err, smopclient.ErrQueryUnsupported) {
+		return refs, err
+	}
+
+	log.V(1).Info("SMoP server does not offer the query endpoint, filtering the listed secrets", "folder", folderPath)
+	if query.Recursive {
+		refs, err = c.smopClient.WalkSecrets(ctx, &folderPath)
+	} else {
+		refs, err = c.smopClient.ListSecrets(ctx, &folderPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(refs, func(sec smopclient.KVRef) bool {
+		return !name.MatchString(sec.Name) || !hasTags(sec.Tags, query.Tags)
+	}), nil
 }
 
-// DeleteSecret will delete the secret from the SMOP provider.
-func (c *Client) DeleteSecret(ctx context.Context, remoteRef esv1.PushSecretRemoteRef) error {
-	return fmt.Errorf(ErrMsgNotImplemented, "DeleteSecret")
+// hasTags reports whether `tags` holds every tag of `want` with the same value.
+func hasTags(tags, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := tags[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
 }
 
-// SecretExists checks if a secret is already present in the SMOP provider at the given location.
-func (c *Client) SecretExists(ctx context.Context, remoteRef esv1.PushSecretRemoteRef) (bool, error) {
-	return false, fmt.Errorf(ErrMsgNotImplemented, "SecretExists")
+// listSecrets lists the secrets at folderPath, and in its sub-folders when the store sets FindRecursive.
+// Only FindPageLimit pages are listed when the store sets it, see listSecretsPages.
+func (c *Client) listSecrets(ctx context.Context, folderPath string) ([]smopclient.KVRef, error) {
+	if c.store.FindRecursive {
+		return c.smopClient.WalkSecrets(ctx, &folderPath)
+	}
+	if c.store.FindPageLimit != nil {
+		return c.listSecretsPages(ctx, folderPath)
+	}
+
+	return c.smopClient.ListSecrets(ctx, &folderPath)
+}
+
+// updatedWithin drops the listed secrets not changed within the FindUpdatedWithin window of the store.
+// If the SMoP server does not report when a listed secret was last changed, every secret is kept.
+func (c *Client) updatedWithin(refs []smopclient.KVRef, now time.Time) []smopclient.KVRef {
+	if c.store.FindUpdatedWithin == nil {
+		return refs
+	}
+	if slices.ContainsFunc(refs, func(ref smopclient.KVRef) bool { return ref.UpdatedAt == nil }) {
+		log.Info("WARNING: SMoP does not report when secrets were last changed, findUpdatedWithin is ignored and all secrets are returned",
+			"window", c.store.FindUpdatedWithin.Duration.String())
+		return refs
+	}
+
+	since := now.Add(-c.store.FindUpdatedWithin.Duration)
+	return slices.DeleteFunc(refs, func(ref smopclient.KVRef) bool { return ref.UpdatedAt.Before(since) })
+}
+
+// relativeKey returns the path of the secret relative to the store folder.
+func relativeKey(folderPath string, ref smopclient.KVRef) string {
+	if ref.FolderPath == nil {
+		return ref.Name
+	}
+	sub := strings.TrimPrefix(strings.Trim(*ref.FolderPath, "/"), strings.Trim(folderPath, "/"))
+	if sub = strings.Trim(sub, "/"); sub == "" {
+		return ref.Name
+	}
+	return sub + "/" + ref.Name
 }
 
 // GetSecretMap returns multiple k/v pairs from the SMOP provider.
+// A bundle KV returns its files keyed by file name, so a TLS bundle maps directly into a Secret.
+// Keys are converted with the conversion strategy of the reference, after the store key filter applies.
 func (c *Client) GetSecretMap(ctx context.Context, ref esv1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
-	return nil, fmt.Errorf(ErrMsgNotImplemented, "GetSecretMap")
+	secrets, err := c.getSecretMap(ctx, ref)
+	if err != nil {
+		return nil, c.withRequeueDelay(err)
+	}
+	return secrets, nil
+}
+
+func (c *Client) getSecretMap(ctx context.Context, ref esv1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	// a converted certificate value maps to the keys of its target format
+	if ref.FormatConversion != nil {
+		value, read, err := c.getSecretValue(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		secrets, err := c.convertFormatMap(ctx, value, ref.FormatConversion)
+		if err != nil {
+			return nil, err
+		}
+		c.recordSync(ctx, read, time.Now())
+		return secrets, nil
+	}
+
+	ctx, err := c.requestContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx, err = scopeContext(ctx, ref.Scope); err != nil {
+		return nil, err
+	}
+
+	name, folderPath, err := c.resolveRemoteKey(ctx, ref.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed rotation never reports the secret missing, which could delete or default it
+	if err := c.rotateOnSync(ctx, name, folderPath, ref.Scope); err != nil {
+		return nil, err
+	}
+
+	read := smopclient.KVRef{Name: name, FolderPath: &folderPath}
+	secret, err := c.getSecret(ctx, name, folderPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %w", c.applyNotFoundPolicy(err))
+	}
+
+	if secret.Secret == nil {
+		return nil, fmt.Errorf("secret value is nil")
+	}
+
+	// the files of a bundle map directly to the keys of the Secret, a property selects one of them
+	if smopclient.IsBundle(secret) {
+		files, err := bundleFiles(secret, ref.Property)
+		if err != nil {
+			return nil, err
+		}
+		if files, err = selectProperties(files, ref.Properties, c.store.SkipMissingProperties, c.store.CaseInsensitiveProperties); err != nil {
+			return nil, err
+		}
+		return c.syncedMap(ctx, read, ref.ConversionStrategy, files)
+	}
+
+	// a property selects a key-value map nested in the secret, properties select some of its keys
+	values := map[string]any(secret.Secret)
+	if ref.Property != "" {
+		if values, err = extractPropertyMap(secret.Secret, ref.Property, c.store.CaseInsensitiveProperties); err != nil {
+			return nil, err
+		}
+	}
+	if values, err = selectProperties(values, ref.Properties, c.store.SkipMissingProperties, c.store.CaseInsensitiveProperties); err != nil {
+		return nil, err
+	}
+	if values, err = flattenValues(values, c.store.Flatten); err != nil {
+		return nil, err
+	}
+
+	if values, err = c.decodePushedValues(ctx, name, folderPath, values); err != nil {
+		return nil, err
+	}
+	secretMap := make(map[string][]byte, len(values))
+	for key, value := range values {
+		valueBytes, err := esutils.GetByteValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get value of key %s: %w", key, err)
+		}
+		secretMap[key] = valueBytes
+	}
+
+	return c.syncedMap(ctx, read, ref.ConversionStrategy, secretMap)
+}
+
+// syncedMap converts the keys of the values of the secret `read` like GetSecretMap returns them,
+// and only then records the secret as synced, see recordSync.
+func (c *Client) syncedMap(ctx context.Context, read smopclient.KVRef, strategy esv1.ExternalSecretConversionStrategy, values map[string][]byte) (map[string][]byte, error) {
+	secrets, err := esutils.ConvertKeys(strategy, c.keyFilter.filterMap(values))
+	if err != nil {
+		return nil, err
+	}
+	c.recordSync(ctx, read, time.Now())
+	return secrets, nil
+}
+
+// DeleteSecret deletes the secret of a PushSecret with deletionPolicy Delete from the SMOP provider.
+// The remote key is resolved like PushSecret resolves it, see resolveRemoteKey.
+// A secret which is already gone counts as deleted.
+func (c *Client) DeleteSecret(ctx context.Context, remoteRef esv1.PushSecretRemoteRef) error {
+	if c.store.ReadOnly {
+		return fmt.Errorf("%w: refusing to delete %q", ErrReadOnlyStore, remoteRef.GetRemoteKey())
+	}
+	if remoteRef.GetProperty() != "" {
+		return fmt.Errorf("deleting property %q of %q is not supported: SMoP secrets are deleted as a whole",
+			remoteRef.GetProperty(), remoteRef.GetRemoteKey())
+	}
+
+	ctx, err := c.requestContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	name, folderPath, err := c.resolveRemoteKey(ctx, remoteRef.GetRemoteKey())
+	if err != nil {
+		return err
+	}
+
+	err = c.smopClient.DeleteSecret(ctx, name, &folderPath)
+	if err != nil && !errors.Is(mapNotFound(err), esv1.NoSecretErr) {
+		return fmt.Errorf("failed to delete secret %q: %w", remoteRef.GetRemoteKey(), err)
+	}
+
+	if c.sidecarMetadata() {
+		err = c.smopClient.DeleteSecret(ctx, name+sidecarSuffix, &folderPath)
+		if err != nil && !errors.Is(mapNotFound(err), esv1.NoSecretErr) {
+			return fmt.Errorf("failed to delete metadata of secret %q: %w", remoteRef.GetRemoteKey(), err)
+		}
+	}
+	return nil
+}
+
+// SecretExists reports whether the secret of a PushSecret is present in SMoP, so the controller can
+// apply the updatePolicy IfNotExists. Secrets are looked up where PushSecret writes them, see resolveRemoteKey.
+// Without a property only the metadata of the KV is fetched; with a property, the KV must hold it.
+// A secret or folder SMoP reports missing does not exist, as for GetSecret, any other failure is returned.
+func (c *Client) SecretExists(ctx context.Context, remoteRef esv1.PushSecretRemoteRef) (bool, error) {
+	ctx, err := c.requestContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	remoteKey := remoteRef.GetRemoteKey()
+	name, folderPath, err := c.resolveRemoteKey(ctx, remoteKey)
+	if err != nil {
+		return false, err
+	}
+
+	if remoteRef.GetProperty() == "" {
+		_, err = c.smopClient.GetSecretMetadata(ctx, name, &folderPath)
+	} else {
+		var kv *cg.KV
+		kv, _, err = c.smopClient.GetSecretWithMetadata(ctx, name, &folderPath)
+		if err == nil {
+			_, ok := kv.Secret[remoteRef.GetProperty()]
+			return ok, nil
+		}
+	}
+	if errors.Is(mapNotFound(err), esv1.NoSecretErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check if secret %q exists: %w", remoteKey, err)
+	}
+	return true, nil
+}
+
+// mapNotFound wraps a SMoP 404 APIError, or a missing folder, with esv1.NoSecretErr,
+// so the controller applies the deletionPolicy for missing secrets.
+func mapNotFound(err error) error {
+	var apiErr *smopclient.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound || errors.Is(err, smopclient.ErrFolderNotFound) {
+		return fmt.Errorf("%w: %w", esv1.NoSecretErr, err)
+	}
+	return err
+}
+
+// applyNotFoundPolicy maps a secret missing in SMoP according to the NotFoundPolicy of the store:
+// with Ignore it is reported as esv1.SkipSecretErr, so the controller leaves the entry out.
+func (c *Client) applyNotFoundPolicy(err error) error {
+	err = mapNotFound(err)
+	if c.store.NotFoundPolicy == esv1.SmopNotFoundPolicyIgnore && errors.Is(err, esv1.NoSecretErr) {
+		return fmt.Errorf("%w: %w", esv1.SkipSecretErr, err)
+	}
+	return err
 }
 
 // Close implements cleanup operations for the SMoP client.
+// The freshness series of the secrets its ExternalSecret no longer reads are deleted, see releaseSyncs.
+// Secrets checked out by the client are checked back in, unless the store holds checkouts.
 func (c *Client) Close(ctx context.Context) error {
-	return nil
+	c.releaseSyncs()
+	return c.checkinAll(ctx)
 }
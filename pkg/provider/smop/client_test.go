@@ -0,0 +1,741 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+	testingfake "github.com/external-secrets/external-secrets/pkg/provider/testing/fake"
+)
+
+func TestValidate(t *testing.T) {
+	// Validate dials the base URL before the authenticated check, so it has to be reachable.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	srvURL, _ := url.Parse(srv.URL)
+
+	tests := map[string]struct {
+		skip    bool
+		listErr error
+		want    esv1.ValidationResult
+		wantErr bool
+	}{
+		"ready": {
+			want: esv1.ValidationResultReady,
+		},
+		"skipped": {
+			skip: true,
+			want: esv1.ValidationResultUnknown,
+		},
+		"unauthorized": {
+			listErr: &smopclient.APIError{StatusCode: http.StatusUnauthorized},
+			want:    esv1.ValidationResultError,
+			wantErr: true,
+		},
+		"server error": {
+			listErr: &smopclient.APIError{StatusCode: http.StatusBadGateway},
+			want:    esv1.ValidationResultUnknown,
+			wantErr: true,
+		},
+		"network error": {
+			listErr: &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			want:    esv1.ValidationResultError,
+			wantErr: true,
+		},
+		"missing folder": {
+			listErr: fmt.Errorf("%w: %q", smopclient.ErrFolderNotFound, "aps"),
+			want:    esv1.ValidationResultError,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{
+				store: &esv1.SmopProvider{SkipValidation: tc.skip},
+				smopClient: &fake.SmopClient{
+					BaseURLFn: func() *url.URL { return srvURL },
+					GetSecretsFn: func(_ context.Context, _ *string) ([]cg.KVListItem, error) {
+						return nil, tc.listErr
+					},
+				},
+			}
+
+			got, err := c.Validate()
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestGetSecretNotFound(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return nil, &smopclient.APIError{StatusCode: http.StatusNotFound, Message: "not found"}
+			},
+		},
+	}
+
+	_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "missing"})
+	assert.ErrorIs(t, err, esv1.NoSecretErr)
+}
+
+func TestDeleteSecret(t *testing.T) {
+	tests := map[string]struct {
+		store     *esv1.SmopProvider
+		ref       testingfake.PushSecretData
+		deleteErr error
+		wantErr   string
+		wantCall  bool
+	}{
+		"deletes the secret": {
+			store:    &esv1.SmopProvider{FolderPath: "apps"},
+			ref:      testingfake.PushSecretData{RemoteKey: "db"},
+			wantCall: true,
+		},
+		"already deleted": {
+			store:     &esv1.SmopProvider{FolderPath: "apps"},
+			ref:       testingfake.PushSecretData{RemoteKey: "db"},
+			deleteErr: &smopclient.APIError{StatusCode: http.StatusNotFound, Message: "not found"},
+			wantCall:  true,
+		},
+		"delete fails": {
+			store:     &esv1.SmopProvider{FolderPath: "apps"},
+			ref:       testingfake.PushSecretData{RemoteKey: "db"},
+			deleteErr: &smopclient.APIError{StatusCode: http.StatusForbidden, Message: "forbidden"},
+			wantErr:   "forbidden",
+			wantCall:  true,
+		},
+		"read-only store": {
+			store:   &esv1.SmopProvider{FolderPath: "apps", ReadOnly: true},
+			ref:     testingfake.PushSecretData{RemoteKey: "db"},
+			wantErr: "read-only",
+		},
+		"single property": {
+			store:   &esv1.SmopProvider{FolderPath: "apps"},
+			ref:     testingfake.PushSecretData{RemoteKey: "db", Property: "password"},
+			wantErr: "not supported",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			c := &Client{
+				store: tc.store,
+				smopClient: &fake.SmopClient{
+					DeleteSecretFn: func(_ context.Context, name string, folderPath *string) error {
+						called = true
+						assert.Equal(t, "db", name)
+						assert.Equal(t, "apps", *folderPath)
+						return tc.deleteErr
+					},
+				},
+			}
+
+			err := c.DeleteSecret(context.Background(), tc.ref)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantCall, called)
+		})
+	}
+}
+
+func TestSecretExists(t *testing.T) {
+	notFound := &smopclient.APIError{StatusCode: http.StatusNotFound, Message: "not found"}
+	tests := map[string]struct {
+		ref     testingfake.PushSecretData
+		err     error
+		want    bool
+		wantErr string
+	}{
+		"exists": {
+			ref:  testingfake.PushSecretData{RemoteKey: "db"},
+			want: true,
+		},
+		"does not exist": {
+			ref: testingfake.PushSecretData{RemoteKey: "db"},
+			err: notFound,
+		},
+		"folder does not exist": {
+			ref: testingfake.PushSecretData{RemoteKey: "db"},
+			err: smopclient.ErrFolderNotFound,
+		},
+		"lookup fails": {
+			ref:     testingfake.PushSecretData{RemoteKey: "db"},
+			err:     &smopclient.APIError{StatusCode: http.StatusForbidden, Message: "forbidden"},
+			wantErr: "forbidden",
+		},
+		"property exists": {
+			ref:  testingfake.PushSecretData{RemoteKey: "db", Property: "password"},
+			want: true,
+		},
+		"property does not exist": {
+			ref: testingfake.PushSecretData{RemoteKey: "db", Property: "token"},
+		},
+		"secret of property does not exist": {
+			ref: testingfake.PushSecretData{RemoteKey: "db", Property: "password"},
+			err: notFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{
+				store: &esv1.SmopProvider{FolderPath: "apps"},
+				smopClient: &fake.SmopClient{
+					GetSecretMetadataFn: func(_ context.Context, name string, folderPath *string) (*smopclient.KVMetadata, error) {
+						assert.Empty(t, tc.ref.Property, "the value is only fetched to look up a property")
+						assert.Equal(t, "db", name)
+						assert.Equal(t, "apps", *folderPath)
+						if tc.err != nil {
+							return nil, tc.err
+						}
+						return &smopclient.KVMetadata{Path: name}, nil
+					},
+					GetSecretWithMetadataFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, *smopclient.KVMetadata, error) {
+						assert.Equal(t, "db", name)
+						assert.Equal(t, "apps", *folderPath)
+						if tc.err != nil {
+							return nil, nil, tc.err
+						}
+						return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}}, &smopclient.KVMetadata{Path: name}, nil
+					},
+				},
+			}
+
+			exists, err := c.SecretExists(context.Background(), tc.ref)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, exists)
+		})
+	}
+}
+
+func TestGetSecretMap(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{
+					"user":    "app",
+					"db":      map[string]any{"host": "db-0", "port": float64(5432)},
+					"encoded": `{"host": "db-1"}`,
+				}}, nil
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		property string
+		want     map[string][]byte
+	}{
+		"whole secret": {
+			want: map[string][]byte{
+				"user":    []byte("app"),
+				"db":      []byte(`{"host":"db-0","port":5432}`),
+				"encoded": []byte(`{"host": "db-1"}`),
+			},
+		},
+		"map field": {
+			property: "db",
+			want:     map[string][]byte{"host": []byte("db-0"), "port": []byte("5432")},
+		},
+		"JSON string field": {
+			property: "encoded",
+			want:     map[string][]byte{"host": []byte("db-1")},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: tc.property})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetSecretMapNumbers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{` +
+			`"id":12345678901234567891,"rate":0.10000000000000000555,"exp":1e3,` +
+			`"nested":{"id":9007199254740993},"encoded":"{\"id\": 9007199254740995}"}}`))
+	}))
+	defer srv.Close()
+
+	smopClient, err := smopclient.NewSMOPClient(srv.URL+"/site/secrets", "t0k3n")
+	require.NoError(t, err)
+	c := &Client{store: &esv1.SmopProvider{}, smopClient: smopClient}
+
+	got, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"id":      []byte("12345678901234567891"),
+		"rate":    []byte("0.10000000000000000555"),
+		"exp":     []byte("1e3"),
+		"nested":  []byte(`{"id":9007199254740993}`),
+		"encoded": []byte(`{"id": 9007199254740995}`),
+	}, got)
+
+	got, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "encoded"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"id": []byte("9007199254740995")}, got)
+
+	value, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "nested.id"})
+	require.NoError(t, err)
+	assert.Equal(t, "9007199254740993", string(value))
+}
+
+func TestGetSecretMapProperties(t *testing.T) {
+	newClient := func(skipMissing bool) *Client {
+		return &Client{
+			store: &esv1.SmopProvider{SkipMissingProperties: skipMissing},
+			smopClient: &fake.SmopClient{
+				GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+					return &cg.KV{Secret: cg.RedactedMap{
+						"user":     "app",
+						"password": "s3cr3t",
+						"db":       map[string]any{"host": "db-0", "port": float64(5432)},
+					}}, nil
+				},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		ref         esv1.ExternalSecretDataRemoteRef
+		skipMissing bool
+		want        map[string][]byte
+		wantErr     string
+	}{
+		"present keys": {
+			ref:  esv1.ExternalSecretDataRemoteRef{Key: "db", Properties: []string{"user", "db"}},
+			want: map[string][]byte{"user": []byte("app"), "db": []byte(`{"host":"db-0","port":5432}`)},
+		},
+		"keys of the map selected by property": {
+			ref:  esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "db", Properties: []string{"host"}},
+			want: map[string][]byte{"host": []byte("db-0")},
+		},
+		"missing key": {
+			ref:     esv1.ExternalSecretDataRemoteRef{Key: "db", Properties: []string{"user", "token"}},
+			wantErr: "property token not found in secret",
+		},
+		"missing key skipped": {
+			ref:         esv1.ExternalSecretDataRemoteRef{Key: "db", Properties: []string{"user", "token"}},
+			skipMissing: true,
+			want:        map[string][]byte{"user": []byte("app")},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := newClient(tc.skipMissing).GetSecretMap(context.Background(), tc.ref)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetAllSecretsRecursive(t *testing.T) {
+	folder := func(p string) *string { return &p }
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "apps", FindRecursive: true},
+		smopClient: &fake.SmopClient{
+			WalkSecretsFn: func(_ context.Context, folderPath *string) ([]smopclient.KVRef, error) {
+				assert.Equal(t, "apps", *folderPath)
+				return []smopclient.KVRef{
+					{Name: "token", FolderPath: folder("apps")},
+					{Name: "password", FolderPath: folder("/apps/db")},
+					{Name: "cert", FolderPath: folder("/apps/db/tls")},
+				}, nil
+			},
+			GetSecretFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{"value": *folderPath + "/" + name}}, nil
+			},
+		},
+	}
+
+	got, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"token":       []byte(`{"value":"apps/token"}`),
+		"db/password": []byte(`{"value":"/apps/db/password"}`),
+		"db/tls/cert": []byte(`{"value":"/apps/db/tls/cert"}`),
+	}, got)
+}
+
+func TestGetAllSecretsQuery(t *testing.T) {
+	folder := func(p string) *string { return &p }
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "apps", FindRecursive: true},
+		smopClient: &fake.SmopClient{
+			QuerySecretsFn: func(_ context.Context, query smopclient.Query) ([]smopclient.KVRef, error) {
+				assert.Equal(t, smopclient.Query{
+					FolderPath: folder("apps"),
+					Recursive:  true,
+					NameRegexp: "^db",
+					Tags:       map[string]string{"team": "payments"},
+				}, query)
+				return []smopclient.KVRef{{Name: "db", FolderPath: folder("apps")}}, nil
+			},
+			GetSecretFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{"value": *folderPath + "/" + name}}, nil
+			},
+		},
+	}
+
+	got, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{
+		Name: &esv1.FindName{RegExp: "^db"},
+		Tags: map[string]string{"team": "payments"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"db": []byte(`{"value":"apps/db"}`)}, got)
+
+	_, err = c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{Name: &esv1.FindName{RegExp: "("}})
+	assert.ErrorContains(t, err, `invalid Smop find name "("`)
+}
+
+func TestGetAllSecretsFindByTags(t *testing.T) {
+	folder := func(p string) *string { return &p }
+	walked := []smopclient.KVRef{
+		{Name: "token", FolderPath: folder("apps"), Tags: map[string]string{"team": "payments"}},
+		{Name: "password", FolderPath: folder("/apps/db"), Tags: map[string]string{"team": "payments", "env": "prod"}},
+		{Name: "cert", FolderPath: folder("/apps/db/tls"), Tags: map[string]string{"team": "payments", "env": "dev"}},
+		{Name: "api", FolderPath: folder("/apps/web"), Tags: map[string]string{"team": "web", "env": "prod"}},
+		{Name: "untagged", FolderPath: folder("/apps/web")},
+	}
+	tests := map[string]struct {
+		find esv1.ExternalSecretFind
+		want []string
+	}{
+		"single tag": {
+			find: esv1.ExternalSecretFind{Tags: map[string]string{"team": "payments"}},
+			want: []string{"token", "db/password", "db/tls/cert"},
+		},
+		"all tags must match": {
+			find: esv1.ExternalSecretFind{Tags: map[string]string{"team": "payments", "env": "prod"}},
+			want: []string{"db/password"},
+		},
+		"tag value must match": {
+			find: esv1.ExternalSecretFind{Tags: map[string]string{"env": "staging"}},
+			want: []string{},
+		},
+		"name and tags": {
+			find: esv1.ExternalSecretFind{Name: &esv1.FindName{RegExp: "^(api|cert)$"}, Tags: map[string]string{"env": "prod"}},
+			want: []string{"web/api"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{
+				// tags are searched in the whole subtree, without FindRecursive
+				store: &esv1.SmopProvider{FolderPath: "apps"},
+				smopClient: &fake.SmopClient{
+					QuerySecretsFn: func(_ context.Context, query smopclient.Query) ([]smopclient.KVRef, error) {
+						assert.True(t, query.Recursive)
+						return nil, smopclient.ErrQueryUnsupported
+					},
+					WalkSecretsFn: func(_ context.Context, folderPath *string) ([]smopclient.KVRef, error) {
+						assert.Equal(t, "apps", *folderPath)
+						return slices.Clone(walked), nil
+					},
+					GetSecretFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, error) {
+						return &cg.KV{Secret: cg.RedactedMap{"value": name}}, nil
+					},
+				},
+			}
+
+			got, err := c.GetAllSecrets(context.Background(), tc.find)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.want, slices.Collect(maps.Keys(got)))
+		})
+	}
+}
+
+func BenchmarkGetAllSecrets(b *testing.B) {
+	const size = 50
+	listed := make([]string, 0, size)
+	fetched := make([]string, 0, size)
+	for i := range size {
+		listed = append(listed, fmt.Sprintf(`{"path":"kv-%d"}`, i))
+		fetched = append(fetched, fmt.Sprintf(`{"path":"kv-%d","folderName":"apps","secret":{"password":"s3cr3t-%d"}}`, i, i))
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		items := listed
+		if r.Method == http.MethodPost {
+			items = fetched
+		}
+		_, _ = fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(items, ","))
+	}))
+	b.Cleanup(srv.Close)
+
+	smopClient, err := smopclient.NewSMOPClient(srv.URL+"/site/secrets", "t0k3n")
+	require.NoError(b, err)
+	c := &Client{store: &esv1.SmopProvider{FolderPath: "apps"}, smopClient: smopClient}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		secrets, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(secrets) != size {
+			b.Fatalf("got %d secrets, want %d", len(secrets), size)
+		}
+	}
+}
+
+func TestConversionStrategy(t *testing.T) {
+	secret := cg.RedactedMap{
+		"db.host":    "db-0",
+		"db/port":    "5432",
+		"prix-€":     "10",
+		"schlüssel":  "value",
+		"plain_key1": "value",
+	}
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "apps"},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Secret: secret}, nil
+			},
+			GetSecretsFn: func(_ context.Context, _ *string) ([]cg.KVListItem, error) {
+				return []cg.KVListItem{{Path: "db.host"}, {Path: "prix-€"}}, nil
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		strategy esv1.ExternalSecretConversionStrategy
+		wantMap  []string
+		wantAll  []string
+	}{
+		"default": {
+			strategy: esv1.ExternalSecretConversionDefault,
+			wantMap:  []string{"db.host", "db_port", "prix-_", "schlüssel", "plain_key1"},
+			wantAll:  []string{"db.host", "prix-_"},
+		},
+		"unicode": {
+			strategy: esv1.ExternalSecretConversionUnicode,
+			wantMap:  []string{"db.host", "db_U002f_port", "prix-_U20ac_", "schlüssel", "plain_key1"},
+			wantAll:  []string{"db.host", "prix-_U20ac_"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", ConversionStrategy: tc.strategy})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantMap, slices.Collect(maps.Keys(got)))
+
+			got, err = c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{ConversionStrategy: tc.strategy})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantAll, slices.Collect(maps.Keys(got)))
+		})
+	}
+}
+
+func TestConversionStrategyCollision(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{"db/port": "5432", "db_port": "5433"}}, nil
+			},
+		},
+	}
+	_, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", ConversionStrategy: esv1.ExternalSecretConversionDefault})
+	assert.ErrorContains(t, err, "collision")
+}
+
+func TestGetAllSecretsMissingFolder(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "aps", RequireFolder: true},
+		smopClient: &fake.SmopClient{
+			GetSecretsFn: func(_ context.Context, folderPath *string) ([]cg.KVListItem, error) {
+				return nil, fmt.Errorf("%w: %q", smopclient.ErrFolderNotFound, *folderPath)
+			},
+		},
+	}
+
+	_, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+	assert.ErrorIs(t, err, esv1.NoSecretErr)
+	assert.ErrorIs(t, err, smopclient.ErrFolderNotFound)
+}
+
+func TestGetAllSecretsAPIError(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretsFn: func(_ context.Context, _ *string) ([]cg.KVListItem, error) {
+				return nil, &smopclient.APIError{StatusCode: http.StatusForbidden, Message: "access denied"}
+			},
+		},
+	}
+
+	_, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+	assertAPIError(t, err, http.StatusForbidden)
+}
+
+func TestGetAllSecretsUpdatedWithin(t *testing.T) {
+	now := time.Now()
+	recent, old := now.Add(-5*time.Minute), now.Add(-2*time.Hour)
+
+	tests := map[string]struct {
+		refs []smopclient.KVRef
+		want []string
+	}{
+		"only recently changed secrets": {
+			refs: []smopclient.KVRef{
+				{Name: "db", UpdatedAt: &recent},
+				{Name: "cache", UpdatedAt: &old},
+				{Name: "api", UpdatedAt: &recent},
+			},
+			want: []string{"db", "api"},
+		},
+		"everything without timestamps": {
+			refs: []smopclient.KVRef{
+				{Name: "db", UpdatedAt: &recent},
+				{Name: "cache"},
+			},
+			want: []string{"db", "cache"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{
+				store: &esv1.SmopProvider{FindUpdatedWithin: &metav1.Duration{Duration: 15 * time.Minute}},
+				smopClient: &fake.SmopClient{
+					ListSecretsFn: func(_ context.Context, _ *string) ([]smopclient.KVRef, error) {
+						return slices.Clone(tc.refs), nil
+					},
+					GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+						return &cg.KV{Path: name, Secret: cg.RedactedMap{"value": name}}, nil
+					},
+				},
+			}
+
+			got, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.want, slices.Collect(maps.Keys(got)))
+		})
+	}
+}
+
+func TestNotFoundPolicy(t *testing.T) {
+	smopClient := &fake.SmopClient{
+		GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+			if name == "gone" {
+				return nil, &smopclient.APIError{StatusCode: http.StatusNotFound, Path: name}
+			}
+			return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}}, nil
+		},
+	}
+
+	tests := map[string]struct {
+		policy  esv1.SmopNotFoundPolicy
+		wantErr error
+	}{
+		"default fails": {wantErr: esv1.NoSecretErr},
+		"fail":          {policy: esv1.SmopNotFoundPolicyFail, wantErr: esv1.NoSecretErr},
+		"ignore skips":  {policy: esv1.SmopNotFoundPolicyIgnore, wantErr: esv1.SkipSecretErr},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: &esv1.SmopProvider{NotFoundPolicy: tc.policy}, smopClient: smopClient}
+			ctx := context.Background()
+
+			got, err := c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "password"})
+			require.NoError(t, err, "existing secrets are not affected by the policy")
+			assert.Equal(t, "s3cr3t", string(got))
+
+			_, err = c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "gone"})
+			assert.ErrorIs(t, err, tc.wantErr)
+			assertAPIError(t, err, http.StatusNotFound)
+			_, err = c.GetSecretMap(ctx, esv1.ExternalSecretDataRemoteRef{Key: "gone"})
+			assert.ErrorIs(t, err, tc.wantErr)
+			assertAPIError(t, err, http.StatusNotFound)
+		})
+	}
+}
+
+// assertAPIError asserts that err wraps a *smopclient.APIError with the status code.
+func assertAPIError(t *testing.T, err error, statusCode int) {
+	t.Helper()
+	var apiErr *smopclient.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, statusCode, apiErr.StatusCode)
+	}
+}
+
+func TestGetSecretMaintenance(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+				return nil, &smopclient.MaintenanceError{
+					APIError: &smopclient.APIError{StatusCode: http.StatusServiceUnavailable, Message: "scheduled maintenance", Path: name},
+					Delay:    10 * time.Minute,
+				}
+			},
+		},
+	}
+
+	_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	assert.ErrorIs(t, err, smopclient.ErrMaintenance)
+
+	var retryAfterErr esv1.RetryAfterError
+	require.ErrorAs(t, err, &retryAfterErr, "the controller must be able to delay the requeue")
+	assert.Equal(t, 10*time.Minute, retryAfterErr.RetryAfter())
+}
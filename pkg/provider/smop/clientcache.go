@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// maxCachedClients caps the number of SMoP API clients, and of workload identity token sources, kept in their cache.
+const maxCachedClients = 128
+
+// clientCacheKey identifies a SMoP API client by a hash of everything it is built from.
+type clientCacheKey [sha256.Size]byte
+
+// newClientCacheKey hashes the store spec, where its references are resolved, and the credentials
+// and TLS material resolved from them. A changed store, token or certificate yields a different key,
+// so a stale client is never reused and ages out of the cache.
+func newClientCacheKey(spec *esv1.SmopProvider, namespace, storeKind, apiKey string, tlsMaterial [][]byte) (clientCacheKey, error) {
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return clientCacheKey{}, err
+	}
+	return hashParts(append([][]byte{specJSON, []byte(namespace), []byte(storeKind), []byte(apiKey)}, tlsMaterial...)), nil
+}
+
+// hashParts returns the SHA-256 hash of parts.
+func hashParts(parts [][]byte) [sha256.Size]byte {
+	h := sha256.New()
+	for _, part := range parts {
+		// prefix each part with its length so adjacent parts cannot run into each other
+		_ = binary.Write(h, binary.BigEndian, uint64(len(part)))
+		_, _ = h.Write(part)
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// lruCache is a least recently used cache, shared by the stores of the provider so identical stores
+// reuse SMoP API clients, with their connection pools and token caches, and workload identity token sources.
+type lruCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	max     int
+	lru     *list.List
+	entries map[K]*list.Element
+	// onEvict, if set, is called with every value evicted or not added, outside of the lock.
+	onEvict func(V)
+}
+
+// lruCacheEntry is an element of lruCache.lru.
+type lruCacheEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// clients caches the SMoP API clients built by newSmopClient.
+// An evicted client closes its idle connections, as no store uses its connection pool anymore.
+var clients = newLRUCache[clientCacheKey, *smopclient.SMOPClient](maxCachedClients, (*smopclient.SMOPClient).CloseIdleConnections)
+
+func newLRUCache[K comparable, V any](maxEntries int, onEvict func(V)) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		max:     maxEntries,
+		lru:     list.New(),
+		entries: map[K]*list.Element{},
+		onEvict: onEvict,
+	}
+}
+
+// get returns the value cached for key, marking it as recently used.
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*lruCacheEntry[K, V]).value, true
+}
+
+// add caches value for key, evicting the least recently used entry if the cache is full.
+// If another value was cached for key in the meantime, that one is kept and returned instead,
+// and value is passed to onEvict like an evicted one.
+func (c *lruCache[K, V]) add(key K, value V) V {
+	kept, evicted := c.insert(key, value)
+	if c.onEvict != nil {
+		for _, v := range evicted {
+			c.onEvict(v)
+		}
+	}
+	return kept
+}
+
+// insert adds value for key like add, returning the values dropped from the cache instead of passing them to onEvict.
+func (c *lruCache[K, V]) insert(key K, value V) (V, []V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*lruCacheEntry[K, V]).value, []V{value}
+	}
+
+	c.entries[key] = c.lru.PushFront(&lruCacheEntry[K, V]{key: key, value: value})
+	var evicted []V
+	for c.lru.Len() > c.max {
+		oldest := c.lru.Remove(c.lru.Back()).(*lruCacheEntry[K, V])
+		delete(c.entries, oldest.key)
+		evicted = append(evicted, oldest.value)
+	}
+	return value, evicted
+}
+
+// len returns the number of cached entries.
+func (c *lruCache[K, V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
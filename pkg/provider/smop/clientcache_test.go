@@ -0,0 +1,176 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestNewSmopClientCached(t *testing.T) {
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "smop-api-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("t0k3n")},
+	}
+	kube := clientfake.NewClientBuilder().WithObjects(tokenSecret).Build()
+	ctx := context.Background()
+
+	first, err := NewGeneratorClient(ctx, kube, makeValidSmopProvider(), "default")
+	require.NoError(t, err)
+	second, err := NewGeneratorClient(ctx, kube, makeValidSmopProvider(), "default")
+	require.NoError(t, err)
+	assert.Same(t, first, second, "identical stores must share a client")
+
+	spec := makeValidSmopProvider()
+	spec.Server.BasePathPrefix = "gateway"
+	changed, err := NewGeneratorClient(ctx, kube, spec, "default")
+	require.NoError(t, err)
+	assert.NotSame(t, first, changed, "a changed store must get a new client")
+
+	tokenSecret.Data["token"] = []byte("r0t4t3d")
+	require.NoError(t, kube.Update(ctx, tokenSecret))
+	// the rotated token is read from its Secret by the next client
+	rotated, err := NewGeneratorClient(ctx, kube, makeValidSmopProvider(), "default")
+	require.NoError(t, err)
+	assert.NotSame(t, first, rotated, "a rotated token must get a new client")
+}
+
+// BenchmarkNewGeneratorClient compares building a client for a store seen before, which only resolves
+// its token and hashes the store, to building one for a new store.
+func BenchmarkNewGeneratorClient(b *testing.B) {
+	kube := clientfake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "smop-api-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("t0k3n")},
+	}).Build()
+	ctx := context.Background()
+
+	b.Run("cache hit", func(b *testing.B) {
+		spec := makeValidSmopProvider()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for range b.N {
+			if _, err := NewGeneratorClient(ctx, kube, spec, "default"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cache miss", func(b *testing.B) {
+		spec := makeValidSmopProvider()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := range b.N {
+			// a distinct prefix makes every store new to the cache
+			spec.Server.BasePathPrefix = fmt.Sprintf("bench-%d", i)
+			if _, err := NewGeneratorClient(ctx, kube, spec, "default"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestClientCacheEviction(t *testing.T) {
+	var evicted []*smopclient.SMOPClient
+	cache := newLRUCache[clientCacheKey, *smopclient.SMOPClient](2, func(c *smopclient.SMOPClient) {
+		evicted = append(evicted, c)
+	})
+	keys := []clientCacheKey{{1}, {2}, {3}}
+	built := map[clientCacheKey]*smopclient.SMOPClient{}
+	for _, key := range keys {
+		built[key] = &smopclient.SMOPClient{}
+	}
+
+	cache.add(keys[0], built[keys[0]])
+	cache.add(keys[1], built[keys[1]])
+	_, ok := cache.get(keys[0])
+	require.True(t, ok)
+	cache.add(keys[2], built[keys[2]])
+
+	assert.Equal(t, 2, cache.len())
+	_, ok = cache.get(keys[1])
+	assert.False(t, ok, "the least recently used client must be evicted")
+	assert.Equal(t, []*smopclient.SMOPClient{built[keys[1]]}, evicted)
+	got, ok := cache.get(keys[0])
+	assert.True(t, ok)
+	assert.Same(t, built[keys[0]], got)
+
+	duplicate := &smopclient.SMOPClient{}
+	assert.Same(t, built[keys[2]], cache.add(keys[2], duplicate), "a cached client must be kept")
+	assert.Same(t, duplicate, evicted[len(evicted)-1], "a client which is not added must be released like an evicted one")
+}
+
+func TestClientCacheEvictionClosesConnections(t *testing.T) {
+	var open atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			open.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			open.Add(-1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	cache := newLRUCache[clientCacheKey, *smopclient.SMOPClient](1, (*smopclient.SMOPClient).CloseIdleConnections)
+	c, err := smopclient.NewSMOPClient(srv.URL+"/site/secrets", "t0k3n")
+	require.NoError(t, err)
+	cache.add(clientCacheKey{1}, c)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+	require.Positive(t, open.Load(), "the client must keep its connection open while it is cached")
+
+	cache.add(clientCacheKey{2}, &smopclient.SMOPClient{})
+	assert.Eventually(t, func() bool { return open.Load() == 0 }, 5*time.Second, 10*time.Millisecond,
+		"an evicted client must close its idle connections")
+}
+
+func TestClientCacheConcurrent(t *testing.T) {
+	cache := newLRUCache[clientCacheKey, *smopclient.SMOPClient](4, nil)
+	var wg sync.WaitGroup
+	for i := range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := clientCacheKey{byte(i % 8)}
+			if _, ok := cache.get(key); !ok {
+				cache.add(key, &smopclient.SMOPClient{})
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 4, cache.len())
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+)
+
+// DefaultedAnnotation is set on the Kubernetes Secret to the sorted JSON array of the remote keys
+// whose remoteRef defaultValue was synced because their SMoP secret is missing, e.g. `["db","redis"]`.
+// It is left out when every secret was read from SMoP. The keys are listed in the ExternalSecret status as well.
+const DefaultedAnnotation = "smop.external-secrets.io/defaulted"
+
+// defaults tracks the remote keys of a Client which were defaulted.
+type defaults struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// observe records the remote key `key` as defaulted.
+func (d *defaults) observe(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.keys == nil {
+		d.keys = map[string]struct{}{}
+	}
+	d.keys[key] = struct{}{}
+}
+
+// list returns the defaulted remote keys, sorted, or nil if no key was defaulted.
+func (d *defaults) list() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.keys) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(d.keys))
+	for key := range d.keys {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// annotation returns the value of the DefaultedAnnotation, or an empty string if no key was defaulted.
+func (d *defaults) annotation() string {
+	keys := d.list()
+	if keys == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(keys)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// DefaultedKeys returns the remote keys whose defaultValue was returned with the Client so far,
+// see esv1.DefaultedKeysHinter.
+func (c *Client) DefaultedKeys() []string {
+	return c.defaults.list()
+}
+
+// defaultValue returns the defaultValue of `ref` in place of a secret reported missing with `err`,
+// when the store allows default values. The value is returned as is, without format conversion.
+func (c *Client) defaultValue(ref esv1.ExternalSecretDataRemoteRef, err error) ([]byte, bool) {
+	if !c.store.AllowDefaultValues || ref.DefaultValue == nil || !errors.Is(err, esv1.NoSecretErr) {
+		return nil, false
+	}
+	log.Info("SMoP secret is missing, using the default value of its remoteRef", "key", ref.Key, "property", ref.Property)
+	c.defaults.observe(ref.Key)
+	return []byte(*ref.DefaultValue), true
+}
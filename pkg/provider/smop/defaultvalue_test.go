@@ -0,0 +1,148 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestGetSecretDefaultValue(t *testing.T) {
+	tests := map[string]struct {
+		store         esv1.SmopProvider
+		key           string
+		defaultValue  *string
+		want          string
+		wantErr       error
+		wantStatus    int
+		wantDefaulted string
+	}{
+		"present": {
+			store:        esv1.SmopProvider{AllowDefaultValues: true},
+			key:          "db",
+			defaultValue: ptr.To("fallback"),
+			want:         "s3cr3t",
+		},
+		"absent with a default": {
+			store:         esv1.SmopProvider{AllowDefaultValues: true},
+			key:           "gone",
+			defaultValue:  ptr.To("fallback"),
+			want:          "fallback",
+			wantDefaulted: `["gone"]`,
+		},
+		"absent with an empty default": {
+			store:         esv1.SmopProvider{AllowDefaultValues: true},
+			key:           "gone",
+			defaultValue:  ptr.To(""),
+			want:          "",
+			wantDefaulted: `["gone"]`,
+		},
+		"absent with a default and the ignore policy": {
+			store:         esv1.SmopProvider{AllowDefaultValues: true, NotFoundPolicy: esv1.SmopNotFoundPolicyIgnore},
+			key:           "gone",
+			defaultValue:  ptr.To("fallback"),
+			want:          "fallback",
+			wantDefaulted: `["gone"]`,
+		},
+		"absent without a default": {
+			store:   esv1.SmopProvider{AllowDefaultValues: true},
+			key:     "gone",
+			wantErr: esv1.NoSecretErr,
+		},
+		"default values not allowed": {
+			key:          "gone",
+			defaultValue: ptr.To("fallback"),
+			wantErr:      esv1.NoSecretErr,
+		},
+		"failure other than a missing secret": {
+			store:        esv1.SmopProvider{AllowDefaultValues: true},
+			key:          "broken",
+			defaultValue: ptr.To("fallback"),
+			wantStatus:   http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{
+				store: &tc.store,
+				smopClient: &fake.SmopClient{
+					GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+						switch name {
+						case "db":
+							return &cg.KV{Path: "db", Secret: cg.RedactedMap{"password": "s3cr3t"}}, nil
+						case "broken":
+							return nil, &smopclient.APIError{StatusCode: http.StatusInternalServerError}
+						}
+						return nil, &smopclient.APIError{StatusCode: http.StatusNotFound}
+					},
+				},
+			}
+
+			got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{
+				Key:          tc.key,
+				Property:     "password",
+				DefaultValue: tc.defaultValue,
+			})
+			if tc.wantErr != nil || tc.wantStatus != 0 {
+				if tc.wantStatus != 0 {
+					assertAPIError(t, err, tc.wantStatus)
+				} else {
+					require.ErrorIs(t, err, tc.wantErr)
+				}
+				assert.NotContains(t, c.SecretAnnotations(), DefaultedAnnotation)
+				assert.Nil(t, c.DefaultedKeys())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+			if tc.wantDefaulted == "" {
+				assert.NotContains(t, c.SecretAnnotations(), DefaultedAnnotation)
+			} else {
+				assert.Equal(t, tc.wantDefaulted, c.SecretAnnotations()[DefaultedAnnotation])
+			}
+		})
+	}
+}
+
+func TestDefaultedAnnotation(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{AllowDefaultValues: true},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return nil, &smopclient.APIError{StatusCode: http.StatusNotFound}
+			},
+		},
+	}
+	for _, key := range []string{"redis", "db", "redis"} {
+		_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: key, DefaultValue: ptr.To("")})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]string{DefaultedAnnotation: `["db","redis"]`}, c.SecretAnnotations())
+	assert.Equal(t, []string{"db", "redis"}, c.DefaultedKeys())
+}
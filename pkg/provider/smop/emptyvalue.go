@@ -0,0 +1,45 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"errors"
+	"fmt"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+)
+
+// ErrEmptySecretValue is returned for an empty secret value when the store sets the Reject EmptyValuePolicy.
+var ErrEmptySecretValue = errors.New("Smop secret value is empty")
+
+// applyEmptyValuePolicy checks the value read for `ref` against the EmptyValuePolicy of the store.
+// Only a value without any byte is empty: whitespace, `""` or `{}` are values like any other.
+// With NotFound an empty value is reported as esv1.NoSecretErr, so NotFoundPolicy and
+// default values apply to it like to a missing secret.
+func (c *Client) applyEmptyValuePolicy(ref esv1.ExternalSecretDataRemoteRef, value []byte) error {
+	if len(value) > 0 {
+		return nil
+	}
+	switch c.store.EmptyValuePolicy {
+	case esv1.SmopEmptyValuePolicyNotFound:
+		return c.applyNotFoundPolicy(fmt.Errorf("%w: secret %q has an empty value", esv1.NoSecretErr, ref.Key))
+	case esv1.SmopEmptyValuePolicyReject:
+		return fmt.Errorf("%w: %q", ErrEmptySecretValue, ref.Key)
+	case esv1.SmopEmptyValuePolicyReturn:
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+)
+
+func TestGetSecretEmptyValuePolicy(t *testing.T) {
+	smopClient := &fake.SmopClient{
+		GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+			if name == "unset" {
+				return &cg.KV{Path: name, Secret: cg.RedactedMap{}}, nil
+			}
+			return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t", "token": "", "space": " ", "quoted": `""`}}, nil
+		},
+	}
+
+	tests := map[string]struct {
+		store   esv1.SmopProvider
+		ref     esv1.ExternalSecretDataRemoteRef
+		want    string
+		wantErr error
+	}{
+		"default returns empty property": {ref: esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "token"}, want: ""},
+		"return":                         {store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyReturn}, ref: esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "token"}, want: ""},
+		"not found":                      {store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyNotFound}, ref: esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "token"}, wantErr: esv1.NoSecretErr},
+		"reject":                         {store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyReject}, ref: esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "token"}, wantErr: ErrEmptySecretValue},
+		"reject keeps a value":           {store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyReject}, ref: esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "password"}, want: "s3cr3t"},
+		"reject keeps whitespace":        {store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyReject}, ref: esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "space"}, want: " "},
+		"reject keeps quotes":            {store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyReject}, ref: esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "quoted"}, want: `""`},
+		"reject keeps a secret without keys": {
+			store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyReject},
+			ref:   esv1.ExternalSecretDataRemoteRef{Key: "unset"},
+			want:  "{}",
+		},
+		"not found ignored": {
+			store:   esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyNotFound, NotFoundPolicy: esv1.SmopNotFoundPolicyIgnore},
+			ref:     esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "token"},
+			wantErr: esv1.SkipSecretErr,
+		},
+		"not found defaulted": {
+			store: esv1.SmopProvider{EmptyValuePolicy: esv1.SmopEmptyValuePolicyNotFound, AllowDefaultValues: true},
+			ref:   esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "token", DefaultValue: ptr.To("fallback")},
+			want:  "fallback",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: &tc.store, smopClient: smopClient}
+
+			got, err := c.GetSecret(context.Background(), tc.ref)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+)
+
+// ErrEnvironmentNotAllowed is returned when the store environment is not one of its allowed environments.
+var ErrEnvironmentNotAllowed = errors.New("environment is not allowed in Smop SecretStore")
+
+// validateEnvironment checks the store environment against its allowed environments.
+func validateEnvironment(spec *esv1.SmopProvider) error {
+	if len(spec.AllowedEnvironments) == 0 {
+		return nil
+	}
+	if !slices.Contains(spec.AllowedEnvironments, spec.Environment) {
+		return fmt.Errorf("%w: %q must be one of %s",
+			ErrEnvironmentNotAllowed, spec.Environment, strings.Join(spec.AllowedEnvironments, ", "))
+	}
+	return nil
+}
+
+// storeFolderPath returns the folder secrets are read from.
+// The environment always comes first, followed by the store folder path: <environment>/<folderPath>.
+func storeFolderPath(spec *esv1.SmopProvider) string {
+	folderPath := strings.Trim(spec.FolderPath, "/")
+	if spec.Environment == "" {
+		return spec.FolderPath
+	}
+	if folderPath == "" {
+		return spec.Environment
+	}
+	return spec.Environment + "/" + folderPath
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+)
+
+func TestStoreFolderPath(t *testing.T) {
+	tests := map[string]struct {
+		environment string
+		folderPath  string
+		want        string
+	}{
+		"no environment":             {folderPath: "apps/db", want: "apps/db"},
+		"environment only":           {environment: "prod", want: "prod"},
+		"environment and folder":     {environment: "prod", folderPath: "apps/db", want: "prod/apps/db"},
+		"folder with slashes":        {environment: "prod", folderPath: "/apps/db/", want: "prod/apps/db"},
+		"environment and root slash": {environment: "prod", folderPath: "/", want: "prod"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &esv1.SmopProvider{Environment: tc.environment, FolderPath: tc.folderPath}
+			assert.Equal(t, tc.want, storeFolderPath(spec))
+		})
+	}
+}
+
+func TestGetSecretEnvironment(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{Environment: "staging", FolderPath: "apps"},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, error) {
+				require.NotNil(t, folderPath)
+				assert.Equal(t, "staging/apps", *folderPath)
+				return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}}, nil
+			},
+		},
+	}
+
+	got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("s3cr3t"), got)
+}
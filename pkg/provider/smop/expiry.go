@@ -0,0 +1,100 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// ExpiresAtAnnotation is set on the Kubernetes Secret to the earliest expiry, in RFC 3339, of the SMoP
+// secrets it was synced from. It is left out when none of them expires.
+const ExpiresAtAnnotation = "smop.external-secrets.io/expires-at"
+
+// expiry tracks the earliest expiry of the secrets read by a Client.
+type expiry struct {
+	mu       sync.Mutex
+	earliest *time.Time
+}
+
+// observe records the expiry of a secret read by the Client, nil if it does not expire.
+func (e *expiry) observe(expiresAt *time.Time) {
+	if expiresAt == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.earliest == nil || expiresAt.Before(*e.earliest) {
+		e.earliest = expiresAt
+	}
+}
+
+// get returns the earliest expiry recorded, nil if none.
+func (e *expiry) get() *time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.earliest
+}
+
+// getSecretWithMetadata reads the secret `name` at `folderPath` and records its expiry and version.
+// A secret past its expiry is rejected with smopclient.ErrSecretExpired when the store sets RejectExpired.
+// The username and account of a privileged-account secret are added to it, see withAccountFields.
+func (c *Client) getSecretWithMetadata(ctx context.Context, name, folderPath string) (*cg.KV, error) {
+	kv, metadata, err := c.smopClient.GetSecretWithMetadata(ctx, name, &folderPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.observeMetadata(name, folderPath, metadata); err != nil {
+		return nil, err
+	}
+	return withAccountFields(kv, metadata.AccountFields()), nil
+}
+
+// observeMetadata records the expiry, version, type and encoded properties of the secret `name` at `folderPath` read by the Client.
+// A secret past its expiry is rejected with smopclient.ErrSecretExpired when the store sets RejectExpired.
+func (c *Client) observeMetadata(name, folderPath string, metadata *smopclient.KVMetadata) error {
+	if c.store.RejectExpired && metadata.Expired(time.Now()) {
+		return fmt.Errorf("%w: %q expired at %s", smopclient.ErrSecretExpired, name, metadata.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	c.expiry.observe(metadata.ExpiresAt)
+	path := smopclient.KVRef{Name: name, FolderPath: &folderPath}.String()
+	c.versions.observe(path, metadata.Version)
+	c.types.observe(path, metadata.Type)
+	c.encodings.observe(path, metadata.Tags)
+	return nil
+}
+
+// SecretAnnotations returns the ExpiresAtAnnotation and DefaultedAnnotation for the secrets read with the Client so far.
+func (c *Client) SecretAnnotations() map[string]string {
+	var annotations map[string]string
+	if earliest := c.expiry.get(); earliest != nil {
+		annotations = map[string]string{ExpiresAtAnnotation: earliest.UTC().Format(time.RFC3339)}
+	}
+	if defaulted := c.defaults.annotation(); defaulted != "" {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[DefaultedAnnotation] = defaulted
+	}
+	return annotations
+}
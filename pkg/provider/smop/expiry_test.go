@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestGetSecretExpiry(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := now.Add(-time.Minute)
+	nearExpiry := now.Add(time.Minute)
+	later := now.Add(time.Hour)
+	expiries := map[string]*time.Time{"expired": &expired, "near-expiry": &nearExpiry, "later": &later, "plain": nil}
+	smopClient := &fake.SmopClient{
+		GetSecretWithMetadataFn: func(_ context.Context, name string, _ *string) (*cg.KV, *smopclient.KVMetadata, error) {
+			return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}},
+				&smopclient.KVMetadata{Path: name, ExpiresAt: expiries[name]}, nil
+		},
+	}
+
+	tests := map[string]struct {
+		rejectExpired bool
+		keys          []string
+		wantErr       error
+		want          map[string]string
+	}{
+		"earliest expiry is annotated": {
+			keys: []string{"later", "near-expiry", "plain"},
+			want: map[string]string{ExpiresAtAnnotation: nearExpiry.Format(time.RFC3339)},
+		},
+		"expired secret is returned by default": {
+			keys: []string{"near-expiry", "expired"},
+			want: map[string]string{ExpiresAtAnnotation: expired.Format(time.RFC3339)},
+		},
+		"expired secret is rejected": {
+			rejectExpired: true,
+			keys:          []string{"expired"},
+			wantErr:       smopclient.ErrSecretExpired,
+		},
+		"near-expiry secret is returned when rejecting expired secrets": {
+			rejectExpired: true,
+			keys:          []string{"near-expiry"},
+			want:          map[string]string{ExpiresAtAnnotation: nearExpiry.Format(time.RFC3339)},
+		},
+		"no annotation without expiry": {
+			keys: []string{"plain"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{smopClient: smopClient, store: &esv1.SmopProvider{RejectExpired: tc.rejectExpired}}
+			for _, key := range tc.keys {
+				got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: key, Property: "password"})
+				if tc.wantErr != nil {
+					assert.ErrorIs(t, err, tc.wantErr)
+					assert.Nil(t, got)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, []byte("s3cr3t"), got)
+			}
+			assert.Equal(t, tc.want, c.SecretAnnotations())
+		})
+	}
+}
+
+func TestGetAllSecretsExpiry(t *testing.T) {
+	nearExpiry := time.Now().UTC().Add(time.Minute).Truncate(time.Second)
+	later := nearExpiry.Add(time.Hour)
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "apps"},
+		smopClient: &fake.SmopClient{
+			ListSecretsFn: func(context.Context, *string) ([]smopclient.KVRef, error) {
+				return []smopclient.KVRef{{Name: "db"}, {Name: "api"}, {Name: "plain"}}, nil
+			},
+			BatchGetSecretsFn: func(_ context.Context, refs []smopclient.KVRef) (map[string]smopclient.BatchResult, error) {
+				kv := &cg.KV{Secret: cg.RedactedMap{"password": "s3cr3t"}}
+				return map[string]smopclient.BatchResult{
+					refs[0].String(): {KV: kv, ExpiresAt: &later},
+					refs[1].String(): {KV: kv, ExpiresAt: &nearExpiry},
+					refs[2].String(): {KV: kv},
+				}, nil
+			},
+		},
+	}
+
+	got, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, map[string]string{ExpiresAtAnnotation: nearExpiry.Format(time.RFC3339)}, c.SecretAnnotations())
+}
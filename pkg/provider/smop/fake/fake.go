@@ -0,0 +1,192 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// SmopClient is a fake implementation of the SMoP SecretsClientInterface.
+type SmopClient struct {
+	BaseURLFn    func() *url.URL
+	GetSecretFn  func(ctx context.Context, name string, folderPath *string) (*cg.KV, error)
+	GetSecretsFn func(ctx context.Context, folderPath *string) ([]cg.KVListItem, error)
+
+	GetSecretWithMetadataFn func(ctx context.Context, name string, folderPath *string) (*cg.KV, *smopclient.KVMetadata, error)
+	GetSecretMetadataFn     func(ctx context.Context, name string, folderPath *string) (*smopclient.KVMetadata, error)
+	GetSecretFieldFn        func(ctx context.Context, name string, folderPath *string, field string) (*cg.KV, *smopclient.KVMetadata, error)
+
+	GetSecretTypeFn func(ctx context.Context, name string, folderPath *string) (string, error)
+	DeleteSecretFn  func(ctx context.Context, name string, folderPath *string) error
+	SetSecretFn     func(ctx context.Context, name string, folderPath *string, secret map[string]any, tags map[string]string) error
+	WalkSecretsFn   func(ctx context.Context, folderPath *string) ([]smopclient.KVRef, error)
+	ListSecretsFn   func(ctx context.Context, folderPath *string) ([]smopclient.KVRef, error)
+
+	ListSecretsPagesFn func(ctx context.Context, folderPath *string, cursor string, maxPages int) ([]smopclient.KVRef, string, error)
+	QuerySecretsFn     func(ctx context.Context, query smopclient.Query) ([]smopclient.KVRef, error)
+
+	BatchGetSecretsFn func(ctx context.Context, refs []smopclient.KVRef) (map[string]smopclient.BatchResult, error)
+	CheckAPIVersionFn func(ctx context.Context) (string, error)
+	CheckoutSecretFn  func(ctx context.Context, name string, folderPath *string, ttl time.Duration) (*smopclient.Checkout, error)
+	CheckinSecretFn   func(ctx context.Context, name string, folderPath *string, checkoutID string) error
+	RotateSecretFn    func(ctx context.Context, name string, folderPath *string) (*smopclient.Rotation, error)
+}
+
+func (c *SmopClient) BaseURL() *url.URL {
+	if c.BaseURLFn != nil {
+		return c.BaseURLFn()
+	}
+	return &url.URL{Scheme: "https", Host: "api.beyondtrust.io"}
+}
+
+func (c *SmopClient) SetBaseURL(_ string) error {
+	return nil
+}
+
+// ContextWithRetryBudget returns ctx unchanged: the fake never retries.
+func (c *SmopClient) ContextWithRetryBudget(ctx context.Context) context.Context {
+	return ctx
+}
+
+func (c *SmopClient) GetSecret(ctx context.Context, name string, folderPath *string) (*cg.KV, error) {
+	return c.GetSecretFn(ctx, name, folderPath)
+}
+
+// GetSecretWithMetadata calls GetSecretWithMetadataFn, or GetSecretFn with metadata holding only the path if it is not set.
+func (c *SmopClient) GetSecretWithMetadata(ctx context.Context, name string, folderPath *string) (*cg.KV, *smopclient.KVMetadata, error) {
+	if c.GetSecretWithMetadataFn != nil {
+		return c.GetSecretWithMetadataFn(ctx, name, folderPath)
+	}
+	kv, err := c.GetSecretFn(ctx, name, folderPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kv, &smopclient.KVMetadata{Path: name}, nil
+}
+
+// GetSecretField calls GetSecretFieldFn, or GetSecretWithMetadata if it is not set.
+func (c *SmopClient) GetSecretField(ctx context.Context, name string, folderPath *string, field string) (*cg.KV, *smopclient.KVMetadata, error) {
+	if c.GetSecretFieldFn != nil {
+		return c.GetSecretFieldFn(ctx, name, folderPath, field)
+	}
+	return c.GetSecretWithMetadata(ctx, name, folderPath)
+}
+
+func (c *SmopClient) GetSecrets(ctx context.Context, folderPath *string) ([]cg.KVListItem, error) {
+	return c.GetSecretsFn(ctx, folderPath)
+}
+
+func (c *SmopClient) GetSecretType(ctx context.Context, name string, folderPath *string) (string, error) {
+	if c.GetSecretTypeFn != nil {
+		return c.GetSecretTypeFn(ctx, name, folderPath)
+	}
+	return "", nil
+}
+
+// GetSecretMetadata calls GetSecretMetadataFn, or returns metadata holding only the path if it is not set.
+func (c *SmopClient) GetSecretMetadata(ctx context.Context, name string, folderPath *string) (*smopclient.KVMetadata, error) {
+	if c.GetSecretMetadataFn != nil {
+		return c.GetSecretMetadataFn(ctx, name, folderPath)
+	}
+	return &smopclient.KVMetadata{Path: name}, nil
+}
+
+func (c *SmopClient) DeleteSecret(ctx context.Context, name string, folderPath *string) error {
+	return c.DeleteSecretFn(ctx, name, folderPath)
+}
+
+func (c *SmopClient) SetSecret(ctx context.Context, name string, folderPath *string, secret map[string]any, tags map[string]string) error {
+	return c.SetSecretFn(ctx, name, folderPath, secret, tags)
+}
+
+func (c *SmopClient) CheckAPIVersion(ctx context.Context) (string, error) {
+	if c.CheckAPIVersionFn != nil {
+		return c.CheckAPIVersionFn(ctx)
+	}
+	return "", nil
+}
+
+// ListSecrets calls ListSecretsFn, or lists the KVs returned by GetSecretsFn if it is not set.
+func (c *SmopClient) ListSecrets(ctx context.Context, folderPath *string) ([]smopclient.KVRef, error) {
+	if c.ListSecretsFn != nil {
+		return c.ListSecretsFn(ctx, folderPath)
+	}
+	items, err := c.GetSecretsFn(ctx, folderPath)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]smopclient.KVRef, 0, len(items))
+	for _, item := range items {
+		refs = append(refs, smopclient.KVRef{Name: item.Path, FolderPath: folderPath})
+	}
+	return refs, nil
+}
+
+func (c *SmopClient) WalkSecrets(ctx context.Context, folderPath *string) ([]smopclient.KVRef, error) {
+	return c.WalkSecretsFn(ctx, folderPath)
+}
+
+func (c *SmopClient) QuerySecrets(ctx context.Context, query smopclient.Query) ([]smopclient.KVRef, error) {
+	return c.QuerySecretsFn(ctx, query)
+}
+
+// ListSecretsPages calls ListSecretsPagesFn, or lists every secret with ListSecretsFn if it is not set.
+func (c *SmopClient) ListSecretsPages(ctx context.Context, folderPath *string, cursor string, maxPages int) ([]smopclient.KVRef, string, error) {
+	if c.ListSecretsPagesFn != nil {
+		return c.ListSecretsPagesFn(ctx, folderPath, cursor, maxPages)
+	}
+	refs, err := c.ListSecretsFn(ctx, folderPath)
+	return refs, "", err
+}
+
+// BatchGetSecrets calls BatchGetSecretsFn, or GetSecretFn for every ref if it is not set.
+func (c *SmopClient) BatchGetSecrets(ctx context.Context, refs []smopclient.KVRef) (map[string]smopclient.BatchResult, error) {
+	if c.BatchGetSecretsFn != nil {
+		return c.BatchGetSecretsFn(ctx, refs)
+	}
+	results := make(map[string]smopclient.BatchResult, len(refs))
+	for _, ref := range refs {
+		kv, err := c.GetSecretFn(ctx, ref.Name, ref.FolderPath)
+		results[ref.String()] = smopclient.BatchResult{KV: kv, Err: err}
+	}
+	return results, nil
+}
+
+func (c *SmopClient) CheckoutSecret(ctx context.Context, name string, folderPath *string, ttl time.Duration) (*smopclient.Checkout, error) {
+	return c.CheckoutSecretFn(ctx, name, folderPath, ttl)
+}
+
+func (c *SmopClient) CheckinSecret(ctx context.Context, name string, folderPath *string, checkoutID string) error {
+	if c.CheckinSecretFn != nil {
+		return c.CheckinSecretFn(ctx, name, folderPath, checkoutID)
+	}
+	return nil
+}
+
+// RotateSecret calls RotateSecretFn, or reports a completed rotation if it is not set.
+func (c *SmopClient) RotateSecret(ctx context.Context, name string, folderPath *string) (*smopclient.Rotation, error) {
+	if c.RotateSecretFn != nil {
+		return c.RotateSecretFn(ctx, name, folderPath)
+	}
+	return &smopclient.Rotation{}, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"fmt"
+	"path"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+)
+
+// keyFilter drops keys which must never be synced from SMoP.
+type keyFilter struct {
+	allow []string
+	deny  []string
+}
+
+// newKeyFilter builds a keyFilter from the store spec, validating its patterns.
+// It returns nil if the spec does not filter keys.
+func newKeyFilter(spec *esv1.SmopKeyFilter) (*keyFilter, error) {
+	if spec == nil || (len(spec.Allow) == 0 && len(spec.Deny) == 0) {
+		return nil, nil
+	}
+
+	for _, pattern := range append(append([]string{}, spec.Allow...), spec.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid Smop key filter pattern %q: %w", pattern, err)
+		}
+	}
+
+	return &keyFilter{
+		allow: spec.Allow,
+		deny:  spec.Deny,
+	}, nil
+}
+
+// allowed reports whether the key may be synced. Deny takes precedence over allow.
+func (f *keyFilter) allowed(key string) bool {
+	if f == nil {
+		return true
+	}
+
+	if matchAny(f.deny, key) {
+		return false
+	}
+
+	return len(f.allow) == 0 || matchAny(f.allow, key)
+}
+
+// filterMap removes the keys which may not be synced from the map.
+func (f *keyFilter) filterMap(in map[string][]byte) map[string][]byte {
+	if f == nil {
+		return in
+	}
+
+	out := make(map[string][]byte, len(in))
+	for key, value := range in {
+		if f.allowed(key) {
+			out[key] = value
+		}
+	}
+
+	if filtered := len(in) - len(out); filtered > 0 {
+		log.V(1).Info("filtered keys from secret map", "filtered", filtered)
+	}
+
+	return out
+}
+
+func matchAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		// patterns are validated in newKeyFilter
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+)
+
+func TestKeyFilter(t *testing.T) {
+	tests := map[string]struct {
+		spec *esv1.SmopKeyFilter
+		want map[string]bool
+	}{
+		"no filter allows everything": {
+			want: map[string]bool{"db-password": true, "root-key": true},
+		},
+		"allow list": {
+			spec: &esv1.SmopKeyFilter{Allow: []string{"db-*"}},
+			want: map[string]bool{"db-password": true, "root-key": false},
+		},
+		"deny list": {
+			spec: &esv1.SmopKeyFilter{Deny: []string{"root-*"}},
+			want: map[string]bool{"db-password": true, "root-key": false},
+		},
+		"deny takes precedence over allow": {
+			spec: &esv1.SmopKeyFilter{Allow: []string{"*"}, Deny: []string{"*-password"}},
+			want: map[string]bool{"db-password": false, "root-key": true},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := newKeyFilter(tc.spec)
+			require.NoError(t, err)
+			for key, want := range tc.want {
+				assert.Equal(t, want, f.allowed(key), key)
+			}
+		})
+	}
+}
+
+func TestNewKeyFilterInvalidPattern(t *testing.T) {
+	_, err := newKeyFilter(&esv1.SmopKeyFilter{Deny: []string{"[a-"}})
+	assert.Error(t, err)
+}
+
+func TestGetAllSecretsKeyFilter(t *testing.T) {
+	f, err := newKeyFilter(&esv1.SmopKeyFilter{Deny: []string{"root-*"}})
+	require.NoError(t, err)
+
+	var fetched []string
+	c := &Client{
+		store:     &esv1.SmopProvider{},
+		keyFilter: f,
+		smopClient: &fake.SmopClient{
+			GetSecretsFn: func(_ context.Context, _ *string) ([]cg.KVListItem, error) {
+				return []cg.KVListItem{{Path: "db-password"}, {Path: "root-key"}}, nil
+			},
+			GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+				fetched = append(fetched, name)
+				return &cg.KV{Secret: cg.RedactedMap{"value": name}}, nil
+			},
+		},
+	}
+
+	got, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"db-password": []byte(`{"value":"db-password"}`)}, got)
+	assert.Equal(t, []string{"db-password"}, fetched)
+}
+
+func TestGetSecretMapKeyFilter(t *testing.T) {
+	f, err := newKeyFilter(&esv1.SmopKeyFilter{Allow: []string{"user*"}})
+	require.NoError(t, err)
+
+	c := &Client{
+		store:     &esv1.SmopProvider{},
+		keyFilter: f,
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{"username": "admin", "password": "s3cr3t"}}, nil
+			},
+		},
+	}
+
+	got, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"username": []byte("admin")}, got)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// findCursors tracks the pagination cursors of the unfinished listings of a Client, see FindCursors.
+type findCursors struct {
+	mu      sync.Mutex
+	pending map[string]string
+}
+
+// findCursorKey returns the key of the pagination cursor of the listing of `folderPath`.
+func findCursorKey(folderPath string) string {
+	return "smop:" + folderPath
+}
+
+// listSecretsPages lists FindPageLimit pages of the secrets at `folderPath`, resuming from the cursor
+// persisted by the last reconcile. A cursor SMoP no longer accepts restarts the listing.
+func (c *Client) listSecretsPages(ctx context.Context, folderPath string) ([]smopclient.KVRef, error) {
+	key := findCursorKey(folderPath)
+	maxPages := int(*c.store.FindPageLimit)
+
+	cursor := esutils.FindCursor(ctx, key)
+	refs, next, err := c.smopClient.ListSecretsPages(ctx, &folderPath, cursor, maxPages)
+	if errors.Is(err, smopclient.ErrInvalidCursor) {
+		log.Info("restarting SMoP listing from an invalid cursor", "folderPath", folderPath, "error", err.Error())
+		refs, next, err = c.smopClient.ListSecretsPages(ctx, &folderPath, "", maxPages)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.cursors.mu.Lock()
+	defer c.cursors.mu.Unlock()
+	if next == "" {
+		delete(c.cursors.pending, key)
+		return refs, nil
+	}
+	if c.cursors.pending == nil {
+		c.cursors.pending = map[string]string{}
+	}
+	c.cursors.pending[key] = next
+	return refs, nil
+}
+
+// FindCursors returns the cursors of the listings of dataFrom.find which did not finish
+// within FindPageLimit pages, so the next reconcile resumes them.
+func (c *Client) FindCursors() map[string]string {
+	c.cursors.mu.Lock()
+	defer c.cursors.mu.Unlock()
+	if len(c.cursors.pending) == 0 {
+		return nil
+	}
+	return maps.Clone(c.cursors.pending)
+}
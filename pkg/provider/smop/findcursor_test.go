@@ -0,0 +1,156 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// pagedSmopClient serves `pages` pages of a single secret each, with the page number as cursor.
+func pagedSmopClient(t *testing.T, pages int) *fake.SmopClient {
+	return &fake.SmopClient{
+		ListSecretsPagesFn: func(_ context.Context, folderPath *string, cursor string, maxPages int) ([]smopclient.KVRef, string, error) {
+			assert.Equal(t, "apps", *folderPath)
+			start := 1
+			if cursor != "" {
+				var err error
+				if start, err = strconv.Atoi(cursor); err != nil {
+					return nil, "", smopclient.ErrInvalidCursor
+				}
+			}
+			var refs []smopclient.KVRef
+			page := start
+			for ; page < start+maxPages && page <= pages; page++ {
+				refs = append(refs, smopclient.KVRef{Name: fmt.Sprintf("secret-%d", page), FolderPath: folderPath})
+			}
+			if page > pages {
+				return refs, "", nil
+			}
+			return refs, strconv.Itoa(page), nil
+		},
+		GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+			return &cg.KV{Path: name, Secret: cg.RedactedMap{"value": name}}, nil
+		},
+	}
+}
+
+func TestGetAllSecretsFindPageLimit(t *testing.T) {
+	smopClient := pagedSmopClient(t, 5)
+	store := &esv1.SmopProvider{FolderPath: "apps", FindPageLimit: ptr.To[int32](2)}
+
+	var found [][]string
+	cursors := map[string]string(nil)
+	for range 4 {
+		c := &Client{store: store, smopClient: smopClient}
+		ctx := esutils.ContextWithFindCursors(context.Background(), cursors)
+		got, err := c.GetAllSecrets(ctx, esv1.ExternalSecretFind{})
+		require.NoError(t, err)
+
+		var keys []string
+		for key := range got {
+			keys = append(keys, key)
+		}
+		found = append(found, keys)
+		cursors = c.FindCursors()
+		if cursors == nil {
+			break
+		}
+	}
+
+	require.Len(t, found, 3, "a listing of 5 pages finishes in 3 reconciles of 2 pages")
+	assert.ElementsMatch(t, []string{"secret-1", "secret-2"}, found[0])
+	assert.ElementsMatch(t, []string{"secret-3", "secret-4"}, found[1])
+	assert.ElementsMatch(t, []string{"secret-5"}, found[2])
+}
+
+func TestGetAllSecretsFindPageLimitCursors(t *testing.T) {
+	store := &esv1.SmopProvider{FolderPath: "apps", FindPageLimit: ptr.To[int32](2)}
+
+	tests := map[string]struct {
+		cursor      string
+		wantKeys    []string
+		wantCursors map[string]string
+	}{
+		"first reconcile": {
+			wantKeys:    []string{"secret-1", "secret-2"},
+			wantCursors: map[string]string{"smop:apps": "3"},
+		},
+		"resumed": {
+			cursor:      "3",
+			wantKeys:    []string{"secret-3"},
+			wantCursors: nil,
+		},
+		"invalid cursor restarts": {
+			cursor:      "not-a-page",
+			wantKeys:    []string{"secret-1", "secret-2"},
+			wantCursors: map[string]string{"smop:apps": "3"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: store, smopClient: pagedSmopClient(t, 3)}
+			ctx := esutils.ContextWithFindCursors(context.Background(), map[string]string{"smop:apps": tc.cursor})
+			got, err := c.GetAllSecrets(ctx, esv1.ExternalSecretFind{})
+			require.NoError(t, err)
+
+			var keys []string
+			for key := range got {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tc.wantKeys, keys)
+			assert.Equal(t, tc.wantCursors, c.FindCursors())
+		})
+	}
+}
+
+func TestValidateStoreFindPageLimit(t *testing.T) {
+	p := &Provider{}
+	for name, tc := range map[string]struct {
+		limit     int32
+		recursive bool
+		wantErr   string
+	}{
+		"valid":          {limit: 10},
+		"zero":           {limit: 0, wantErr: "must be positive"},
+		"with recursive": {limit: 10, recursive: true, wantErr: "not supported with findRecursive"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spec := makeValidSmopProvider()
+			spec.FindPageLimit = ptr.To(tc.limit)
+			spec.FindRecursive = tc.recursive
+			_, err := p.ValidateStore(makeSmopStore(spec))
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
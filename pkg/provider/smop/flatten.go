@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"fmt"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+)
+
+const defaultFlattenSeparator = "."
+
+// flattenValues flattens the nested objects of values into keys joined by the separator of spec,
+// down to its max depth. Values are returned unchanged when spec is nil.
+// Two values flattened to the same key are reported instead of one silently replacing the other.
+func flattenValues(values map[string]any, spec *esv1.SmopFlatten) (map[string]any, error) {
+	if spec == nil {
+		return values, nil
+	}
+	separator := spec.Separator
+	if separator == "" {
+		separator = defaultFlattenSeparator
+	}
+
+	flat := make(map[string]any, len(values))
+	if err := flattenInto(flat, "", values, separator, int(spec.MaxDepth), 0); err != nil {
+		return nil, err
+	}
+	return flat, nil
+}
+
+// flattenInto adds the values to flat, prefixing their keys with prefix.
+// Objects at depth maxDepth are added as-is, a maxDepth of 0 flattens every level.
+func flattenInto(flat map[string]any, prefix string, values map[string]any, separator string, maxDepth, depth int) error {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + separator + key
+		}
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 && (maxDepth == 0 || depth < maxDepth) {
+			if err := flattenInto(flat, key, nested, separator, maxDepth, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := flat[key]; ok {
+			return fmt.Errorf("flattening the secret yields the key %q more than once", key)
+		}
+		flat[key] = value
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+)
+
+const nestedSecret = `{
+	"user": "app",
+	"db": {
+		"host": "db.internal",
+		"port": 5432,
+		"replica": {"host": "replica.internal", "tls": {"mode": "verify-full"}}
+	},
+	"tags": ["a", "b"],
+	"empty": {}
+}`
+
+func TestGetSecretMapFlatten(t *testing.T) {
+	tests := map[string]struct {
+		flatten  *esv1.SmopFlatten
+		property string
+		want     map[string]string
+		err      string
+	}{
+		"top-level only by default": {
+			want: map[string]string{
+				"user":  "app",
+				"db":    `{"host":"db.internal","port":5432,"replica":{"host":"replica.internal","tls":{"mode":"verify-full"}}}`,
+				"tags":  `["a","b"]`,
+				"empty": `{}`,
+			},
+		},
+		"every level": {
+			flatten: &esv1.SmopFlatten{},
+			want: map[string]string{
+				"user":                "app",
+				"db.host":             "db.internal",
+				"db.port":             "5432",
+				"db.replica.host":     "replica.internal",
+				"db.replica.tls.mode": "verify-full",
+				"tags":                `["a","b"]`,
+				"empty":               `{}`,
+			},
+		},
+		"separator": {
+			flatten: &esv1.SmopFlatten{Separator: "_"},
+			want: map[string]string{
+				"user":                "app",
+				"db_host":             "db.internal",
+				"db_port":             "5432",
+				"db_replica_host":     "replica.internal",
+				"db_replica_tls_mode": "verify-full",
+				"tags":                `["a","b"]`,
+				"empty":               `{}`,
+			},
+		},
+		"max depth": {
+			flatten: &esv1.SmopFlatten{MaxDepth: 2},
+			want: map[string]string{
+				"user":            "app",
+				"db.host":         "db.internal",
+				"db.port":         "5432",
+				"db.replica.host": "replica.internal",
+				"db.replica.tls":  `{"mode":"verify-full"}`,
+				"tags":            `["a","b"]`,
+				"empty":           `{}`,
+			},
+		},
+		"property": {
+			flatten:  &esv1.SmopFlatten{},
+			property: "db",
+			want: map[string]string{
+				"host":             "db.internal",
+				"port":             "5432",
+				"replica.host":     "replica.internal",
+				"replica.tls.mode": "verify-full",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newFlattenTestClient(t, nestedSecret, tt.flatten)
+			got, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "app", Property: tt.property})
+			require.NoError(t, err)
+
+			want := make(map[string][]byte, len(tt.want))
+			for k, v := range tt.want {
+				want[k] = []byte(v)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestGetSecretMapFlattenCollision(t *testing.T) {
+	c := newFlattenTestClient(t, `{"db.host": "a", "db": {"host": "b"}}`, &esv1.SmopFlatten{})
+	_, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "app"})
+	assert.ErrorContains(t, err, `"db.host" more than once`)
+}
+
+func newFlattenTestClient(t *testing.T, secret string, flatten *esv1.SmopFlatten) *Client {
+	t.Helper()
+	var values cg.RedactedMap
+	require.NoError(t, json.Unmarshal([]byte(secret), &values))
+	return &Client{
+		store: &esv1.SmopProvider{Flatten: flatten},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Path: name, Secret: values}, nil
+			},
+		},
+	}
+}
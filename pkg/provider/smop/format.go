@@ -0,0 +1,291 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"strings"
+
+	keystore "github.com/pavlo-v-chernykh/keystore-go/v4"
+	gopkcs12 "software.sslmate.com/src/go-pkcs12"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils/resolvers"
+)
+
+const (
+	// Keys of the Secret a converted certificate maps to, see convertFormatMap.
+	formatKeyCert   = "tls.crt"
+	formatKeyKey    = "tls.key"
+	formatKeyPKCS12 = "keystore.p12"
+	formatKeyJKS    = "keystore.jks"
+
+	// jksAlias is the alias of the private key entry of a converted JKS keystore.
+	jksAlias = "certificate"
+)
+
+// ErrFormatConversion is returned when a certificate value cannot be converted to another format.
+var ErrFormatConversion = errors.New("failed to convert Smop secret format")
+
+// certificateBundle is a private key with its certificate and the chain of CA certificates.
+type certificateBundle struct {
+	key     crypto.PrivateKey
+	cert    *x509.Certificate
+	caCerts []*x509.Certificate
+}
+
+// convertFormat converts a certificate value to the target format of conversion.
+// A PEM value holds the private key followed by the certificate chain, keystores are returned in binary form.
+func (c *Client) convertFormat(ctx context.Context, value []byte, conversion *esv1.ExternalSecretFormatConversion) ([]byte, error) {
+	converted, err := c.convertFormatMap(ctx, value, conversion)
+	if err != nil {
+		return nil, err
+	}
+	if conversion.To == esv1.ExternalSecretFormatPEM {
+		return append(converted[formatKeyKey], converted[formatKeyCert]...), nil
+	}
+	return converted[formatKeyOf(conversion.To)], nil
+}
+
+// convertFormatMap converts a certificate value to the target format of conversion, keyed like a Secret:
+// a PEM pair as tls.crt and tls.key, keystores as keystore.p12 or keystore.jks.
+func (c *Client) convertFormatMap(ctx context.Context, value []byte, conversion *esv1.ExternalSecretFormatConversion) (map[string][]byte, error) {
+	passphrase, err := c.formatPassphrase(ctx, conversion)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := convertCertificate(value, conversion.From, conversion.To, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w from %s to %s: %w", ErrFormatConversion, conversion.From, conversion.To, err)
+	}
+	return converted, nil
+}
+
+// formatPassphrase resolves the passphrase of a conversion in the namespace of the ExternalSecret.
+func (c *Client) formatPassphrase(ctx context.Context, conversion *esv1.ExternalSecretFormatConversion) (string, error) {
+	if conversion.PassphraseSecretRef == nil {
+		if conversion.To == esv1.ExternalSecretFormatJKS {
+			return "", fmt.Errorf("%w from %s to %s: a JKS keystore requires passphraseSecretRef", ErrFormatConversion, conversion.From, conversion.To)
+		}
+		return "", nil
+	}
+	if c.kube == nil {
+		return "", fmt.Errorf("%w: passphraseSecretRef cannot be resolved", ErrFormatConversion)
+	}
+	passphrase, err := resolvers.SecretKeyRef(ctx, c.kube, resolvers.EmptyStoreKind, c.namespace, conversion.PassphraseSecretRef)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to resolve passphraseSecretRef: %w", ErrFormatConversion, err)
+	}
+	return passphrase, nil
+}
+
+// convertCertificate decodes value in the from format and encodes it in the to format.
+func convertCertificate(value []byte, from, to esv1.ExternalSecretFormat, passphrase string) (map[string][]byte, error) {
+	if from == to {
+		return nil, errors.New("the formats must differ")
+	}
+
+	var (
+		bundle *certificateBundle
+		err    error
+	)
+	switch from {
+	case esv1.ExternalSecretFormatPEM:
+		bundle, err = decodePEMBundle(value)
+	case esv1.ExternalSecretFormatPKCS12:
+		bundle, err = decodePKCS12Bundle(value, passphrase)
+	default:
+		return nil, fmt.Errorf("unsupported source format %q", from)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// keystores are salted with a stream derived from their content, so an unchanged
+	// value converts to the same keystore and the Secret is not rewritten on each refresh
+	random := mathrand.NewChaCha8(sha256.Sum256(append(append([]byte{}, value...), passphrase...)))
+
+	switch to {
+	case esv1.ExternalSecretFormatPEM:
+		return encodePEMBundle(bundle)
+	case esv1.ExternalSecretFormatPKCS12:
+		pfx, err := gopkcs12.Modern.WithRand(random).Encode(bundle.key, bundle.cert, bundle.caCerts, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{formatKeyPKCS12: pfx}, nil
+	case esv1.ExternalSecretFormatJKS:
+		jks, err := encodeJKS(bundle, passphrase, random)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{formatKeyJKS: jks}, nil
+	}
+	return nil, fmt.Errorf("unsupported target format %q", to)
+}
+
+// formatKeyOf returns the Secret key of a keystore format.
+func formatKeyOf(format esv1.ExternalSecretFormat) string {
+	if format == esv1.ExternalSecretFormatJKS {
+		return formatKeyJKS
+	}
+	return formatKeyPKCS12
+}
+
+// decodePKCS12Bundle decodes a PKCS12 keystore, given in binary form or base64 encoded.
+func decodePKCS12Bundle(value []byte, passphrase string) (*certificateBundle, error) {
+	key, cert, caCerts, err := gopkcs12.DecodeChain(value, passphrase)
+	if err != nil {
+		// keystores are stored in SMoP as strings, where binary values are base64 encoded
+		decoded, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(string(value)))
+		if decodeErr != nil {
+			return nil, fmt.Errorf("failed to decode PKCS12 keystore: %w", err)
+		}
+		if key, cert, caCerts, err = gopkcs12.DecodeChain(decoded, passphrase); err != nil {
+			return nil, fmt.Errorf("failed to decode PKCS12 keystore: %w", err)
+		}
+	}
+	return &certificateBundle{key: key, cert: cert, caCerts: caCerts}, nil
+}
+
+// decodePEMBundle decodes a PEM bundle of a private key and its certificate chain, in any order.
+// The certificate matching the private key is the leaf, the others are its CA certificates.
+func decodePEMBundle(value []byte) (*certificateBundle, error) {
+	var (
+		key   crypto.PrivateKey
+		certs []*x509.Certificate
+	)
+	for rest := value; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			if key != nil {
+				return nil, errors.New("PEM bundle holds more than one private key")
+			}
+			parsed, err := parsePEMPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			key = parsed
+		}
+	}
+	if key == nil {
+		return nil, errors.New("PEM bundle holds no private key")
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	bundle := &certificateBundle{key: key}
+	for _, cert := range certs {
+		if bundle.cert == nil && publicKeyEqual(cert.PublicKey, signer.Public()) {
+			bundle.cert = cert
+			continue
+		}
+		bundle.caCerts = append(bundle.caCerts, cert)
+	}
+	if bundle.cert == nil {
+		return nil, errors.New("PEM bundle holds no certificate of the private key")
+	}
+	return bundle, nil
+}
+
+// parsePEMPrivateKey parses a PKCS8, PKCS1 or SEC 1 encoded private key.
+func parsePEMPrivateKey(der []byte) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("failed to parse private key: unsupported or encrypted key")
+}
+
+// publicKeyEqual reports whether two public keys are the same.
+func publicKeyEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(b)
+}
+
+// encodePEMBundle encodes the private key as PKCS8 and the certificate chain starting with the leaf.
+func encodePEMBundle(bundle *certificateBundle) (map[string][]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(bundle.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	var chain bytes.Buffer
+	for _, cert := range append([]*x509.Certificate{bundle.cert}, bundle.caCerts...) {
+		if err := pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}); err != nil {
+			return nil, err
+		}
+	}
+	return map[string][]byte{
+		formatKeyCert: chain.Bytes(),
+		formatKeyKey:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}, nil
+}
+
+// encodeJKS encodes the bundle as a JKS keystore with a single private key entry.
+// The entry is dated with the start of validity of the certificate, so the keystore is stable.
+func encodeJKS(bundle *certificateBundle, passphrase string, random io.Reader) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(bundle.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	chain := make([]keystore.Certificate, 0, len(bundle.caCerts)+1)
+	for _, cert := range append([]*x509.Certificate{bundle.cert}, bundle.caCerts...) {
+		chain = append(chain, keystore.Certificate{Type: "X509", Content: cert.Raw})
+	}
+
+	ks := keystore.New(keystore.WithCustomRandomNumberGenerator(random))
+	entry := keystore.PrivateKeyEntry{CreationTime: bundle.cert.NotBefore, PrivateKey: der, CertificateChain: chain}
+	if err := ks.SetPrivateKeyEntry(jksAlias, entry, []byte(passphrase)); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := ks.Store(&out, []byte(passphrase)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
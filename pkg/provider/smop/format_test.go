@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	keystore "github.com/pavlo-v-chernykh/keystore-go/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gopkcs12 "software.sslmate.com/src/go-pkcs12"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+)
+
+func TestFormatConversion(t *testing.T) {
+	certPEM, keyPEM := newTestKeyPair(t)
+	bundle, err := decodePEMBundle(append(append([]byte{}, keyPEM...), certPEM...))
+	require.NoError(t, err)
+	pfx, err := gopkcs12.Modern.Encode(bundle.key, bundle.cert, nil, "1234")
+	require.NoError(t, err)
+
+	kube := clientfake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore-pass", Namespace: "apps"},
+		Data:       map[string][]byte{"pass": []byte("1234"), "wrong": []byte("4321")},
+	}).Build()
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{
+					"keystore": base64.StdEncoding.EncodeToString(pfx),
+					"pem":      string(certPEM) + string(keyPEM),
+				}}, nil
+			},
+		},
+		kube:      kube,
+		namespace: "apps",
+	}
+	passphrase := func(key string) *esmeta.SecretKeySelector {
+		return &esmeta.SecretKeySelector{Name: "keystore-pass", Key: key}
+	}
+	ref := func(property string, conversion esv1.ExternalSecretFormatConversion) esv1.ExternalSecretDataRemoteRef {
+		return esv1.ExternalSecretDataRemoteRef{Key: "tls", Property: property, FormatConversion: &conversion}
+	}
+	ctx := context.Background()
+
+	t.Run("PKCS12 to a PEM pair", func(t *testing.T) {
+		got, err := c.GetSecretMap(ctx, ref("keystore", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPKCS12, To: esv1.ExternalSecretFormatPEM, PassphraseSecretRef: passphrase("pass"),
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"tls.crt": certPEM, "tls.key": keyPEM}, got)
+	})
+
+	t.Run("PKCS12 to a PEM bundle", func(t *testing.T) {
+		got, err := c.GetSecret(ctx, ref("keystore", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPKCS12, To: esv1.ExternalSecretFormatPEM, PassphraseSecretRef: passphrase("pass"),
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, string(keyPEM)+string(certPEM), string(got))
+	})
+
+	t.Run("PEM to JKS", func(t *testing.T) {
+		conversion := esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPEM, To: esv1.ExternalSecretFormatJKS, PassphraseSecretRef: passphrase("pass"),
+		}
+		got, err := c.GetSecretMap(ctx, ref("pem", conversion))
+		require.NoError(t, err)
+
+		ks := keystore.New()
+		require.NoError(t, ks.Load(bytes.NewReader(got["keystore.jks"]), []byte("1234")))
+		entry, err := ks.GetPrivateKeyEntry(jksAlias, []byte("1234"))
+		require.NoError(t, err)
+		require.Len(t, entry.CertificateChain, 1)
+		assert.Equal(t, bundle.cert.Raw, entry.CertificateChain[0].Content)
+
+		again, err := c.GetSecretMap(ctx, ref("pem", conversion))
+		require.NoError(t, err)
+		assert.Equal(t, got, again, "an unchanged value must convert to the same keystore")
+	})
+
+	t.Run("PEM to PKCS12", func(t *testing.T) {
+		got, err := c.GetSecret(ctx, ref("pem", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPEM, To: esv1.ExternalSecretFormatPKCS12,
+		}))
+		require.NoError(t, err)
+		_, cert, _, err := gopkcs12.DecodeChain(got, "")
+		require.NoError(t, err)
+		assert.Equal(t, bundle.cert.Raw, cert.Raw)
+	})
+
+	for name, tc := range map[string]esv1.ExternalSecretDataRemoteRef{
+		"wrong passphrase": ref("keystore", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPKCS12, To: esv1.ExternalSecretFormatPEM, PassphraseSecretRef: passphrase("wrong"),
+		}),
+		"missing passphrase secret": ref("keystore", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPKCS12, To: esv1.ExternalSecretFormatPEM, PassphraseSecretRef: passphrase("missing"),
+		}),
+		"JKS without passphrase": ref("pem", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPEM, To: esv1.ExternalSecretFormatJKS,
+		}),
+		"value of another format": ref("pem", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPKCS12, To: esv1.ExternalSecretFormatPEM,
+		}),
+		"same formats": ref("pem", esv1.ExternalSecretFormatConversion{
+			From: esv1.ExternalSecretFormatPEM, To: esv1.ExternalSecretFormatPEM,
+		}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.GetSecret(ctx, tc)
+			assert.ErrorIs(t, err, ErrFormatConversion)
+		})
+	}
+}
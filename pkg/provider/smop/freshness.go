@@ -0,0 +1,189 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+var _ esv1.SourceDeletionHandler = &Provider{}
+
+const (
+	metricsSubsystem = "smop"
+
+	// maxFreshnessPaths bounds the number of path label values of the freshness metric per store.
+	// Paths synced once the limit is reached have no series until paths of the store are released.
+	maxFreshnessPaths = 250
+)
+
+var lastSyncTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Subsystem: metricsSubsystem,
+	Name:      "last_successful_sync_timestamp_seconds",
+	Help:      "Unix time a SMoP secret was last read successfully, by store and SMoP path",
+}, []string{"store_kind", "store_namespace", "store_name", "path"})
+
+// storeRef identifies the SecretStore or ClusterSecretStore a Client was created for.
+type storeRef struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// newStoreRef returns the storeRef of `store`.
+func newStoreRef(store esv1.GenericStore) storeRef {
+	return storeRef{kind: store.GetKind(), namespace: store.GetNamespace(), name: store.GetName()}
+}
+
+// freshnessPaths tracks the path label values of the freshness metric per store, with the sources
+// reading each path, keeping the metric cardinality bounded by maxFreshnessPaths.
+// The series of a path is deleted once no source reads it anymore, see release.
+type freshnessPaths struct {
+	mu    sync.Mutex
+	paths map[storeRef]map[string]map[esutils.SourceRef]struct{}
+}
+
+var syncedPaths = newFreshnessPaths()
+
+func newFreshnessPaths() *freshnessPaths {
+	return &freshnessPaths{paths: map[storeRef]map[string]map[esutils.SourceRef]struct{}{}}
+}
+
+// track records that `source` read `path` of `store`. It reports false, leaving the path without a series,
+// once the store has maxFreshnessPaths other paths.
+func (f *freshnessPaths) track(store storeRef, source esutils.SourceRef, path string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	paths, ok := f.paths[store]
+	if !ok {
+		paths = map[string]map[esutils.SourceRef]struct{}{}
+		f.paths[store] = paths
+	}
+	sources, ok := paths[path]
+	if !ok {
+		if len(paths) >= maxFreshnessPaths {
+			return false
+		}
+		sources = map[esutils.SourceRef]struct{}{}
+		paths[path] = sources
+	}
+	sources[source] = struct{}{}
+	return true
+}
+
+// release drops `source` from the paths of `store` it no longer reads, those missing from `keep`,
+// and deletes the series of the paths no source reads anymore.
+func (f *freshnessPaths) release(store storeRef, source esutils.SourceRef, keep map[string]struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	paths := f.paths[store]
+	for path, sources := range paths {
+		if _, ok := keep[path]; ok {
+			continue
+		}
+		delete(sources, source)
+		if len(sources) == 0 {
+			delete(paths, path)
+			lastSyncTimestamp.DeleteLabelValues(store.kind, store.namespace, store.name, path)
+		}
+	}
+	if len(paths) == 0 {
+		delete(f.paths, store)
+	}
+}
+
+// forget releases every path read by `source`, in any store.
+func (f *freshnessPaths) forget(source esutils.SourceRef) {
+	f.mu.Lock()
+	stores := make([]storeRef, 0, len(f.paths))
+	for store := range f.paths {
+		stores = append(stores, store)
+	}
+	f.mu.Unlock()
+
+	for _, store := range stores {
+		f.release(store, source, nil)
+	}
+}
+
+// freshness holds the paths a Client recorded the freshness metric of, and whether a read failed,
+// so Close releases the paths its source no longer reads, see releaseSyncs.
+type freshness struct {
+	mu     sync.Mutex
+	source *esutils.SourceRef
+	synced map[string]struct{}
+	failed bool
+}
+
+// recordSync sets the freshness metric of the secret `ref` to `now`.
+func (c *Client) recordSync(ctx context.Context, ref smopclient.KVRef, now time.Time) {
+	source, _ := esutils.SourceRefFromContext(ctx)
+	path := ref.String()
+	if !syncedPaths.track(c.storeRef, source, path) {
+		return
+	}
+	lastSyncTimestamp.WithLabelValues(c.storeRef.kind, c.storeRef.namespace, c.storeRef.name, path).
+		Set(float64(now.Unix()))
+
+	c.freshness.mu.Lock()
+	defer c.freshness.mu.Unlock()
+	c.freshness.source = &source
+	if c.freshness.synced == nil {
+		c.freshness.synced = map[string]struct{}{}
+	}
+	c.freshness.synced[path] = struct{}{}
+}
+
+// recordReadFailure keeps Close from releasing any path, as a failed read may have left paths unread.
+func (c *Client) recordReadFailure() {
+	c.freshness.mu.Lock()
+	defer c.freshness.mu.Unlock()
+	c.freshness.failed = true
+}
+
+// releaseSyncs deletes the series of the paths the source of the Client read before, but not
+// with the Client, unless a read of the Client failed. Only an ExternalSecret is a source whose
+// reads are all made with one Client.
+func (c *Client) releaseSyncs() {
+	c.freshness.mu.Lock()
+	defer c.freshness.mu.Unlock()
+	source := c.freshness.source
+	if source == nil || source.Kind != esv1.ExtSecretKind || c.freshness.failed {
+		return
+	}
+	syncedPaths.release(c.storeRef, *source, c.freshness.synced)
+}
+
+// SourceDeleted implements esv1.SourceDeletionHandler: the series of the paths only read by
+// the deleted ExternalSecret are deleted.
+func (p *Provider) SourceDeleted(namespace, name string) {
+	syncedPaths.forget(esutils.SourceRef{Kind: esv1.ExtSecretKind, Namespace: namespace, Name: name})
+}
+
+func init() {
+	metrics.Registry.MustRegister(lastSyncTimestamp)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestRecordSync(t *testing.T) {
+	store := storeRef{kind: esv1.SecretStoreKind, namespace: "default", name: "freshness"}
+	c := &Client{
+		storeRef: store,
+		store:    &esv1.SmopProvider{FolderPath: "apps"},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+				switch name {
+				case "gone":
+					return nil, &smopclient.APIError{StatusCode: 404}
+				case "empty":
+					return &cg.KV{Path: name}, nil
+				}
+				return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}}, nil
+			},
+		},
+	}
+	gauge := func(path string) float64 {
+		return testutil.ToFloat64(lastSyncTimestamp.WithLabelValues(store.kind, store.namespace, store.name, path))
+	}
+
+	before := time.Now().Unix()
+	_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "password"})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, gauge("apps/db"), float64(before))
+
+	_, err = c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "gone"})
+	require.Error(t, err)
+	assert.Zero(t, gauge("apps/gone"), "a failed read must not count as a sync")
+
+	// a read whose value cannot be returned does not count as a sync either
+	_, err = c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "empty"})
+	require.ErrorContains(t, err, "secret value is nil")
+	_, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "empty"})
+	require.ErrorContains(t, err, "secret value is nil")
+	assert.Zero(t, gauge("apps/empty"), "a nil value must not count as a sync")
+
+	_, err = c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "other", Property: "username"})
+	require.Error(t, err)
+	_, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "other", Properties: []string{"username"}})
+	require.Error(t, err)
+	assert.Zero(t, gauge("apps/other"), "a missing property must not count as a sync")
+
+	_, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "other"})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, gauge("apps/other"), float64(before))
+}
+
+func TestFreshnessPathsBounded(t *testing.T) {
+	paths := newFreshnessPaths()
+	store := storeRef{kind: esv1.ClusterSecretStoreKind, name: "bounded"}
+	source := esutils.SourceRef{Kind: esv1.ExtSecretKind, Namespace: "apps", Name: "db"}
+
+	for i := range maxFreshnessPaths {
+		assert.True(t, paths.track(store, source, fmt.Sprintf("apps/secret-%d", i)))
+	}
+	assert.False(t, paths.track(store, source, "apps/one-too-many"), "paths beyond the limit must have no series")
+	assert.True(t, paths.track(store, source, "apps/secret-0"), "a tracked path must keep its series")
+
+	other := storeRef{kind: esv1.ClusterSecretStoreKind, name: "other"}
+	assert.True(t, paths.track(other, source, "apps/one-too-many"), "the limit applies per store")
+
+	paths.release(store, source, map[string]struct{}{"apps/secret-0": {}})
+	assert.True(t, paths.track(store, source, "apps/one-too-many"), "released paths must free their label")
+}
+
+func TestFreshnessRelease(t *testing.T) {
+	previous := syncedPaths
+	syncedPaths = newFreshnessPaths()
+	t.Cleanup(func() { syncedPaths = previous })
+
+	store := storeRef{kind: esv1.SecretStoreKind, namespace: "apps", name: "release"}
+	newClient := func() *Client {
+		return &Client{
+			storeRef: store,
+			store:    &esv1.SmopProvider{},
+			smopClient: &fake.SmopClient{
+				GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+					if name == "gone" {
+						return nil, &smopclient.APIError{StatusCode: 404}
+					}
+					return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}}, nil
+				},
+			},
+		}
+	}
+	series := func() int { return testutil.CollectAndCount(lastSyncTimestamp) }
+	reconcile := func(source esutils.SourceRef, keys ...string) {
+		t.Helper()
+		ctx := esutils.ContextWithSourceRef(context.Background(), source)
+		c := newClient()
+		for _, key := range keys {
+			_, _ = c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: key})
+		}
+		require.NoError(t, c.Close(ctx))
+	}
+	db := esutils.SourceRef{Kind: esv1.ExtSecretKind, Namespace: "apps", Name: "db"}
+	cache := esutils.SourceRef{Kind: esv1.ExtSecretKind, Namespace: "apps", Name: "cache"}
+	start := series()
+
+	reconcile(db, "db", "shared")
+	reconcile(cache, "shared")
+	assert.Equal(t, start+2, series())
+
+	reconcile(db, "db", "gone")
+	assert.Equal(t, start+2, series(), "paths must be kept when a read failed")
+
+	reconcile(db, "db")
+	assert.Equal(t, start+2, series(), "a path must be kept while another ExternalSecret reads it")
+	reconcile(cache, "cache")
+	assert.Equal(t, start+2, series(), "a path no ExternalSecret reads must be deleted")
+
+	(&Provider{}).SourceDeleted("apps", "db")
+	(&Provider{}).SourceDeleted("apps", "cache")
+	assert.Equal(t, start, series(), "the paths of deleted ExternalSecrets must be deleted")
+}
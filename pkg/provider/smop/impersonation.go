@@ -0,0 +1,87 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// ImpersonationAnnotation lets an ExternalSecret choose the impersonation subject,
+// if the store sets impersonation.allowAnnotationOverride.
+const ImpersonationAnnotation = "smop.external-secrets.io/on-behalf-of"
+
+// ErrImpersonationSubjectConflict is returned when both an impersonation subject and ServiceAccount are set.
+var ErrImpersonationSubjectConflict = errors.New("invalid Smop impersonation in Smop SecretStore: subject and serviceAccountRef are mutually exclusive")
+
+// validateImpersonation checks the impersonation configuration of the store.
+func validateImpersonation(store esv1.GenericStore, spec *esv1.SmopImpersonation) error {
+	if spec == nil {
+		return nil
+	}
+	if spec.Subject != "" && spec.ServiceAccountRef != nil {
+		return ErrImpersonationSubjectConflict
+	}
+	if spec.Subject != "" {
+		return smopclient.ValidateImpersonationSubject(spec.Subject)
+	}
+	if spec.ServiceAccountRef != nil {
+		return esutils.ValidateServiceAccountSelector(store, *spec.ServiceAccountRef)
+	}
+	return nil
+}
+
+// impersonationSubject returns the subject requests of the store are attributed to, if any.
+// A ServiceAccount of a SecretStore always lives in the store namespace.
+func impersonationSubject(spec *esv1.SmopImpersonation, namespace, storeKind string) string {
+	switch {
+	case spec == nil:
+		return ""
+	case spec.Subject != "":
+		return spec.Subject
+	case spec.ServiceAccountRef != nil:
+		if storeKind == esv1.ClusterSecretStoreKind && spec.ServiceAccountRef.Namespace != nil {
+			namespace = *spec.ServiceAccountRef.Namespace
+		}
+		return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, spec.ServiceAccountRef.Name)
+	}
+	return ""
+}
+
+// requestContext returns the context of the requests of a single call of the provider. They share the
+// retry budget of the store (see esv1.SmopRetries), and are attributed to the subject of the
+// ImpersonationAnnotation of the ExternalSecret, if the store allows it.
+func (c *Client) requestContext(ctx context.Context) (context.Context, error) {
+	ctx = c.smopClient.ContextWithRetryBudget(ctx)
+	if c.store.Impersonation == nil || !c.store.Impersonation.AllowAnnotationOverride {
+		return ctx, nil
+	}
+
+	subject, ok := esutils.SourceAnnotation(ctx, ImpersonationAnnotation)
+	if !ok {
+		return ctx, nil
+	}
+	if err := smopclient.ValidateImpersonationSubject(subject); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", ImpersonationAnnotation, err)
+	}
+	return smopclient.ContextWithImpersonationSubject(ctx, subject), nil
+}
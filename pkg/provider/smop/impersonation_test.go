@@ -0,0 +1,122 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestValidateStoreImpersonation(t *testing.T) {
+	tests := map[string]struct {
+		impersonation *esv1.SmopImpersonation
+		wantErr       error
+	}{
+		"subject": {
+			impersonation: &esv1.SmopImpersonation{Subject: "ci@example.com"},
+		},
+		"service account": {
+			impersonation: &esv1.SmopImpersonation{ServiceAccountRef: &esmeta.ServiceAccountSelector{Name: "eso"}},
+		},
+		"annotation only": {
+			impersonation: &esv1.SmopImpersonation{AllowAnnotationOverride: true},
+		},
+		"subject and service account": {
+			impersonation: &esv1.SmopImpersonation{
+				Subject:           "ci@example.com",
+				ServiceAccountRef: &esmeta.ServiceAccountSelector{Name: "eso"},
+			},
+			wantErr: ErrImpersonationSubjectConflict,
+		},
+		"invalid subject": {
+			impersonation: &esv1.SmopImpersonation{Subject: "not valid"},
+			wantErr:       smopclient.ErrInvalidImpersonationSubject,
+		},
+	}
+
+	p := &Provider{}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := makeValidSmopProvider()
+			spec.Impersonation = tc.impersonation
+			_, err := p.ValidateStore(makeSmopStore(spec))
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestImpersonationSubject(t *testing.T) {
+	other := "other"
+	tests := map[string]struct {
+		spec      *esv1.SmopImpersonation
+		storeKind string
+		want      string
+	}{
+		"none": {},
+		"subject": {
+			spec: &esv1.SmopImpersonation{Subject: "ci@example.com"},
+			want: "ci@example.com",
+		},
+		"service account": {
+			spec:      &esv1.SmopImpersonation{ServiceAccountRef: &esmeta.ServiceAccountSelector{Name: "eso", Namespace: &other}},
+			storeKind: esv1.SecretStoreKind,
+			want:      "system:serviceaccount:apps:eso",
+		},
+		"cluster store service account": {
+			spec:      &esv1.SmopImpersonation{ServiceAccountRef: &esmeta.ServiceAccountSelector{Name: "eso", Namespace: &other}},
+			storeKind: esv1.ClusterSecretStoreKind,
+			want:      "system:serviceaccount:other:eso",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, impersonationSubject(tc.spec, "apps", tc.storeKind))
+		})
+	}
+}
+
+func TestRequestContextImpersonation(t *testing.T) {
+	annotated := esutils.ContextWithSourceAnnotations(context.Background(), map[string]string{
+		ImpersonationAnnotation: "jane@example.com",
+	})
+
+	c := &Client{store: &esv1.SmopProvider{}, smopClient: &fake.SmopClient{}}
+	ctx, err := c.requestContext(annotated)
+	require.NoError(t, err)
+	assert.Equal(t, annotated, ctx, "the annotation must be ignored unless the store allows it")
+
+	c.store.Impersonation = &esv1.SmopImpersonation{AllowAnnotationOverride: true}
+	ctx, err = c.requestContext(annotated)
+	require.NoError(t, err)
+	assert.NotEqual(t, annotated, ctx)
+
+	invalid := esutils.ContextWithSourceAnnotations(context.Background(), map[string]string{
+		ImpersonationAnnotation: "not valid",
+	})
+	_, err = c.requestContext(invalid)
+	assert.ErrorIs(t, err, smopclient.ErrInvalidImpersonationSubject)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// ManagedSecret is a KV pushed by ESO, as identified by its built-in push tags (see esv1.SmopPushMetadata).
+type ManagedSecret struct {
+	// Path is the full path of the KV, "<folder>/<name>".
+	Path string
+	// Cluster is the "eso.cluster" tag, empty if the pushing store sets no ClusterName.
+	Cluster string
+	// Kind, Namespace and Name identify the PushSecret which pushed the KV.
+	Kind      string
+	Namespace string
+	Name      string
+	// SecretName is the Kubernetes Secret the KV was pushed from.
+	SecretName string
+}
+
+// ListManagedSecrets lists the KVs carrying the "eso.managed-by" push tag in the folder PushSecret
+// resolves remote keys against (see readFolderPath) and all its sub-folders, e.g. to find KVs whose PushSecret no longer exists.
+// The tags are taken from the listing, or read from the sidecar KVs if the store keeps them there.
+// KVs pushed without PushMetadata carry no tags and are not listed.
+func (c *Client) ListManagedSecrets(ctx context.Context) ([]ManagedSecret, error) {
+	ctx, err := c.requestContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	folderPath, err := c.readFolderPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := c.smopClient.WalkSecrets(ctx, &folderPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", mapNotFound(err))
+	}
+
+	var managed []ManagedSecret
+	for _, ref := range refs {
+		// in Sidecar mode the tags of a KV are the values of its sidecar KV
+		if c.sidecarMetadata() && !c.isSidecar(ref.Name) {
+			continue
+		}
+
+		tags, err := c.managedTags(ctx, ref)
+		if errors.Is(mapNotFound(err), esv1.NoSecretErr) {
+			// deleted since it was listed
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags of secret %q: %w", ref.String(), err)
+		}
+		if tags[tagManagedBy] != managedByESO {
+			continue
+		}
+
+		ref.Name = strings.TrimSuffix(ref.Name, sidecarSuffix)
+		managed = append(managed, ManagedSecret{
+			Path:       ref.String(),
+			Cluster:    tags[tagCluster],
+			Kind:       tags[tagKind],
+			Namespace:  tags[tagNamespace],
+			Name:       tags[tagName],
+			SecretName: tags[tagSecret],
+		})
+	}
+	return managed, nil
+}
+
+// managedTags returns the push tags of a listed KV: the tags reported by the listing,
+// or the values of a sidecar KV in Sidecar mode.
+func (c *Client) managedTags(ctx context.Context, ref smopclient.KVRef) (map[string]string, error) {
+	if !c.sidecarMetadata() {
+		return ref.Tags, nil
+	}
+
+	kv, err := c.smopClient.GetSecret(ctx, ref.Name, ref.FolderPath)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(kv.Secret))
+	for key, value := range kv.Secret {
+		if str, ok := value.(string); ok {
+			tags[key] = str
+		}
+	}
+	return tags, nil
+}
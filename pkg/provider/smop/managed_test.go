@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestListManagedSecrets(t *testing.T) {
+	apps, team := "apps", "apps/team"
+	owner := map[string]string{
+		tagManagedBy: managedByESO,
+		tagCluster:   "prod",
+		tagKind:      "PushSecret",
+		tagNamespace: "default",
+		tagName:      "push-db",
+		tagSecret:    "db-credentials",
+	}
+	want := []ManagedSecret{
+		{Path: "apps/db", Cluster: "prod", Kind: "PushSecret", Namespace: "default", Name: "push-db", SecretName: "db-credentials"},
+		{Path: "apps/team/api", Cluster: "prod", Kind: "PushSecret", Namespace: "default", Name: "push-db", SecretName: "db-credentials"},
+	}
+
+	t.Run("tags", func(t *testing.T) {
+		c := &Client{
+			store: &esv1.SmopProvider{FolderPath: "apps"},
+			smopClient: &fake.SmopClient{
+				WalkSecretsFn: func(_ context.Context, folderPath *string) ([]smopclient.KVRef, error) {
+					assert.Equal(t, "apps", *folderPath)
+					return []smopclient.KVRef{
+						{Name: "db", FolderPath: &apps, Tags: owner},
+						{Name: "manual", FolderPath: &apps},
+						{Name: "other-tool", FolderPath: &apps, Tags: map[string]string{tagManagedBy: "another-operator"}},
+						{Name: "api", FolderPath: &team, Tags: owner},
+					}, nil
+				},
+				GetSecretMetadataFn: func(_ context.Context, name string, _ *string) (*smopclient.KVMetadata, error) {
+					t.Errorf("unexpected metadata request for %q: the listing reports the tags", name)
+					return nil, nil
+				},
+			},
+		}
+
+		got, err := c.ListManagedSecrets(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("sidecar", func(t *testing.T) {
+		sidecar := cg.RedactedMap{}
+		for key, value := range owner {
+			sidecar[key] = value
+		}
+		c := &Client{
+			store: &esv1.SmopProvider{
+				FolderPath:   "apps",
+				PushMetadata: &esv1.SmopPushMetadata{Mode: esv1.SmopPushMetadataSidecar},
+			},
+			smopClient: &fake.SmopClient{
+				WalkSecretsFn: func(_ context.Context, _ *string) ([]smopclient.KVRef, error) {
+					return []smopclient.KVRef{
+						{Name: "db", FolderPath: &apps},
+						{Name: "db" + sidecarSuffix, FolderPath: &apps},
+						{Name: "manual", FolderPath: &apps},
+						{Name: "api", FolderPath: &team},
+						{Name: "api" + sidecarSuffix, FolderPath: &team},
+						{Name: "gone" + sidecarSuffix, FolderPath: &team},
+					}, nil
+				},
+				GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+					require.Contains(t, []string{"db" + sidecarSuffix, "api" + sidecarSuffix, "gone" + sidecarSuffix}, name, "only sidecar KVs are read")
+					if name == "gone"+sidecarSuffix {
+						// deleted since it was listed
+						return nil, &smopclient.APIError{StatusCode: http.StatusNotFound, Path: name}
+					}
+					return &cg.KV{Path: name, Secret: sidecar}, nil
+				},
+			},
+		}
+
+		got, err := c.ListManagedSecrets(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("listing error", func(t *testing.T) {
+		c := &Client{
+			store: &esv1.SmopProvider{},
+			smopClient: &fake.SmopClient{
+				WalkSecretsFn: func(_ context.Context, _ *string) ([]smopclient.KVRef, error) {
+					return nil, &smopclient.APIError{StatusCode: http.StatusForbidden}
+				},
+			},
+		}
+
+		_, err := c.ListManagedSecrets(context.Background())
+		assertAPIError(t, err, http.StatusForbidden)
+	})
+}
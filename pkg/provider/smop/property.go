@@ -0,0 +1,239 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// propertySegment is a single step of a property path: an object key or an array index.
+type propertySegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// ErrAmbiguousProperty is returned when several keys of a secret match a property ignoring case,
+// and none of them matches it exactly.
+var ErrAmbiguousProperty = errors.New("ambiguous Smop property")
+
+// extractProperty returns the value of the property in the secret.
+// A property matching a top-level key exactly is returned as-is, otherwise the property
+// is read as a path of object keys and array indices, e.g. "certs[0]" or "servers[1].host".
+// Values which are JSON encoded strings are decoded while walking the path.
+// An out-of-range array index is reported as esv1.NoSecretErr.
+// With ignoreCase, keys are matched ignoring case, see lookupKey.
+func extractProperty(secret map[string]any, property string, ignoreCase bool) (any, error) {
+	if value, ok, err := lookupKey(secret, property, ignoreCase); ok || err != nil {
+		return value, err
+	}
+
+	segments, err := parsePropertyPath(property)
+	if err != nil {
+		return nil, err
+	}
+
+	var current any = secret
+	for _, seg := range segments {
+		current = decodeJSONString(current)
+
+		if seg.isIndex {
+			list, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("property %s: value is not an array", property)
+			}
+			if seg.index >= len(list) {
+				return nil, fmt.Errorf("property %s: index %d out of range (len %d): %w", property, seg.index, len(list), esv1.NoSecretErr)
+			}
+			current = list[seg.index]
+			continue
+		}
+
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("property %s not found in secret", property)
+		}
+		value, ok, err := lookupKey(object, seg.key, ignoreCase)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("property %s not found in secret", property)
+		}
+		current = value
+	}
+
+	return current, nil
+}
+
+// extractPropertyMap returns the property in the secret as a map, for secrets whose
+// properties are themselves key-value maps. Map values are returned as-is,
+// only string values are parsed as a JSON object.
+func extractPropertyMap(secret map[string]any, property string, ignoreCase bool) (map[string]any, error) {
+	value, err := extractProperty(secret, property, ignoreCase)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return v, nil
+	case string:
+		var decoded map[string]any
+		if err := smopclient.UnmarshalUseNumber([]byte(v), &decoded); err != nil {
+			return nil, fmt.Errorf("property %s is not a key-value map: %w", property, err)
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("property %s is not a key-value map: got %T", property, value)
+}
+
+// selectProperties returns the requested top-level keys of values.
+// A requested key missing from values is an error, or left out when skipMissing is set.
+// No requested keys select all of values. With ignoreCase, keys are matched ignoring case, see lookupKey.
+// Selected values are keyed by the requested key.
+func selectProperties[V any](values map[string]V, properties []string, skipMissing, ignoreCase bool) (map[string]V, error) {
+	if len(properties) == 0 {
+		return values, nil
+	}
+
+	selected := make(map[string]V, len(properties))
+	for _, property := range properties {
+		value, ok, err := lookupKey(values, property, ignoreCase)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if skipMissing {
+				continue
+			}
+			return nil, fmt.Errorf("property %s not found in secret", property)
+		}
+		selected[property] = value
+	}
+	return selected, nil
+}
+
+// lookupKey returns the value of `key` in values. A key matching exactly is preferred;
+// with ignoreCase, a single key matching ignoring case is returned otherwise,
+// and several of them are reported as ErrAmbiguousProperty.
+func lookupKey[V any](values map[string]V, key string, ignoreCase bool) (V, bool, error) {
+	if value, ok := values[key]; ok || !ignoreCase {
+		return value, ok, nil
+	}
+
+	var matches []string
+	for k := range values {
+		if strings.EqualFold(k, key) {
+			matches = append(matches, k)
+		}
+	}
+	var zero V
+	switch len(matches) {
+	case 0:
+		return zero, false, nil
+	case 1:
+		return values[matches[0]], true, nil
+	}
+	slices.Sort(matches)
+	return zero, false, fmt.Errorf("%w %s: matches %s", ErrAmbiguousProperty, key, strings.Join(matches, ", "))
+}
+
+// parsePropertyPath splits a property path like "servers[1].host" into its segments.
+func parsePropertyPath(property string) ([]propertySegment, error) {
+	var segments []propertySegment
+
+	for _, part := range strings.Split(property, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key == "" && !strings.HasPrefix(part, "[") {
+			return nil, fmt.Errorf("invalid property path %q", property)
+		}
+		if key != "" {
+			segments = append(segments, propertySegment{key: key})
+		}
+
+		for rest != "" {
+			index, after, found := strings.Cut(rest, "]")
+			if !found {
+				return nil, fmt.Errorf("invalid property path %q: missing ]", property)
+			}
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid property path %q: invalid index %q", property, index)
+			}
+			segments = append(segments, propertySegment{index: i, isIndex: true})
+
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("invalid property path %q", property)
+			}
+			rest = after[1:]
+		}
+	}
+
+	return segments, nil
+}
+
+// decodeJSONString decodes string values holding a JSON object or array,
+// so paths can walk into them. Other values are returned unchanged.
+func decodeJSONString(value any) any {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	trimmed := strings.TrimSpace(str)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return value
+	}
+
+	var decoded any
+	if err := smopclient.UnmarshalUseNumber([]byte(trimmed), &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+// getSecretProperty reads the secret `name` at `folderPath` to extract its `property` from.
+// With ServerSideProperty set on the store, Smop is asked to return only the property,
+// see smopclient.SMOPClient.GetSecretField. Checkouts always return the whole value.
+func (c *Client) getSecretProperty(ctx context.Context, name, folderPath, property string) (*cg.KV, error) {
+	if property == "" || !c.store.ServerSideProperty || c.store.Checkout != nil {
+		return c.getSecret(ctx, name, folderPath)
+	}
+
+	kv, metadata, err := c.smopClient.GetSecretField(ctx, name, &folderPath, property)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.observeMetadata(name, folderPath, metadata); err != nil {
+		return nil, err
+	}
+	return withAccountFields(kv, metadata.AccountFields()), nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestExtractProperty(t *testing.T) {
+	var secret map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"password": "s3cr3t",
+		"a.b": "literal",
+		"certs": ["cert-0", "cert-1"],
+		"servers": [{"host": "db-0"}, {"host": "db-1", "ports": [5432, 5433]}],
+		"chain": "[\"root\", \"intermediate\"]",
+		"matrix": [[1, 2], [3, 4]]
+	}`), &secret))
+
+	tests := map[string]struct {
+		property string
+		want     any
+		wantErr  error
+		errMsg   string
+	}{
+		"top-level key": {
+			property: "password",
+			want:     "s3cr3t",
+		},
+		"top-level key containing a dot": {
+			property: "a.b",
+			want:     "literal",
+		},
+		"array index": {
+			property: "certs[0]",
+			want:     "cert-0",
+		},
+		"nested array of objects": {
+			property: "servers[1].host",
+			want:     "db-1",
+		},
+		"array in nested object": {
+			property: "servers[1].ports[1]",
+			want:     float64(5433),
+		},
+		"nested arrays": {
+			property: "matrix[1][0]",
+			want:     float64(3),
+		},
+		"array encoded as JSON string": {
+			property: "chain[1]",
+			want:     "intermediate",
+		},
+		"index out of range": {
+			property: "certs[2]",
+			wantErr:  esv1.NoSecretErr,
+		},
+		"nested index out of range": {
+			property: "servers[5].host",
+			wantErr:  esv1.NoSecretErr,
+		},
+		"missing key": {
+			property: "servers[0].port",
+			errMsg:   "not found",
+		},
+		"index on object": {
+			property: "password[0]",
+			errMsg:   "not an array",
+		},
+		"invalid index": {
+			property: "certs[x]",
+			errMsg:   "invalid index",
+		},
+		"unterminated index": {
+			property: "certs[0",
+			errMsg:   "missing ]",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := extractProperty(secret, tc.property, false)
+			switch {
+			case tc.wantErr != nil:
+				assert.ErrorIs(t, err, tc.wantErr)
+			case tc.errMsg != "":
+				assert.ErrorContains(t, err, tc.errMsg)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestExtractPropertyMap(t *testing.T) {
+	secret := map[string]any{
+		"db":       map[string]any{"user": "app", "port": float64(5432)},
+		"encoded":  `{"user": "app"}`,
+		"password": "s3cr3t",
+		"ports":    []any{float64(5432)},
+	}
+
+	tests := map[string]struct {
+		property string
+		want     map[string]any
+		errMsg   string
+	}{
+		"map value": {
+			property: "db",
+			want:     map[string]any{"user": "app", "port": float64(5432)},
+		},
+		"JSON encoded string value": {
+			property: "encoded",
+			want:     map[string]any{"user": "app"},
+		},
+		"plain string value": {
+			property: "password",
+			errMsg:   "property password is not a key-value map",
+		},
+		"array value": {
+			property: "ports",
+			errMsg:   "property ports is not a key-value map: got []interface {}",
+		},
+		"missing property": {
+			property: "missing",
+			errMsg:   "property missing not found in secret",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := extractPropertyMap(secret, tc.property, false)
+			if tc.errMsg != "" {
+				assert.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetSecretServerSideProperty(t *testing.T) {
+	full := &cg.KV{Path: "db", Secret: cg.RedactedMap{"username": "app", "password": "s3cr3t"}}
+
+	tests := map[string]struct {
+		store      esv1.SmopProvider
+		property   string
+		selected   *cg.KV
+		want       string
+		wantFields []string
+	}{
+		"selected by the server": {
+			store:      esv1.SmopProvider{ServerSideProperty: true},
+			property:   "password",
+			selected:   &cg.KV{Path: "db", Secret: cg.RedactedMap{"password": "s3cr3t"}},
+			want:       "s3cr3t",
+			wantFields: []string{"password"},
+		},
+		"extracted when the server returns the whole value": {
+			store:      esv1.SmopProvider{ServerSideProperty: true},
+			property:   "username",
+			selected:   full,
+			want:       "app",
+			wantFields: []string{"username"},
+		},
+		"not enabled": {
+			property: "password",
+			want:     "s3cr3t",
+		},
+		"whole secret": {
+			store: esv1.SmopProvider{ServerSideProperty: true},
+			want:  `{"password":"s3cr3t","username":"app"}`,
+		},
+		"checkout": {
+			store:    esv1.SmopProvider{ServerSideProperty: true, Checkout: &esv1.SmopCheckout{}},
+			property: "password",
+			want:     "s3cr3t",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var fields []string
+			c := &Client{
+				store: &tc.store,
+				smopClient: &fake.SmopClient{
+					GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+						return full, nil
+					},
+					GetSecretFieldFn: func(_ context.Context, _ string, _ *string, field string) (*cg.KV, *smopclient.KVMetadata, error) {
+						fields = append(fields, field)
+						return tc.selected, &smopclient.KVMetadata{Path: "db", Version: 2}, nil
+					},
+					CheckoutSecretFn: func(_ context.Context, _ string, _ *string, _ time.Duration) (*smopclient.Checkout, error) {
+						return &smopclient.Checkout{ID: "c-1", KV: *full}, nil
+					},
+				},
+			}
+
+			got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: tc.property})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+			assert.Equal(t, tc.wantFields, fields)
+		})
+	}
+}
+
+func TestCaseInsensitiveProperties(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{CaseInsensitiveProperties: true},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{
+					"DB_Host":  "db-0",
+					"Password": "s3cr3t",
+					"password": "other",
+					"Server":   map[string]any{"Port": "5432"},
+					"token":    "t0k3n",
+					"TOKEN":    "T0K3N",
+				}}, nil
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		property string
+		want     string
+		wantErr  error
+		errMsg   string
+	}{
+		"exact":                            {property: "DB_Host", want: "db-0"},
+		"differing case":                   {property: "db_host", want: "db-0"},
+		"differing case in a path":         {property: "server.port", want: "5432"},
+		"exact preferred over other cases": {property: "password", want: "other"},
+		"ambiguous":                        {property: "Token", wantErr: ErrAmbiguousProperty, errMsg: "matches TOKEN, token"},
+		"missing":                          {property: "user", errMsg: "property user not found in secret"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: tc.property})
+			switch {
+			case tc.wantErr != nil:
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.ErrorContains(t, err, tc.errMsg)
+			case tc.errMsg != "":
+				assert.ErrorContains(t, err, tc.errMsg)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, string(got))
+			}
+		})
+	}
+
+	t.Run("properties", func(t *testing.T) {
+		got, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Properties: []string{"db_host", "PASSWORD"}})
+		assert.ErrorIs(t, err, ErrAmbiguousProperty)
+		assert.Nil(t, got)
+
+		got, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Properties: []string{"db_host", "Password"}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"db_host": []byte("db-0"), "Password": []byte("s3cr3t")}, got)
+	})
+
+	t.Run("case-sensitive by default", func(t *testing.T) {
+		c := &Client{store: &esv1.SmopProvider{}, smopClient: c.smopClient}
+		_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "db_host"})
+		assert.ErrorContains(t, err, "property db_host not found in secret")
+	})
+}
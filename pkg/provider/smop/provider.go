@@ -18,36 +18,88 @@ package smop
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"mime"
+	"slices"
+	"strings"
+	"sync"
 
+	"github.com/spf13/pflag"
+	ctrl "sigs.k8s.io/controller-runtime"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
 	"github.com/external-secrets/external-secrets/pkg/esutils"
 	"github.com/external-secrets/external-secrets/pkg/esutils/resolvers"
+	"github.com/external-secrets/external-secrets/pkg/feature"
 	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
 )
 
 var (
-	ErrNoStore = errors.New("missing or invalid Smop SecretStore")
-	ErrNoApiKey = errors.New("missing or invalid Smop API Token in Smop SecretStore")
-	ErrNoTokenName = errors.New("missing or invalid Smop API Token name in Smop SecretStore")
-	ErrNoTokenKey = errors.New("missing or invalid Smop API Token key in Smop SecretStore")
-	ErrNoServer = errors.New("missing or invalid Smop Server in Smop SecretStore")
-	ErrNoApiUrl = errors.New("missing or invalid Smop Server API URL in Smop SecretStore")
-	ErrNoSiteId = errors.New("missing or invalid Smop Server site ID in Smop SecretStore")
+	ErrNoStore              = errors.New("missing or invalid Smop SecretStore")
+	ErrNoApiKey             = errors.New("missing or invalid Smop API Token in Smop SecretStore")
+	ErrNoTokenName          = errors.New("missing or invalid Smop API Token name in Smop SecretStore")
+	ErrNoTokenKey           = errors.New("missing or invalid Smop API Token key in Smop SecretStore")
+	ErrNoServer             = errors.New("missing or invalid Smop Server in Smop SecretStore")
+	ErrNoApiUrl             = errors.New("missing or invalid Smop Server API URL in Smop SecretStore")
+	ErrNoSiteId             = errors.New("missing or invalid Smop Server site ID in Smop SecretStore")
+	ErrNoAuth               = errors.New("missing or invalid Smop auth in Smop SecretStore")
+	ErrTLSClientCertKeyPair = errors.New("missing Smop TLS clientCert or clientKey: both must be specified together")
+	ErrTLSInvalidCA         = errors.New("failed to parse Smop TLS CA bundle")
+	ErrTLSInvalidClientCert = errors.New("failed to parse Smop TLS clientCert and clientKey")
+	ErrTLSInvalidPolicy     = errors.New("invalid Smop TLS minVersion or cipherSuites")
+
+	ErrInsecureSkipVerifyNotAllowed = errors.New("insecureSkipVerify in Smop TLS is not allowed: " +
+		"it exposes the Smop token and secrets to interception, start the controller with " +
+		"--smop-allow-insecure-skip-verify to allow it")
 )
 
+var log = ctrl.Log.WithName("provider").WithName("smop")
+
+// defaultMaxRetries is how often a request failing with a transient error is retried when the store sets no Retries.
+const defaultMaxRetries = 3
+
 // Provider is a Doppler secrets provider implementing NewClient and ValidateStore for the esv1.Provider interface.
 type Provider struct{}
 
 // https://github.com/external-secrets/external-secrets/issues/644
 var _ esv1.SecretsClient = &Client{}
+var _ esv1.SecretTypeHinter = &Client{}
+var _ esv1.RefreshJitterHinter = &Client{}
+var _ esv1.SecretAnnotationsHinter = &Client{}
+var _ esv1.FindCursorHinter = &Client{}
+var _ esv1.DefaultedKeysHinter = &Client{}
 var _ esv1.Provider = &Provider{}
 
+// allowInsecureSkipVerify is the operator-level guard for stores disabling TLS verification.
+var allowInsecureSkipVerify bool
+
+// maxConcurrentRequests is the operator-level cap on SMoP requests in flight across all stores, 0 for none.
+var maxConcurrentRequests int
+
+// requestLimiter enforces maxConcurrentRequests, it is shared by every SMoP client.
+var requestLimiter = sync.OnceValues(func() (*smopclient.RequestLimiter, error) {
+	if maxConcurrentRequests == 0 {
+		return nil, nil
+	}
+	return smopclient.NewRequestLimiter(maxConcurrentRequests)
+})
+
 func init() {
+	fs := pflag.NewFlagSet("smop", pflag.ExitOnError)
+	fs.BoolVar(&allowInsecureSkipVerify, "smop-allow-insecure-skip-verify", false,
+		"Allow Smop stores to set tls.insecureSkipVerify (DANGER: exposes Smop tokens and secrets to interception, only use in lab environments).")
+	fs.IntVar(&maxConcurrentRequests, "smop-max-concurrent-requests", 0,
+		"Maximum number of Smop requests in flight across all stores, to protect a shared Smop tenant. 0 means no limit.")
+	feature.Register(feature.Feature{
+		Flags: fs,
+	})
+
 	esv1.Register(&Provider{}, &esv1.SecretStoreProvider{
 		Smop: &esv1.SmopProvider{},
 	}, esv1.MaintenanceStatusMaintained)
@@ -55,42 +107,209 @@ func init() {
 
 // NewClient constructs a Smop SecretsManager Provider.
 func (p *Provider) NewClient(ctx context.Context, store esv1.GenericStore, kube kclient.Client, namespace string) (esv1.SecretsClient, error) {
+	smopClient, err := NewClientFromStore(ctx, store, kube, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	smopStoreSpec := store.GetSpec().Provider.Smop
+
+	keyFilter, err := newKeyFilter(smopStoreSpec.KeyFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		smopClient: smopClient,
+		store:      smopStoreSpec,
+		keyFilter:  keyFilter,
+		storeRef:   newStoreRef(store),
+		kube:       kube,
+		namespace:  namespace,
+	}
+
+	return client, nil
+}
+
+// NewClientFromStore constructs a ready SMoP API client for the Smop provider of `store`.
+// Its auth and TLS secrets are resolved in `namespace` the way the kind of `store` resolves them:
+// a ClusterSecretStore may name the namespace of each secret reference.
+func NewClientFromStore(ctx context.Context, store esv1.GenericStore, kube kclient.Client, namespace string) (*smopclient.SMOPClient, error) {
+	if store == nil {
+		return nil, ErrNoStore
+	}
 	storeSpec := store.GetSpec()
 	if storeSpec == nil || storeSpec.Provider == nil || storeSpec.Provider.Smop == nil {
 		return nil, ErrNoStore
 	}
-	
-	smopStoreSpec := storeSpec.Provider.Smop
+	return newSmopClient(ctx, storeSpec.Provider.Smop, kube, namespace, newStoreRef(store))
+}
 
-	storeKind := store.GetKind()
-	apiKey, err := loadApiKeyFromSpec(ctx, smopStoreSpec, kube, namespace, storeKind)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load credentials: %w", err)
+// NewGeneratorClient constructs a SMoP API client for a generator.
+// Secret references in `spec` are resolved in `namespace`.
+func NewGeneratorClient(ctx context.Context, kube kclient.Client, spec *esv1.SmopProvider, namespace string) (*smopclient.SMOPClient, error) {
+	if spec == nil {
+		return nil, ErrNoStore
 	}
+	return newSmopClient(ctx, spec, kube, namespace, storeRef{kind: resolvers.EmptyStoreKind})
+}
 
-	baseURL, siteID, err := loadUrlFromSpec(smopStoreSpec)
+// newSmopClient constructs the SMoP API client for the given provider spec of `store`.
+func newSmopClient(ctx context.Context, spec *esv1.SmopProvider, kube kclient.Client, namespace string, store storeRef) (*smopclient.SMOPClient, error) {
+	storeKind := store.kind
+	baseURL, siteID, err := loadUrlFromSpec(spec)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load server URL configuration: %w", err)
 	}
-	
+
 	smopServerURL := fmt.Sprintf("%s/%s/secrets", baseURL, siteID)
 
-	smopClient, err := smopclient.NewSMOPClient(smopServerURL, apiKey)
+	tlsConfig, tlsMaterial, err := loadTLSConfigFromSpec(ctx, spec, kube, namespace, storeKind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS configuration: %w", err)
+	}
+
+	var (
+		apiKey      string
+		tokenSource smopclient.TokenSource
+	)
+	if spec.Auth != nil && spec.Auth.WorkloadIdentity != nil {
+		tokenSource, err = workloadIdentityTokenSource(spec.Auth.WorkloadIdentity, kube, namespace, storeKind,
+			fmt.Sprintf("%s/%s", baseURL, siteID), tlsConfig, tlsMaterial)
+	} else {
+		apiKey, err = loadApiKeyFromSpec(ctx, spec, kube, namespace, storeKind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credentials: %w", err)
+	}
+
+	opts := []smopclient.ClientOption{
+		smopclient.WithFollowAliases(!spec.DisableAliasResolution),
+		smopclient.WithRejectExpired(spec.RejectExpired),
+		smopclient.WithRecursiveDelete(spec.RecursiveDelete),
+		smopclient.WithBaseURLPathPrefix(spec.Server.BasePathPrefix),
+		smopclient.WithStrictAPIVersion(spec.Server.StrictAPIVersion),
+		smopclient.WithAPIVersion(spec.Server.APIVersion),
+		smopclient.WithResponseSchemaValidation(spec.ValidateResponseSchema),
+		smopclient.WithContentTypeSniffing(!spec.DisableContentTypeSniffing),
+		smopclient.WithRequireFolder(spec.RequireFolder),
+	}
+	if spec.DefaultResponseContentType != nil {
+		opts = append(opts, smopclient.WithDefaultResponseContentType(*spec.DefaultResponseContentType))
+	}
+	if len(spec.EmptyListStatusCodes) > 0 {
+		opts = append(opts, smopclient.WithEmptyListStatusCodes(spec.EmptyListStatusCodes...))
+	}
+	if spec.Server.HostOverride != "" {
+		opts = append(opts, smopclient.WithHostOverride(spec.Server.HostOverride))
+	}
+	if timeouts := spec.Timeouts; timeouts != nil {
+		if timeouts.Request != nil {
+			opts = append(opts, smopclient.WithRequestTimeout(timeouts.Request.Duration))
+		}
+		if timeouts.Get != nil {
+			opts = append(opts, smopclient.WithGetTimeout(timeouts.Get.Duration))
+		}
+		if timeouts.List != nil {
+			opts = append(opts, smopclient.WithListTimeout(timeouts.List.Duration))
+		}
+		if timeouts.DefaultDeadline != nil {
+			opts = append(opts, smopclient.WithDefaultDeadline(timeouts.DefaultDeadline.Duration))
+		}
+	}
+	if tokenSource != nil {
+		opts = append(opts, smopclient.WithTokenSource(tokenSource))
+	}
+	if tlsConfig != nil {
+		opts = append(opts, smopclient.WithTLSConfig(tlsConfig))
+	}
+	tlsPolicyOpts, err := tlsPolicyOptions(spec.TLS)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, tlsPolicyOpts...)
+	if spec.TLS != nil && spec.TLS.InsecureSkipVerify {
+		if !allowInsecureSkipVerify {
+			return nil, ErrInsecureSkipVerifyNotAllowed
+		}
+		// logged for every client, including the clients reused from the client cache
+		log.Info("WARNING: SMoP server certificate verification is DISABLED (insecureSkipVerify). "+
+			"The SMoP token and all secrets can be intercepted. Never use this outside of lab environments.",
+			"server", smopServerURL)
+		opts = append(opts, smopclient.WithInsecureSkipVerify(true))
+	}
+	limiter, err := requestLimiter()
+	if err != nil {
+		return nil, err
+	}
+	if limiter != nil {
+		opts = append(opts, smopclient.WithRequestLimiter(limiter))
+	}
+	if subject := impersonationSubject(spec.Impersonation, namespace, storeKind); subject != "" {
+		opts = append(opts, smopclient.WithImpersonationSubject(subject))
+	}
+	if ap := spec.ApprovalPolling; ap != nil {
+		opts = append(opts, smopclient.WithApprovalPolling(ap.Interval.Duration, ap.Timeout.Duration))
+	}
+	if limits := spec.WalkLimits; limits != nil {
+		if limits.MaxDepth > 0 {
+			opts = append(opts, smopclient.WithWalkMaxDepth(int(limits.MaxDepth)))
+		}
+		if limits.MaxItems > 0 {
+			opts = append(opts, smopclient.WithWalkMaxItems(int(limits.MaxItems)))
+		}
+	}
+	maxRetries := defaultMaxRetries
+	if retries := spec.Retries; retries != nil {
+		if retries.MaxRetries != nil {
+			maxRetries = int(*retries.MaxRetries)
+		}
+		budget := smopclient.RetryBudget{MaxAttempts: int(retries.MaxAttempts)}
+		if retries.MaxDuration != nil {
+			budget.MaxDuration = retries.MaxDuration.Duration
+		}
+		opts = append(opts, smopclient.WithRetryBudget(budget))
+	}
+	opts = append(opts, smopclient.WithMaxRetries(maxRetries))
+	if transport := spec.Transport; transport != nil {
+		if transport.MaxIdleConnsPerHost > 0 {
+			opts = append(opts, smopclient.WithMaxIdleConnsPerHost(transport.MaxIdleConnsPerHost))
+		}
+		if transport.MaxConnsPerHost > 0 {
+			opts = append(opts, smopclient.WithMaxConnsPerHost(transport.MaxConnsPerHost))
+		}
+		if transport.IdleConnTimeout != nil {
+			opts = append(opts, smopclient.WithIdleConnTimeout(transport.IdleConnTimeout.Duration))
+		}
+		if transport.TLSHandshakeTimeout != nil {
+			opts = append(opts, smopclient.WithTLSHandshakeTimeout(transport.TLSHandshakeTimeout.Duration))
+		}
+		if transport.MaxRedirects != nil {
+			opts = append(opts, smopclient.WithMaxRedirects(int(*transport.MaxRedirects)))
+		}
+		opts = append(opts, smopclient.WithCrossHostRedirects(transport.AllowCrossHostRedirects))
+	}
+
+	// identical stores share a client, keeping its connection pool across reconciles
+	cacheKey, err := newClientCacheKey(spec, namespace, storeKind, apiKey, tlsMaterial)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SMOP client: %w", err)
 	}
+	if smopClient, ok := clients.get(cacheKey); ok {
+		return smopClient, nil
+	}
 
-	err = smopClient.SetBaseURL(baseURL)
+	smopClient, err := smopclient.NewSMOPClient(smopServerURL, apiKey, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set base URL for SMOP client: %w", err)
+		return nil, fmt.Errorf("failed to create SMOP client: %w", err)
 	}
 
-	client := &Client{
-		smopClient: smopClient,
-		store:     smopStoreSpec,
+	err = smopClient.SetBaseURL(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set base URL for SMOP client: %w", err)
 	}
 
-	return client, nil
+	return clients.add(cacheKey, smopClient), nil
 }
 
 // ValidateStore checks if the Smop store is valid.
@@ -99,26 +318,159 @@ func (p *Provider) NewClient(ctx context.Context, store esv1.GenericStore, kube
 // or other type of message that is NOT a validation failure but should be noticed by the user.
 func (p *Provider) ValidateStore(store esv1.GenericStore) (admission.Warnings, error) {
 	storeSpec := store.GetSpec()
+	if storeSpec == nil || storeSpec.Provider == nil || storeSpec.Provider.Smop == nil {
+		return nil, ErrNoStore
+	}
+
 	smopStoreSpec := storeSpec.Provider.Smop
-	smopTokenSecretRef := smopStoreSpec.Auth.APIKey.SmopToken
-	if err := esutils.ValidateSecretSelector(store, smopTokenSecretRef); err != nil {
+	if err := validateAuth(store, smopStoreSpec.Auth); err != nil {
 		return nil, err
 	}
 
-	if smopTokenSecretRef.Name == "" {
-		return nil, ErrNoServer
+	if _, _, err := loadUrlFromSpec(smopStoreSpec); err != nil {
+		return nil, err
+	}
+
+	if err := validateTLSSpec(store, smopStoreSpec.TLS); err != nil {
+		return nil, err
+	}
+
+	if err := smopclient.ValidateEmptyListStatusCodes(smopStoreSpec.EmptyListStatusCodes); err != nil {
+		return nil, err
+	}
+
+	if o := smopStoreSpec.Server.HostOverride; o != "" {
+		if err := smopclient.ValidateHostOverride(o); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := newKeyFilter(smopStoreSpec.KeyFilter); err != nil {
+		return nil, err
+	}
+
+	if err := validateEnvironment(smopStoreSpec); err != nil {
+		return nil, err
+	}
+
+	if err := validateImpersonation(store, smopStoreSpec.Impersonation); err != nil {
+		return nil, err
+	}
+
+	if err := validatePushMetadata(smopStoreSpec.PushMetadata); err != nil {
+		return nil, err
+	}
+
+	if ct := smopStoreSpec.DefaultResponseContentType; ct != nil && *ct != "" {
+		if _, _, err := mime.ParseMediaType(*ct); err != nil {
+			return nil, fmt.Errorf("invalid Smop defaultResponseContentType %q: must be a media type", *ct)
+		}
+	}
+
+	if w := smopStoreSpec.FindUpdatedWithin; w != nil && w.Duration <= 0 {
+		return nil, fmt.Errorf("invalid Smop findUpdatedWithin %s: must be positive", w.Duration)
+	}
+
+	if limit := smopStoreSpec.FindPageLimit; limit != nil {
+		if *limit <= 0 {
+			return nil, fmt.Errorf("invalid Smop findPageLimit %d: must be positive", *limit)
+		}
+		if smopStoreSpec.FindRecursive {
+			return nil, errors.New("invalid Smop findPageLimit: not supported with findRecursive")
+		}
+	}
+
+	if tr := smopStoreSpec.Transport; tr != nil && tr.IdleConnTimeout != nil && tr.IdleConnTimeout.Duration <= 0 {
+		return nil, fmt.Errorf("invalid Smop transport idleConnTimeout %s: must be positive", tr.IdleConnTimeout.Duration)
+	}
+	if tr := smopStoreSpec.Transport; tr != nil && tr.TLSHandshakeTimeout != nil && tr.TLSHandshakeTimeout.Duration <= 0 {
+		return nil, fmt.Errorf("invalid Smop transport tlsHandshakeTimeout %s: must be positive", tr.TLSHandshakeTimeout.Duration)
+	}
+
+	if tr := smopStoreSpec.Transport; tr != nil && tr.MaxRedirects != nil && *tr.MaxRedirects < 0 {
+		return nil, fmt.Errorf("invalid Smop transport maxRedirects %d: must not be negative", *tr.MaxRedirects)
+	}
+
+	if co := smopStoreSpec.Checkout; co != nil && co.TTL != nil && co.TTL.Duration <= 0 {
+		return nil, fmt.Errorf("invalid Smop checkout ttl %s: must be positive", co.TTL.Duration)
 	}
 
-	return nil, nil
+	if rs := smopStoreSpec.RotateOnSync; rs != nil {
+		if rs.MinInterval != nil && rs.MinInterval.Duration <= 0 {
+			return nil, fmt.Errorf("invalid Smop rotateOnSync minInterval %s: must be positive", rs.MinInterval.Duration)
+		}
+		if smopStoreSpec.Checkout != nil {
+			return nil, errors.New("invalid Smop rotateOnSync: not supported with checkout")
+		}
+	}
+
+	if ap := smopStoreSpec.ApprovalPolling; ap != nil && (ap.Interval.Duration <= 0 || ap.Timeout.Duration < ap.Interval.Duration) {
+		return nil, fmt.Errorf("invalid Smop approvalPolling every %s for %s: must be positive and ordered", ap.Interval.Duration, ap.Timeout.Duration)
+	}
+
+	if l := smopStoreSpec.WalkLimits; l != nil && (l.MaxDepth < 0 || l.MaxItems < 0) {
+		return nil, fmt.Errorf("invalid Smop walkLimits %d levels and %d items: must not be negative", l.MaxDepth, l.MaxItems)
+	}
+
+	if r := smopStoreSpec.Retries; r != nil {
+		if r.MaxRetries != nil && *r.MaxRetries < 0 {
+			return nil, fmt.Errorf("invalid Smop retries maxRetries %d: must not be negative", *r.MaxRetries)
+		}
+		if r.MaxAttempts < 0 {
+			return nil, fmt.Errorf("invalid Smop retries maxAttempts %d: must not be negative", r.MaxAttempts)
+		}
+		if r.MaxDuration != nil && r.MaxDuration.Duration < 0 {
+			return nil, fmt.Errorf("invalid Smop retries maxDuration %s: must not be negative", r.MaxDuration.Duration)
+		}
+	}
+
+	if f := smopStoreSpec.Flatten; f != nil && f.MaxDepth < 0 {
+		return nil, fmt.Errorf("invalid Smop flatten maxDepth %d: must not be negative", f.MaxDepth)
+	}
+
+	if err := validateRequeueDelays(smopStoreSpec.RequeueDelays); err != nil {
+		return nil, err
+	}
+
+	var warnings admission.Warnings
+	if smopStoreSpec.TLS != nil && smopStoreSpec.TLS.InsecureSkipVerify {
+		warnings = append(warnings, "Smop TLS insecureSkipVerify disables server certificate verification: "+
+			"the Smop token and secrets can be intercepted. It is refused unless the controller runs with --smop-allow-insecure-skip-verify")
+	}
+	if v := smopStoreSpec.Server.APIVersion; v != "" && !slices.Contains(smopclient.KnownAPIVersions(), v) {
+		warnings = append(warnings, fmt.Sprintf("Smop API version %q is not a known version (%s), it is sent as-is",
+			v, strings.Join(smopclient.KnownAPIVersions(), ", ")))
+	}
+
+	return warnings, nil
 }
 
 // Capabilities returns the Smop provider Capabilities (Read, Write, ReadWrite).
 func (p *Provider) Capabilities() esv1.SecretStoreCapabilities {
-	return esv1.SecretStoreReadOnly
+	return esv1.SecretStoreReadWrite
+}
+
+// validateAuth checks that exactly one authentication method of the store is set and valid.
+func validateAuth(store esv1.GenericStore, auth *esv1.SmopAuth) error {
+	switch {
+	case auth == nil, (auth.APIKey == nil) == (auth.WorkloadIdentity == nil):
+		return ErrNoAuth
+	case auth.WorkloadIdentity != nil:
+		return validateWorkloadIdentity(store, auth.WorkloadIdentity)
+	}
+
+	smopTokenSecretRef := auth.APIKey.SmopToken
+	if err := esutils.ValidateSecretSelector(store, smopTokenSecretRef); err != nil {
+		return err
+	}
+	if smopTokenSecretRef.Name == "" {
+		return ErrNoTokenName
+	}
+	return nil
 }
 
 func loadApiKeyFromSpec(ctx context.Context, spec *esv1.SmopProvider, kube kclient.Client, namespace, storeKind string) (string, error) {
-	if spec.Auth == nil {
+	if spec.Auth == nil || spec.Auth.APIKey == nil {
 		return "", ErrNoApiKey
 	}
 
@@ -148,3 +500,163 @@ func loadUrlFromSpec(spec *esv1.SmopProvider) (string, string, error) {
 
 	return spec.Server.APIURL, spec.Server.SiteId, nil
 }
+
+func validateTLSSpec(store esv1.GenericStore, spec *esv1.SmopTLS) error {
+	if spec == nil {
+		return nil
+	}
+
+	if err := validateClientCertKeyPair(spec); err != nil {
+		return err
+	}
+
+	if len(spec.CABundle) > 0 {
+		if _, err := parseCABundle(spec.CABundle); err != nil {
+			return err
+		}
+	}
+
+	if spec.ClientCert != nil {
+		if err := esutils.ValidateSecretSelector(store, *spec.ClientCert); err != nil {
+			return err
+		}
+		if err := esutils.ValidateSecretSelector(store, *spec.ClientKey); err != nil {
+			return err
+		}
+	}
+
+	_, err := tlsPolicyOptions(spec)
+	return err
+}
+
+// tlsMinVersions maps the Smop TLS minVersion values to crypto/tls versions.
+var tlsMinVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsPolicyOptions returns the client options applying the TLS minVersion and cipherSuites of the store.
+// Only the secure cipher suites of crypto/tls are accepted.
+func tlsPolicyOptions(spec *esv1.SmopTLS) ([]smopclient.ClientOption, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	var opts []smopclient.ClientOption
+	if spec.MinVersion != "" {
+		version, ok := tlsMinVersions[spec.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported minVersion %q", ErrTLSInvalidPolicy, spec.MinVersion)
+		}
+		opts = append(opts, smopclient.WithTLSMinVersion(version))
+	}
+
+	if len(spec.CipherSuites) > 0 {
+		ids := make([]uint16, 0, len(spec.CipherSuites))
+		for _, name := range spec.CipherSuites {
+			i := slices.IndexFunc(tls.CipherSuites(), func(suite *tls.CipherSuite) bool { return suite.Name == name })
+			if i < 0 {
+				return nil, fmt.Errorf("%w: unknown or insecure cipher suite %q", ErrTLSInvalidPolicy, name)
+			}
+			ids = append(ids, tls.CipherSuites()[i].ID)
+		}
+		opts = append(opts, smopclient.WithTLSCipherSuites(ids))
+	}
+
+	return opts, nil
+}
+
+// loadTLSConfigFromSpec builds the tls.Config for the Smop server connection.
+// It also returns the CA bundle and client key pair resolved from Secrets or ConfigMaps,
+// which identify the configuration in the client cache.
+// It returns nil if the store does not configure TLS.
+func loadTLSConfigFromSpec(ctx context.Context, spec *esv1.SmopProvider, kube kclient.Client, namespace, storeKind string) (*tls.Config, [][]byte, error) {
+	if spec.TLS == nil {
+		return nil, nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	caCert, err := esutils.FetchCACertFromSource(ctx, esutils.CreateCertOpts{
+		CABundle:   spec.TLS.CABundle,
+		CAProvider: spec.TLS.CAProvider,
+		StoreKind:  storeKind,
+		Namespace:  namespace,
+		Client:     kube,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	material := [][]byte{caCert}
+	if len(caCert) > 0 {
+		caCertPool, err := parseCABundle(caCert)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if err := validateClientCertKeyPair(spec.TLS); err != nil {
+		return nil, nil, err
+	}
+
+	if spec.TLS.ClientCert != nil {
+		clientCert, err := resolvers.SecretKeyRef(ctx, kube, storeKind, namespace, spec.TLS.ClientCert)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS client certificate: %w", err)
+		}
+		clientKey, err := resolvers.SecretKeyRef(ctx, kube, storeKind, namespace, spec.TLS.ClientKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS client key: %w", err)
+		}
+
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrTLSInvalidClientCert, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		material = append(material, []byte(clientCert), []byte(clientKey))
+	}
+
+	return tlsConfig, material, nil
+}
+
+// validateClientCertKeyPair reports a client certificate set without its key, or a key without its certificate.
+func validateClientCertKeyPair(spec *esv1.SmopTLS) error {
+	switch {
+	case spec.ClientCert != nil && spec.ClientKey == nil:
+		return fmt.Errorf("%w: clientCert is set without clientKey", ErrTLSClientCertKeyPair)
+	case spec.ClientCert == nil && spec.ClientKey != nil:
+		return fmt.Errorf("%w: clientKey is set without clientCert", ErrTLSClientCertKeyPair)
+	}
+	return nil
+}
+
+// parseCABundle parses a PEM encoded CA bundle. Unlike x509.CertPool.AppendCertsFromPEM, which skips
+// what it cannot parse, every PEM block must be a valid certificate, so a broken bundle fails
+// the store instead of a later TLS handshake.
+func parseCABundle(bundle []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	rest := bundle
+	for i := 0; ; i++ {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			if i == 0 {
+				return nil, fmt.Errorf("%w: no PEM encoded certificate found", ErrTLSInvalidCA)
+			}
+			return pool, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("%w: PEM block %d is a %s, not a CERTIFICATE", ErrTLSInvalidCA, i+1, block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: certificate %d: %w", ErrTLSInvalidCA, i+1, err)
+		}
+		pool.AddCert(cert)
+	}
+}
@@ -0,0 +1,344 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// base64ValuePrefix prefixes pushed values stored base64 encoded, see encodePushValue.
+const base64ValuePrefix = "base64:"
+
+var (
+	// ErrEmptyPushValue is returned when pushing an empty value, unless the store allows it.
+	ErrEmptyPushValue = errors.New("refusing to push an empty value to Smop")
+	// ErrPushValueTooLarge is returned when pushing a value larger than the store allows.
+	ErrPushValueTooLarge = errors.New("refusing to push a value larger than the Smop SecretStore allows")
+	// ErrLowEntropyPushValue is returned when pushing a low-entropy value to a store rejecting them.
+	ErrLowEntropyPushValue = errors.New("refusing to push a low-entropy value to Smop")
+)
+
+// PushSecret writes a Secret to the Smop KV at the remote key, which is resolved like the remote keys
+// of an ExternalSecret, see BaseFolderAnnotation.
+//
+// When data selects a secret key, its value is written to the top-level property of the KV named
+// by data, or named like the secret key, keeping the other properties of the KV.
+// Otherwise the whole Secret is written as the KV value, each Secret key becoming a property:
+// the inverse of GetSecretMap. encoding/json writes the properties sorted by key, so pushing the
+// same Secret always produces the same KV. See encodePushValue for values which are not valid UTF-8,
+// which are listed by the tagEncoded tag of the KV, or of its sidecar KV in Sidecar mode.
+//
+// Values are checked against the store PushValidation first, so invalid values are rejected before any write.
+// A KV which already holds the pushed value and tags (see pushTags) is not written again.
+// In Sidecar mode, the tags are written to the sidecar KV instead, which is also only written when its content changes.
+func (c *Client) PushSecret(ctx context.Context, secret *corev1.Secret, data esv1.PushSecretData) error {
+	if c.store.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
+	remoteKey := data.GetRemoteKey()
+	values := pushValues(secret, data)
+	if err := c.validatePushValues(remoteKey, values); err != nil {
+		return err
+	}
+
+	tags, err := c.pushTags(ctx, secret, data)
+	if err != nil {
+		return err
+	}
+
+	ctx, err = c.requestContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	name, folderPath, err := c.resolveRemoteKey(ctx, remoteKey)
+	if err != nil {
+		return err
+	}
+	sidecarTags := tags
+	if c.sidecarMetadata() {
+		tags = nil
+	}
+
+	current := map[string]any{}
+	// an expired secret is read with its metadata, so that pushing can replace it
+	kv, metadata, err := c.smopClient.GetSecretWithMetadata(ctx, name, &folderPath)
+	switch {
+	case err == nil && kv.Secret != nil:
+		current = map[string]any(kv.Secret)
+	case err != nil && !errors.Is(mapNotFound(err), esv1.NoSecretErr):
+		return fmt.Errorf("failed to get secret %q: %w", remoteKey, err)
+	}
+
+	var (
+		desired map[string]any
+		encoded = map[string]bool{}
+	)
+	if key := data.GetSecretKey(); key != "" {
+		property := data.GetProperty()
+		if property == "" {
+			property = key
+		}
+		// the other properties of the KV are kept, and so is their encoding
+		if kv != nil {
+			if encoded, err = c.encodedProperties(ctx, name, folderPath, metadata.Tags[tagEncoded]); err != nil {
+				return err
+			}
+		}
+		desired = maps.Clone(current)
+		desired[property], encoded[property] = encodePushValue(values[key])
+	} else {
+		desired = make(map[string]any, len(values))
+		for key, value := range values {
+			desired[key], encoded[key] = encodePushValue(value)
+		}
+	}
+	if marker := encodedTag(encoded); marker != "" && c.sidecarMetadata() {
+		sidecarTags = withTag(sidecarTags, tagEncoded, marker)
+	} else if marker != "" {
+		tags = withTag(tags, tagEncoded, marker)
+	}
+
+	// SetSecret keeps the tags of the KV when it writes none, so only different tags need a write
+	if kv == nil || !reflect.DeepEqual(current, desired) || len(tags) > 0 && !maps.Equal(tags, metadata.Tags) {
+		if err := c.smopClient.SetSecret(ctx, name, &folderPath, desired, tags); err != nil {
+			return fmt.Errorf("failed to push secret %q: %w", remoteKey, err)
+		}
+	}
+
+	if c.sidecarMetadata() {
+		return c.pushSidecar(ctx, name, &folderPath, sidecarTags)
+	}
+	return nil
+}
+
+// encodePushValue converts a pushed value to a KV property. Values are stored as strings, except values
+// which are not valid UTF-8: these are stored as "base64:" followed by their standard base64 encoding,
+// and reported encoded, so PushSecret lists them in the tagEncoded tag of the KV.
+// decodePushedValues reverses the encoding when reading the KV.
+func encodePushValue(value []byte) (string, bool) {
+	if utf8.Valid(value) {
+		return string(value), false
+	}
+	return base64ValuePrefix + base64.StdEncoding.EncodeToString(value), true
+}
+
+// encodedTag returns the value of the tagEncoded tag listing the encoded properties,
+// a JSON array sorted by name, or an empty string if there are none.
+func encodedTag(encoded map[string]bool) string {
+	var properties []string
+	for property, ok := range encoded {
+		if ok {
+			properties = append(properties, property)
+		}
+	}
+	if len(properties) == 0 {
+		return ""
+	}
+	slices.Sort(properties)
+	marker, _ := json.Marshal(properties)
+	return string(marker)
+}
+
+// withTag returns tags with the tag key set to value, leaving tags unchanged.
+func withTag(tags map[string]string, key, value string) map[string]string {
+	tags = maps.Clone(tags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	tags[key] = value
+	return tags
+}
+
+// encodedProperties returns the properties of the KV `name` at `folderPath` PushSecret stored base64 encoded,
+// listed by `marker`, the tagEncoded tag of the KV. In Sidecar mode the tag is read from the sidecar KV instead.
+// A tag which is not a JSON array of property names lists none.
+func (c *Client) encodedProperties(ctx context.Context, name, folderPath, marker string) (map[string]bool, error) {
+	if c.sidecarMetadata() {
+		kv, err := c.smopClient.GetSecret(ctx, name+sidecarSuffix, &folderPath)
+		switch {
+		case err == nil:
+			marker, _ = kv.Secret[tagEncoded].(string)
+		case errors.Is(mapNotFound(err), esv1.NoSecretErr):
+			marker = ""
+		default:
+			return nil, fmt.Errorf("failed to get metadata of secret %q: %w", name, err)
+		}
+	}
+
+	encoded := map[string]bool{}
+	var properties []string
+	if marker == "" || json.Unmarshal([]byte(marker), &properties) != nil {
+		return encoded, nil
+	}
+	for _, property := range properties {
+		encoded[property] = true
+	}
+	return encoded, nil
+}
+
+// decodePushedValues decodes the values of the KV `name` at `folderPath`, keyed by their property,
+// which PushSecret stored base64 encoded, see encodePushValue. Only the properties listed by the tagEncoded tag
+// of the KV are decoded: other values starting with "base64:" are returned unchanged, as are values
+// with no valid base64 after the prefix. The tag is only looked up if a value starts with the prefix.
+func (c *Client) decodePushedValues(ctx context.Context, name, folderPath string, values map[string]any) (map[string]any, error) {
+	prefixed := false
+	for _, value := range values {
+		if str, ok := value.(string); ok && strings.HasPrefix(str, base64ValuePrefix) {
+			prefixed = true
+			break
+		}
+	}
+	if !prefixed {
+		return values, nil
+	}
+
+	encoded, err := c.encodedProperties(ctx, name, folderPath, c.encodings.get(smopclient.KVRef{Name: name, FolderPath: &folderPath}.String()))
+	if err != nil {
+		return nil, err
+	}
+	decoded := maps.Clone(values)
+	for property, value := range values {
+		str, ok := value.(string)
+		if !ok || !c.isEncoded(encoded, property) || !strings.HasPrefix(str, base64ValuePrefix) {
+			continue
+		}
+		if bytes, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(str, base64ValuePrefix)); err == nil {
+			decoded[property] = bytes
+		}
+	}
+	return decoded, nil
+}
+
+// isEncoded reports whether encoded lists property, ignoring its case if the store
+// sets CaseInsensitiveProperties, the way the property was looked up.
+func (c *Client) isEncoded(encoded map[string]bool, property string) bool {
+	if encoded[property] || !c.store.CaseInsensitiveProperties {
+		return encoded[property]
+	}
+	for name := range encoded {
+		if strings.EqualFold(name, property) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodings records the tagEncoded tags of the KVs read by a Client, keyed by their full SMoP path.
+type encodings struct {
+	mu   sync.Mutex
+	tags map[string]string
+}
+
+// observe records the tagEncoded tag of the KV at `path` read with tags.
+func (e *encodings) observe(path string, tags map[string]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.tags == nil {
+		e.tags = map[string]string{}
+	}
+	e.tags[path] = tags[tagEncoded]
+}
+
+// get returns the tagEncoded tag recorded for the KV at `path`.
+func (e *encodings) get(path string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.tags[path]
+}
+
+// pushValues returns the values of secret pushed by data, keyed by their Secret key.
+// The whole Secret is pushed when data does not select a key.
+func pushValues(secret *corev1.Secret, data esv1.PushSecretData) map[string][]byte {
+	key := data.GetSecretKey()
+	if key == "" {
+		return secret.Data
+	}
+	return map[string][]byte{key: secret.Data[key]}
+}
+
+// validatePushValues checks the values pushed to remoteKey against the store PushValidation.
+// Low-entropy values are only logged unless the store rejects them.
+func (c *Client) validatePushValues(remoteKey string, values map[string][]byte) error {
+	validation := esv1.SmopPushValidation{}
+	if c.store.PushValidation != nil {
+		validation = *c.store.PushValidation
+	}
+	if len(values) == 0 && !validation.AllowEmpty {
+		return fmt.Errorf("%w: the Secret has no data for %q", ErrEmptyPushValue, remoteKey)
+	}
+
+	var errs []error
+	for key, value := range values {
+		if len(value) == 0 && !validation.AllowEmpty {
+			errs = append(errs, fmt.Errorf("%w: secret key %q for %q is empty", ErrEmptyPushValue, key, remoteKey))
+			continue
+		}
+		if validation.MaxSizeBytes > 0 && len(value) > int(validation.MaxSizeBytes) {
+			errs = append(errs, fmt.Errorf("%w: secret key %q for %q is %d bytes, the limit is %d", ErrPushValueTooLarge, key, remoteKey, len(value), validation.MaxSizeBytes))
+			continue
+		}
+		if validation.MinEntropyBits > 0 && len(value) > 0 {
+			bits := entropyBits(value)
+			if bits >= float64(validation.MinEntropyBits) {
+				continue
+			}
+			if validation.RejectLowEntropy {
+				errs = append(errs, fmt.Errorf("%w: secret key %q for %q has %.0f bits of entropy, at least %d are required", ErrLowEntropyPushValue, key, remoteKey, bits, validation.MinEntropyBits))
+				continue
+			}
+			log.Info("pushing a low-entropy value to Smop", "key", key, "remoteKey", remoteKey, "entropyBits", math.Round(bits), "minEntropyBits", validation.MinEntropyBits)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// entropyBits returns the Shannon entropy of value in bits,
+// i.e. its length times the entropy of its byte distribution.
+func entropyBits(value []byte) float64 {
+	var counts [256]int
+	for _, b := range value {
+		counts[b]++
+	}
+	n := float64(len(value))
+	var perByte float64
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / n
+		perByte -= p * math.Log2(p)
+	}
+	return perByte * n
+}
@@ -0,0 +1,338 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+	testingfake "github.com/external-secrets/external-secrets/pkg/provider/testing/fake"
+)
+
+func TestValidatePushValues(t *testing.T) {
+	tests := map[string]struct {
+		validation *esv1.SmopPushValidation
+		values     map[string][]byte
+		wantErr    error
+	}{
+		"value": {
+			values: map[string][]byte{"password": []byte("changeme")},
+		},
+		"empty value is rejected by default": {
+			values:  map[string][]byte{"password": {}},
+			wantErr: ErrEmptyPushValue,
+		},
+		"missing key is rejected by default": {
+			values:  map[string][]byte{"password": nil},
+			wantErr: ErrEmptyPushValue,
+		},
+		"empty secret is rejected by default": {
+			values:  map[string][]byte{},
+			wantErr: ErrEmptyPushValue,
+		},
+		"empty value is allowed": {
+			validation: &esv1.SmopPushValidation{AllowEmpty: true},
+			values:     map[string][]byte{"password": {}},
+		},
+		"too large": {
+			validation: &esv1.SmopPushValidation{MaxSizeBytes: 4},
+			values:     map[string][]byte{"password": []byte("changeme")},
+			wantErr:    ErrPushValueTooLarge,
+		},
+		"low entropy is only logged": {
+			validation: &esv1.SmopPushValidation{MinEntropyBits: 64},
+			values:     map[string][]byte{"password": []byte("changeme")},
+		},
+		"low entropy is rejected": {
+			validation: &esv1.SmopPushValidation{MinEntropyBits: 64, RejectLowEntropy: true},
+			values:     map[string][]byte{"password": []byte("changeme")},
+			wantErr:    ErrLowEntropyPushValue,
+		},
+		"enough entropy": {
+			validation: &esv1.SmopPushValidation{MinEntropyBits: 64, RejectLowEntropy: true},
+			values:     map[string][]byte{"password": []byte("q8Zr!vT2#mLx9$Wp")},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: &esv1.SmopProvider{PushValidation: tc.validation}}
+			err := c.validatePushValues("db/password", tc.values)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEntropyBits(t *testing.T) {
+	assert.Zero(t, entropyBits([]byte("aaaa")))
+	assert.InDelta(t, 22, entropyBits([]byte("changeme")), 0.01)
+	assert.InDelta(t, 64, entropyBits([]byte("0123456789abcdef")), 0.01)
+}
+
+func TestPushSecretRejectsEmptyValue(t *testing.T) {
+	c := &Client{store: &esv1.SmopProvider{}}
+	secret := &corev1.Secret{Data: map[string][]byte{"password": {}}}
+	err := c.PushSecret(context.Background(), secret, testingfake.PushSecretData{SecretKey: "password", RemoteKey: "db/password"})
+	require.ErrorIs(t, err, ErrEmptyPushValue)
+	assert.Contains(t, err.Error(), `secret key "password" for "db/password" is empty`)
+}
+
+// pushTestStore is an in-memory Smop KV store.
+type pushTestStore struct {
+	kvs    map[string]map[string]any
+	tags   map[string]map[string]string
+	writes int
+}
+
+// newPushTestClient returns a client backed by an in-memory KV store holding kvs.
+func newPushTestClient(store *esv1.SmopProvider, kvs map[string]map[string]any) (*Client, *pushTestStore) {
+	backend := &pushTestStore{kvs: kvs, tags: map[string]map[string]string{}}
+	return &Client{
+		store: store,
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+				secret, ok := backend.kvs[name]
+				if !ok {
+					return nil, &smopclient.APIError{StatusCode: http.StatusNotFound, Path: name}
+				}
+				return &cg.KV{Path: name, Secret: cg.RedactedMap(secret)}, nil
+			},
+			GetSecretWithMetadataFn: func(_ context.Context, name string, _ *string) (*cg.KV, *smopclient.KVMetadata, error) {
+				secret, ok := backend.kvs[name]
+				if !ok {
+					return nil, nil, &smopclient.APIError{StatusCode: http.StatusNotFound, Path: name}
+				}
+				return &cg.KV{Path: name, Secret: cg.RedactedMap(secret)}, &smopclient.KVMetadata{Path: name, Tags: backend.tags[name]}, nil
+			},
+			SetSecretFn: func(_ context.Context, name string, _ *string, secret map[string]any, tags map[string]string) error {
+				backend.writes++
+				backend.kvs[name] = secret
+				backend.tags[name] = tags
+				return nil
+			},
+			DeleteSecretFn: func(_ context.Context, name string, _ *string) error {
+				if _, ok := backend.kvs[name]; !ok {
+					return &smopclient.APIError{StatusCode: http.StatusNotFound, Path: name}
+				}
+				delete(backend.kvs, name)
+				return nil
+			},
+		},
+	}, backend
+}
+
+func TestPushSecretMapRoundTrip(t *testing.T) {
+	kvs := map[string]map[string]any{}
+	c, backend := newPushTestClient(&esv1.SmopProvider{}, kvs)
+
+	data := map[string][]byte{
+		"user":     []byte("app"),
+		"config":   []byte(`{"debug":true}`),
+		"keystore": {0xff, 0x00, 0xfe},
+		"prefixed": []byte("base64:not encoded"),
+	}
+	secret := &corev1.Secret{Data: data}
+	ref := testingfake.PushSecretData{RemoteKey: "db"}
+	require.NoError(t, c.PushSecret(context.Background(), secret, ref))
+	assert.Equal(t, map[string]any{
+		"user":     "app",
+		"config":   `{"debug":true}`,
+		"keystore": "base64:/wD+",
+		"prefixed": "base64:not encoded",
+	}, kvs["db"])
+	assert.Equal(t, map[string]string{tagEncoded: `["keystore"]`}, backend.tags["db"])
+
+	got, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// pushing the same Secret again does not write the KV
+	require.NoError(t, c.PushSecret(context.Background(), secret, ref))
+	assert.Equal(t, 1, backend.writes)
+}
+
+func TestDecodePushedValues(t *testing.T) {
+	kvs := map[string]map[string]any{
+		"foreign": {"value": "base64:aGVsbG8="},
+		"db":      {"keystore": "base64:/wD+", "note": "base64:aGVsbG8="},
+	}
+	c, backend := newPushTestClient(&esv1.SmopProvider{}, kvs)
+	backend.tags["db"] = map[string]string{tagEncoded: `["keystore"]`}
+
+	got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "foreign", Property: "value"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("base64:aGVsbG8="), got, "values not pushed by ESO must not be decoded")
+
+	gotMap, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"keystore": {0xff, 0x00, 0xfe}, "note": []byte("base64:aGVsbG8=")}, gotMap)
+
+	// pushing another key keeps the encoding of the other properties
+	secret := &corev1.Secret{Data: map[string][]byte{"user": []byte("app")}}
+	require.NoError(t, c.PushSecret(context.Background(), secret, testingfake.PushSecretData{SecretKey: "user", RemoteKey: "db"}))
+	assert.Equal(t, `["keystore"]`, backend.tags["db"][tagEncoded])
+}
+
+func TestPushSecretEncodedSidecar(t *testing.T) {
+	store := &esv1.SmopProvider{PushMetadata: &esv1.SmopPushMetadata{Mode: esv1.SmopPushMetadataSidecar}}
+	c, backend := newPushTestClient(store, map[string]map[string]any{})
+	secret := &corev1.Secret{Data: map[string][]byte{"keystore": {0xff, 0x00, 0xfe}}}
+
+	require.NoError(t, c.PushSecret(context.Background(), secret, testingfake.PushSecretData{RemoteKey: "db"}))
+	assert.Nil(t, backend.tags["db"])
+	assert.Equal(t, `["keystore"]`, backend.kvs["db.eso-metadata"][tagEncoded])
+
+	got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "keystore"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0x00, 0xfe}, got)
+}
+
+func TestPushSecretKey(t *testing.T) {
+	kvs := map[string]map[string]any{"db": {"user": "app", "password": "old"}}
+	c, backend := newPushTestClient(&esv1.SmopProvider{}, kvs)
+	secret := &corev1.Secret{Data: map[string][]byte{"password": []byte("new"), "token": []byte("t0k3n")}}
+
+	require.NoError(t, c.PushSecret(context.Background(), secret, testingfake.PushSecretData{SecretKey: "password", RemoteKey: "db"}))
+	require.NoError(t, c.PushSecret(context.Background(), secret, testingfake.PushSecretData{SecretKey: "token", RemoteKey: "db", Property: "api-token"}))
+	require.NoError(t, c.PushSecret(context.Background(), secret, testingfake.PushSecretData{SecretKey: "token", RemoteKey: "svc"}))
+
+	assert.Equal(t, map[string]any{"user": "app", "password": "new", "api-token": "t0k3n"}, kvs["db"])
+	assert.Equal(t, map[string]any{"token": "t0k3n"}, kvs["svc"])
+	assert.Equal(t, 3, backend.writes)
+
+	got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "api-token"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("t0k3n"), got)
+}
+
+func TestPushSecretAccountFields(t *testing.T) {
+	kvs := map[string]map[string]any{"db-admin": {"password": "old"}}
+	c, backend := newPushTestClient(&esv1.SmopProvider{}, kvs)
+	smopClient := c.smopClient.(*fake.SmopClient)
+	smopClient.GetSecretWithMetadataFn = func(ctx context.Context, name string, folderPath *string) (*cg.KV, *smopclient.KVMetadata, error) {
+		kv, err := smopClient.GetSecretFn(ctx, name, folderPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return kv, &smopclient.KVMetadata{Path: name, Username: "sa", Account: "sa@db.internal"}, nil
+	}
+
+	// the account of a privileged-account KV is read with it, but never written back
+	secret := &corev1.Secret{Data: map[string][]byte{"password": []byte("new")}}
+	require.NoError(t, c.PushSecret(context.Background(), secret, testingfake.PushSecretData{SecretKey: "password", RemoteKey: "db-admin"}))
+	assert.Equal(t, map[string]any{"password": "new"}, backend.kvs["db-admin"])
+
+	got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db-admin", Property: "username"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("sa"), got)
+}
+
+func TestPushSecretReadOnly(t *testing.T) {
+	c, backend := newPushTestClient(&esv1.SmopProvider{ReadOnly: true}, map[string]map[string]any{})
+	secret := &corev1.Secret{Data: map[string][]byte{"password": []byte("s3cr3t")}}
+	err := c.PushSecret(context.Background(), secret, testingfake.PushSecretData{RemoteKey: "db"})
+	assert.ErrorIs(t, err, ErrReadOnlyStore)
+	assert.Zero(t, backend.writes)
+}
+
+func TestPushSecretTags(t *testing.T) {
+	store := &esv1.SmopProvider{PushMetadata: &esv1.SmopPushMetadata{
+		ClusterName: "prod-eu",
+		Tags: map[string]string{
+			"owner": "{{ .Namespace }}/{{ .Name }}",
+			"team":  "payments",
+		},
+	}}
+	ctx := esutils.ContextWithSourceRef(context.Background(), esutils.SourceRef{Kind: "PushSecret", Namespace: "apps", Name: "push-db"})
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "apps"}, Data: map[string][]byte{"password": []byte("s3cr3t")}}
+	meta := &apiextensionsv1.JSON{Raw: []byte(`{"apiVersion":"kubernetes.external-secrets.io/v1alpha1","kind":"PushSecretMetadata","spec":{"tags":{"team":"billing"}}}`)}
+
+	t.Run("tags", func(t *testing.T) {
+		c, backend := newPushTestClient(store, map[string]map[string]any{})
+		require.NoError(t, c.PushSecret(ctx, secret, testingfake.PushSecretData{RemoteKey: "db", Metadata: meta}))
+		assert.Equal(t, map[string]string{
+			"eso.managed-by": "external-secrets",
+			"eso.cluster":    "prod-eu",
+			"eso.kind":       "PushSecret",
+			"eso.namespace":  "apps",
+			"eso.name":       "push-db",
+			"eso.secret":     "db-credentials",
+			"owner":          "apps/push-db",
+			"team":           "billing",
+		}, backend.tags["db"])
+	})
+
+	t.Run("unchanged tags", func(t *testing.T) {
+		c, backend := newPushTestClient(store, map[string]map[string]any{})
+		ref := testingfake.PushSecretData{RemoteKey: "db", Metadata: meta}
+		require.NoError(t, c.PushSecret(ctx, secret, ref))
+		require.NoError(t, c.PushSecret(ctx, secret, ref))
+		assert.Equal(t, 1, backend.writes, "a KV holding the pushed value and tags must not be written again")
+
+		retagged := &apiextensionsv1.JSON{Raw: []byte(`{"apiVersion":"kubernetes.external-secrets.io/v1alpha1","kind":"PushSecretMetadata","spec":{"tags":{"team":"payments"}}}`)}
+		require.NoError(t, c.PushSecret(ctx, secret, testingfake.PushSecretData{RemoteKey: "db", Metadata: retagged}))
+		assert.Equal(t, 2, backend.writes, "changed tags must be written")
+		assert.Equal(t, "payments", backend.tags["db"]["team"])
+	})
+
+	t.Run("sidecar", func(t *testing.T) {
+		sidecarStore := store.DeepCopy()
+		sidecarStore.PushMetadata.Mode = esv1.SmopPushMetadataSidecar
+		c, backend := newPushTestClient(sidecarStore, map[string]map[string]any{})
+
+		ref := testingfake.PushSecretData{RemoteKey: "db"}
+		require.NoError(t, c.PushSecret(ctx, secret, ref))
+		require.NoError(t, c.PushSecret(ctx, secret, ref))
+		assert.Nil(t, backend.tags["db"])
+		assert.Equal(t, "push-db", backend.kvs["db.eso-metadata"]["eso.name"])
+		assert.Equal(t, 2, backend.writes, "unchanged KVs must not be written again")
+
+		require.NoError(t, c.DeleteSecret(ctx, ref))
+		assert.Empty(t, backend.kvs)
+	})
+
+	t.Run("built-in tags cannot be overridden", func(t *testing.T) {
+		c, backend := newPushTestClient(store, map[string]map[string]any{})
+		override := &apiextensionsv1.JSON{Raw: []byte(`{"apiVersion":"kubernetes.external-secrets.io/v1alpha1","kind":"PushSecretMetadata","spec":{"tags":{"eso.namespace":"other"}}}`)}
+		err := c.PushSecret(ctx, secret, testingfake.PushSecretData{RemoteKey: "db", Metadata: override})
+		assert.ErrorIs(t, err, ErrReservedPushTag)
+		assert.Zero(t, backend.writes)
+	})
+}
+
+func TestValidatePushMetadata(t *testing.T) {
+	assert.NoError(t, validatePushMetadata(nil))
+	assert.NoError(t, validatePushMetadata(&esv1.SmopPushMetadata{Tags: map[string]string{"owner": "{{ .Name }}"}}))
+	assert.ErrorIs(t, validatePushMetadata(&esv1.SmopPushMetadata{Tags: map[string]string{"eso.cluster": "x"}}), ErrReservedPushTag)
+	assert.ErrorContains(t, validatePushMetadata(&esv1.SmopPushMetadata{Tags: map[string]string{"owner": "{{ .Name"}}), "invalid template")
+}
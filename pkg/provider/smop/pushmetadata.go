@@ -0,0 +1,203 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/esutils/metadata"
+)
+
+// Built-in tags of pushed secrets, see esv1.SmopPushMetadata.
+const (
+	tagManagedBy = "eso.managed-by"
+	tagCluster   = "eso.cluster"
+	tagKind      = "eso.kind"
+	tagNamespace = "eso.namespace"
+	tagName      = "eso.name"
+	tagSecret    = "eso.secret"
+	// tagEncoded lists the properties of the KV stored base64 encoded, see encodePushValue.
+	tagEncoded = "eso.base64-properties"
+
+	managedByESO = "external-secrets"
+
+	// sidecarSuffix names the sidecar KV holding the tags of a pushed KV in Sidecar mode.
+	sidecarSuffix = ".eso-metadata"
+)
+
+// ErrReservedPushTag is returned when a configured tag uses the key of a built-in tag.
+// Built-in tags cannot be overridden, so they reliably identify ESO-managed KVs.
+var ErrReservedPushTag = errors.New("tag key is reserved for the built-in Smop push tags")
+
+// PushSecretMetadataSpec is the spec of the PushSecret metadata supported by the Smop provider.
+type PushSecretMetadataSpec struct {
+	// Tags are added to the tags of the pushed KV, taking precedence over the store tags.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// pushTagData holds the fields available to tag templates.
+type pushTagData struct {
+	Cluster    string
+	Kind       string
+	Namespace  string
+	Name       string
+	SecretName string
+	RemoteKey  string
+}
+
+// isReservedPushTag reports whether key is the key of a built-in tag.
+func isReservedPushTag(key string) bool {
+	switch key {
+	case tagManagedBy, tagCluster, tagKind, tagNamespace, tagName, tagSecret, tagEncoded:
+		return true
+	}
+	return false
+}
+
+// validatePushMetadata checks the tags configured on the store.
+func validatePushMetadata(pushMetadata *esv1.SmopPushMetadata) error {
+	if pushMetadata == nil {
+		return nil
+	}
+	for key, value := range pushMetadata.Tags {
+		if isReservedPushTag(key) {
+			return fmt.Errorf("%w: %q", ErrReservedPushTag, key)
+		}
+		if _, err := parseTagTemplate(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseTagTemplate(key, value string) (*template.Template, error) {
+	tpl, err := template.New(key).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template of Smop push tag %q: %w", key, err)
+	}
+	return tpl, nil
+}
+
+// pushTags returns the tags of a KV pushed from secret by data: the built-in tags, if the store sets
+// PushMetadata, the store tags, then the tags of the PushSecret metadata. A later tag wins over an
+// earlier one with the same key, except for built-in tags, which cannot be overridden.
+// It returns no tags when there are none to attach.
+func (c *Client) pushTags(ctx context.Context, secret *corev1.Secret, data esv1.PushSecretData) (map[string]string, error) {
+	tags := map[string]string{}
+
+	if pm := c.store.PushMetadata; pm != nil {
+		source, _ := esutils.SourceRefFromContext(ctx)
+		tplData := pushTagData{
+			Cluster:    pm.ClusterName,
+			Kind:       source.Kind,
+			Namespace:  source.Namespace,
+			Name:       source.Name,
+			SecretName: secret.Name,
+			RemoteKey:  data.GetRemoteKey(),
+		}
+		if tplData.Namespace == "" {
+			tplData.Namespace = secret.Namespace
+		}
+
+		for key, value := range pm.Tags {
+			if isReservedPushTag(key) {
+				return nil, fmt.Errorf("%w: %q", ErrReservedPushTag, key)
+			}
+			tpl, err := parseTagTemplate(key, value)
+			if err != nil {
+				return nil, err
+			}
+			var rendered strings.Builder
+			if err := tpl.Execute(&rendered, tplData); err != nil {
+				return nil, fmt.Errorf("failed to render Smop push tag %q: %w", key, err)
+			}
+			tags[key] = rendered.String()
+		}
+
+		builtin := map[string]string{
+			tagManagedBy: managedByESO,
+			tagCluster:   tplData.Cluster,
+			tagKind:      tplData.Kind,
+			tagNamespace: tplData.Namespace,
+			tagName:      tplData.Name,
+			tagSecret:    tplData.SecretName,
+		}
+		for key, value := range builtin {
+			if value != "" {
+				tags[key] = value
+			}
+		}
+	}
+
+	meta, err := metadata.ParseMetadataParameters[PushSecretMetadataSpec](data.GetMetadata())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PushSecret metadata: %w", err)
+	}
+	if meta != nil {
+		for key, value := range meta.Spec.Tags {
+			if isReservedPushTag(key) {
+				return nil, fmt.Errorf("%w: %q", ErrReservedPushTag, key)
+			}
+			tags[key] = value
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// sidecarMetadata reports whether the store keeps the tags of pushed KVs in sidecar KVs.
+func (c *Client) sidecarMetadata() bool {
+	return c.store.PushMetadata != nil && c.store.PushMetadata.Mode == esv1.SmopPushMetadataSidecar
+}
+
+// isSidecar reports whether the KV name is a sidecar KV of the store.
+func (c *Client) isSidecar(name string) bool {
+	return c.sidecarMetadata() && strings.HasSuffix(name, sidecarSuffix)
+}
+
+// pushSidecar writes the tags of the KV remoteKey to its sidecar KV, unless it already holds them.
+func (c *Client) pushSidecar(ctx context.Context, remoteKey string, folderPath *string, tags map[string]string) error {
+	name := remoteKey + sidecarSuffix
+	desired := make(map[string]any, len(tags))
+	for key, value := range tags {
+		desired[key] = value
+	}
+
+	kv, err := c.smopClient.GetSecret(ctx, name, folderPath)
+	switch {
+	case err == nil && reflect.DeepEqual(map[string]any(kv.Secret), desired):
+		return nil
+	case err != nil && !errors.Is(mapNotFound(err), esv1.NoSecretErr):
+		return fmt.Errorf("failed to get metadata of secret %q: %w", remoteKey, err)
+	}
+	if err := c.smopClient.SetSecret(ctx, name, folderPath, desired, nil); err != nil {
+		return fmt.Errorf("failed to push metadata of secret %q: %w", remoteKey, err)
+	}
+	return nil
+}
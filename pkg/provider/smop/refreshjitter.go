@@ -0,0 +1,31 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"time"
+)
+
+// RefreshJitter returns the maximum delay the controller may add to the requeue of an ExternalSecret,
+// spreading the refreshes of ExternalSecrets sharing the same refresh interval.
+// There is no jitter unless the store sets RefreshJitterPercent.
+func (c *Client) RefreshJitter(refreshInterval time.Duration) time.Duration {
+	if c.store.RefreshJitterPercent == nil || *c.store.RefreshJitterPercent <= 0 || refreshInterval <= 0 {
+		return 0
+	}
+	return refreshInterval * time.Duration(*c.store.RefreshJitterPercent) / 100
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+)
+
+func TestRefreshJitter(t *testing.T) {
+	tests := map[string]struct {
+		percent  *int32
+		interval time.Duration
+		want     time.Duration
+	}{
+		"not set": {
+			interval: time.Hour,
+		},
+		"custom percent": {
+			percent:  ptr.To[int32](25),
+			interval: time.Hour,
+			want:     15 * time.Minute,
+		},
+		"disabled": {
+			percent:  ptr.To[int32](0),
+			interval: time.Hour,
+		},
+		"no refresh": {
+			interval: 0,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: &esv1.SmopProvider{RefreshJitterPercent: tc.percent}}
+			assert.Equal(t, tc.want, c.RefreshJitter(tc.interval))
+		})
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// errorClass is the class of a Smop error, which selects its requeue delay (see esv1.SmopRequeueDelays).
+type errorClass string
+
+const (
+	errorClassAuth      errorClass = "auth"
+	errorClassRateLimit errorClass = "rateLimit"
+	errorClassTransient errorClass = "transient"
+)
+
+// Default requeue delays of the error classes.
+const (
+	defaultAuthRequeueDelay      = 10 * time.Minute
+	defaultRateLimitRequeueDelay = time.Minute
+	defaultTransientRequeueDelay = 10 * time.Second
+)
+
+// requeueError suggests the controller sync again after a delay chosen by the class of the error.
+// It implements esv1.RetryAfterError, which the controller honours instead of its exponential backoff.
+type requeueError struct {
+	err   error
+	class errorClass
+	delay time.Duration
+}
+
+func (e *requeueError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requeueError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter implements esv1.RetryAfterError.
+func (e *requeueError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+// failureReasonError reports the reason of a Smop error to the controller, which records
+// the Event of the failure with it. It implements esv1.FailureReasonError.
+type failureReasonError struct {
+	err    error
+	reason esv1.ProviderFailureReason
+}
+
+func (e *failureReasonError) Error() string {
+	return e.err.Error()
+}
+
+func (e *failureReasonError) Unwrap() error {
+	return e.err
+}
+
+// FailureReason implements esv1.FailureReasonError.
+func (e *failureReasonError) FailureReason() esv1.ProviderFailureReason {
+	return e.reason
+}
+
+// withRequeueDelay attaches the failure reason of err to it (see failureReason), and the requeue
+// delay of the store for its class. Errors which already tell when to retry, like a maintenance window,
+// keep their own delay. Errors of a class whose delay is set to 0 on the store are left to the exponential
+// backoff of the controller, and errors of no class and no reason are returned unchanged.
+// The read failure is recorded for the freshness metric, see recordReadFailure.
+func (c *Client) withRequeueDelay(err error) error {
+	if err == nil {
+		return nil
+	}
+	c.recordReadFailure()
+	if reason := failureReason(err); reason != "" {
+		err = &failureReasonError{err: err, reason: reason}
+	}
+	var retryAfter esv1.RetryAfterError
+	if errors.As(err, &retryAfter) {
+		return err
+	}
+
+	class, ok := classifyError(err)
+	if !ok {
+		return err
+	}
+	delay := requeueDelay(c.store.RequeueDelays, class)
+	if delay <= 0 {
+		return err
+	}
+	return &requeueError{err: err, class: class, delay: delay}
+}
+
+// failureReason returns the reason reported to the controller for a Smop error, if it has one.
+// Secrets missing in Smop or pending approval are reported as such, other errors by their class.
+func failureReason(err error) esv1.ProviderFailureReason {
+	switch {
+	case errors.Is(err, esv1.NoSecretErr):
+		return esv1.ProviderFailureReasonNotFound
+	case errors.Is(err, smopclient.ErrApprovalPending):
+		return esv1.ProviderFailureReasonApprovalPending
+	}
+	class, ok := classifyError(err)
+	switch {
+	case !ok:
+		return ""
+	case class == errorClassAuth:
+		return esv1.ProviderFailureReasonAuth
+	case class == errorClassRateLimit:
+		return esv1.ProviderFailureReasonRateLimited
+	}
+	return esv1.ProviderFailureReasonServerError
+}
+
+// classifyError returns the class of a Smop error, if it has one.
+// Network errors are transient even when they fail a token exchange.
+func classifyError(err error) (errorClass, bool) {
+	if errors.Is(err, smopclient.ErrRetryBudgetExhausted) {
+		return errorClassRateLimit, true
+	}
+
+	var apiErr *smopclient.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return errorClassAuth, true
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return errorClassRateLimit, true
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return errorClassTransient, true
+		}
+		return "", false
+	}
+
+	if isTransientNetworkError(err) {
+		return errorClassTransient, true
+	}
+	if errors.Is(err, smopclient.ErrTokenExchange) || errors.Is(err, smopclient.ErrInvalidToken) {
+		return errorClassAuth, true
+	}
+	return "", false
+}
+
+// isTransientNetworkError reports whether err is a timeout or a failure to reach or talk to the server.
+func isTransientNetworkError(err error) bool {
+	var (
+		netErr net.Error
+		opErr  *net.OpError
+		dnsErr *net.DNSError
+	)
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netErr) && netErr.Timeout() ||
+		errors.As(err, &opErr) || errors.As(err, &dnsErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// requeueDelay returns the delay configured on the store for the class, or else its default.
+func requeueDelay(delays *esv1.SmopRequeueDelays, class errorClass) time.Duration {
+	var configured *metav1.Duration
+	var fallback time.Duration
+	switch class {
+	case errorClassAuth:
+		fallback = defaultAuthRequeueDelay
+		if delays != nil {
+			configured = delays.Auth
+		}
+	case errorClassRateLimit:
+		fallback = defaultRateLimitRequeueDelay
+		if delays != nil {
+			configured = delays.RateLimit
+		}
+	case errorClassTransient:
+		fallback = defaultTransientRequeueDelay
+		if delays != nil {
+			configured = delays.Transient
+		}
+	}
+	if configured != nil {
+		return configured.Duration
+	}
+	return fallback
+}
+
+// validateRequeueDelays checks that no requeue delay of the store is negative.
+func validateRequeueDelays(delays *esv1.SmopRequeueDelays) error {
+	if delays == nil {
+		return nil
+	}
+	for _, d := range []struct {
+		class errorClass
+		delay *metav1.Duration
+	}{
+		{errorClassAuth, delays.Auth},
+		{errorClassRateLimit, delays.RateLimit},
+		{errorClassTransient, delays.Transient},
+	} {
+		if d.delay != nil && d.delay.Duration < 0 {
+			return fmt.Errorf("invalid Smop requeueDelays %s %s: must not be negative", d.class, d.delay.Duration)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestWithRequeueDelay(t *testing.T) {
+	apiError := func(status int) error {
+		return fmt.Errorf("failed to get secret %w", &smopclient.APIError{StatusCode: status, Message: http.StatusText(status)})
+	}
+	maintenance := &smopclient.MaintenanceError{APIError: &smopclient.APIError{StatusCode: http.StatusServiceUnavailable}, Delay: 3 * time.Minute}
+
+	tests := map[string]struct {
+		delays *esv1.SmopRequeueDelays
+		err    error
+		want   time.Duration
+	}{
+		"unauthorized":           {err: apiError(http.StatusUnauthorized), want: defaultAuthRequeueDelay},
+		"forbidden":              {err: apiError(http.StatusForbidden), want: defaultAuthRequeueDelay},
+		"token exchange":         {err: fmt.Errorf("%w: denied", smopclient.ErrTokenExchange), want: defaultAuthRequeueDelay},
+		"invalid token":          {err: smopclient.ErrInvalidToken, want: defaultAuthRequeueDelay},
+		"too many requests":      {err: apiError(http.StatusTooManyRequests), want: defaultRateLimitRequeueDelay},
+		"retry budget exhausted": {err: fmt.Errorf("%w: after 3 attempts", smopclient.ErrRetryBudgetExhausted), want: defaultRateLimitRequeueDelay},
+		"server error":           {err: apiError(http.StatusBadGateway), want: defaultTransientRequeueDelay},
+		"deadline exceeded":      {err: context.DeadlineExceeded, want: defaultTransientRequeueDelay},
+		"connection refused": {
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			want: defaultTransientRequeueDelay,
+		},
+		"dns":                    {err: &net.DNSError{Err: "no such host", Name: "smop.example"}, want: defaultTransientRequeueDelay},
+		"token exchange timeout": {err: fmt.Errorf("%w: %w", smopclient.ErrTokenExchange, context.DeadlineExceeded), want: defaultTransientRequeueDelay},
+		"overridden": {
+			delays: &esv1.SmopRequeueDelays{Auth: &metav1.Duration{Duration: time.Hour}, Transient: &metav1.Duration{Duration: time.Second}},
+			err:    apiError(http.StatusUnauthorized),
+			want:   time.Hour,
+		},
+		"other class keeps its default": {
+			delays: &esv1.SmopRequeueDelays{Auth: &metav1.Duration{Duration: time.Hour}},
+			err:    apiError(http.StatusTooManyRequests),
+			want:   defaultRateLimitRequeueDelay,
+		},
+		"zero leaves backoff": {
+			delays: &esv1.SmopRequeueDelays{Transient: &metav1.Duration{}},
+			err:    apiError(http.StatusInternalServerError),
+		},
+		"client error":        {err: apiError(http.StatusBadRequest)},
+		"not found":           {err: esv1.NoSecretErr},
+		"unclassified":        {err: errors.New("secret value is nil")},
+		"maintenance":         {err: maintenance, want: maintenance.Delay},
+		"wrapped maintenance": {err: fmt.Errorf("failed to get secret %w", maintenance), want: maintenance.Delay},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: &esv1.SmopProvider{RequeueDelays: tc.delays}}
+
+			err := c.withRequeueDelay(tc.err)
+			require.ErrorIs(t, err, tc.err)
+			assert.EqualError(t, err, tc.err.Error())
+
+			var retryAfter esv1.RetryAfterError
+			if tc.want == 0 {
+				assert.False(t, errors.As(err, &retryAfter), "error should leave the requeue to the controller backoff")
+				return
+			}
+			require.True(t, errors.As(err, &retryAfter))
+			assert.Equal(t, tc.want, retryAfter.RetryAfter())
+		})
+	}
+
+	c := &Client{store: &esv1.SmopProvider{}}
+	assert.NoError(t, c.withRequeueDelay(nil))
+}
+
+func TestGetSecretRequeueDelay(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{RequeueDelays: &esv1.SmopRequeueDelays{RateLimit: &metav1.Duration{Duration: 5 * time.Minute}}},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return nil, &smopclient.APIError{StatusCode: http.StatusTooManyRequests}
+			},
+		},
+	}
+	ref := esv1.ExternalSecretDataRemoteRef{Key: "db"}
+
+	_, err := c.GetSecret(context.Background(), ref)
+	assertRetryAfter(t, err, 5*time.Minute)
+	_, err = c.GetSecretMap(context.Background(), ref)
+	assertRetryAfter(t, err, 5*time.Minute)
+	assertAPIError(t, err, http.StatusTooManyRequests)
+}
+
+func TestFailureReason(t *testing.T) {
+	apiError := func(status int) error {
+		return fmt.Errorf("failed to get secret %w", &smopclient.APIError{StatusCode: status, Message: http.StatusText(status)})
+	}
+	maintenance := &smopclient.MaintenanceError{APIError: &smopclient.APIError{StatusCode: http.StatusServiceUnavailable}, Delay: 3 * time.Minute}
+
+	tests := map[string]struct {
+		err  error
+		want esv1.ProviderFailureReason
+	}{
+		"unauthorized":           {err: apiError(http.StatusUnauthorized), want: esv1.ProviderFailureReasonAuth},
+		"token exchange":         {err: fmt.Errorf("%w: denied", smopclient.ErrTokenExchange), want: esv1.ProviderFailureReasonAuth},
+		"not found":              {err: mapNotFound(apiError(http.StatusNotFound)), want: esv1.ProviderFailureReasonNotFound},
+		"missing property":       {err: fmt.Errorf("property certs[2]: %w", esv1.NoSecretErr), want: esv1.ProviderFailureReasonNotFound},
+		"too many requests":      {err: apiError(http.StatusTooManyRequests), want: esv1.ProviderFailureReasonRateLimited},
+		"retry budget exhausted": {err: smopclient.ErrRetryBudgetExhausted, want: esv1.ProviderFailureReasonRateLimited},
+		"server error":           {err: apiError(http.StatusBadGateway), want: esv1.ProviderFailureReasonServerError},
+		"unreachable":            {err: &net.DNSError{Err: "no such host", Name: "smop.example"}, want: esv1.ProviderFailureReasonServerError},
+		"maintenance":            {err: maintenance, want: esv1.ProviderFailureReasonServerError},
+		"client error":           {err: apiError(http.StatusBadRequest)},
+		"unclassified":           {err: errors.New("secret value is nil")},
+		"approval pending": {
+			err:  &smopclient.ApprovalPendingError{APIError: &smopclient.APIError{StatusCode: http.StatusForbidden}, Delay: time.Hour},
+			want: esv1.ProviderFailureReasonApprovalPending,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{store: &esv1.SmopProvider{}}
+
+			err := c.withRequeueDelay(tc.err)
+			require.ErrorIs(t, err, tc.err)
+			assert.EqualError(t, err, tc.err.Error())
+
+			var reasonErr esv1.FailureReasonError
+			if tc.want == "" {
+				assert.False(t, errors.As(err, &reasonErr), "error should be recorded with the default reason")
+				return
+			}
+			require.True(t, errors.As(err, &reasonErr))
+			assert.Equal(t, tc.want, reasonErr.FailureReason())
+		})
+	}
+
+	t.Run("maintenance keeps its delay", func(t *testing.T) {
+		err := (&Client{store: &esv1.SmopProvider{}}).withRequeueDelay(maintenance)
+		assertRetryAfter(t, err, maintenance.Delay)
+	})
+}
+
+func TestGetSecretFailureReason(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+				if name == "missing" {
+					return nil, &smopclient.APIError{StatusCode: http.StatusNotFound}
+				}
+				return nil, &smopclient.APIError{StatusCode: http.StatusForbidden}
+			},
+		},
+	}
+
+	_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "missing"})
+	assert.ErrorIs(t, err, esv1.NoSecretErr)
+	var reasonErr esv1.FailureReasonError
+	require.True(t, errors.As(err, &reasonErr))
+	assert.Equal(t, esv1.ProviderFailureReasonNotFound, reasonErr.FailureReason())
+
+	_, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	require.True(t, errors.As(err, &reasonErr))
+	assert.Equal(t, esv1.ProviderFailureReasonAuth, reasonErr.FailureReason())
+	assertRetryAfter(t, err, defaultAuthRequeueDelay)
+}
+
+func TestGetSecretApprovalPending(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{},
+		smopClient: &fake.SmopClient{
+			GetSecretFn: func(_ context.Context, _ string, _ *string) (*cg.KV, error) {
+				return nil, &smopclient.ApprovalPendingError{
+					APIError:  &smopclient.APIError{StatusCode: http.StatusForbidden, Message: "access requires approval", Path: "/db"},
+					RequestID: "apr-42",
+					Delay:     15 * time.Minute,
+				}
+			},
+		},
+	}
+
+	_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	assert.ErrorIs(t, err, smopclient.ErrApprovalPending)
+	assert.ErrorContains(t, err, "approval request apr-42")
+	assertRetryAfter(t, err, 15*time.Minute)
+	var reasonErr esv1.FailureReasonError
+	require.True(t, errors.As(err, &reasonErr))
+	assert.Equal(t, esv1.ProviderFailureReasonApprovalPending, reasonErr.FailureReason())
+}
+
+func TestValidateRequeueDelays(t *testing.T) {
+	assert.NoError(t, validateRequeueDelays(nil))
+	assert.NoError(t, validateRequeueDelays(&esv1.SmopRequeueDelays{Auth: &metav1.Duration{}, RateLimit: &metav1.Duration{Duration: time.Minute}}))
+	assert.EqualError(t,
+		validateRequeueDelays(&esv1.SmopRequeueDelays{RateLimit: &metav1.Duration{Duration: -time.Second}}),
+		"invalid Smop requeueDelays rateLimit -1s: must not be negative")
+}
+
+func assertRetryAfter(t *testing.T, err error, want time.Duration) {
+	t.Helper()
+	var retryAfter esv1.RetryAfterError
+	require.True(t, errors.As(err, &retryAfter), "expected a retry after hint, got %v", err)
+	assert.Equal(t, want, retryAfter.RetryAfter())
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// TestGetSecretMapRewrite runs the output of GetSecretMap through the rewrite operations
+// of dataFrom, like the ExternalSecret controller does.
+func TestGetSecretMapRewrite(t *testing.T) {
+	const secret = `{
+		"db": {"host": "db.internal", "port": 5432},
+		"api-key": "k3y",
+		"api-secret": "s3cr3t"
+	}`
+
+	tests := map[string]struct {
+		store   esv1.SmopProvider
+		ref     esv1.ExternalSecretDataRemoteRef
+		rewrite []esv1.ExternalSecretRewrite
+		want    map[string]string
+	}{
+		"regexp": {
+			rewrite: []esv1.ExternalSecretRewrite{
+				{Regexp: &esv1.ExternalSecretRewriteRegexp{Source: "^api-(.*)$", Target: "API_$1"}},
+			},
+			want: map[string]string{
+				"db":         `{"host":"db.internal","port":5432}`,
+				"API_key":    "k3y",
+				"API_secret": "s3cr3t",
+			},
+		},
+		"regexp on flattened keys": {
+			store: esv1.SmopProvider{Flatten: &esv1.SmopFlatten{}},
+			rewrite: []esv1.ExternalSecretRewrite{
+				{Regexp: &esv1.ExternalSecretRewriteRegexp{Source: `\.`, Target: "_"}},
+				{Transform: &esv1.ExternalSecretRewriteTransform{Template: `{{ .value | upper }}`}},
+			},
+			want: map[string]string{
+				"DB_HOST":    "db.internal",
+				"DB_PORT":    "5432",
+				"API-KEY":    "k3y",
+				"API-SECRET": "s3cr3t",
+			},
+		},
+		"regexp after key filter": {
+			store: esv1.SmopProvider{KeyFilter: &esv1.SmopKeyFilter{Allow: []string{"api-*"}}},
+			rewrite: []esv1.ExternalSecretRewrite{
+				{Regexp: &esv1.ExternalSecretRewriteRegexp{Source: "-", Target: "_"}},
+			},
+			want: map[string]string{
+				"api_key":    "k3y",
+				"api_secret": "s3cr3t",
+			},
+		},
+		"regexp on a property": {
+			ref: esv1.ExternalSecretDataRemoteRef{Property: "db"},
+			rewrite: []esv1.ExternalSecretRewrite{
+				{Regexp: &esv1.ExternalSecretRewriteRegexp{Source: "^(.*)$", Target: "db_$1"}},
+			},
+			want: map[string]string{
+				"db_host": "db.internal",
+				"db_port": "5432",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var values cg.RedactedMap
+			require.NoError(t, json.Unmarshal([]byte(secret), &values))
+			store := tc.store
+			keyFilter, err := newKeyFilter(store.KeyFilter)
+			require.NoError(t, err)
+			c := &Client{
+				store:     &store,
+				keyFilter: keyFilter,
+				smopClient: &fake.SmopClient{
+					GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+						return &cg.KV{Path: name, Secret: values}, nil
+					},
+				},
+			}
+
+			ref := tc.ref
+			ref.Key = "app"
+			secretMap, err := c.GetSecretMap(context.Background(), ref)
+			require.NoError(t, err)
+			got, err := esutils.RewriteMap(tc.rewrite, secretMap)
+			require.NoError(t, err)
+
+			assert.Equal(t, toByteMap(tc.want), got)
+		})
+	}
+}
+
+// TestGetAllSecretsRewrite rewrites the relative paths dataFrom.find returns for a recursive store
+// into valid Secret keys.
+func TestGetAllSecretsRewrite(t *testing.T) {
+	folder := func(p string) *string { return &p }
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "apps", FindRecursive: true},
+		smopClient: &fake.SmopClient{
+			WalkSecretsFn: func(_ context.Context, _ *string) ([]smopclient.KVRef, error) {
+				return []smopclient.KVRef{
+					{Name: "token", FolderPath: folder("apps")},
+					{Name: "password", FolderPath: folder("/apps/db")},
+				}, nil
+			},
+			GetSecretFn: func(_ context.Context, name string, _ *string) (*cg.KV, error) {
+				return &cg.KV{Secret: cg.RedactedMap{"value": name}}, nil
+			},
+		},
+	}
+
+	secretMap, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+	require.NoError(t, err)
+	got, err := esutils.RewriteMap([]esv1.ExternalSecretRewrite{
+		{Regexp: &esv1.ExternalSecretRewriteRegexp{Source: "/", Target: "-"}},
+	}, secretMap)
+	require.NoError(t, err)
+
+	assert.Equal(t, toByteMap(map[string]string{
+		"token":       `{"value":"token"}`,
+		"db-password": `{"value":"password"}`,
+	}), got)
+}
+
+func toByteMap(in map[string]string) map[string][]byte {
+	out := make(map[string][]byte, len(in))
+	for k, v := range in {
+		out[k] = []byte(v)
+	}
+	return out
+}
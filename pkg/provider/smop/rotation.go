@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// defaultRotationMinInterval is the minimum time between two rotations of a secret, see SmopRotateOnSync.
+const defaultRotationMinInterval = time.Hour
+
+// rotationTracker remembers until when each secret must not be rotated again. It is shared by every
+// Client, as a Client only lives for a single sync, so a secret read by several ExternalSecrets, or by
+// several data entries of one, is rotated once per minimum interval.
+type rotationTracker struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+var rotations = &rotationTracker{until: map[string]time.Time{}}
+
+// reserve reports whether the secret `key` may be rotated at `now`, and if so blocks further rotations
+// for minInterval, so concurrent syncs do not rotate it too. Entries past their interval are dropped.
+func (r *rotationTracker) reserve(key string, now time.Time, minInterval time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, until := range r.until {
+		if !now.Before(until) {
+			delete(r.until, k)
+		}
+	}
+	if _, ok := r.until[key]; ok {
+		return false
+	}
+	r.until[key] = now.Add(minInterval)
+	return true
+}
+
+// release allows rotating the secret `key` again, after its rotation failed.
+func (r *rotationTracker) release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.until, key)
+}
+
+// rotateOnSync rotates the secret `name` at `folderPath` in the group `scope` before it is read, when the
+// store sets RotateOnSync. A secret rotated within the minimum interval of the store is read as is.
+// Secrets are told apart by their SMoP server, the folder of the store, their scope and path, so only
+// stores reading the same secret share its interval.
+// A failed rotation is returned as is: a 404 of the rotate endpoint does not mean the secret is missing.
+func (c *Client) rotateOnSync(ctx context.Context, name, folderPath, scope string) error {
+	if c.store.RotateOnSync == nil {
+		return nil
+	}
+	minInterval := defaultRotationMinInterval
+	if c.store.RotateOnSync.MinInterval != nil {
+		minInterval = c.store.RotateOnSync.MinInterval.Duration
+	}
+
+	ref := smopclient.KVRef{Name: name, FolderPath: &folderPath}
+	key := strings.Join([]string{c.smopClient.BaseURL().String(), storeFolderPath(c.store), scope, ref.String()}, "\x00")
+	if !rotations.reserve(key, time.Now(), minInterval) {
+		log.V(1).Info("skipping rotation of SMoP secret rotated within the minimum interval", "path", ref.String(),
+			"minInterval", minInterval)
+		return nil
+	}
+
+	rotation, err := c.smopClient.RotateSecret(ctx, name, &folderPath)
+	if err != nil {
+		rotations.release(key)
+		return fmt.Errorf("failed to rotate SMoP secret %q: %w", ref.String(), err)
+	}
+	log.V(1).Info("rotated SMoP secret", "path", ref.String(), "version", rotation.Version)
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// useRotationTracker gives the test an empty rotation tracker, so rotations of other tests,
+// or of earlier runs of the test, do not count.
+func useRotationTracker(t *testing.T) {
+	t.Helper()
+	previous := rotations
+	rotations = &rotationTracker{until: map[string]time.Time{}}
+	t.Cleanup(func() { rotations = previous })
+}
+
+// newRotationTestClient returns a client rotating on sync which records the SMoP calls it makes in calls.
+func newRotationTestClient(t *testing.T, rotateOnSync *esv1.SmopRotateOnSync, rotateErr error, calls *[]string) *Client {
+	t.Helper()
+	return &Client{
+		store: &esv1.SmopProvider{FolderPath: "apps", RotateOnSync: rotateOnSync},
+		smopClient: &fake.SmopClient{
+			RotateSecretFn: func(_ context.Context, name string, folderPath *string) (*smopclient.Rotation, error) {
+				*calls = append(*calls, "rotate "+*folderPath+"/"+name)
+				if rotateErr != nil {
+					return nil, rotateErr
+				}
+				return &smopclient.Rotation{Version: 2}, nil
+			},
+			GetSecretFn: func(_ context.Context, name string, folderPath *string) (*cg.KV, error) {
+				*calls = append(*calls, "get "+*folderPath+"/"+name)
+				return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "r0t4t3d"}}, nil
+			},
+		},
+	}
+}
+
+func TestRotateOnSync(t *testing.T) {
+	useRotationTracker(t)
+	var calls []string
+	c := newRotationTestClient(t, &esv1.SmopRotateOnSync{}, nil, &calls)
+
+	got, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Property: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("r0t4t3d"), got)
+	assert.Equal(t, []string{"rotate apps/db", "get apps/db"}, calls, "the secret must be rotated before it is read")
+
+	// a later sync within the minimum interval reads the secret without rotating it again
+	calls = nil
+	c = newRotationTestClient(t, &esv1.SmopRotateOnSync{}, nil, &calls)
+	_, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+	require.NoError(t, err)
+	_, err = c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get apps/db", "rotate apps/other", "get apps/other"}, calls)
+}
+
+func TestRotateOnSyncMinInterval(t *testing.T) {
+	useRotationTracker(t)
+	var calls []string
+	c := newRotationTestClient(t, &esv1.SmopRotateOnSync{MinInterval: &metav1.Duration{Duration: time.Nanosecond}}, nil, &calls)
+
+	for range 2 {
+		_, err := c.GetSecretMap(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, []string{"rotate apps/db", "get apps/db", "rotate apps/db", "get apps/db"}, calls)
+}
+
+func TestRotateOnSyncFailure(t *testing.T) {
+	tests := map[string]struct {
+		err     error
+		wantErr error
+	}{
+		"rotation failed": {
+			err:     smopclient.ErrRotationFailed,
+			wantErr: smopclient.ErrRotationFailed,
+		},
+		// a 404 of the rotate endpoint does not tell the secret is missing
+		"rotate endpoint not found": {
+			err:     &smopclient.APIError{StatusCode: http.StatusNotFound, Message: "not found"},
+			wantErr: &smopclient.APIError{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			useRotationTracker(t)
+			var calls []string
+			c := newRotationTestClient(t, &esv1.SmopRotateOnSync{}, tc.err, &calls)
+			// neither the not found policy nor default values apply to a failed rotation
+			c.store.NotFoundPolicy = esv1.SmopNotFoundPolicyIgnore
+			c.store.AllowDefaultValues = true
+
+			for range 2 {
+				_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", DefaultValue: ptr.To("fallback")})
+				require.ErrorContains(t, err, `failed to rotate SMoP secret "apps/db"`)
+				if apiErr := (&smopclient.APIError{}); errors.As(tc.wantErr, &apiErr) {
+					assert.ErrorAs(t, err, &apiErr)
+				} else {
+					assert.ErrorIs(t, err, tc.wantErr)
+				}
+				assert.NotErrorIs(t, err, esv1.NoSecretErr)
+				assert.NotErrorIs(t, err, esv1.SkipSecretErr)
+			}
+			// the secret is not read, and a failed rotation does not count towards the minimum interval
+			assert.Equal(t, []string{"rotate apps/db", "rotate apps/db"}, calls)
+		})
+	}
+}
+
+func TestRotateOnSyncScope(t *testing.T) {
+	useRotationTracker(t)
+	var calls []string
+	c := newRotationTestClient(t, &esv1.SmopRotateOnSync{}, nil, &calls)
+
+	// the same secret read in two scopes is rotated in each of them
+	for _, scope := range []string{"team-a", "team-b", "team-a"} {
+		_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db", Scope: scope})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"rotate apps/db", "get apps/db", "rotate apps/db", "get apps/db", "get apps/db"}, calls)
+}
+
+func TestRotationTracker(t *testing.T) {
+	r := &rotationTracker{until: map[string]time.Time{}}
+	now := time.Now()
+
+	assert.True(t, r.reserve("a", now, time.Minute))
+	assert.False(t, r.reserve("a", now.Add(30*time.Second), time.Minute))
+	assert.True(t, r.reserve("b", now.Add(30*time.Second), time.Hour))
+	assert.True(t, r.reserve("a", now.Add(time.Minute), time.Minute))
+	r.release("b")
+	assert.True(t, r.reserve("b", now.Add(time.Minute), time.Hour))
+
+	r.reserve("c", now, time.Second)
+	r.reserve("d", now.Add(2*time.Second), time.Minute)
+	assert.NotContains(t, r.until, "c", "entries past their interval must be dropped")
+}
+
+func TestValidateStoreRotateOnSync(t *testing.T) {
+	p := &Provider{}
+	for name, tc := range map[string]struct {
+		rotateOnSync *esv1.SmopRotateOnSync
+		checkout     *esv1.SmopCheckout
+		wantErr      string
+	}{
+		"valid":         {rotateOnSync: &esv1.SmopRotateOnSync{MinInterval: &metav1.Duration{Duration: time.Minute}}},
+		"default":       {rotateOnSync: &esv1.SmopRotateOnSync{}},
+		"zero interval": {rotateOnSync: &esv1.SmopRotateOnSync{MinInterval: &metav1.Duration{}}, wantErr: "must be positive"},
+		"with checkout": {rotateOnSync: &esv1.SmopRotateOnSync{}, checkout: &esv1.SmopCheckout{}, wantErr: "not supported with checkout"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spec := makeValidSmopProvider()
+			spec.RotateOnSync = tc.rotateOnSync
+			spec.Checkout = tc.checkout
+			_, err := p.ValidateStore(makeSmopStore(spec))
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
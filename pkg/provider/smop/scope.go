@@ -0,0 +1,40 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// scopeContext returns a copy of ctx reading the secrets shared with the SMoP group named by `scope`,
+// the scope of a remoteRef. An empty scope reads the personal secrets of the token owner.
+//
+// The group only selects which secrets the remote key is looked up in: the tenant of the store
+// (server.siteId) still applies, and the remote key is resolved within the group exactly as
+// without a scope, relative to the store folder and the base folder (see BaseFolderAnnotation).
+func scopeContext(ctx context.Context, scope string) (context.Context, error) {
+	if scope == "" {
+		return ctx, nil
+	}
+	if err := smopclient.ValidateGroup(scope); err != nil {
+		return nil, fmt.Errorf("invalid Smop remoteRef scope: %w", err)
+	}
+	return smopclient.ContextWithGroup(ctx, scope), nil
+}
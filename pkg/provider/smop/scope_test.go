@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestGetSecretScope(t *testing.T) {
+	var gotGroup, gotFolder string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotGroup = r.URL.Query().Get("group")
+		gotFolder = r.URL.Query().Get("folderName")
+		w.Header().Set("Content-Type", "application/json")
+		password := "personal"
+		if gotGroup != "" {
+			password = "shared-with-" + gotGroup
+		}
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"` + password + `"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	smopClient, err := smopclient.NewSMOPClient(srv.URL+"/site/secrets", "t0k3n")
+	require.NoError(t, err)
+	c := &Client{store: &esv1.SmopProvider{FolderPath: "apps"}, smopClient: smopClient}
+	ctx := esutils.ContextWithSourceAnnotations(context.Background(), map[string]string{BaseFolderAnnotation: "payments"})
+
+	value, err := c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "eu/db", Property: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "personal", string(value))
+	assert.Empty(t, gotGroup)
+
+	value, err = c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "eu/db", Property: "password", Scope: "platform-team"})
+	require.NoError(t, err)
+	assert.Equal(t, "shared-with-platform-team", string(value))
+	assert.Equal(t, "platform-team", gotGroup)
+	assert.Equal(t, "apps/payments/eu", gotFolder, "the remote key must resolve within the group like without a scope")
+
+	values, err := c.GetSecretMap(ctx, esv1.ExternalSecretDataRemoteRef{Key: "db", Scope: "eu.payments"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"password": []byte("shared-with-eu.payments")}, values)
+
+	gotGroup = ""
+	_, err = c.GetSecret(ctx, esv1.ExternalSecretDataRemoteRef{Key: "db", Scope: "platform/../admins"})
+	assert.ErrorIs(t, err, smopclient.ErrInvalidGroup)
+	assert.ErrorContains(t, err, "invalid Smop remoteRef scope")
+	assert.Empty(t, gotGroup, "a remoteRef with an invalid scope must not be fetched")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+// smopSecretTypes maps the SMoP KV type hints to Kubernetes Secret types.
+var smopSecretTypes = map[string]corev1.SecretType{
+	"opaque":     corev1.SecretTypeOpaque,
+	"tls":        corev1.SecretTypeTLS,
+	"ssh-key":    corev1.SecretTypeSSHAuth,
+	"basic-auth": corev1.SecretTypeBasicAuth,
+}
+
+// kvTypes tracks the SMoP types of the secrets read by a Client, keyed by their full SMoP path.
+type kvTypes struct {
+	mu   sync.Mutex
+	read map[string]string
+}
+
+// observe records the SMoP type of the secret at `path`, empty if it has none.
+func (t *kvTypes) observe(path, kvType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.read == nil {
+		t.read = map[string]string{}
+	}
+	t.read[path] = kvType
+}
+
+// get returns the SMoP type of the secret at `path`, if the Client read it.
+func (t *kvTypes) get(path string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	kvType, ok := t.read[path]
+	return kvType, ok
+}
+
+// SecretTypeHint returns the Kubernetes Secret type matching the SMoP type of the referenced KV.
+// KVs without a known SMoP type yield no hint. The type of a KV already read by the Client,
+// e.g. by GetSecretMap for the same dataFrom.extract, is reused instead of fetching the KV again.
+func (c *Client) SecretTypeHint(ctx context.Context, ref esv1.ExternalSecretDataRemoteRef) (corev1.SecretType, error) {
+	ctx, err := c.requestContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if ctx, err = scopeContext(ctx, ref.Scope); err != nil {
+		return "", err
+	}
+
+	name, folderPath, err := c.resolveRemoteKey(ctx, ref.Key)
+	if err != nil {
+		return "", err
+	}
+
+	if kvType, ok := c.types.get(smopclient.KVRef{Name: name, FolderPath: &folderPath}.String()); ok {
+		return secretTypeFromSmop(kvType), nil
+	}
+
+	kvType, err := c.smopClient.GetSecretType(ctx, name, &folderPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret type %w", mapNotFound(err))
+	}
+
+	return secretTypeFromSmop(kvType), nil
+}
+
+// secretTypeFromSmop maps a SMoP KV type to a Kubernetes Secret type, or an empty type if it is unknown.
+func secretTypeFromSmop(kvType string) corev1.SecretType {
+	return smopSecretTypes[strings.ToLower(kvType)]
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestSecretTypeHint(t *testing.T) {
+	tests := map[string]struct {
+		kvType  string
+		kvErr   error
+		want    corev1.SecretType
+		wantErr error
+	}{
+		"tls": {
+			kvType: "tls",
+			want:   corev1.SecretTypeTLS,
+		},
+		"tls is case insensitive": {
+			kvType: "TLS",
+			want:   corev1.SecretTypeTLS,
+		},
+		"opaque": {
+			kvType: "opaque",
+			want:   corev1.SecretTypeOpaque,
+		},
+		"ssh key": {
+			kvType: "ssh-key",
+			want:   corev1.SecretTypeSSHAuth,
+		},
+		"basic auth": {
+			kvType: "basic-auth",
+			want:   corev1.SecretTypeBasicAuth,
+		},
+		"untyped": {
+			kvType: "",
+			want:   "",
+		},
+		"unknown type": {
+			kvType: "certificate-chain",
+			want:   "",
+		},
+		"not found": {
+			kvErr:   &smopclient.APIError{StatusCode: http.StatusNotFound},
+			wantErr: esv1.NoSecretErr,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{
+				store: &esv1.SmopProvider{FolderPath: "apps"},
+				smopClient: &fake.SmopClient{
+					GetSecretTypeFn: func(_ context.Context, name string, folderPath *string) (string, error) {
+						assert.Equal(t, "db", name)
+						assert.Equal(t, "apps", *folderPath)
+						return tc.kvType, tc.kvErr
+					},
+				},
+			}
+
+			got, err := c.SecretTypeHint(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: "db"})
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSecretTypeHintReusesRead(t *testing.T) {
+	c := &Client{
+		store: &esv1.SmopProvider{FolderPath: "apps"},
+		smopClient: &fake.SmopClient{
+			GetSecretWithMetadataFn: func(_ context.Context, name string, _ *string) (*cg.KV, *smopclient.KVMetadata, error) {
+				return &cg.KV{Path: name, Secret: cg.RedactedMap{"tls.crt": "crt", "tls.key": "key"}}, &smopclient.KVMetadata{Path: name, Type: "tls"}, nil
+			},
+			GetSecretTypeFn: func(_ context.Context, name string, _ *string) (string, error) {
+				t.Errorf("unexpected fetch of the type of %q: GetSecretMap already read it", name)
+				return "", nil
+			},
+		},
+	}
+
+	ref := esv1.ExternalSecretDataRemoteRef{Key: "tls"}
+	_, err := c.GetSecretMap(context.Background(), ref)
+	require.NoError(t, err)
+
+	got, err := c.SecretTypeHint(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeTLS, got)
+}
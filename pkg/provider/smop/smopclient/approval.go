@@ -0,0 +1,109 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+const (
+	// approvalPendingCode is the error code of SMoP responses to a request for a KV whose access awaits approval.
+	approvalPendingCode = "APPROVAL_PENDING"
+	// defaultApprovalRetryAfter is the delay suggested for a pending approval without a retry-after hint.
+	// Approvals are granted by people, so it is longer than the delay of a maintenance.
+	defaultApprovalRetryAfter = 15 * time.Minute
+	// defaultApprovalPollInterval is how often a KV pending approval is fetched again, see WithApprovalPolling.
+	defaultApprovalPollInterval = 10 * time.Second
+)
+
+// ErrApprovalPending is matched by errors returned for a KV whose access requires an approval not granted yet.
+var ErrApprovalPending = errors.New("SMoP secret access pending approval")
+
+// ApprovalPendingError is returned when reading a KV requires an approval which is not granted yet.
+// It matches ErrApprovalPending and unwraps to the *APIError of the response.
+type ApprovalPendingError struct {
+	APIError *APIError
+	// RequestID identifies the approval request for the approvers, if SMoP reported it.
+	RequestID string
+	// Delay is how long to wait before trying again, as hinted by SMoP, or a default.
+	Delay time.Duration
+}
+
+func (e *ApprovalPendingError) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("%s, retry after %s: %s", ErrApprovalPending, e.Delay, e.APIError)
+	}
+	return fmt.Sprintf("%s (approval request %s), retry after %s: %s", ErrApprovalPending, e.RequestID, e.Delay, e.APIError)
+}
+
+func (e *ApprovalPendingError) Is(target error) bool {
+	return target == ErrApprovalPending
+}
+
+func (e *ApprovalPendingError) Unwrap() error {
+	return e.APIError
+}
+
+// RetryAfter returns how long to wait before trying again.
+// It makes the controller requeue ExternalSecrets later instead of backing off from a failure.
+func (e *ApprovalPendingError) RetryAfter() time.Duration {
+	return e.Delay
+}
+
+// approvalPendingResponse is the body of a SMoP response for a KV pending approval, e.g.
+// {"error": "access requires approval", "code": "APPROVAL_PENDING", "approvalRequestId": "apr-42", "retryAfter": 900}.
+// retryAfter takes the same forms as in a maintenance response.
+type approvalPendingResponse struct {
+	Error             string          `json:"error"`
+	Code              string          `json:"code"`
+	ApprovalRequestID string          `json:"approvalRequestId,omitempty"`
+	RetryAfter        json.RawMessage `json:"retryAfter,omitempty"`
+}
+
+// parseApprovalPendingResponse returns an *ApprovalPendingError if the error response body reports a pending approval.
+func parseApprovalPendingResponse(body []byte, path string, statusCode int, now time.Time) error {
+	var resp approvalPendingResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Code != approvalPendingCode {
+		return nil
+	}
+
+	message := resp.Error
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	return &ApprovalPendingError{
+		APIError:  &APIError{StatusCode: statusCode, Message: message, Path: path},
+		RequestID: resp.ApprovalRequestID,
+		Delay:     parseRetryAfter(resp.RetryAfter, now, defaultApprovalRetryAfter),
+	}
+}
+
+// awaitApproval returns the KV fetched with `fetch`. A KV pending approval is fetched again every
+// approval poll interval until it is approved, for up to the approval wait set with WithApprovalPolling;
+// without one, or once it is over, the *ApprovalPendingError is returned.
+func (c *SMOPClient) awaitApproval(ctx context.Context, fetch func(context.Context) (*cg.KV, kvAttributes, error)) (*cg.KV, kvAttributes, error) {
+	kv, attrs, err := fetch(ctx)
+	if c.approvalWait <= 0 {
+		return kv, attrs, err
+	}
+
+	deadline := time.Now().Add(c.approvalWait)
+	var pending *ApprovalPendingError
+	for errors.As(err, &pending) {
+		delay := min(c.approvalPollInterval, time.Until(deadline))
+		if delay <= 0 {
+			break
+		}
+		log.V(1).Info("SMoP secret access pending approval, waiting", "approvalRequestID", pending.RequestID, "path", pending.APIError.Path)
+		if sleepContext(ctx, delay) != nil {
+			break
+		}
+		kv, attrs, err = fetch(ctx)
+	}
+	return kv, attrs, err
+}
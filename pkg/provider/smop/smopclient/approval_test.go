@@ -0,0 +1,103 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newApprovalServer returns a server answering the first `pending` requests for a KV as pending approval,
+// and the following ones with the KV.
+func newApprovalServer(t *testing.T, pending int64, requests *atomic.Int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) <= pending {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"error":"access requires approval","code":"APPROVAL_PENDING","approvalRequestId":"apr-42"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetSecretApprovalPending(t *testing.T) {
+	var requests atomic.Int64
+	srv := newApprovalServer(t, 1, &requests)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.ErrorIs(t, err, ErrApprovalPending)
+	assert.ErrorContains(t, err, "approval request apr-42")
+
+	var pending *ApprovalPendingError
+	require.ErrorAs(t, err, &pending)
+	assert.Equal(t, "apr-42", pending.RequestID)
+	assert.Equal(t, defaultApprovalRetryAfter, pending.RetryAfter())
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusAccepted, apiErr.StatusCode)
+	assert.Equal(t, "access requires approval", apiErr.Message)
+	assert.Equal(t, int64(1), requests.Load(), "a pending approval must not be retried without polling")
+
+	kv, err := c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", kv.Secret["password"])
+}
+
+func TestGetSecretApprovalPolling(t *testing.T) {
+	t.Run("approved while waiting", func(t *testing.T) {
+		var requests atomic.Int64
+		srv := newApprovalServer(t, 2, &requests)
+
+		c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithApprovalPolling(time.Millisecond, time.Second))
+		require.NoError(t, err)
+
+		kv, err := c.GetSecret(context.Background(), "db", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", kv.Secret["password"])
+		assert.Equal(t, int64(3), requests.Load())
+	})
+
+	t.Run("still pending after the wait", func(t *testing.T) {
+		var requests atomic.Int64
+		srv := newApprovalServer(t, 1000, &requests)
+
+		c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithApprovalPolling(10*time.Millisecond, 50*time.Millisecond))
+		require.NoError(t, err)
+
+		_, _, err = c.GetSecretWithMetadata(context.Background(), "db", nil)
+		assert.ErrorIs(t, err, ErrApprovalPending)
+		assert.Greater(t, requests.Load(), int64(1))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithApprovalPolling(time.Minute, time.Second))
+		assert.ErrorContains(t, err, "invalid SMoP approval polling every 1m0s for 1s")
+	})
+}
+
+func TestParseApprovalPendingResponse(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	err := parseApprovalPendingResponse([]byte(`{"code":"APPROVAL_PENDING","retryAfter":"1h"}`), "apps/db", http.StatusForbidden, now)
+	var pending *ApprovalPendingError
+	require.ErrorAs(t, err, &pending)
+	assert.Equal(t, time.Hour, pending.Delay)
+	assert.Empty(t, pending.RequestID)
+	assert.Equal(t, http.StatusText(http.StatusForbidden), pending.APIError.Message)
+	assert.NotContains(t, err.Error(), "approval request")
+
+	assert.NoError(t, parseApprovalPendingResponse([]byte(`{"error":"forbidden"}`), "apps/db", http.StatusForbidden, now))
+	assert.NoError(t, parseApprovalPendingResponse([]byte(`not json`), "apps/db", http.StatusForbidden, now))
+}
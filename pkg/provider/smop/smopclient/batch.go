@@ -0,0 +1,263 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+// maxBatchSize is the number of KVs fetched with a single batch request.
+const maxBatchSize = 100
+
+// ErrInvalidBatchResponse is returned when the batch endpoint returns a KV which was not requested,
+// or leaves out a requested KV.
+var ErrInvalidBatchResponse = errors.New("invalid SMoP batch response")
+
+// BatchResult is the outcome of fetching a single KV of a batch.
+type BatchResult struct {
+	KV  *cg.KV
+	Err error
+	// ExpiresAt is the expiry of the KV, nil if it does not expire.
+	ExpiresAt *time.Time
+	// Version is the version of the KV, zero if not reported by SMoP.
+	Version int64
+	// AccountFields are the username and account of a privileged-account KV, see KVMetadata.AccountFields.
+	AccountFields map[string]string
+}
+
+// String returns the full path of the KV, which keys its BatchResult.
+func (r KVRef) String() string {
+	return joinKVPath(getPathString(r.FolderPath), r.Name)
+}
+
+// batchItem is a KV requested from, or returned by, the batch endpoint.
+type batchItem struct {
+	Path       string  `json:"path"`
+	FolderName *string `json:"folderName,omitempty"`
+}
+
+// batchResultItem is a single result of the batch endpoint: either the KV or the error fetching it.
+type batchResultItem struct {
+	batchItem
+	kvAttributes
+	Secret cg.RedactedMap `json:"secret"`
+	Status int            `json:"status,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// BatchGetSecrets fetches the KVs identified by `refs`, keyed by their full path (see KVRef.String).
+// KVs are fetched with the `POST kv/batch` endpoint in a single round-trip per 100 KVs, falling
+// back to one request per KV when the SMoP server does not offer the batch endpoint.
+// Failures to fetch a single KV are reported in its BatchResult; the returned error is only set
+// when the batch as a whole failed, e.g. because the SMoP token was rejected, or when the response
+// does not match the requested KVs, see ErrInvalidBatchResponse.
+func (c *SMOPClient) BatchGetSecrets(ctx context.Context, refs []KVRef) (map[string]BatchResult, error) {
+	ctx, cancel := c.withDefaultDeadline(ctx, "BatchGetSecrets")
+	defer cancel()
+
+	results := make(map[string]BatchResult, len(refs))
+
+	for start := 0; start < len(refs); start += maxBatchSize {
+		chunk := refs[start:min(start+maxBatchSize, len(refs))]
+
+		if !c.batchUnsupported.Load() {
+			supported, err := c.batchGet(ctx, chunk, results)
+			if err != nil {
+				return nil, err
+			}
+			if supported {
+				continue
+			}
+			log.V(1).Info("SMoP server does not offer the batch endpoint, falling back to fetching secrets one by one")
+			c.batchUnsupported.Store(true)
+		}
+
+		if err := c.getBatchResults(ctx, chunk, results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// batchGet fetches `refs` with a single batch request into `results`.
+// It reports false if the SMoP server does not offer the batch endpoint.
+func (c *SMOPClient) batchGet(ctx context.Context, refs []KVRef, results map[string]BatchResult) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	items := make([]batchItem, 0, len(refs))
+	for _, ref := range refs {
+		items = append(items, batchItem{Path: ref.Name, FolderName: ref.FolderPath})
+	}
+
+	resp, err := c.doRaw(ctx, http.MethodPost, nil, map[string]any{"items": items}, "kv", "batch")
+	if err != nil {
+		return false, fmt.Errorf("failed to batch fetch %d secrets: %w", len(refs), err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return false, fmt.Errorf("failed to read batch fetch response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return false, nil
+	}
+
+	respContentType := resp.Header.Get("Content-Type")
+	isJSON := c.isJSONResponse(respContentType, respBytes)
+	if resp.StatusCode != http.StatusOK || !isJSON {
+		if isJSON {
+			if err := parseAPIErrorResponse(respBytes, "kv/batch", resp.StatusCode); err != nil {
+				return false, err
+			}
+		}
+		return false, createAPIError(resp.StatusCode, respContentType, "kv/batch")
+	}
+
+	var dest struct {
+		Data []batchResultItem `json:"data"`
+	}
+	if err := UnmarshalUseNumber(respBytes, &dest); err != nil {
+		return false, fmt.Errorf("failed to unmarshal batch fetch response: %w", err)
+	}
+
+	// the server may echo a path with other leading or trailing slashes than it was requested with
+	requested := make(map[string]KVRef, len(refs))
+	for _, ref := range refs {
+		requested[batchKey(ref)] = ref
+	}
+	returned := make(map[string]batchResultItem, len(dest.Data))
+	for _, item := range dest.Data {
+		key := batchKey(KVRef{Name: item.Path, FolderPath: item.FolderName})
+		if _, ok := requested[key]; !ok {
+			return false, fmt.Errorf("%w: secret %q was not requested", ErrInvalidBatchResponse, key)
+		}
+		returned[key] = item
+	}
+	for key, ref := range requested {
+		item, ok := returned[key]
+		if !ok {
+			return false, fmt.Errorf("%w: secret %q is missing", ErrInvalidBatchResponse, ref.String())
+		}
+		results[ref.String()] = c.batchResult(ctx, ref, item)
+	}
+	return true, nil
+}
+
+// batchKey returns the full path of the KV without leading and trailing slashes, which matches
+// a KV of the batch response to the requested KV.
+func batchKey(ref KVRef) string {
+	return strings.Trim(ref.String(), "/")
+}
+
+// batchResult converts a single result of the batch endpoint, following aliases and fetching signed content like GetSecret.
+func (c *SMOPClient) batchResult(ctx context.Context, ref KVRef, item batchResultItem) BatchResult {
+	if item.Error != "" || item.Status >= http.StatusBadRequest {
+		status := item.Status
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		return BatchResult{Err: &APIError{StatusCode: status, Message: item.Error, Path: ref.String()}}
+	}
+	// the content of an alias target or of signed content is not part of the batch response
+	if item.isAlias() && c.followAliases || item.SignedContent != nil {
+		return c.getBatchResult(ctx, ref)
+	}
+
+	metadata := newKVMetadata(item.Path, item.kvAttributes)
+	if c.rejectExpired && metadata.Expired(time.Now()) {
+		return BatchResult{Err: metadata.expiredError()}
+	}
+	return BatchResult{
+		KV:            &cg.KV{Path: item.Path, Secret: item.Secret},
+		ExpiresAt:     item.ExpiresAt,
+		Version:       item.Version,
+		AccountFields: metadata.AccountFields(),
+	}
+}
+
+// getBatchResults fetches `refs` one by one into `results`, up to fetchConcurrency at a time.
+// A failure which fails every other fetch as well, like rejected credentials, cancels the fetches
+// still running and is returned. Other failures are reported in the BatchResult of their KV.
+func (c *SMOPClient) getBatchResults(ctx context.Context, refs []KVRef, results map[string]BatchResult) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		fatalErr error
+	)
+	fetched := make([]BatchResult, len(refs))
+	sem := make(chan struct{}, c.fetchConcurrency)
+	for i, ref := range refs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := c.getBatchResult(ctx, ref)
+			if isFatalFetchError(result.Err) {
+				errOnce.Do(func() {
+					fatalErr = result.Err
+					cancel()
+				})
+			}
+			fetched[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if fatalErr != nil {
+		return fmt.Errorf("failed to fetch secrets: %w", fatalErr)
+	}
+	// the parent context was cancelled before every KV was fetched
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for i, ref := range refs {
+		results[ref.String()] = fetched[i]
+	}
+	return nil
+}
+
+// isFatalFetchError reports whether a fetch failed in a way every other fetch of the client fails
+// as well: the SMoP token was rejected or could not be obtained. A 403 is not fatal, as the token
+// may only lack access to some of the KVs.
+func isFatalFetchError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusUnauthorized
+	}
+	return errors.Is(err, ErrTokenExchange) || errors.Is(err, ErrInvalidToken)
+}
+
+// getBatchResult fetches a single KV of a batch with GetSecret, for servers without the batch endpoint and for aliases.
+func (c *SMOPClient) getBatchResult(ctx context.Context, ref KVRef) BatchResult {
+	kv, metadata, err := c.GetSecretWithMetadata(ctx, ref.Name, ref.FolderPath)
+	if err != nil {
+		return BatchResult{Err: err}
+	}
+	if c.rejectExpired && metadata.Expired(time.Now()) {
+		return BatchResult{Err: metadata.expiredError()}
+	}
+	return BatchResult{KV: kv, ExpiresAt: metadata.ExpiresAt, Version: metadata.Version, AccountFields: metadata.AccountFields()}
+}
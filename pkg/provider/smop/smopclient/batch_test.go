@@ -0,0 +1,293 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchGetSecrets(t *testing.T) {
+	var batchRequests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/site/secrets/kv/batch", r.URL.Path)
+		batchRequests.Add(1)
+
+		var req struct {
+			Items []batchItem `json:"items"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Items, 3)
+
+		// paths are matched to the request regardless of leading and trailing slashes
+		_, _ = w.Write([]byte(`{"data":[
+			{"path":"db","folderName":"/apps/","secret":{"password":"s3cr3t"}},
+			{"path":"gone","folderName":"apps","status":404,"error":"not found"},
+			{"path":"root","secret":{"token":"t0k3n"}}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	apps, root := "apps", "/"
+	results, err := c.BatchGetSecrets(context.Background(), []KVRef{
+		{Name: "db", FolderPath: &apps},
+		{Name: "gone", FolderPath: &apps},
+		{Name: "root", FolderPath: &root},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), batchRequests.Load())
+	require.Len(t, results, 3)
+
+	require.NoError(t, results["apps/db"].Err)
+	assert.Equal(t, "s3cr3t", results["apps/db"].KV.Secret["password"])
+	require.NoError(t, results["/root"].Err)
+	assert.Equal(t, "t0k3n", results["/root"].KV.Secret["token"])
+
+	var apiErr *APIError
+	require.ErrorAs(t, results["apps/gone"].Err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestBatchGetSecretsUnmatchedItems(t *testing.T) {
+	tests := map[string]struct {
+		response string
+		wantErr  string
+	}{
+		"missing": {
+			response: `{"data":[{"path":"db","folderName":"apps","secret":{"password":"s3cr3t"}}]}`,
+			wantErr:  `secret "apps/omitted" is missing`,
+		},
+		"not requested": {
+			response: `{"data":[
+				{"path":"db","folderName":"apps","secret":{"password":"s3cr3t"}},
+				{"path":"omitted","folderName":"other","secret":{"password":"s3cr3t"}}
+			]}`,
+			wantErr: `secret "other/omitted" was not requested`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+			require.NoError(t, err)
+
+			apps := "apps"
+			_, err = c.BatchGetSecrets(context.Background(), []KVRef{{Name: "db", FolderPath: &apps}, {Name: "omitted", FolderPath: &apps}})
+			require.ErrorIs(t, err, ErrInvalidBatchResponse)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestBatchGetSecretsFallback(t *testing.T) {
+	var batchRequests, getRequests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			batchRequests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		getRequests.Add(1)
+		if path.Base(r.URL.Path) == "gone" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	refs := []KVRef{{Name: "db"}, {Name: "gone"}}
+	for range 2 {
+		results, err := c.BatchGetSecrets(context.Background(), refs)
+		require.NoError(t, err)
+		require.NoError(t, results["/db"].Err)
+		assert.Equal(t, "s3cr3t", results["/db"].KV.Secret["password"])
+		assert.Error(t, results["/gone"].Err)
+	}
+
+	assert.Equal(t, int64(1), batchRequests.Load(), "an unsupported batch endpoint must only be tried once")
+	assert.Equal(t, int64(4), getRequests.Load())
+}
+
+func TestBatchGetSecretsExpiry(t *testing.T) {
+	expired := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	nearExpiry := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"path":"expired","expiresAt":"` + expired.Format(time.RFC3339) + `","secret":{"password":"s3cr3t"}},
+			{"path":"near-expiry","expiresAt":"` + nearExpiry.Format(time.RFC3339) + `","secret":{"password":"s3cr3t"}}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+	refs := []KVRef{{Name: "expired"}, {Name: "near-expiry"}}
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	results, err := c.BatchGetSecrets(context.Background(), refs)
+	require.NoError(t, err)
+	require.NoError(t, results["/expired"].Err)
+	assert.Equal(t, &expired, results["/expired"].ExpiresAt)
+	assert.Equal(t, &nearExpiry, results["/near-expiry"].ExpiresAt)
+
+	c, err = NewSMOPClient(srv.URL+"/site/secrets", testToken, WithRejectExpired(true))
+	require.NoError(t, err)
+	results, err = c.BatchGetSecrets(context.Background(), refs)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results["/expired"].Err, ErrSecretExpired)
+	assert.Nil(t, results["/expired"].KV)
+	require.NoError(t, results["/near-expiry"].Err)
+	assert.Equal(t, &nearExpiry, results["/near-expiry"].ExpiresAt)
+}
+
+// newBatchFallbackServer starts a stub SMoP server without the batch endpoint, answering GETs with `handle`.
+func newBatchFallbackServer(t testing.TB, handle http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		handle(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func BenchmarkBatchGetSecrets(b *testing.B) {
+	const size = 50
+	apps := "apps"
+	refs := make([]KVRef, 0, size)
+	items := make([]string, 0, size)
+	for i := range size {
+		refs = append(refs, KVRef{Name: fmt.Sprintf("kv-%d", i), FolderPath: &apps})
+		items = append(items, fmt.Sprintf(`{"path":"kv-%d","folderName":"apps","secret":{"password":"s3cr3t-%d"}}`, i, i))
+	}
+
+	batch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(items, ","))
+	}))
+	b.Cleanup(batch.Close)
+	fallback := newBatchFallbackServer(b, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"path":%q,"secret":{"password":"s3cr3t"}}`, path.Base(r.URL.Path))
+	})
+
+	for name, srv := range map[string]*httptest.Server{"batch endpoint": batch, "fallback": fallback} {
+		b.Run(name, func(b *testing.B) {
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+			require.NoError(b, err)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				results, err := c.BatchGetSecrets(context.Background(), refs)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(results) != size {
+					b.Fatalf("fetched %d KVs, want %d", len(results), size)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchGetSecretsFallbackCancelsOnAuthFailure(t *testing.T) {
+	var requests, cancelled atomic.Int64
+	srv := newBatchFallbackServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if path.Base(r.URL.Path) == "kv-0" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"token expired"}`))
+			return
+		}
+		// the other fetches hang until they are cancelled
+		select {
+		case <-r.Context().Done():
+			cancelled.Add(1)
+		case <-time.After(10 * time.Second):
+		}
+	})
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithFetchConcurrency(3))
+	require.NoError(t, err)
+
+	refs := make([]KVRef, 10)
+	for i := range refs {
+		refs[i] = KVRef{Name: fmt.Sprintf("kv-%d", i)}
+	}
+
+	start := time.Now()
+	_, err = c.BatchGetSecrets(context.Background(), refs)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.Less(t, time.Since(start), 5*time.Second, "an auth failure must cancel the other fetches")
+
+	assert.LessOrEqual(t, requests.Load(), int64(3), "no fetch may start after an auth failure")
+	assert.Eventually(t, func() bool { return cancelled.Load() == requests.Load()-1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestBatchGetSecretsFallbackKeyFailures(t *testing.T) {
+	var requests atomic.Int64
+	srv := newBatchFallbackServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch path.Base(r.URL.Path) {
+		case "forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"forbidden"}`))
+		case "gone":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		default:
+			_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+		}
+	})
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithFetchConcurrency(2))
+	require.NoError(t, err)
+
+	results, err := c.BatchGetSecrets(context.Background(), []KVRef{{Name: "forbidden"}, {Name: "gone"}, {Name: "db"}, {Name: "db-2"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), requests.Load(), "a failure of a single KV must not cancel the others")
+	for _, key := range []string{"/db", "/db-2"} {
+		require.NoError(t, results[key].Err, key)
+		assert.Equal(t, "s3cr3t", results[key].KV.Secret["password"], key)
+	}
+	for key, status := range map[string]int{"/forbidden": http.StatusForbidden, "/gone": http.StatusNotFound} {
+		var apiErr *APIError
+		require.ErrorAs(t, results[key].Err, &apiErr, key)
+		assert.Equal(t, status, apiErr.StatusCode, key)
+	}
+}
+
+func TestWithFetchConcurrency(t *testing.T) {
+	_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithFetchConcurrency(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+const (
+	// bundleFilesKey is the only key of the value of a bundle KV, holding its files.
+	bundleFilesKey = "files"
+	// bundleEncodingBase64 is the encoding of bundle files holding binary content.
+	bundleEncodingBase64 = "base64"
+)
+
+var (
+	// ErrNotBundle is returned when reading the files of a KV which is not a bundle.
+	ErrNotBundle = errors.New("SMoP secret is not a bundle")
+	// ErrInvalidBundle is returned for a bundle KV whose files cannot be read.
+	ErrInvalidBundle = errors.New("invalid SMoP secret bundle")
+)
+
+// IsBundle reports whether the KV is a bundle of several files, e.g. a certificate with its key and chain,
+// rather than a key-value secret. The value of a bundle holds nothing but a list of files:
+//
+//	{"files": [{"name": "tls.crt", "content": "..."}, {"name": "tls.key", "content": "...", "encoding": "base64"}]}
+func IsBundle(kv *cg.KV) bool {
+	if kv == nil || len(kv.Secret) != 1 {
+		return false
+	}
+	files, ok := kv.Secret[bundleFilesKey].([]any)
+	if !ok || len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		fields, ok := file.(map[string]any)
+		if !ok {
+			return false
+		}
+		if _, ok := fields["name"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// BundleFiles returns the content of the files of a bundle KV (see IsBundle), keyed by file name.
+// Base64 encoded files are decoded. A KV which is not a bundle is reported as ErrNotBundle.
+func BundleFiles(kv *cg.KV) (map[string][]byte, error) {
+	if !IsBundle(kv) {
+		return nil, ErrNotBundle
+	}
+
+	files := kv.Secret[bundleFilesKey].([]any)
+	contents := make(map[string][]byte, len(files))
+	for _, file := range files {
+		fields := file.(map[string]any)
+		name := fields["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%w %q: file without a name", ErrInvalidBundle, kv.Path)
+		}
+		if _, ok := contents[name]; ok {
+			return nil, fmt.Errorf("%w %q: duplicate file %q", ErrInvalidBundle, kv.Path, name)
+		}
+
+		content, ok := fields["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w %q: file %q has no content", ErrInvalidBundle, kv.Path, name)
+		}
+		switch encoding, _ := fields["encoding"].(string); encoding {
+		case "":
+			contents[name] = []byte(content)
+		case bundleEncodingBase64:
+			decoded, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				return nil, fmt.Errorf("%w %q: file %q: %w", ErrInvalidBundle, kv.Path, name, err)
+			}
+			contents[name] = decoded
+		default:
+			return nil, fmt.Errorf("%w %q: file %q has unsupported encoding %q", ErrInvalidBundle, kv.Path, name, encoding)
+		}
+	}
+	return contents, nil
+}
+
+// GetSecretBundle returns the files of the bundle KV `name` at the specified `folderPath`, keyed by file name.
+// A KV which is not a bundle is reported as ErrNotBundle.
+func (c *SMOPClient) GetSecretBundle(ctx context.Context, name string, folderPath *string) (map[string][]byte, error) {
+	kv, err := c.GetSecret(ctx, name, folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := BundleFiles(kv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %q at %q: %w", name, getPathString(folderPath), err)
+	}
+	return files, nil
+}
@@ -0,0 +1,137 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+// ErrSecretCheckedOut is returned by CheckoutSecret when the KV is already checked out by someone else.
+var ErrSecretCheckedOut = errors.New("SMoP secret is already checked out")
+
+// Checkout is an exclusive reservation of a KV returned by CheckoutSecret.
+type Checkout struct {
+	// ID identifies the checkout when checking the KV back in.
+	ID string `json:"checkoutId"`
+	// ExpiresAt is when SMoP checks the KV back in by itself, if reported.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// KV is the checked out KV with its value.
+	KV cg.KV `json:"kv"`
+}
+
+// checkoutConflict is the body of a 409 response to a checkout.
+type checkoutConflict struct {
+	Error      string     `json:"error"`
+	CheckedOut string     `json:"checkedOutBy,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// CheckoutSecret checks out the KV `name` at the specified `folderPath`, reserving it exclusively
+// until it is checked in with CheckinSecret, or `ttl` has passed. A zero ttl uses the SMoP default.
+// A KV which is already checked out is reported as ErrSecretCheckedOut.
+func (c *SMOPClient) CheckoutSecret(ctx context.Context, name string, folderPath *string, ttl time.Duration) (*Checkout, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	var query url.Values
+	if folderPath != nil {
+		query = url.Values{"folderName": []string{*folderPath}}
+	}
+	body := map[string]any{}
+	if ttl > 0 {
+		body["ttlSeconds"] = int64(ttl.Seconds())
+	}
+
+	path := getPathString(folderPath)
+	resp, err := c.doRaw(ctx, http.MethodPost, query, body, "kv", name, "checkout")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check out secret %q at %q: %w", name, path, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read check out response %q at %q: %w", name, path, err)
+	}
+
+	fullKvPath := joinKVPath(path, name)
+	respContentType := resp.Header.Get("Content-Type")
+	isJSON := c.isJSONResponse(respContentType, respBytes)
+
+	switch {
+	case (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated) && isJSON:
+		var checkout Checkout
+		if err := UnmarshalUseNumber(respBytes, &checkout); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal check out response %q at %q: %w", name, path, err)
+		}
+		if checkout.ID == "" {
+			return nil, fmt.Errorf("failed to check out secret %q at %q: response has no checkout ID", name, path)
+		}
+		if checkout.KV.Path == "" {
+			checkout.KV.Path = name
+		}
+		return &checkout, nil
+	case resp.StatusCode == http.StatusConflict:
+		var conflict checkoutConflict
+		if isJSON && json.Unmarshal(respBytes, &conflict) == nil && conflict.CheckedOut != "" {
+			if conflict.ExpiresAt != nil {
+				return nil, fmt.Errorf("%w: %q is checked out by %q until %s", ErrSecretCheckedOut, fullKvPath, conflict.CheckedOut, conflict.ExpiresAt.Format(time.RFC3339))
+			}
+			return nil, fmt.Errorf("%w: %q is checked out by %q", ErrSecretCheckedOut, fullKvPath, conflict.CheckedOut)
+		}
+		return nil, fmt.Errorf("%w: %q", ErrSecretCheckedOut, fullKvPath)
+	}
+
+	// Try to parse error response
+	if isJSON {
+		if err := parseAPIErrorResponse(respBytes, fullKvPath, resp.StatusCode); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fallback error if we can't parse the response
+	return nil, createAPIError(resp.StatusCode, respContentType, fullKvPath)
+}
+
+// CheckinSecret checks the KV `name` at the specified `folderPath` back in, ending the checkout `checkoutID`.
+// Checking in a checkout which already expired, or a KV which is gone, is not an error.
+func (c *SMOPClient) CheckinSecret(ctx context.Context, name string, folderPath *string, checkoutID string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	var query url.Values
+	if folderPath != nil {
+		query = url.Values{"folderName": []string{*folderPath}}
+	}
+
+	path := getPathString(folderPath)
+	resp, err := c.doRaw(ctx, http.MethodPost, query, map[string]any{"checkoutId": checkoutID}, "kv", name, "checkin")
+	if err != nil {
+		return fmt.Errorf("failed to check in secret %q at %q: %w", name, path, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return fmt.Errorf("failed to read check in response %q at %q: %w", name, path, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound, http.StatusGone:
+		return nil
+	}
+
+	fullKvPath := joinKVPath(path, name)
+	respContentType := resp.Header.Get("Content-Type")
+	if c.isJSONResponse(respContentType, respBytes) {
+		if err := parseAPIErrorResponse(respBytes, fullKvPath, resp.StatusCode); err != nil {
+			return err
+		}
+	}
+
+	return createAPIError(resp.StatusCode, respContentType, fullKvPath)
+}
@@ -0,0 +1,65 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckoutSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.Equal(t, http.MethodPost, r.Method)
+		switch r.URL.Path {
+		case "/site/secrets/kv/db/checkout":
+			assert.Equal(t, "apps", r.URL.Query().Get("folderName"))
+			var body map[string]int64
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, int64(300), body["ttlSeconds"])
+			_, _ = w.Write([]byte(`{"checkoutId":"co-1","expiresAt":"2026-01-02T03:04:05Z","kv":{"path":"db","secret":{"password":"s3cr3t"}}}`))
+		case "/site/secrets/kv/locked/checkout":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"checked out","checkedOutBy":"alice","expiresAt":"2026-01-02T03:04:05Z"}`))
+		case "/site/secrets/kv/db/checkin":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "co-1", body["checkoutId"])
+			w.WriteHeader(http.StatusNoContent)
+		case "/site/secrets/kv/expired/checkin":
+			w.WriteHeader(http.StatusGone)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	ctx := context.Background()
+	apps := "apps"
+
+	checkout, err := c.CheckoutSecret(ctx, "db", &apps, 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "co-1", checkout.ID)
+	require.NotNil(t, checkout.ExpiresAt)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), checkout.ExpiresAt.UTC())
+	assert.Equal(t, "s3cr3t", checkout.KV.Secret["password"])
+
+	_, err = c.CheckoutSecret(ctx, "locked", nil, 0)
+	require.ErrorIs(t, err, ErrSecretCheckedOut)
+	assert.Contains(t, err.Error(), `"alice"`)
+
+	require.NoError(t, c.CheckinSecret(ctx, "db", &apps, checkout.ID))
+	require.NoError(t, c.CheckinSecret(ctx, "expired", nil, "co-2"))
+
+	err = c.CheckinSecret(ctx, "broken", nil, "co-3")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+}
@@ -0,0 +1,119 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidCredentialPath is returned when a credential-issuance path is empty or escapes the site API.
+var ErrInvalidCredentialPath = errors.New("invalid SMoP credential path")
+
+// Credential is the material returned by a SMoP credential-issuance endpoint.
+type Credential struct {
+	// Data holds the issued credential material.
+	Data map[string]any `json:"data"`
+	// LeaseID identifies the issued credential for revocation.
+	LeaseID string `json:"leaseId,omitempty"`
+	// LeaseDuration is the lifetime of the credential in seconds.
+	LeaseDuration int64 `json:"leaseDuration,omitempty"`
+	// Revocable reports whether the credential can be revoked before it expires.
+	Revocable bool `json:"revocable,omitempty"`
+}
+
+// IssueCredential requests a new credential from the SMoP credential-issuance endpoint at `credPath`.
+// `params` are sent as the JSON request body.
+func (c *SMOPClient) IssueCredential(ctx context.Context, credPath string, params map[string]any) (*Credential, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	segments, err := splitCredentialPath(credPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params == nil {
+		params = map[string]any{}
+	}
+	resp, err := c.doRaw(ctx, http.MethodPost, nil, params, append([]string{"credentials"}, segments...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue credential at %q: %w", credPath, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issue credential response at %q: %w", credPath, err)
+	}
+
+	respContentType := resp.Header.Get("Content-Type")
+	isJSON := c.isJSONResponse(respContentType, respBytes)
+
+	if (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated) && isJSON {
+		var cred Credential
+		if err = json.Unmarshal(respBytes, &cred); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response from issue credential at %q: %w", credPath, err)
+		}
+		return &cred, nil
+	}
+
+	// Try to parse error response
+	if isJSON {
+		if err := parseAPIErrorResponse(respBytes, credPath, resp.StatusCode); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fallback error if we can't parse the response
+	return nil, createAPIError(resp.StatusCode, respContentType, credPath)
+}
+
+// RevokeCredential revokes the credential issued under `leaseID`.
+// Revoking a lease that no longer exists is not an error.
+func (c *SMOPClient) RevokeCredential(ctx context.Context, leaseID string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	resp, err := c.doRaw(ctx, http.MethodDelete, nil, nil, "leases", leaseID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke credential lease %q: %w", leaseID, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return fmt.Errorf("failed to read revoke credential response for lease %q: %w", leaseID, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+
+	respContentType := resp.Header.Get("Content-Type")
+	if c.isJSONResponse(respContentType, respBytes) {
+		if err := parseAPIErrorResponse(respBytes, leaseID, resp.StatusCode); err != nil {
+			return err
+		}
+	}
+
+	return createAPIError(resp.StatusCode, respContentType, leaseID)
+}
+
+// splitCredentialPath splits a credential path into its URL path segments,
+// rejecting empty paths and dot segments that would escape the site API.
+func splitCredentialPath(credPath string) ([]string, error) {
+	trimmed := strings.Trim(credPath, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidCredentialPath)
+	}
+
+	segments := strings.Split(trimmed, "/")
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return nil, fmt.Errorf("%w %q", ErrInvalidCredentialPath, credPath)
+		}
+	}
+	return segments, nil
+}
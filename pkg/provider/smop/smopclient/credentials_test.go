@@ -0,0 +1,94 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueCredential(t *testing.T) {
+	var gotMethod, gotPath, gotAuth string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotAuth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/site/secrets/credentials/database/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"no such role"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{"username":"u","password":"p"},"leaseId":"lease-1","leaseDuration":3600,"revocable":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	cred, err := c.IssueCredential(context.Background(), "/database/readonly", map[string]any{"ttl": "1h"})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, gotMethod)
+	assert.Equal(t, "/site/secrets/credentials/database/readonly", gotPath)
+	assert.Equal(t, "Bearer "+testToken, gotAuth)
+	assert.Equal(t, map[string]any{"ttl": "1h"}, gotBody)
+	assert.Equal(t, &Credential{
+		Data:          map[string]any{"username": "u", "password": "p"},
+		LeaseID:       "lease-1",
+		LeaseDuration: 3600,
+		Revocable:     true,
+	}, cred)
+
+	_, err = c.IssueCredential(context.Background(), "database/missing", nil)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "no such role", apiErr.Message)
+
+	for _, p := range []string{"", "/", "database/../../other", "a//b"} {
+		_, err = c.IssueCredential(context.Background(), p, nil)
+		assert.ErrorIs(t, err, ErrInvalidCredentialPath, p)
+	}
+}
+
+func TestRevokeCredential(t *testing.T) {
+	tests := map[string]struct {
+		status  int
+		wantErr bool
+	}{
+		"revoked":         {status: http.StatusNoContent},
+		"already revoked": {status: http.StatusNotFound},
+		"forbidden":       {status: http.StatusForbidden, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotMethod, gotPath string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotPath = r.Method, r.URL.Path
+				w.WriteHeader(tc.status)
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+			require.NoError(t, err)
+
+			err = c.RevokeCredential(context.Background(), "lease-1")
+			assert.Equal(t, http.MethodDelete, gotMethod)
+			assert.Equal(t, "/site/secrets/leases/lease-1", gotPath)
+			if tc.wantErr {
+				var apiErr *APIError
+				require.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, tc.status, apiErr.StatusCode)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
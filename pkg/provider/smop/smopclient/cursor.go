@@ -0,0 +1,81 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidCursor is returned by ListSecretsPages for a cursor it did not return.
+var ErrInvalidCursor = errors.New("invalid SMoP pagination cursor")
+
+// ListSecretsPages lists the KVs at `folderPath` like ListSecrets, but fetches at most `maxPages` pages,
+// starting at `cursor`. An empty cursor starts at the first page.
+//
+// The returned cursor resumes the listing at the next page, e.g. in a later reconcile, and is empty
+// once the last page was listed. A cursor is the path and query of the next page advertised by SMoP,
+// it is always resolved against the SMoP API so it cannot redirect the SMoP token elsewhere.
+// Pages changed by SMoP between two calls are not detected: KVs may be listed twice or not at all.
+func (c *SMOPClient) ListSecretsPages(ctx context.Context, folderPath *string, cursor string, maxPages int) ([]KVRef, string, error) {
+	if maxPages <= 0 {
+		return nil, "", fmt.Errorf("invalid SMoP page limit %d: must be positive", maxPages)
+	}
+	next, err := c.resolveCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+	defer cancel()
+
+	var refs []KVRef
+	for range maxPages {
+		items, attrs, nextURL, err := c.getKVPage(ctx, folderPath, next)
+		if err != nil {
+			return nil, "", err
+		}
+		for i, item := range items {
+			if !attrs[i].isFolder() {
+				refs = append(refs, KVRef{Name: item.Path, FolderPath: folderPath, UpdatedAt: attrs[i].UpdatedAt, Tags: attrs[i].Tags})
+			}
+		}
+		if nextURL == nil {
+			if cursor == "" {
+				if err := c.requireFolder(ctx, folderPath, len(refs) == 0); err != nil {
+					return nil, "", err
+				}
+			}
+			return refs, "", nil
+		}
+		if next != nil && nextURL.String() == next.String() {
+			return nil, "", fmt.Errorf("failed to list secrets at %q: pagination loop at %q", getPathString(folderPath), nextURL.Redacted())
+		}
+		next = nextURL
+	}
+	return refs, next.RequestURI(), nil
+}
+
+// resolveCursor returns the URL of the page `cursor` points to, or nil for an empty cursor.
+func (c *SMOPClient) resolveCursor(cursor string) (*url.URL, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	ref, err := url.Parse(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if ref.Scheme != "" || ref.Host != "" || ref.User != nil {
+		return nil, fmt.Errorf("%w: %q must be a path and query", ErrInvalidCursor, ref.Redacted())
+	}
+	serverURL, err := url.Parse(c.raw.Server)
+	if err != nil {
+		return nil, err
+	}
+	resolved := serverURL.ResolveReference(ref)
+	if !strings.HasPrefix(resolved.Path, strings.TrimSuffix(serverURL.Path, "/")+"/") {
+		return nil, fmt.Errorf("%w: %q is outside of the SMoP API", ErrInvalidCursor, ref.Redacted())
+	}
+	return resolved, nil
+}
@@ -0,0 +1,73 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSecretsPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		_, _ = fmt.Fprintf(w, `{"data":[{"path":"kv-%d"},{"path":"sub-%d","type":"folder"}]}`, page, page)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	folder := "apps"
+	ctx := context.Background()
+
+	var names []string
+	var cursors []string
+	cursor := ""
+	for {
+		refs, next, err := c.ListSecretsPages(ctx, &folder, cursor, 2)
+		require.NoError(t, err)
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+		cursors = append(cursors, next)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, []string{"kv-1", "kv-2", "kv-3"}, names, "sub-folders are skipped")
+	assert.Equal(t, []string{"/site/secrets/kv?page=3", ""}, cursors)
+
+	refs, next, err := c.ListSecretsPages(ctx, &folder, "", 5)
+	require.NoError(t, err)
+	assert.Len(t, refs, 3)
+	assert.Empty(t, next, "a listing within the page limit completes")
+}
+
+func TestListSecretsPagesInvalidCursor(t *testing.T) {
+	c, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken)
+	require.NoError(t, err)
+
+	for name, cursor := range map[string]string{
+		"absolute URL":       "https://evil.example.com/site/secrets/kv?page=2",
+		"scheme relative":    "//evil.example.com/site/secrets/kv?page=2",
+		"outside of the API": "/other/kv?page=2",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := c.ListSecretsPages(context.Background(), nil, cursor, 1)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+
+	_, _, err = c.ListSecretsPages(context.Background(), nil, "", 0)
+	assert.ErrorContains(t, err, "invalid SMoP page limit")
+}
@@ -0,0 +1,57 @@
+package smopclient
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// debugLogLevel is the verbosity at which the metadata of write requests is logged.
+const debugLogLevel = 1
+
+// redactedHeaders are request headers whose values are never logged.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+}
+
+// logWriteRequest logs the shape of a request changing SMoP at debug level, to help diagnose failing
+// pushes and deletes: method, path, query, headers and the length of the body.
+// The body is never logged as it holds the secret value, nor are credential headers.
+func logWriteRequest(req *http.Request, bodyLength int) {
+	if !log.V(debugLogLevel).Enabled() || !isWriteMethod(req.Method) {
+		return
+	}
+
+	log.V(debugLogLevel).Info("sending SMoP write request",
+		"method", req.Method,
+		"path", req.URL.EscapedPath(),
+		"query", req.URL.RawQuery,
+		"headers", redactHeaders(req.Header),
+		"bodyLength", bodyLength)
+}
+
+// redactHeaders returns the request headers as "Name: value" strings, sorted by name,
+// with the values of credential headers replaced.
+func redactHeaders(header http.Header) []string {
+	lines := make([]string, 0, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		lines = append(lines, name+": "+value)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLog replaces the package logger with one writing to the returned builder at the given verbosity.
+func captureLog(t *testing.T, verbosity int) *strings.Builder {
+	t.Helper()
+
+	var out strings.Builder
+	previous := log
+	log = funcr.New(func(prefix, args string) {
+		out.WriteString(prefix + " " + args + "\n")
+	}, funcr.Options{Verbosity: verbosity})
+	t.Cleanup(func() { log = previous })
+	return &out
+}
+
+func TestWriteRequestDebugLogRedactsValue(t *testing.T) {
+	const value = "hunter2-do-not-log"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	ctx := context.Background()
+	apps := "apps"
+
+	out := captureLog(t, debugLogLevel)
+	require.NoError(t, c.SetSecret(ctx, "db", &apps, map[string]any{"password": value}, map[string]string{"team": "payments"}))
+	require.NoError(t, c.DeleteSecret(ctx, "db", &apps))
+
+	logs := out.String()
+	assert.Contains(t, logs, `"method"="PUT"`)
+	assert.Contains(t, logs, `"method"="DELETE"`)
+	assert.Contains(t, logs, `"path"="/site/secrets/kv/db"`)
+	assert.Contains(t, logs, `"bodyLength"=`)
+	assert.Contains(t, logs, "Authorization: [REDACTED]")
+	assert.NotContains(t, logs, value)
+	assert.NotContains(t, logs, testToken)
+}
+
+func TestWriteRequestDebugLogDisabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	out := captureLog(t, 0)
+	require.NoError(t, c.SetSecret(context.Background(), "db", nil, map[string]any{"password": "s3cr3t"}, nil))
+	assert.Empty(t, out.String())
+}
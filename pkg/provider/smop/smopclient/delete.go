@@ -0,0 +1,173 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrBulkDeleteNotConfirmed is returned when DeleteSecrets is called without confirmation.
+var ErrBulkDeleteNotConfirmed = errors.New("SMoP bulk delete must be explicitly confirmed")
+
+// ErrFolderNotEmpty is returned when DeleteSecret targets a folder which still holds KVs or sub-folders,
+// unless WithRecursiveDelete is set.
+var ErrFolderNotEmpty = errors.New("SMoP folder not empty")
+
+// DeleteSecret deletes the KV `name` at the specified `folderPath`.
+// If SMoP has no KV `name` but a sub-folder, the folder is deleted instead. It must be empty unless
+// WithRecursiveDelete is set, so that deleting a folder never orphans the KVs it holds.
+func (c *SMOPClient) DeleteSecret(ctx context.Context, name string, folderPath *string) error {
+	err := c.deleteKV(ctx, name, folderPath)
+	var apiErr *APIError
+	if err == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	// only a missing KV may be a folder, so KVs are deleted without looking up their folder first
+	target := joinKVPath(getPathString(folderPath), name)
+	listCtx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+	folder, lookupErr := c.lookupFolder(listCtx, target)
+	cancel()
+	if lookupErr != nil {
+		return fmt.Errorf("failed to delete secret %q at %q: %w", name, getPathString(folderPath), lookupErr)
+	}
+	if folder == nil {
+		return err
+	}
+	return c.deleteFolder(ctx, target)
+}
+
+// deleteFolder deletes the folder `folder`. A folder which is not empty is refused,
+// unless WithRecursiveDelete is set: its KVs and sub-folders are then deleted first, depth-first.
+func (c *SMOPClient) deleteFolder(ctx context.Context, folder string) error {
+	listCtx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+	items, attrs, err := c.listKVs(listCtx, &folder)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to list folder %q: %w", folder, err)
+	}
+
+	if len(items) > 0 && !c.recursiveDelete {
+		return fmt.Errorf("%w: refusing to delete %q, it holds %d KVs or sub-folders", ErrFolderNotEmpty, folder, len(items))
+	}
+	for i, item := range items {
+		child := strings.Trim(item.Path, "/")
+		if attrs[i].isFolder() {
+			err = c.deleteFolder(ctx, joinKVPath(folder, child))
+		} else {
+			err = c.deleteKV(ctx, child, &folder)
+		}
+		var apiErr *APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound) {
+			return err
+		}
+	}
+
+	if err := c.deleteEntry(ctx, url.Values{"path": []string{folder}}, folder, "folder"); err != nil {
+		return fmt.Errorf("failed to delete folder %q: %w", folder, err)
+	}
+	return nil
+}
+
+// deleteKV deletes the KV `name` at the specified `folderPath`.
+func (c *SMOPClient) deleteKV(ctx context.Context, name string, folderPath *string) error {
+	var query url.Values
+	if folderPath != nil {
+		query = url.Values{"folderName": []string{*folderPath}}
+	}
+
+	if err := c.deleteEntry(ctx, query, joinKVPath(getPathString(folderPath), name), "kv", name); err != nil {
+		return fmt.Errorf("failed to delete secret %q at %q: %w", name, getPathString(folderPath), err)
+	}
+	return nil
+}
+
+// deleteEntry sends a DELETE to the endpoint `segments` for the KV or folder at `fullPath`.
+func (c *SMOPClient) deleteEntry(ctx context.Context, query url.Values, fullPath string, segments ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	resp, err := c.doRaw(ctx, http.MethodDelete, query, nil, segments...)
+	if err != nil {
+		return err
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return fmt.Errorf("failed to read delete response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	}
+
+	respContentType := resp.Header.Get("Content-Type")
+	// Try to parse error response
+	if c.isJSONResponse(respContentType, respBytes) {
+		if err := parseAPIErrorResponse(respBytes, fullPath, resp.StatusCode); err != nil {
+			return err
+		}
+	}
+
+	// Fallback error if we can't parse the response
+	return createAPIError(resp.StatusCode, respContentType, fullPath)
+}
+
+// DeleteSecrets deletes every KV at the specified `folderPath`, and in its sub-folders when `recursive` is set.
+// As a safeguard against accidental mass deletion `confirm` must be true.
+// KVs are deleted in parallel (see WithDeleteConcurrency); KVs which are already gone count as deleted.
+// All failures are returned joined.
+func (c *SMOPClient) DeleteSecrets(ctx context.Context, folderPath *string, recursive, confirm bool) error {
+	if !confirm {
+		return fmt.Errorf("%w: refusing to delete secrets at %q", ErrBulkDeleteNotConfirmed, getPathString(folderPath))
+	}
+
+	ctx, cancel := c.withDefaultDeadline(ctx, "DeleteSecrets")
+	defer cancel()
+
+	targets, err := c.walk(ctx, folderPath, recursive)
+	if err != nil {
+		return err
+	}
+
+	log.Info("deleting SMoP secrets", "folder", getPathString(folderPath), "recursive", recursive, "count", len(targets))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, c.deleteConcurrency)
+	for _, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := c.deleteKV(ctx, target.Name, target.FolderPath)
+			var apiErr *APIError
+			if err == nil || errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				return
+			}
+
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete %d of %d secrets at %q: %w",
+			len(errs), len(targets), getPathString(folderPath), errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDeleteTestServer serves folder listings from `folders`, keyed by their path with a leading slash,
+// and records DELETE requests.
+// Deleting a KV named "gone" answers 404, deleting a KV named "stuck" answers 500.
+func newDeleteTestServer(t *testing.T, folders map[string]string) (*SMOPClient, func() []string) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			if r.URL.Path == "/site/secrets/folder" {
+				mu.Lock()
+				deleted = append(deleted, r.URL.Query().Get("path"))
+				mu.Unlock()
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			name := r.URL.Path[len("/site/secrets/kv/"):]
+			if _, ok := folders[r.URL.Query().Get("folderName")+"/"+name]; ok {
+				// folders are no KVs
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"not found"}`))
+				return
+			}
+			switch name {
+			case "gone":
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"not found"}`))
+				return
+			case "stuck":
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"error":"boom"}`))
+				return
+			}
+			mu.Lock()
+			deleted = append(deleted, r.URL.Query().Get("folderName")+"/"+name)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body, ok := folders["/"+strings.Trim(r.URL.Query().Get("path"), "/")]
+		if !ok {
+			body = `{"data":[]}`
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithDeleteConcurrency(2))
+	require.NoError(t, err)
+
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, deleted...)
+	}
+}
+
+func TestDeleteSecrets(t *testing.T) {
+	folders := map[string]string{
+		"/apps":     `{"data":[{"path":"a"},{"path":"b"},{"path":"gone"},{"path":"sub","type":"folder"}]}`,
+		"/apps/sub": `{"data":[{"path":"c"}]}`,
+		"/broken":   `{"data":[{"path":"a"},{"path":"stuck"}]}`,
+	}
+
+	tests := map[string]struct {
+		folder      string
+		recursive   bool
+		confirm     bool
+		wantDeleted []string
+		wantErr     error
+		wantErrMsg  string
+	}{
+		"not confirmed": {
+			folder:  "/apps",
+			wantErr: ErrBulkDeleteNotConfirmed,
+		},
+		"folder only": {
+			folder:      "/apps",
+			confirm:     true,
+			wantDeleted: []string{"/apps/a", "/apps/b"},
+		},
+		"recursive": {
+			folder:      "/apps",
+			recursive:   true,
+			confirm:     true,
+			wantDeleted: []string{"/apps/a", "/apps/b", "/apps/sub/c"},
+		},
+		"aggregates failures": {
+			folder:      "/broken",
+			confirm:     true,
+			wantDeleted: []string{"/broken/a"},
+			wantErrMsg:  "failed to delete 1 of 2 secrets",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, deleted := newDeleteTestServer(t, folders)
+
+			err := c.DeleteSecrets(context.Background(), &tc.folder, tc.recursive, tc.confirm)
+			switch {
+			case tc.wantErr != nil:
+				assert.ErrorIs(t, err, tc.wantErr)
+			case tc.wantErrMsg != "":
+				assert.ErrorContains(t, err, tc.wantErrMsg)
+			default:
+				assert.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tc.wantDeleted, deleted())
+		})
+	}
+}
+
+func TestWithDeleteConcurrency(t *testing.T) {
+	_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithDeleteConcurrency(0))
+	assert.Error(t, err)
+}
+
+func TestDeleteSecretFolder(t *testing.T) {
+	folders := map[string]string{
+		"/apps":             `{"data":[{"path":"a"},{"path":"empty","type":"folder"},{"path":"full","type":"folder"}]}`,
+		"/apps/empty":       `{"data":[]}`,
+		"/apps/full":        `{"data":[{"path":"b"},{"path":"gone"},{"path":"nested","type":"folder"}]}`,
+		"/apps/full/nested": `{"data":[{"path":"c"}]}`,
+	}
+
+	tests := map[string]struct {
+		name        string
+		recursive   bool
+		wantDeleted []string
+		wantErr     error
+	}{
+		"kv": {
+			name:        "a",
+			wantDeleted: []string{"/apps/a"},
+		},
+		"empty folder": {
+			name:        "empty",
+			wantDeleted: []string{"/apps/empty"},
+		},
+		"non-empty folder": {
+			name:        "full",
+			wantDeleted: []string{},
+			wantErr:     ErrFolderNotEmpty,
+		},
+		"non-empty folder recursive": {
+			name:        "full",
+			recursive:   true,
+			wantDeleted: []string{"/apps/full/b", "/apps/full/nested/c", "/apps/full/nested", "/apps/full"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, deleted := newDeleteTestServer(t, folders)
+			require.NoError(t, WithRecursiveDelete(tc.recursive)(c))
+
+			folder := "/apps"
+			err := c.DeleteSecret(context.Background(), tc.name, &folder)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantDeleted, deleted())
+		})
+	}
+}
+
+func TestDeleteSecretNotFound(t *testing.T) {
+	var lists atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet:
+			lists.Add(1)
+			_, _ = w.Write([]byte(`{"data":[{"path":"a"}]}`))
+		case r.URL.Path == "/site/secrets/kv/a":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	folder := "/apps"
+	require.NoError(t, c.DeleteSecret(context.Background(), "a", &folder))
+	assert.Equal(t, int64(0), lists.Load(), "a KV must be deleted without looking up its folder")
+
+	err = c.DeleteSecret(context.Background(), "missing", &folder)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, int64(1), lists.Load(), "only a missing KV may be a folder")
+}
@@ -0,0 +1,69 @@
+package smopclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultDialRetries is how often a connection to the SMoP server is dialed again
+	// after failing to resolve its host or to connect.
+	defaultDialRetries = 2
+	// defaultDialRetryDelay is the delay before the first dial retry, doubled for every further retry.
+	defaultDialRetryDelay = 100 * time.Millisecond
+)
+
+// retryDial returns a dial function which dials again, up to `retries` times, when `dial` failed to
+// resolve the host or to connect, e.g. while the cluster DNS restarts. Nothing of the request was sent
+// yet, so unlike the retries of retryTransport these apply to every request, writes included.
+func retryDial(dial dialFunc, retries int, delay time.Duration) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		for attempt := 0; ; attempt++ {
+			conn, err := dial(ctx, network, addr)
+			if err == nil || attempt >= retries || !isRetryableDialError(err) || ctx.Err() != nil {
+				return conn, err
+			}
+
+			wait := delay << attempt
+			log.V(1).Info("retrying SMoP connection", "addr", addr, "attempt", attempt+1, "delay", wait, "error", err.Error())
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// isRetryableDialError reports whether a dial failed transiently: a DNS lookup which did not report
+// the host as nonexistent, or a connection which was refused, reset, timed out or found no route.
+func isRetryableDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout() ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH)
+}
+
+// isTLSVerificationError reports whether err is caused by a server certificate which failed verification.
+// These failures are persistent, so they are never retried.
+func isTLSVerificationError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	return errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
@@ -0,0 +1,175 @@
+package smopclient
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyDialTransport returns a transport whose first `failures` dials fail with `dialErr`,
+// and the number of dials it made.
+func flakyDialTransport(failures int64, dialErr error) (*http.Transport, *atomic.Int64) {
+	var dials atomic.Int64
+	var dialer net.Dialer
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if dials.Add(1) <= failures {
+				return nil, dialErr
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}, &dials
+}
+
+func TestDialRetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := map[string]struct {
+		failures  int64
+		dialErr   error
+		opts      []ClientOption
+		wantErr   bool
+		wantDials int64
+	}{
+		"dns failure": {
+			failures:  1,
+			dialErr:   &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "server misbehaving", Name: "smop.example.com", IsTemporary: true}},
+			wantDials: 2,
+		},
+		"connection refused": {
+			failures:  2,
+			dialErr:   &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			wantDials: 3,
+		},
+		"retries exhausted": {
+			failures:  3,
+			dialErr:   &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			wantErr:   true,
+			wantDials: 3,
+		},
+		"nonexistent host": {
+			failures:  1,
+			dialErr:   &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "smop.example.com", IsNotFound: true}},
+			wantErr:   true,
+			wantDials: 1,
+		},
+		"disabled": {
+			failures:  1,
+			dialErr:   &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			opts:      []ClientOption{WithDialRetries(0, 0)},
+			wantErr:   true,
+			wantDials: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			transport, dials := flakyDialTransport(tc.failures, tc.dialErr)
+			opts := append([]ClientOption{WithTransport(transport), WithDialRetries(defaultDialRetries, time.Millisecond)}, tc.opts...)
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, opts...)
+			require.NoError(t, err)
+
+			kv, err := c.GetSecret(context.Background(), "db", nil)
+			if tc.wantErr {
+				require.ErrorIs(t, err, tc.dialErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "s3cr3t", kv.Secret["password"])
+			}
+			assert.Equal(t, tc.wantDials, dials.Load())
+		})
+	}
+}
+
+func TestDialRetryWrites(t *testing.T) {
+	var writes atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writes.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	transport, dials := flakyDialTransport(1, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED})
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithTransport(transport), WithDialRetries(1, time.Millisecond))
+	require.NoError(t, err)
+
+	require.NoError(t, c.SetSecret(context.Background(), "db", nil, map[string]any{"password": "s3cr3t"}, nil))
+	assert.Equal(t, int64(2), dials.Load())
+	assert.Equal(t, int64(1), writes.Load())
+}
+
+func TestDialRetryContextDone(t *testing.T) {
+	transport, dials := flakyDialTransport(100, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED})
+	c, err := NewSMOPClient("http://smop.example.com/site/secrets", testToken, WithTransport(transport), WithDialRetries(5, time.Hour))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.GetSecret(ctx, "db", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Minute, "a dial retry must not wait past the request context")
+	assert.Equal(t, int64(1), dials.Load())
+}
+
+func TestRetryTLSVerificationFailure(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(srv.Close)
+
+	var dials atomic.Int64
+	var dialer net.Dialer
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		return dialer.DialContext(ctx, network, addr)
+	}}
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken,
+		WithTransport(transport), WithMaxRetries(3), WithRetryBackoff(time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	var authorityErr x509.UnknownAuthorityError
+	require.ErrorAs(t, err, &authorityErr)
+	assert.False(t, IsRetryable(err))
+	assert.Equal(t, int64(1), dials.Load(), "a certificate failing verification must not be retried")
+	assert.Zero(t, requests.Load())
+}
+
+func TestIsRetryableDialError(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"dns timeout":        {err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}, want: true},
+		"dns temporary":      {err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}, want: true},
+		"dns not found":      {err: &net.DNSError{Err: "no such host", IsNotFound: true}},
+		"connection refused": {err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, want: true},
+		"no route":           {err: &net.OpError{Op: "dial", Err: syscall.EHOSTUNREACH}, want: true},
+		"wrapped":            {err: fmt.Errorf("dial: %w", syscall.ENETUNREACH), want: true},
+		"permission denied":  {err: &net.OpError{Op: "dial", Err: syscall.EACCES}},
+		"other":              {err: errors.New("boom")},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isRetryableDialError(tc.err))
+		})
+	}
+}
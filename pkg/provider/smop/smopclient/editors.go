@@ -0,0 +1,36 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+// chainRequestEditors composes `editors` into a single RequestEditorFn applying them in order.
+// All editors edit the same request, so each one sees the changes of the editors before it.
+// The first error stops the chain.
+func chainRequestEditors(editors ...cg.RequestEditorFn) cg.RequestEditorFn {
+	return func(ctx context.Context, req *http.Request) error {
+		for _, edit := range editors {
+			if err := edit(ctx, req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// requestEditor returns the per-request RequestEditorFn of the client: the editor adding the
+// Authorization and impersonation headers, followed by the editors of WithRequestEditorChain.
+func (c *SMOPClient) requestEditor() (cg.RequestEditorFn, error) {
+	auth, err := getRequestEditor(c.smopToken, c.tokenSource, c.impersonationSubject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request editor: %w", err)
+	}
+	if len(c.requestEditors) == 0 {
+		return auth, nil
+	}
+	return chainRequestEditors(append([]cg.RequestEditorFn{auth}, c.requestEditors...)...), nil
+}
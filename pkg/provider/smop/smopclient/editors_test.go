@@ -0,0 +1,91 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+func TestWithRequestEditorChain(t *testing.T) {
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/kv") {
+			_, _ = w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var order []string
+	editor := func(name string) cg.RequestEditorFn {
+		return func(_ context.Context, req *http.Request) error {
+			order = append(order, name)
+			req.Header.Add("X-Chain", name)
+			return nil
+		}
+	}
+	// sign sees the Authorization header and every header added before it
+	sign := func(_ context.Context, req *http.Request) error {
+		order = append(order, "sign")
+		req.Header.Set("X-Signature", req.Header.Get("Authorization")+"|"+strings.Join(req.Header.Values("X-Chain"), ","))
+		return nil
+	}
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken,
+		WithRequestEditorChain(editor("user-agent"), editor("custom")),
+		WithRequestEditorChain(sign))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = c.GetSecret(ctx, "db", nil)
+	require.NoError(t, err)
+	_, err = c.GetSecrets(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteSecret(ctx, "db", nil))
+
+	require.NotEmpty(t, headers)
+	for _, header := range headers {
+		assert.Equal(t, []string{"user-agent", "custom"}, header.Values("X-Chain"))
+		assert.Equal(t, "Bearer "+testToken+"|user-agent,custom", header.Get("X-Signature"))
+	}
+	assert.Equal(t, []string{"user-agent", "custom", "sign"}, order[:3])
+	assert.Len(t, order, 3*len(headers), "every request must run the whole chain once")
+}
+
+func TestChainRequestEditorsError(t *testing.T) {
+	errStop := errors.New("stop")
+	var ran []string
+	chain := chainRequestEditors(
+		func(context.Context, *http.Request) error { ran = append(ran, "first"); return nil },
+		func(context.Context, *http.Request) error { ran = append(ran, "second"); return errStop },
+		func(context.Context, *http.Request) error { ran = append(ran, "third"); return nil },
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "https://smop.example.com", nil)
+	assert.ErrorIs(t, chain(context.Background(), req), errStop)
+	assert.Equal(t, []string{"first", "second"}, ran)
+}
+
+func TestWithRequestEditorChainNil(t *testing.T) {
+	_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithRequestEditorChain(nil))
+	assert.ErrorContains(t, err, "invalid SMoP request editor chain")
+}
+
+func TestRequestEditorChainError(t *testing.T) {
+	errSign := errors.New("signing failed")
+	c := newTestClient(t, map[string]string{"db": `{"path":"db","secret":{}}`},
+		WithRequestEditorChain(func(context.Context, *http.Request) error { return errSign }))
+
+	_, err := c.GetSecret(context.Background(), "db", nil)
+	assert.ErrorIs(t, err, errSign)
+}
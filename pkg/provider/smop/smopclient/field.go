@@ -0,0 +1,60 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+// fieldQueryParam is the query parameter asking SMoP to return a single field of a KV value.
+const fieldQueryParam = "field"
+
+// GetSecretField fetches the specified secret like GetSecretWithMetadata, asking SMoP to return only
+// `field` of its value. Callers extract `field` from the returned value either way: SMoP servers
+// which do not select fields return the whole value.
+//
+// A server answering HTTP 501 is not asked to select fields again. A 400 or 404 falls back to
+// fetching the whole value for this call only, as it may come from the field rather than the
+// server, e.g. a file of a bundle the server cannot select; a missing KV is then reported by the full fetch.
+// An alias KV is followed with full fetches.
+func (c *SMOPClient) GetSecretField(ctx context.Context, name string, folderPath *string, field string) (*cg.KV, *KVMetadata, error) {
+	if field == "" || c.fieldSelectionUnsupported.Load() {
+		return c.GetSecretWithMetadata(ctx, name, folderPath)
+	}
+
+	kv, attrs, err := c.getField(ctx, name, folderPath, field)
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotImplemented:
+		if !c.fieldSelectionUnsupported.Swap(true) {
+			log.V(1).Info("SMoP server does not select fields of secrets, falling back to fetching whole secret values")
+		}
+		return c.GetSecretWithMetadata(ctx, name, folderPath)
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusNotFound):
+		return c.GetSecretWithMetadata(ctx, name, folderPath)
+	case err != nil:
+		return nil, nil, err
+	case attrs.isAlias() && c.followAliases:
+		return c.GetSecretWithMetadata(ctx, name, folderPath)
+	}
+	return kv, newKVMetadata(kv.Path, attrs), nil
+}
+
+// getField fetches a single KV without following aliases, with the field query parameter set.
+func (c *SMOPClient) getField(ctx context.Context, name string, folderPath *string, field string) (*cg.KV, kvAttributes, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+	return c.getKV(ctx, name, folderPath, setQueryParam(fieldQueryParam, field))
+}
+
+// setQueryParam sets the query parameter `key` of a request to `value`.
+func setQueryParam(key, value string) cg.RequestEditorFn {
+	return func(_ context.Context, req *http.Request) error {
+		query := req.URL.Query()
+		query.Set(key, value)
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecretField(t *testing.T) {
+	const full = `{"path":"db","version":4,"secret":{"username":"app","password":"s3cr3t"}}`
+
+	tests := map[string]struct {
+		// handle answers a request with the field query parameter
+		handle     func(w http.ResponseWriter)
+		field      string
+		want       cg.RedactedMap
+		wantFields []string
+	}{
+		"selected by the server": {
+			handle: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte(`{"path":"db","version":4,"secret":{"password":"s3cr3t"}}`))
+			},
+			field:      "password",
+			want:       cg.RedactedMap{"password": "s3cr3t"},
+			wantFields: []string{"password", "password"},
+		},
+		"ignored by the server": {
+			handle: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte(full))
+			},
+			field:      "password",
+			want:       cg.RedactedMap{"username": "app", "password": "s3cr3t"},
+			wantFields: []string{"password", "password"},
+		},
+		"not implemented by the server": {
+			handle: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotImplemented)
+				_, _ = w.Write([]byte(`{"error":"field selection not implemented"}`))
+			},
+			field:      "password",
+			want:       cg.RedactedMap{"username": "app", "password": "s3cr3t"},
+			wantFields: []string{"password", "", ""},
+		},
+		"rejected by the server": {
+			handle: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid field"}`))
+			},
+			field:      "password",
+			want:       cg.RedactedMap{"username": "app", "password": "s3cr3t"},
+			wantFields: []string{"password", "", "password", ""},
+		},
+		"not selectable": {
+			handle: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"field not found"}`))
+			},
+			field:      "tls.crt",
+			want:       cg.RedactedMap{"username": "app", "password": "s3cr3t"},
+			wantFields: []string{"tls.crt", "", "tls.crt", ""},
+		},
+		"no field": {
+			want:       cg.RedactedMap{"username": "app", "password": "s3cr3t"},
+			wantFields: []string{"", ""},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var fields []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				field := r.URL.Query().Get(fieldQueryParam)
+				mu.Lock()
+				fields = append(fields, field)
+				mu.Unlock()
+				if field != "" {
+					tc.handle(w)
+					return
+				}
+				_, _ = w.Write([]byte(full))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+			require.NoError(t, err)
+
+			for range 2 {
+				kv, md, err := c.GetSecretField(context.Background(), "db", nil, tc.field)
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, kv.Secret)
+				assert.Equal(t, int64(4), md.Version)
+			}
+			assert.Equal(t, tc.wantFields, fields)
+		})
+	}
+}
+
+func TestGetSecretFieldAlias(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"db":      `{"path":"db","secret":{"username":"app","password":"s3cr3t"}}`,
+		"db-link": `{"path":"db-link","type":"alias","target":"apps/db","secret":{}}`,
+	})
+
+	kv, _, err := c.GetSecretField(context.Background(), "db-link", nil, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", kv.Secret["password"])
+}
+
+func TestGetSecretFieldMissing(t *testing.T) {
+	c := newTestClient(t, map[string]string{})
+
+	_, _, err := c.GetSecretField(context.Background(), "gone", nil, "password")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
@@ -0,0 +1,60 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrFolderNotFound is returned when listing a folder that does not exist, if WithRequireFolder is set.
+var ErrFolderNotFound = errors.New("SMoP folder not found")
+
+// FolderExists reports whether the folder at `folderPath` exists. The root folder always exists.
+// SMoP lists a nonexistent folder like an empty one, so the folder is looked up in the listing of its parent,
+// which requires the SMoP server to report sub-folders in listings.
+func (c *SMOPClient) FolderExists(ctx context.Context, folderPath *string) (bool, error) {
+	if folderPath == nil || strings.Trim(*folderPath, "/") == "" {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+	defer cancel()
+
+	entry, err := c.lookupFolder(ctx, *folderPath)
+	if err != nil {
+		return false, err
+	}
+	return entry != nil, nil
+}
+
+// lookupFolder returns the entry of the folder at `folderPath` in the listing of its parent, nil if there is none.
+func (c *SMOPClient) lookupFolder(ctx context.Context, folderPath string) (*kvAttributes, error) {
+	parent, name := splitKVPath(folderPath)
+	items, attrs, err := c.listKVs(ctx, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up folder %q: %w", folderPath, err)
+	}
+	for i, item := range items {
+		if attrs[i].isFolder() && strings.Trim(item.Path, "/") == name {
+			return &attrs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// requireFolder returns ErrFolderNotFound if an empty listing of `folderPath` is due to a nonexistent folder.
+// It only checks when WithRequireFolder is set.
+func (c *SMOPClient) requireFolder(ctx context.Context, folderPath *string, empty bool) error {
+	if !c.requireExistingFolder || !empty {
+		return nil
+	}
+	exists, err := c.FolderExists(ctx, folderPath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %q", ErrFolderNotFound, getPathString(folderPath))
+	}
+	return nil
+}
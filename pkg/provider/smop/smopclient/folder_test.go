@@ -0,0 +1,87 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFolderTestClient starts a stub SMoP server holding the folder "apps" with the empty sub-folder "apps/empty".
+func newFolderTestClient(t *testing.T, opts ...ClientOption) *SMOPClient {
+	t.Helper()
+
+	listings := map[string]string{
+		"":           `{"data":[{"path":"apps","type":"folder"}]}`,
+		"apps":       `{"data":[{"path":"db"},{"path":"empty","type":"folder"}]}`,
+		"apps/empty": `{"data":[]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		listing, ok := listings[r.URL.Query().Get("path")]
+		if !ok {
+			// like SMoP, a nonexistent folder is listed as empty
+			listing = `{"data":[]}`
+		}
+		_, _ = w.Write([]byte(listing))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, testToken, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestFolderExists(t *testing.T) {
+	c := newFolderTestClient(t)
+	folder := func(p string) *string { return &p }
+
+	tests := map[string]struct {
+		folderPath *string
+		want       bool
+	}{
+		"root":             {folderPath: nil, want: true},
+		"top level":        {folderPath: folder("apps"), want: true},
+		"empty sub-folder": {folderPath: folder("apps/empty"), want: true},
+		"KV is no folder":  {folderPath: folder("apps/db")},
+		"typo":             {folderPath: folder("aps")},
+		"missing parent":   {folderPath: folder("aps/empty")},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.FolderExists(context.Background(), tc.folderPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRequireFolder(t *testing.T) {
+	empty, typo := "apps/empty", "aps/empty"
+
+	t.Run("empty and missing folders look alike by default", func(t *testing.T) {
+		c := newFolderTestClient(t)
+		for _, folderPath := range []*string{&empty, &typo} {
+			items, err := c.GetSecrets(context.Background(), folderPath)
+			require.NoError(t, err)
+			assert.Empty(t, items)
+		}
+	})
+
+	t.Run("missing folders are reported when required", func(t *testing.T) {
+		c := newFolderTestClient(t, WithRequireFolder(true))
+
+		items, err := c.GetSecrets(context.Background(), &empty)
+		require.NoError(t, err)
+		assert.Empty(t, items)
+
+		_, err = c.GetSecrets(context.Background(), &typo)
+		assert.ErrorIs(t, err, ErrFolderNotFound)
+
+		_, err = c.WalkSecrets(context.Background(), &typo)
+		assert.ErrorIs(t, err, ErrFolderNotFound)
+	})
+}
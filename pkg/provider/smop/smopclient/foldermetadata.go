@@ -0,0 +1,160 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FolderMetadata describes a folder for access reviews: who owns it and who may access it.
+type FolderMetadata struct {
+	// Path is the full path of the folder, empty for the root folder.
+	Path string
+	// Owner is the principal owning the folder, empty if not reported.
+	Owner string
+	// Permissions are the access control entries of the folder, nil if not reported.
+	Permissions []FolderPermission
+	// CreatedAt and UpdatedAt are nil if not reported by SMoP.
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	// Tags are nil if the folder has none.
+	Tags map[string]string
+	// Partial is set when the SMoP server does not offer the folder metadata endpoint:
+	// the metadata is then read from the listing of the parent folder, without owner and permissions.
+	Partial bool
+}
+
+// FolderPermission grants a principal actions on a folder.
+type FolderPermission struct {
+	// Principal is the user, group or service account granted the actions, e.g. "group:ops".
+	Principal string `json:"principal"`
+	// Actions are the granted actions, e.g. "read" or "write".
+	Actions []string `json:"actions,omitempty"`
+	// Inherited is set when the permission is granted on a parent folder.
+	Inherited bool `json:"inherited,omitempty"`
+}
+
+// folderMetadataItem is the response of the folder metadata endpoint.
+type folderMetadataItem struct {
+	Path        string             `json:"path"`
+	Owner       string             `json:"owner,omitempty"`
+	Permissions []FolderPermission `json:"permissions,omitempty"`
+	kvAttributes
+}
+
+// GetFolderMetadata fetches the metadata of the folder at `folderPath`, such as its owner and permissions,
+// for reporting; it is never needed to sync secrets.
+//
+// The metadata is fetched with the `GET folder/metadata` endpoint. When the SMoP server does not offer it,
+// GetFolderMetadata degrades to the entry of the folder in the listing of its parent, reporting the
+// metadata as Partial. The folder is then reported as ErrFolderNotFound if it does not exist.
+//
+// The endpoint is not tried again once the server answered it with HTTP 405 or 501, or with a 404
+// for a folder the listing then showed to exist. A 404 for a missing folder tells nothing about the endpoint.
+func (c *SMOPClient) GetFolderMetadata(ctx context.Context, folderPath *string) (*FolderMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	path := strings.Trim(getPathString(folderPath), "/")
+	if c.folderMetadataUnsupported.Load() {
+		return c.listedFolderMetadata(ctx, path)
+	}
+
+	md, err := c.getFolderMetadata(ctx, path)
+	switch {
+	case errors.Is(err, errMetadataUnsupported):
+		c.markFolderMetadataUnsupported()
+		return c.listedFolderMetadata(ctx, path)
+	case errors.Is(err, errFolderMetadataNotFound):
+		md, err := c.listedFolderMetadata(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		c.markFolderMetadataUnsupported()
+		return md, nil
+	}
+	return md, err
+}
+
+// errFolderMetadataNotFound is returned by getFolderMetadata on a 404, which cannot be told apart from a missing folder.
+var errFolderMetadataNotFound = errors.New("SMoP folder metadata not found")
+
+// markFolderMetadataUnsupported stops GetFolderMetadata from trying the folder metadata endpoint.
+func (c *SMOPClient) markFolderMetadataUnsupported() {
+	if !c.folderMetadataUnsupported.Swap(true) {
+		log.V(1).Info("SMoP server does not offer the folder metadata endpoint, falling back to folder listings")
+	}
+}
+
+// getFolderMetadata fetches the metadata of a folder with the folder metadata endpoint.
+// A 405 or 501 is reported as errMetadataUnsupported and a 404 as errFolderMetadataNotFound.
+func (c *SMOPClient) getFolderMetadata(ctx context.Context, path string) (*FolderMetadata, error) {
+	resp, err := c.doRaw(ctx, http.MethodGet, url.Values{"path": []string{path}}, nil, "folder", "metadata")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch folder metadata at %q: %w", path, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read folder metadata response at %q: %w", path, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, errFolderMetadataNotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, errMetadataUnsupported
+	}
+
+	respContentType := resp.Header.Get("Content-Type")
+	isJSON := c.isJSONResponse(respContentType, respBytes)
+	if resp.StatusCode != http.StatusOK || !isJSON {
+		if isJSON {
+			if err := parseAPIErrorResponse(respBytes, path, resp.StatusCode); err != nil {
+				return nil, err
+			}
+		}
+		return nil, createAPIError(resp.StatusCode, respContentType, path)
+	}
+
+	var item folderMetadataItem
+	if err := json.Unmarshal(respBytes, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal folder metadata at %q: %w", path, err)
+	}
+	return &FolderMetadata{
+		Path:        path,
+		Owner:       item.Owner,
+		Permissions: item.Permissions,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+		Tags:        item.Tags,
+	}, nil
+}
+
+// listedFolderMetadata reads the metadata of a folder from the listing of its parent.
+// The root folder has no parent and no metadata beyond its path.
+func (c *SMOPClient) listedFolderMetadata(ctx context.Context, path string) (*FolderMetadata, error) {
+	if path == "" {
+		return &FolderMetadata{Partial: true}, nil
+	}
+
+	entry, err := c.lookupFolder(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %q", ErrFolderNotFound, path)
+	}
+	return &FolderMetadata{
+		Path:      path,
+		CreatedAt: entry.CreatedAt,
+		UpdatedAt: entry.UpdatedAt,
+		Tags:      entry.Tags,
+		Partial:   true,
+	}, nil
+}
@@ -0,0 +1,142 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFolderMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.Equal(t, "/site/secrets/folder/metadata", r.URL.Path)
+		require.Equal(t, "apps/db", r.URL.Query().Get("path"))
+		_, _ = w.Write([]byte(`{
+			"path":"apps/db",
+			"owner":"user:alice",
+			"permissions":[
+				{"principal":"group:ops","actions":["read","write"]},
+				{"principal":"group:audit","actions":["read"],"inherited":true}
+			],
+			"createdAt":"2025-01-02T03:04:05Z",
+			"tags":{"team":"payments"}
+		}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	folder := "/apps/db/"
+	md, err := c.GetFolderMetadata(context.Background(), &folder)
+	require.NoError(t, err)
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, &FolderMetadata{
+		Path:  "apps/db",
+		Owner: "user:alice",
+		Permissions: []FolderPermission{
+			{Principal: "group:ops", Actions: []string{"read", "write"}},
+			{Principal: "group:audit", Actions: []string{"read"}, Inherited: true},
+		},
+		CreatedAt: &created,
+		Tags:      map[string]string{"team": "payments"},
+	}, md)
+}
+
+func TestGetFolderMetadataFallback(t *testing.T) {
+	var metadataRequests, listRequests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/folder/metadata") {
+			metadataRequests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		listRequests.Add(1)
+		require.Equal(t, "apps", r.URL.Query().Get("path"))
+		_, _ = w.Write([]byte(`{"data":[
+			{"path":"token"},
+			{"path":"db","type":"folder","updatedAt":"2025-01-02T03:04:05Z","tags":{"team":"payments"}}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	// a missing folder does not tell whether the metadata endpoint is available
+	gone := "apps/gone"
+	_, err = c.GetFolderMetadata(context.Background(), &gone)
+	assert.ErrorIs(t, err, ErrFolderNotFound)
+
+	folder := "apps/db"
+	updated := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	for range 2 {
+		md, err := c.GetFolderMetadata(context.Background(), &folder)
+		require.NoError(t, err)
+		assert.Equal(t, &FolderMetadata{
+			Path:      "apps/db",
+			UpdatedAt: &updated,
+			Tags:      map[string]string{"team": "payments"},
+			Partial:   true,
+		}, md)
+	}
+
+	// the root folder is not listed anywhere
+	md, err := c.GetFolderMetadata(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, &FolderMetadata{Partial: true}, md)
+
+	assert.Equal(t, int64(2), metadataRequests.Load(), "an unsupported metadata endpoint must only be tried until a folder is found")
+	assert.Equal(t, int64(3), listRequests.Load())
+}
+
+func TestGetFolderMetadataNotImplemented(t *testing.T) {
+	var metadataRequests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/folder/metadata") {
+			metadataRequests.Add(1)
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	// a 501 tells the endpoint is not offered even when the folder does not exist
+	gone := "apps/gone"
+	for range 2 {
+		_, err = c.GetFolderMetadata(context.Background(), &gone)
+		assert.ErrorIs(t, err, ErrFolderNotFound)
+	}
+	assert.Equal(t, int64(1), metadataRequests.Load())
+}
+
+func TestGetFolderMetadataError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"missing scope folders:read"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	folder := "apps"
+	_, err = c.GetFolderMetadata(context.Background(), &folder)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+}
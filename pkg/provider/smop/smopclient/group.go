@@ -0,0 +1,58 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+const (
+	// groupQueryParam is the query parameter addressing the secrets shared with a group
+	// instead of the personal secrets of the token owner.
+	groupQueryParam = "group"
+
+	// maxGroupLength bounds the length of a group name.
+	maxGroupLength = 128
+)
+
+// ErrInvalidGroup is returned for a group name SMoP would reject.
+var ErrInvalidGroup = errors.New("invalid SMoP group")
+
+// groupPattern matches SMoP group names like "platform-team" or "eu.payments".
+var groupPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateGroup checks the format of a group name.
+func ValidateGroup(group string) error {
+	if len(group) > maxGroupLength {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidGroup, maxGroupLength)
+	}
+	if !groupPattern.MatchString(group) {
+		return fmt.Errorf("%w %q: must start with a letter or digit and only contain letters, digits and ._-",
+			ErrInvalidGroup, group)
+	}
+	return nil
+}
+
+// groupKey is the context key of the group of a request.
+type groupKey struct{}
+
+// ContextWithGroup returns a copy of ctx in which SMoP requests address the secrets shared with `group`.
+// Folder paths and KV names are resolved within the group, in the same tenant. An empty group addresses
+// the personal secrets of the token owner. The group must be valid (see ValidateGroup).
+func ContextWithGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, groupKey{}, group)
+}
+
+// setGroupQueryParam sets the group query parameter to the group on the request context, if any.
+func setGroupQueryParam(ctx context.Context, req *http.Request) error {
+	group, ok := ctx.Value(groupKey{}).(string)
+	if !ok || group == "" {
+		return nil
+	}
+	if err := ValidateGroup(group); err != nil {
+		return err
+	}
+	return setQueryParam(groupQueryParam, group)(ctx, req)
+}
@@ -0,0 +1,68 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGroup(t *testing.T) {
+	tests := map[string]struct {
+		group   string
+		wantErr bool
+	}{
+		"name":         {group: "platform-team"},
+		"dotted":       {group: "eu.payments_2"},
+		"empty":        {group: "", wantErr: true},
+		"leading dash": {group: "-admins", wantErr: true},
+		"slash":        {group: "team/../admins", wantErr: true},
+		"query break":  {group: "team&admin=true", wantErr: true},
+		"too long":     {group: strings.Repeat("a", maxGroupLength+1), wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateGroup(tc.group)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidGroup)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGroupQueryParam(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query().Get(groupQueryParam))
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/kv") {
+			_, _ = w.Write([]byte(`{"data":[{"path":"db","secret":{"password":"shared"}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"shared"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	folder := "apps"
+	_, err = c.GetSecret(context.Background(), "db", &folder)
+	require.NoError(t, err)
+	_, err = c.GetSecret(ContextWithGroup(context.Background(), "platform-team"), "db", &folder)
+	require.NoError(t, err)
+	_, err = c.GetSecrets(ContextWithGroup(context.Background(), "platform-team"), &folder)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "platform-team", "platform-team"}, got)
+
+	_, err = c.GetSecret(ContextWithGroup(context.Background(), "not valid"), "db", &folder)
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+	assert.Len(t, got, 3, "a request with an invalid group must not be sent")
+}
@@ -0,0 +1,78 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidHostOverride is returned for a WithHostOverride address which is not a host or "host:port".
+var ErrInvalidHostOverride = errors.New("invalid SMoP host override")
+
+// dialFunc is the signature of http.Transport.DialContext.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// hostOverride is the address connections to the SMoP server host are dialed to instead.
+type hostOverride struct {
+	host string
+	// port replaces the port of the server URL, if set.
+	port string
+}
+
+// ValidateHostOverride checks that address is a valid WithHostOverride address:
+// an IP address or a hostname, optionally followed by a port, e.g. "10.0.0.12" or "10.0.0.12:8443".
+func ValidateHostOverride(address string) error {
+	_, err := parseHostOverride(address)
+	return err
+}
+
+func parseHostOverride(address string) (hostOverride, error) {
+	invalid := func(reason string) (hostOverride, error) {
+		return hostOverride{}, fmt.Errorf("%w %q: %s", ErrInvalidHostOverride, address, reason)
+	}
+	if address == "" {
+		return invalid("must not be empty")
+	}
+	if strings.ContainsAny(address, "/?#@ \t") {
+		return invalid(`must be a host or "host:port", not a URL`)
+	}
+
+	// a bare IPv6 address has colons but no port
+	if ip := net.ParseIP(strings.Trim(address, "[]")); ip != nil {
+		return hostOverride{host: ip.String()}, nil
+	}
+
+	host, port := address, ""
+	if strings.Contains(address, ":") {
+		var err error
+		if host, port, err = net.SplitHostPort(address); err != nil {
+			return invalid(err.Error())
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return invalid("the port must be between 1 and 65535")
+		}
+	}
+	if host == "" {
+		return invalid("the host must not be empty")
+	}
+	return hostOverride{host: host, port: port}, nil
+}
+
+// dial returns a dial function connecting to the override whenever `serverHost` is dialed.
+// Only the connection target changes: requests, and the TLS server name the certificate is
+// verified against, keep the host of the server URL.
+func (o hostOverride) dial(dial dialFunc, serverHost string) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil && strings.EqualFold(host, serverHost) {
+			if o.port != "" {
+				port = o.port
+			}
+			addr = net.JoinHostPort(o.host, port)
+		}
+		return dial(ctx, network, addr)
+	}
+}
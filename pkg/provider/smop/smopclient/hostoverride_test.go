@@ -0,0 +1,117 @@
+package smopclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostOverride(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		serverName string
+	)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		host, _, err := net.SplitHostPort(r.Host)
+		assert.NoError(t, err)
+		assert.Equal(t, "smop.example.com", host, "requests keep the host of the server URL")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	srv.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			mu.Lock()
+			serverName = hello.ServerName
+			mu.Unlock()
+			return nil, nil
+		},
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+
+	// the test certificate is valid for example.com and its sub-domains
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	var dialed []string
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+
+	c, err := NewSMOPClient("https://smop.example.com:"+port+"/site/secrets", testToken,
+		WithTransport(transport), WithTLSConfig(&tls.Config{RootCAs: pool}), WithHostOverride("127.0.0.1"))
+	require.NoError(t, err)
+
+	kv, err := c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", kv.Secret["password"])
+	assert.Equal(t, []string{"127.0.0.1:" + port}, dialed, "the connection must target the override")
+	assert.Equal(t, "smop.example.com", serverName, "TLS must use the host of the server URL")
+}
+
+func TestHostOverridePort(t *testing.T) {
+	override := hostOverride{host: "10.0.0.12", port: "8443"}
+	var dialed []string
+	dial := override.dial(func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, &net.OpError{Op: "dial", Err: net.ErrClosed}
+	}, "smop.example.com")
+
+	for _, addr := range []string{"SMOP.example.com:443", "other.example.com:443"} {
+		_, _ = dial(context.Background(), "tcp", addr)
+	}
+	assert.Equal(t, []string{"10.0.0.12:8443", "other.example.com:443"}, dialed, "only the server host is overridden")
+}
+
+func TestValidateHostOverride(t *testing.T) {
+	tests := map[string]bool{
+		"10.0.0.12":            true,
+		"10.0.0.12:8443":       true,
+		"smop.internal":        true,
+		"smop.internal:443":    true,
+		"fd00::12":             true,
+		"[fd00::12]":           true,
+		"[fd00::12]:8443":      true,
+		"":                     false,
+		":8443":                false,
+		"10.0.0.12:0":          false,
+		"10.0.0.12:65536":      false,
+		"10.0.0.12:https":      false,
+		"https://10.0.0.12":    false,
+		"10.0.0.12/site":       false,
+		"user@10.0.0.12":       false,
+		"smop internal":        false,
+		"smop.internal:443:80": false,
+	}
+
+	for address, valid := range tests {
+		t.Run(address, func(t *testing.T) {
+			err := ValidateHostOverride(address)
+			if valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidHostOverride)
+			}
+		})
+	}
+
+	t.Run("requires an http.Transport", func(t *testing.T) {
+		_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+			WithTransport(roundTripperFunc(nil)), WithHostOverride("10.0.0.12"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,71 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+const (
+	// ImpersonationHeader attributes a request to a subject other than the token owner.
+	ImpersonationHeader = "On-Behalf-Of"
+
+	// maxImpersonationSubjectLength bounds the length of an impersonation subject.
+	maxImpersonationSubjectLength = 256
+)
+
+// ErrInvalidImpersonationSubject is returned for an impersonation subject SMoP would reject.
+var ErrInvalidImpersonationSubject = errors.New("invalid SMoP impersonation subject")
+
+// impersonationSubjectPattern matches user names, e-mail addresses and
+// Kubernetes ServiceAccount subjects like "system:serviceaccount:ns:name".
+var impersonationSubjectPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9@._:+-]*$`)
+
+// ValidateImpersonationSubject checks the format of an impersonation subject.
+func ValidateImpersonationSubject(subject string) error {
+	if len(subject) > maxImpersonationSubjectLength {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidImpersonationSubject, maxImpersonationSubjectLength)
+	}
+	if !impersonationSubjectPattern.MatchString(subject) {
+		return fmt.Errorf("%w %q: must start with a letter or digit and only contain letters, digits and @._:+-",
+			ErrInvalidImpersonationSubject, subject)
+	}
+	return nil
+}
+
+// WithImpersonationSubject attributes every request to `subject` through the On-Behalf-Of header.
+// The SMoP token must be allowed to impersonate the subject.
+func WithImpersonationSubject(subject string) ClientOption {
+	return func(c *SMOPClient) error {
+		if err := ValidateImpersonationSubject(subject); err != nil {
+			return err
+		}
+		c.impersonationSubject = subject
+		return nil
+	}
+}
+
+// impersonationKey is the context key of a per-request impersonation subject.
+type impersonationKey struct{}
+
+// ContextWithImpersonationSubject returns a copy of ctx in which SMoP requests are attributed to `subject`
+// instead of the client's impersonation subject. The subject must be valid (see ValidateImpersonationSubject).
+func ContextWithImpersonationSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, subject)
+}
+
+// setImpersonationHeader sets the On-Behalf-Of header, preferring the subject on the request context over `subject`.
+func setImpersonationHeader(ctx context.Context, req *http.Request, subject string) error {
+	if override, ok := ctx.Value(impersonationKey{}).(string); ok && override != "" {
+		if err := ValidateImpersonationSubject(override); err != nil {
+			return err
+		}
+		subject = override
+	}
+	if subject != "" {
+		req.Header.Set(ImpersonationHeader, subject)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImpersonationSubject(t *testing.T) {
+	tests := map[string]struct {
+		subject string
+		wantErr bool
+	}{
+		"user":            {subject: "jane"},
+		"e-mail address":  {subject: "ci+deploy@example.com"},
+		"service account": {subject: "system:serviceaccount:apps:eso"},
+		"empty":           {subject: "", wantErr: true},
+		"leading colon":   {subject: ":admin", wantErr: true},
+		"header break":    {subject: "jane\r\nX-Admin: true", wantErr: true},
+		"space":           {subject: "jane doe", wantErr: true},
+		"too long":        {subject: string(make([]byte, maxImpersonationSubjectLength+1)), wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateImpersonationSubject(tc.subject)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidImpersonationSubject)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestImpersonationHeader(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(ImpersonationHeader))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	plain, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	_, err = plain.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithImpersonationSubject("ci@example.com"))
+	require.NoError(t, err)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+	_, err = c.GetSecret(ContextWithImpersonationSubject(context.Background(), "jane@example.com"), "db", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "ci@example.com", "jane@example.com"}, got)
+
+	_, err = c.GetSecret(ContextWithImpersonationSubject(context.Background(), "not valid"), "db", nil)
+	assert.ErrorIs(t, err, ErrInvalidImpersonationSubject)
+	assert.Len(t, got, 3, "a request with an invalid subject must not be sent")
+
+	_, err = NewSMOPClient(srv.URL+"/site/secrets", testToken, WithImpersonationSubject("not valid"))
+	assert.ErrorIs(t, err, ErrInvalidImpersonationSubject)
+}
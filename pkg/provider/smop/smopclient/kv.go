@@ -0,0 +1,68 @@
+package smopclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	// kvTypeAlias is the KV type SMoP reports for a KV that references another KV.
+	kvTypeAlias = "alias"
+	// kvTypeFolder is the type SMoP reports for a sub-folder in a folder listing.
+	kvTypeFolder = "folder"
+
+	// AccountUsernameKey and AccountNameKey are the keys under which KVMetadata.AccountFields returns the
+	// username and account of a privileged-account KV, so they can be selected like its password.
+	AccountUsernameKey = "username"
+	AccountNameKey     = "account"
+)
+
+// kvAttributes holds KV attributes returned by SMoP that are not modelled by cg.KV.
+type kvAttributes struct {
+	// Type is the SMoP KV type, e.g. "alias", "folder" or a content hint such as "tls".
+	Type string `json:"type,omitempty"`
+	// Target is the full path ("folder/name") of the KV an alias points to.
+	Target string `json:"target,omitempty"`
+	// Version is incremented by SMoP on every change of the KV.
+	Version int64 `json:"version,omitempty"`
+	// CreatedAt and UpdatedAt are the times the KV was created and last changed.
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	// Username and Account identify the account a privileged-account KV holds the password of.
+	Username string `json:"username,omitempty"`
+	Account  string `json:"account,omitempty"`
+	// ExpiresAt is the time after which SMoP considers the value of the KV expired, nil if it does not expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Tags are the tags of the KV, e.g. the push tags identifying KVs pushed by ESO.
+	Tags map[string]string `json:"tags,omitempty"`
+	// SignedContent, if set, points to the value of a large KV, see getKV.
+	SignedContent *signedContent `json:"signedContent,omitempty"`
+}
+
+// isAlias reports whether the KV references another KV.
+func (a kvAttributes) isAlias() bool {
+	return a.Type == kvTypeAlias && a.Target != ""
+}
+
+// isFolder reports whether the list entry is a sub-folder rather than a KV.
+func (a kvAttributes) isFolder() bool {
+	return a.Type == kvTypeFolder
+}
+
+// UnmarshalUseNumber decodes the JSON `data`, e.g. a response holding KV values, into v like
+// json.Unmarshal, but decodes numbers as json.Number: they keep their exact representation, e.g.
+// IDs beyond 2^53 or decimals beyond float64 precision, when written to a Kubernetes Secret.
+func UnmarshalUseNumber(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
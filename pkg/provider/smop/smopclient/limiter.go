@@ -0,0 +1,61 @@
+package smopclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// RequestLimiter caps the number of SMoP requests in flight across every client it is set on
+// with WithRequestLimiter. A request holds its slot until its response body is closed.
+type RequestLimiter struct {
+	slots chan struct{}
+}
+
+// NewRequestLimiter returns a RequestLimiter allowing up to n requests in flight.
+func NewRequestLimiter(n int) (*RequestLimiter, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid SMoP request limit %d: must be at least 1", n)
+	}
+	return &RequestLimiter{slots: make(chan struct{}, n)}, nil
+}
+
+// InFlight returns the number of requests currently holding a slot.
+func (l *RequestLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// limitedTransport takes a slot of a RequestLimiter for each request, waiting for one
+// to be free until the request context is done.
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *RequestLimiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.limiter.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		<-t.limiter.slots
+		return resp, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, release: sync.OnceFunc(func() { <-t.limiter.slots })}
+	return resp, nil
+}
+
+// limitedBody frees the slot of its request once closed.
+type limitedBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *limitedBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,81 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLimiter(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	limiter, err := NewRequestLimiter(2)
+	require.NoError(t, err)
+
+	// each client allows more connections than the shared limit
+	var clients []*SMOPClient
+	for range 3 {
+		c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken,
+			WithRequestLimiter(limiter), WithMaxConnsPerHost(4))
+		require.NoError(t, err)
+		clients = append(clients, c)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 12 {
+		wg.Add(1)
+		go func(c *SMOPClient) {
+			defer wg.Done()
+			_, err := c.GetSecret(context.Background(), "db", nil)
+			assert.NoError(t, err)
+		}(clients[i%len(clients)])
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(2), maxInFlight.Load(), "requests of all clients must share the limit")
+	assert.Zero(t, limiter.InFlight(), "slots must be freed once the responses are read")
+}
+
+func TestRequestLimiterContextDone(t *testing.T) {
+	limiter, err := NewRequestLimiter(1)
+	require.NoError(t, err)
+	limiter.slots <- struct{}{}
+
+	c := newTestClient(t, map[string]string{
+		"db": `{"path":"db","secret":{"password":"s3cr3t"}}`,
+	}, WithRequestLimiter(limiter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.GetSecret(ctx, "db", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRequestLimiterOptions(t *testing.T) {
+	_, err := NewRequestLimiter(0)
+	assert.Error(t, err)
+
+	_, err = NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithRequestLimiter(nil))
+	assert.Error(t, err)
+}
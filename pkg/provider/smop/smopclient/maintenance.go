@@ -0,0 +1,99 @@
+package smopclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maintenanceCode is the error code of SMoP responses sent while SMoP is down for maintenance.
+	maintenanceCode = "MAINTENANCE"
+	// defaultMaintenanceRetryAfter is the delay suggested for a maintenance without a retry-after hint.
+	defaultMaintenanceRetryAfter = 5 * time.Minute
+)
+
+// ErrMaintenance is matched by errors returned while SMoP is down for maintenance.
+var ErrMaintenance = errors.New("SMoP is down for maintenance")
+
+// MaintenanceError is returned when a SMoP request failed because SMoP is down for maintenance.
+// It matches ErrMaintenance and unwraps to the *APIError of the response.
+type MaintenanceError struct {
+	APIError *APIError
+	// Delay is how long to wait before trying again, as hinted by SMoP, or a default.
+	Delay time.Duration
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("%s, retry after %s: %s", ErrMaintenance, e.Delay, e.APIError)
+}
+
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrMaintenance
+}
+
+func (e *MaintenanceError) Unwrap() error {
+	return e.APIError
+}
+
+// RetryAfter returns how long to wait before trying again.
+// It makes the controller requeue ExternalSecrets after the maintenance instead of right away.
+func (e *MaintenanceError) RetryAfter() time.Duration {
+	return e.Delay
+}
+
+// maintenanceResponse is the body of a SMoP response sent during maintenance, e.g.
+// {"error": "scheduled maintenance", "code": "MAINTENANCE", "retryAfter": 600}.
+// retryAfter is either a number of seconds, a duration such as "10m", or the RFC 3339 time the maintenance ends.
+type maintenanceResponse struct {
+	Error      string          `json:"error"`
+	Code       string          `json:"code"`
+	RetryAfter json.RawMessage `json:"retryAfter,omitempty"`
+}
+
+// parseMaintenanceResponse returns a *MaintenanceError if the error response body reports a maintenance.
+func parseMaintenanceResponse(body []byte, path string, statusCode int, now time.Time) error {
+	var resp maintenanceResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Code != maintenanceCode {
+		return nil
+	}
+
+	message := resp.Error
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	return &MaintenanceError{
+		APIError: &APIError{StatusCode: statusCode, Message: message, Path: path},
+		Delay:    parseRetryAfter(resp.RetryAfter, now, defaultMaintenanceRetryAfter),
+	}
+}
+
+// parseRetryAfter parses the retry-after hint of a maintenance or approval pending response.
+// A missing, invalid or past hint is replaced by `fallback`.
+func parseRetryAfter(raw json.RawMessage, now time.Time, fallback time.Duration) time.Duration {
+	var delay time.Duration
+
+	var seconds float64
+	var text string
+	switch {
+	case len(raw) == 0:
+	case json.Unmarshal(raw, &seconds) == nil:
+		delay = time.Duration(seconds * float64(time.Second))
+	case json.Unmarshal(raw, &text) == nil:
+		if d, err := time.ParseDuration(text); err == nil {
+			delay = d
+		} else if s, err := strconv.ParseFloat(text, 64); err == nil {
+			delay = time.Duration(s * float64(time.Second))
+		} else if until, err := time.Parse(time.RFC3339, text); err == nil {
+			delay = until.Sub(now)
+		}
+	}
+
+	if delay <= 0 {
+		return fallback
+	}
+	return delay
+}
@@ -0,0 +1,79 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecretMaintenance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"scheduled maintenance","code":"MAINTENANCE","retryAfter":600}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.ErrorIs(t, err, ErrMaintenance)
+
+	var maintenanceErr *MaintenanceError
+	require.ErrorAs(t, err, &maintenanceErr)
+	assert.Equal(t, 10*time.Minute, maintenanceErr.RetryAfter())
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, "scheduled maintenance", apiErr.Message)
+	assert.True(t, IsRetryable(err))
+}
+
+func TestGetSecretServiceUnavailableIsNotMaintenance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"overloaded"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrMaintenance)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		raw  string
+		want time.Duration
+	}{
+		"missing":        {want: defaultMaintenanceRetryAfter},
+		"seconds":        {raw: `90`, want: 90 * time.Second},
+		"seconds string": {raw: `"90"`, want: 90 * time.Second},
+		"duration":       {raw: `"15m"`, want: 15 * time.Minute},
+		"end time":       {raw: `"2026-01-02T04:30:00Z"`, want: 90 * time.Minute},
+		"past end time":  {raw: `"2026-01-02T02:00:00Z"`, want: defaultMaintenanceRetryAfter},
+		"negative":       {raw: `-5`, want: defaultMaintenanceRetryAfter},
+		"invalid":        {raw: `"soon"`, want: defaultMaintenanceRetryAfter},
+		"wrong type":     {raw: `{"minutes":5}`, want: defaultMaintenanceRetryAfter},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseRetryAfter(json.RawMessage(tc.raw), now, defaultMaintenanceRetryAfter))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrSecretExpired is returned by GetSecret for a KV past its expiry when expired KVs are rejected,
+// see WithRejectExpired.
+var ErrSecretExpired = errors.New("SMoP secret expired")
+
+// KVMetadata describes a KV without its value.
+type KVMetadata struct {
+	// Path is the name of the KV.
+	Path string
+	// Type is the SMoP KV type, e.g. "alias" or a content hint such as "tls".
+	Type string
+	// Target is the full path of the KV an alias points to.
+	Target string
+	// Version is incremented by SMoP on every change of the KV, zero if not reported.
+	Version int64
+	// CreatedAt and UpdatedAt are nil if not reported by SMoP.
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	// ExpiresAt is nil if the KV does not expire.
+	ExpiresAt *time.Time
+	// Tags are nil if the KV has none.
+	Tags map[string]string
+	// Username and Account identify the account a privileged-account KV holds the password of,
+	// empty if SMoP reports no account.
+	Username string
+	Account  string
+}
+
+// AccountFields returns the username and account of a privileged-account KV keyed by AccountUsernameKey
+// and AccountNameKey, or nil if SMoP reports no account. They are not part of the secret of the KV, so
+// writing a read KV back does not add them to it.
+func (m *KVMetadata) AccountFields() map[string]string {
+	if m == nil || m.Username == "" && m.Account == "" {
+		return nil
+	}
+	fields := make(map[string]string, 2)
+	for key, value := range map[string]string{AccountUsernameKey: m.Username, AccountNameKey: m.Account} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// Expired reports whether the KV has expired at `now`. A KV without an expiry never expires.
+func (m *KVMetadata) Expired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
+// expiredError returns the ErrSecretExpired to report for the expired KV.
+func (m *KVMetadata) expiredError() error {
+	return fmt.Errorf("%w: %q expired at %s", ErrSecretExpired, m.Path, m.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// metadataItem is the response of the metadata endpoint.
+type metadataItem struct {
+	Path string `json:"path"`
+	kvAttributes
+}
+
+// GetSecretMetadata fetches the metadata of the KV `name` at the specified `folderPath`, without its value.
+// Aliases are not followed, so the metadata of the alias itself is returned.
+//
+// The metadata is fetched with the `GET kv/{name}/metadata` endpoint, which only requires the
+// read-metadata scope. When the SMoP server does not offer it, GetSecretMetadata falls back to a
+// full GET of the KV, which requires the read scope; the value is then discarded.
+func (c *SMOPClient) GetSecretMetadata(ctx context.Context, name string, folderPath *string) (*KVMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	if !c.metadataUnsupported.Load() {
+		md, err := c.getMetadata(ctx, name, folderPath)
+		if !errors.Is(err, errMetadataUnsupported) {
+			return md, err
+		}
+	}
+
+	kv, attrs, err := c.getKV(ctx, name, folderPath)
+	if err != nil {
+		return nil, err
+	}
+	if !c.metadataUnsupported.Swap(true) {
+		log.V(1).Info("SMoP server does not offer the metadata endpoint, falling back to fetching secrets with their value")
+	}
+	if kv.Path == "" {
+		kv.Path = name
+	}
+	return newKVMetadata(kv.Path, attrs), nil
+}
+
+// errMetadataUnsupported is returned by getMetadata when the SMoP server may not offer the metadata endpoint.
+var errMetadataUnsupported = errors.New("SMoP metadata endpoint not available")
+
+// getMetadata fetches the metadata of a KV with the metadata endpoint.
+// A 404 is reported as errMetadataUnsupported, as it cannot be told apart from a missing KV:
+// the fallback GET of the KV then reports the KV missing, or the endpoint as unsupported.
+func (c *SMOPClient) getMetadata(ctx context.Context, name string, folderPath *string) (*KVMetadata, error) {
+	var query url.Values
+	if folderPath != nil {
+		query = url.Values{"folderName": []string{*folderPath}}
+	}
+
+	path := getPathString(folderPath)
+	resp, err := c.doRaw(ctx, http.MethodGet, query, nil, "kv", name, "metadata")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret metadata %q at %q: %w", name, path, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret metadata response %q at %q: %w", name, path, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, errMetadataUnsupported
+	}
+
+	fullKvPath := joinKVPath(path, name)
+	respContentType := resp.Header.Get("Content-Type")
+	isJSON := c.isJSONResponse(respContentType, respBytes)
+	if resp.StatusCode != http.StatusOK || !isJSON {
+		if isJSON {
+			if err := parseAPIErrorResponse(respBytes, fullKvPath, resp.StatusCode); err != nil {
+				return nil, err
+			}
+		}
+		return nil, createAPIError(resp.StatusCode, respContentType, fullKvPath)
+	}
+
+	var item metadataItem
+	if err := json.Unmarshal(respBytes, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secret metadata %q at %q: %w", name, path, err)
+	}
+	if item.Path == "" {
+		item.Path = name
+	}
+	return newKVMetadata(item.Path, item.kvAttributes), nil
+}
+
+func newKVMetadata(path string, attrs kvAttributes) *KVMetadata {
+	return &KVMetadata{
+		Path:      path,
+		Type:      attrs.Type,
+		Target:    attrs.Target,
+		Version:   attrs.Version,
+		CreatedAt: attrs.CreatedAt,
+		UpdatedAt: attrs.UpdatedAt,
+		ExpiresAt: attrs.ExpiresAt,
+		Tags:      attrs.Tags,
+		Username:  attrs.Username,
+		Account:   attrs.Account,
+	}
+}
@@ -0,0 +1,76 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecretMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.Equal(t, "/site/secrets/kv/db/metadata", r.URL.Path)
+		require.Equal(t, "apps", r.URL.Query().Get("folderName"))
+		_, _ = w.Write([]byte(`{"path":"db","type":"tls","version":7,"updatedAt":"2025-01-02T03:04:05Z","tags":{"eso.managed-by":"external-secrets"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	apps := "apps"
+	md, err := c.GetSecretMetadata(context.Background(), "db", &apps)
+	require.NoError(t, err)
+	assert.Equal(t, "db", md.Path)
+	assert.Equal(t, "tls", md.Type)
+	assert.Equal(t, int64(7), md.Version)
+	require.NotNil(t, md.UpdatedAt)
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), *md.UpdatedAt)
+	assert.Nil(t, md.CreatedAt)
+	assert.Equal(t, map[string]string{"eso.managed-by": "external-secrets"}, md.Tags)
+}
+
+func TestGetSecretMetadataFallback(t *testing.T) {
+	var metadataRequests, getRequests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/metadata") {
+			metadataRequests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		getRequests.Add(1)
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"db","version":3,"secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	// a missing KV does not tell whether the metadata endpoint is available
+	_, err = c.GetSecretMetadata(context.Background(), "gone", nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	for range 2 {
+		md, err := c.GetSecretMetadata(context.Background(), "db", nil)
+		require.NoError(t, err)
+		assert.Equal(t, &KVMetadata{Path: "db", Version: 3}, md)
+	}
+
+	assert.Equal(t, int64(2), metadataRequests.Load(), "an unsupported metadata endpoint must only be tried until a KV is found")
+	assert.Equal(t, int64(3), getRequests.Load())
+}
@@ -0,0 +1,58 @@
+package smopclient
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsSubsystem = "smop"
+
+	connStateActive = "active"
+	connStateIdle   = "idle"
+)
+
+var (
+	// connsOpen counts open connections to SMoP servers.
+	connsOpen atomic.Int64
+	// connsInUse counts connections currently serving a request.
+	connsInUse atomic.Int64
+
+	connWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "connection_wait_seconds",
+		Help:      "Time SMoP API requests waited to obtain a connection",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	})
+)
+
+func connGauge(state string, value func() float64) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Subsystem:   metricsSubsystem,
+		Name:        "connections",
+		Help:        "Number of connections to SMoP servers by state",
+		ConstLabels: prometheus.Labels{"state": state},
+	}, value)
+}
+
+func activeConns() float64 {
+	return float64(connsInUse.Load())
+}
+
+func idleConns() float64 {
+	idle := connsOpen.Load() - connsInUse.Load()
+	if idle < 0 {
+		return 0
+	}
+	return float64(idle)
+}
+
+func init() {
+	metrics.Registry.MustRegister(
+		connGauge(connStateActive, activeConns),
+		connGauge(connStateIdle, idleConns),
+		connWaitSeconds,
+	)
+}
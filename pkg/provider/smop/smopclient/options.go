@@ -0,0 +1,658 @@
+package smopclient
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+const (
+	// defaultMaxAliasDepth is the maximum number of alias hops followed by GetSecret.
+	defaultMaxAliasDepth = 5
+
+	// defaultGetTimeout bounds fetching a single secret.
+	defaultGetTimeout = 30 * time.Second
+	// defaultListTimeout bounds listing a folder, which legitimately takes longer on large folders.
+	defaultListTimeout = 2 * time.Minute
+
+	// defaultDeadline bounds calls spanning many requests when the caller's context has no deadline.
+	defaultDeadline = 10 * time.Minute
+
+	// defaultDeleteConcurrency is the number of KVs DeleteSecrets deletes in parallel.
+	defaultDeleteConcurrency = 4
+	// defaultFetchConcurrency is the number of KVs BatchGetSecrets fetches in parallel without the batch endpoint.
+	defaultFetchConcurrency = 4
+
+	// defaultResponseContentType is the Content-Type assumed for SMoP responses without one.
+	defaultResponseContentType = "application/json"
+)
+
+// ClientOption configures a SMOPClient.
+type ClientOption func(*SMOPClient) error
+
+// WithClientGenOptions passes options through to the generated SMoP API client.
+func WithClientGenOptions(opts ...cg.ClientOption) ClientOption {
+	return func(c *SMOPClient) error {
+		c.clientOpts = append(c.clientOpts, opts...)
+		return nil
+	}
+}
+
+// WithRequestEditorChain appends `editors` to the chain of RequestEditorFns applied to every request,
+// e.g. to add custom headers or sign requests. Editors run in order after the Authorization and
+// impersonation headers are set, each seeing the changes of the editors before it.
+// Editors of repeated options are chained in the order of the options.
+func WithRequestEditorChain(editors ...cg.RequestEditorFn) ClientOption {
+	return func(c *SMOPClient) error {
+		for i, edit := range editors {
+			if edit == nil {
+				return fmt.Errorf("invalid SMoP request editor chain: editor %d is nil", i)
+			}
+		}
+		c.requestEditors = append(c.requestEditors, editors...)
+		return nil
+	}
+}
+
+// WithTokenSource authenticates requests with tokens from ts instead of the static token
+// passed to NewSMOPClient, e.g. a TokenExchangeSource refreshing short-lived tokens.
+func WithTokenSource(ts TokenSource) ClientOption {
+	return func(c *SMOPClient) error {
+		if ts == nil {
+			return errors.New("invalid SMoP token source: must not be nil")
+		}
+		c.tokenSource = ts
+		return nil
+	}
+}
+
+// WithExpectJWTToken makes NewSMOPClient refuse a static token which is not shaped like a JWT,
+// for SMoP deployments issuing JWT API tokens.
+func WithExpectJWTToken(expect bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.expectJWTToken = expect
+		return nil
+	}
+}
+
+// WithFollowAliases controls whether GetSecret follows alias KVs to their target.
+// When disabled the alias KV itself is returned.
+func WithFollowAliases(follow bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.followAliases = follow
+		return nil
+	}
+}
+
+// WithMaxAliasDepth sets the maximum number of alias hops GetSecret follows.
+func WithMaxAliasDepth(depth int) ClientOption {
+	return func(c *SMOPClient) error {
+		if depth < 1 {
+			return fmt.Errorf("invalid SMoP max alias depth %d: must be at least 1", depth)
+		}
+		c.maxAliasDepth = depth
+		return nil
+	}
+}
+
+// WithBaseURLPathPrefix prepends a path prefix (e.g. "/smop/v1") to every SMoP API request path.
+// This is required when the SMoP API is served behind a gateway under a sub-path.
+func WithBaseURLPathPrefix(prefix string) ClientOption {
+	return func(c *SMOPClient) error {
+		c.pathPrefix = prefix
+		return nil
+	}
+}
+
+// WithRequestTimeout sets the default timeout for every SMoP API operation.
+// Operation specific timeouts set with WithGetTimeout or WithListTimeout take precedence.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		c.requestTimeout = timeout
+		return nil
+	}
+}
+
+// WithDefaultDeadline sets the deadline of calls spanning many requests, such as WalkSecrets,
+// DeleteSecrets and BatchGetSecrets, when the caller's context has none. Defaults to 10m, 0 disables it.
+// An earlier deadline of the caller's context is kept.
+func WithDefaultDeadline(deadline time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if deadline < 0 {
+			return fmt.Errorf("invalid SMoP default deadline %s: must not be negative", deadline)
+		}
+		c.defaultDeadline = deadline
+		return nil
+	}
+}
+
+// WithGetTimeout sets the timeout for fetching a single secret.
+func WithGetTimeout(timeout time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		c.getTimeout = timeout
+		return nil
+	}
+}
+
+// WithListTimeout sets the timeout for listing a folder.
+func WithListTimeout(timeout time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		c.listTimeout = timeout
+		return nil
+	}
+}
+
+// WithEmptyListStatusCodes makes GetSecrets return an empty list instead of an APIError
+// when the list response has one of the given status codes, e.g. 204 No Content
+// returned by some gateways for an empty folder.
+// See ValidateEmptyListStatusCodes for the codes that can be mapped.
+func WithEmptyListStatusCodes(codes ...int) ClientOption {
+	return func(c *SMOPClient) error {
+		if err := ValidateEmptyListStatusCodes(codes); err != nil {
+			return err
+		}
+		if c.emptyListStatusCodes == nil {
+			c.emptyListStatusCodes = make(map[int]struct{}, len(codes))
+		}
+		for _, code := range codes {
+			c.emptyListStatusCodes[code] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// ValidateEmptyListStatusCodes checks that the given status codes are safe to treat as an empty list.
+// Only 2xx and 4xx codes can be mapped. 401, 403, 404 and 429 are refused as they report
+// auth failures, missing folders and rate limiting which must never look like an empty folder.
+func ValidateEmptyListStatusCodes(codes []int) error {
+	for _, code := range codes {
+		switch {
+		case code == http.StatusUnauthorized, code == http.StatusForbidden,
+			code == http.StatusNotFound, code == http.StatusTooManyRequests:
+			return fmt.Errorf("SMoP status code %d cannot be mapped to an empty list", code)
+		case code < 200, code >= 500, code >= 300 && code < 400:
+			return fmt.Errorf("SMoP status code %d cannot be mapped to an empty list: only 2xx and 4xx codes are allowed", code)
+		}
+	}
+	return nil
+}
+
+// WithStrictAPIVersion makes NewSMOPClient fail when the API version cannot be
+// determined, instead of falling back to the default API version.
+func WithStrictAPIVersion(strict bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.strictAPIVersion = strict
+		return nil
+	}
+}
+
+// WithAPIVersion overrides the SMoP API version sent with every request,
+// e.g. to pin compatibility with a tenant during a server upgrade.
+func WithAPIVersion(version string) ClientOption {
+	return func(c *SMOPClient) error {
+		c.apiVersion = version
+		return nil
+	}
+}
+
+// WithFailOnAPIVersionMismatch makes CheckAPIVersion return ErrAPIVersionMismatch when the
+// SMoP server reports a different API version, instead of only logging a warning.
+func WithFailOnAPIVersionMismatch(fail bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.failOnAPIVersionMismatch = fail
+		return nil
+	}
+}
+
+// WithDeleteConcurrency sets the number of KVs DeleteSecrets deletes in parallel.
+func WithDeleteConcurrency(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP delete concurrency %d: must be at least 1", n)
+		}
+		c.deleteConcurrency = n
+		return nil
+	}
+}
+
+// WithFetchConcurrency sets the number of KVs BatchGetSecrets fetches in parallel
+// when the SMoP server does not offer the batch endpoint.
+func WithFetchConcurrency(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP fetch concurrency %d: must be at least 1", n)
+		}
+		c.fetchConcurrency = n
+		return nil
+	}
+}
+
+// WithSignedURLTimeout sets the timeout for fetching the content of a large KV from the signed URL SMoP returns for it.
+// It applies within the timeout of fetching the KV, see WithGetTimeout. Defaults to 20s.
+func WithSignedURLTimeout(d time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid SMoP signed URL timeout %s: must be positive", d)
+		}
+		c.signedURLTimeout = d
+		return nil
+	}
+}
+
+// WithSignedURLMaxSize sets the maximum size in bytes of the content fetched from a signed URL.
+// Larger content fails with ErrSignedContentTooLarge. Defaults to 1 MiB, the size limit of a Kubernetes Secret.
+func WithSignedURLMaxSize(n int64) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP signed URL max size %d: must be positive", n)
+		}
+		c.signedURLMaxSize = n
+		return nil
+	}
+}
+
+// WithRotationPolling sets how often RotateSecret polls a rotation in progress, and how long it waits
+// for the rotation to complete at most. Defaults to every 2s, for 2m.
+func WithRotationPolling(interval, timeout time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if interval <= 0 || timeout < interval {
+			return fmt.Errorf("invalid SMoP rotation polling every %s for %s: durations must be positive and ordered", interval, timeout)
+		}
+		c.rotationPollInterval = interval
+		c.rotationTimeout = timeout
+		return nil
+	}
+}
+
+// WithApprovalPolling waits for up to `wait` for the approval of a KV whose access is pending
+// approval (see ErrApprovalPending), fetching it again every `interval`. By default a pending
+// approval is returned right away. The wait holds up the caller, so keep it short.
+func WithApprovalPolling(interval, wait time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if interval <= 0 || wait < interval {
+			return fmt.Errorf("invalid SMoP approval polling every %s for %s: durations must be positive and ordered", interval, wait)
+		}
+		c.approvalPollInterval = interval
+		c.approvalWait = wait
+		return nil
+	}
+}
+
+// WithRequestLimiter caps the requests in flight of the client with a limiter which may be shared
+// by many clients, e.g. to protect a SMoP tenant used by many stores. The limits of the client itself,
+// like WithMaxConnsPerHost and WithWalkConcurrency, still apply beneath it.
+func WithRequestLimiter(limiter *RequestLimiter) ClientOption {
+	return func(c *SMOPClient) error {
+		if limiter == nil {
+			return errors.New("invalid SMoP request limiter: must not be nil")
+		}
+		c.requestLimiter = limiter
+		return nil
+	}
+}
+
+// WithResponseSchemaValidation enables validating the shape of KV and KV list responses
+// before they are unmarshalled, reporting ErrUnexpectedSchema naming the offending field.
+// This helps diagnose gateways that transform responses, at the cost of parsing each response twice.
+func WithResponseSchemaValidation(enabled bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.validateSchema = enabled
+		return nil
+	}
+}
+
+// WithTransport sets the RoundTripper requests to the SMoP server are sent with,
+// e.g. to add logging or authentication middleware, or to stub the server in tests.
+// Retries and connection pool instrumentation are layered on top of it, so middleware
+// sees every attempt of a retried request.
+//
+// If rt is an *http.Transport, a clone of it is used with the TLS and connection pool
+// options applied. For any other RoundTripper these options cannot be applied and
+// NewSMOPClient fails if they are set.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *SMOPClient) error {
+		if rt == nil {
+			return errors.New("invalid SMoP transport: must not be nil")
+		}
+		c.transport = rt
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used when connecting to the SMoP server.
+// Its MinVersion is raised to TLS 1.2, or to the version set with WithTLSMinVersion.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(c *SMOPClient) error {
+		c.tlsConfig = cfg
+		return nil
+	}
+}
+
+// WithTLSMinVersion sets the minimum TLS version accepted from the SMoP server, e.g. tls.VersionTLS13.
+// It defaults to TLS 1.2; lower versions are refused unless WithAllowInsecureTLS is set.
+// It takes precedence over the MinVersion of WithTLSConfig, unless that one is stricter.
+func WithTLSMinVersion(version uint16) ClientOption {
+	return func(c *SMOPClient) error {
+		if !isKnownTLSVersion(version) {
+			return fmt.Errorf("invalid SMoP TLS min version %#04x: unknown TLS version", version)
+		}
+		c.tlsMinVersion = version
+		return nil
+	}
+}
+
+// WithTLSCipherSuites restricts the cipher suites offered to the SMoP server to the given IDs,
+// e.g. tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384. Go does not allow restricting the TLS 1.3 cipher
+// suites, so they only apply to TLS 1.2 connections. Cipher suites listed by tls.InsecureCipherSuites
+// are refused unless WithAllowInsecureTLS is set.
+func WithTLSCipherSuites(suites []uint16) ClientOption {
+	return func(c *SMOPClient) error {
+		if len(suites) == 0 {
+			return errors.New("invalid SMoP TLS cipher suites: must not be empty")
+		}
+		for _, id := range suites {
+			if cipherSuite(id) == nil {
+				return fmt.Errorf("invalid SMoP TLS cipher suite %#04x: unknown cipher suite", id)
+			}
+		}
+		c.tlsCipherSuites = slices.Clone(suites)
+		return nil
+	}
+}
+
+// WithAllowInsecureTLS allows WithTLSMinVersion below TLS 1.2 and insecure WithTLSCipherSuites,
+// for legacy SMoP gateways only.
+func WithAllowInsecureTLS(allow bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.allowInsecureTLS = allow
+		return nil
+	}
+}
+
+// WithHostOverride connects to `address` instead of the address the SMoP server host resolves to,
+// e.g. in split-horizon DNS setups. The address is an IP address or hostname, optionally with a port
+// replacing the port of the server URL. TLS still verifies the certificate against the server URL host.
+func WithHostOverride(address string) ClientOption {
+	return func(c *SMOPClient) error {
+		override, err := parseHostOverride(address)
+		if err != nil {
+			return err
+		}
+		c.hostOverride = &override
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the number of idle connections kept open to the SMoP server.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP max idle connections per host %d: must be at least 1", n)
+		}
+		c.maxIdleConnsPerHost = n
+		return nil
+	}
+}
+
+// WithMaxConnsPerHost limits the total number of connections opened to the SMoP server.
+// Requests wait for a free connection once the limit is reached.
+func WithMaxConnsPerHost(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP max connections per host %d: must be at least 1", n)
+		}
+		c.maxConnsPerHost = n
+		return nil
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection to the SMoP server is kept open for reuse.
+// Keep it below the idle timeout of load balancers and proxies in front of SMoP, which drop idle
+// connections without notice. Defaults to 50s.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid SMoP idle connection timeout %s: must be positive", d)
+		}
+		c.idleConnTimeout = d
+		return nil
+	}
+}
+
+// WithTLSHandshakeTimeout sets how long the TLS handshake of a new connection to the SMoP server
+// may take, independently of the dial and request timeouts. Defaults to 5s.
+func WithTLSHandshakeTimeout(d time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid SMoP TLS handshake timeout %s: must be positive", d)
+		}
+		c.tlsHandshakeTimeout = d
+		return nil
+	}
+}
+
+// WithMaxRedirects sets how many redirects a single request follows, 5 by default.
+// 0 does not follow redirects, failing the request with the status of the redirect instead.
+func WithMaxRedirects(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 0 {
+			return fmt.Errorf("invalid SMoP max redirects %d: must not be negative", n)
+		}
+		c.maxRedirects = n
+		return nil
+	}
+}
+
+// WithCrossHostRedirects sets whether requests follow redirects to another host than the SMoP server,
+// or from HTTPS to HTTP. They are refused by default so the SMoP token cannot leak to another host.
+func WithCrossHostRedirects(allowed bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.allowCrossHostRedirects = allowed
+		return nil
+	}
+}
+
+// WithMaxResponseHeaderBytes limits the size of the response headers read from the SMoP server.
+// A response exceeding it fails the request with a transport error, like an unreachable server,
+// so it is retried if retries are enabled. Defaults to 64KiB.
+func WithMaxResponseHeaderBytes(n int64) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP max response header bytes %d: must be at least 1", n)
+		}
+		c.maxResponseHeaderBytes = n
+		return nil
+	}
+}
+
+// WithMaxRetries sets how often an idempotent request failing with a transient error is retried:
+// network errors and HTTP 429, 502, 503 and 504. Requests are not retried by default.
+func WithMaxRetries(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 0 {
+			return fmt.Errorf("invalid SMoP max retries %d: must not be negative", n)
+		}
+		c.maxRetries = n
+		return nil
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry, which doubles for every further retry up to maxDelay.
+func WithRetryBackoff(baseDelay, maxDelay time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if baseDelay <= 0 || maxDelay < baseDelay {
+			return fmt.Errorf("invalid SMoP retry backoff %s-%s: delays must be positive and ordered", baseDelay, maxDelay)
+		}
+		c.retryBaseDelay = baseDelay
+		c.retryMaxDelay = maxDelay
+		return nil
+	}
+}
+
+// WithDialRetries sets how often a connection to the SMoP server is dialed again after failing to
+// resolve the host or to connect, waiting `delay` before the first retry and twice as long for every
+// further one. These retries are separate from WithMaxRetries and apply to every request.
+// Connections are retried twice, after 100ms and 200ms, by default; 0 retries disable them.
+func WithDialRetries(retries int, delay time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if retries < 0 || retries > 0 && delay <= 0 {
+			return fmt.Errorf("invalid SMoP dial retries %d after %s: must not be negative, with a positive delay", retries, delay)
+		}
+		c.dialRetries = retries
+		c.dialRetryDelay = delay
+		return nil
+	}
+}
+
+// WithBeforeRetry sets a callback run before every retry of a failed request (see WithMaxRetries),
+// e.g. to record a metric or log the failure. It cannot change whether or when the request is retried.
+// The callback runs on the goroutine sending the request and delays the retry until it returns.
+func WithBeforeRetry(fn BeforeRetryFunc) ClientOption {
+	return func(c *SMOPClient) error {
+		if fn == nil {
+			return errors.New("invalid SMoP before retry callback: must not be nil")
+		}
+		c.beforeRetry = fn
+		return nil
+	}
+}
+
+// WithRetryBudget bounds the total retries of all requests made with a context returned by
+// ContextWithRetryBudget, so retries cannot add up beyond a reconcile deadline.
+// Requests failing once the budget is exhausted return ErrRetryBudgetExhausted.
+func WithRetryBudget(budget RetryBudget) ClientOption {
+	return func(c *SMOPClient) error {
+		if budget.MaxAttempts < 0 || budget.MaxDuration < 0 {
+			return fmt.Errorf("invalid SMoP retry budget: limits must not be negative")
+		}
+		c.retryBudget = budget
+		return nil
+	}
+}
+
+// WithWalkConcurrency sets the number of folders WalkSecrets lists in parallel.
+func WithWalkConcurrency(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP walk concurrency %d: must be at least 1", n)
+		}
+		c.walkConcurrency = n
+		return nil
+	}
+}
+
+// WithWalkLevelDelay sets the pause of WalkSecrets before it lists the next level of folders.
+func WithWalkLevelDelay(d time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if d < 0 {
+			return fmt.Errorf("invalid SMoP walk level delay %s: must not be negative", d)
+		}
+		c.walkLevelDelay = d
+		return nil
+	}
+}
+
+// WithWalkBackoff sets the delay between listings WalkSecrets starts with once it is rate limited,
+// which doubles on every further rate limited listing up to maxDelay.
+func WithWalkBackoff(baseDelay, maxDelay time.Duration) ClientOption {
+	return func(c *SMOPClient) error {
+		if baseDelay <= 0 || maxDelay < baseDelay {
+			return fmt.Errorf("invalid SMoP walk backoff %s-%s: delays must be positive and ordered", baseDelay, maxDelay)
+		}
+		c.walkBackoffBase = baseDelay
+		c.walkBackoffMax = maxDelay
+		return nil
+	}
+}
+
+// WithWalkMaxDepth sets how many levels of sub-folders WalkSecrets and DeleteSecrets descend at most,
+// failing with ErrWalkLimitExceeded on a deeper tree. Defaults to 32.
+func WithWalkMaxDepth(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP walk max depth %d: must be at least 1", n)
+		}
+		c.walkMaxDepth = n
+		return nil
+	}
+}
+
+// WithWalkMaxItems sets how many KVs and folders WalkSecrets and DeleteSecrets list at most,
+// failing with ErrWalkLimitExceeded on a larger tree. Defaults to 100000.
+func WithWalkMaxItems(n int) ClientOption {
+	return func(c *SMOPClient) error {
+		if n < 1 {
+			return fmt.Errorf("invalid SMoP walk max items %d: must be at least 1", n)
+		}
+		c.walkMaxItems = n
+		return nil
+	}
+}
+
+// WithContentTypeSniffing sets whether a response with a missing or generic Content-Type is
+// sniffed for a JSON body. Sniffing is enabled by default; strict setups may disable it to only
+// accept responses declaring a JSON Content-Type.
+func WithContentTypeSniffing(enabled bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.disableSniffing = !enabled
+		return nil
+	}
+}
+
+// WithDefaultResponseContentType sets the Content-Type assumed for responses without one,
+// which defaults to application/json. An empty content type assumes none, leaving such
+// responses to content type sniffing (see WithContentTypeSniffing).
+func WithDefaultResponseContentType(contentType string) ClientOption {
+	return func(c *SMOPClient) error {
+		if contentType != "" {
+			if _, _, err := mime.ParseMediaType(contentType); err != nil {
+				return fmt.Errorf("invalid SMoP default response content type %q: must be a media type: %w", contentType, err)
+			}
+		}
+		c.defaultContentType = contentType
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables verification of the SMoP server certificate.
+// This exposes the token and all secrets to anyone able to intercept the connection.
+// Only use it in lab environments.
+func WithInsecureSkipVerify(enabled bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.insecureSkipVerify = enabled
+		return nil
+	}
+}
+
+// WithRecursiveDelete makes DeleteSecret delete a folder together with the KVs and sub-folders it holds,
+// instead of returning ErrFolderNotEmpty.
+func WithRecursiveDelete(recursive bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.recursiveDelete = recursive
+		return nil
+	}
+}
+
+// WithRejectExpired makes GetSecret and BatchGetSecrets return ErrSecretExpired for a KV past its expiry,
+// instead of returning its value.
+func WithRejectExpired(reject bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.rejectExpired = reject
+		return nil
+	}
+}
+
+// WithRequireFolder makes GetSecrets and WalkSecrets return ErrFolderNotFound when the listed folder
+// does not exist, instead of an empty listing. Only empty listings cost an extra request to check the folder.
+func WithRequireFolder(require bool) ClientOption {
+	return func(c *SMOPClient) error {
+		c.requireExistingFolder = require
+		return nil
+	}
+}
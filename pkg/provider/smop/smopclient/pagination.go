@@ -0,0 +1,103 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+// nextPageURL returns the URL of the next page advertised by the RFC 5988 Link
+// header of the response, resolved against the request URL.
+// It returns nil when the response is the last page.
+func nextPageURL(resp *http.Response) (*url.URL, error) {
+	next, ok := parseLinkHeader(resp.Header.Values("Link"))["next"]
+	if !ok {
+		return nil, nil
+	}
+
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next page link %q: %w", next, err)
+	}
+	if resp.Request == nil || resp.Request.URL == nil {
+		return nextURL, nil
+	}
+
+	nextURL = resp.Request.URL.ResolveReference(nextURL)
+	// never send the SMoP token to another host
+	if nextURL.Host != resp.Request.URL.Host {
+		return nil, fmt.Errorf("next page link %q points to a different host", nextURL.Redacted())
+	}
+
+	return nextURL, nil
+}
+
+// parseLinkHeader parses RFC 5988 Link header values into a map of rel to target URL.
+// A link with several space separated rels is registered under each of them.
+// The first link registered for a rel wins.
+func parseLinkHeader(values []string) map[string]string {
+	links := map[string]string{}
+
+	for _, value := range values {
+		for _, link := range splitUnquoted(value, ',') {
+			parts := splitUnquoted(link, ';')
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+
+			for _, param := range parts[1:] {
+				key, val, found := strings.Cut(param, "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					rel = strings.ToLower(rel)
+					if _, ok := links[rel]; !ok {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+
+	return links
+}
+
+// splitUnquoted splits s around each sep which is neither inside a quoted
+// string nor inside a <...> link target.
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	var quoted, bracketed bool
+
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"' && !bracketed:
+			quoted = !quoted
+		case r == '<' && !quoted:
+			bracketed = true
+		case r == '>' && !quoted:
+			bracketed = false
+		case r == sep && !quoted && !bracketed:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// setRequestURL returns a RequestEditorFn which sends the request to the given URL instead.
+func setRequestURL(u *url.URL) cg.RequestEditorFn {
+	return func(_ context.Context, req *http.Request) error {
+		req.URL = u
+		req.Host = u.Host
+		return nil
+	}
+}
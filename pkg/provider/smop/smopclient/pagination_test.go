@@ -0,0 +1,92 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	tests := map[string]struct {
+		values []string
+		want   map[string]string
+	}{
+		"no header": {
+			want: map[string]string{},
+		},
+		"single next link": {
+			values: []string{`<https://smop.example/kv?page=2>; rel="next"`},
+			want:   map[string]string{"next": "https://smop.example/kv?page=2"},
+		},
+		"multiple links and rels": {
+			values: []string{`<?page=1>; rel="prev first", <?page=3>; rel=next; title="a, b; c"`},
+			want: map[string]string{
+				"prev":  "?page=1",
+				"first": "?page=1",
+				"next":  "?page=3",
+			},
+		},
+		"multiple header values": {
+			values: []string{`<?page=1>; rel="first"`, `<?page=2,3>; REL="Next"`},
+			want: map[string]string{
+				"first": "?page=1",
+				"next":  "?page=2,3",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseLinkHeader(tc.values))
+		})
+	}
+}
+
+func TestGetSecretsLinkPagination(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "Bearer "+testToken, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next", <%s?page=2>; rel="last"`, r.URL.Path, r.URL.Path))
+			_, _ = w.Write([]byte(`{"data":[{"path":"a"},{"path":"b"}]}`))
+		case "2":
+			_, _ = w.Write([]byte(`{"data":[{"path":"c"}]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	items, err := c.GetSecrets(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []cg.KVListItem{{Path: "a"}, {Path: "b"}, {Path: "c"}}, items)
+	assert.Equal(t, 2, requests)
+}
+
+func TestGetSecretsLinkPaginationLoop(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Link", `<?page=2>; rel="next"`)
+		_, _ = w.Write([]byte(`{"data":[{"path":"a"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	_, err = c.GetSecrets(context.Background(), nil)
+	assert.ErrorContains(t, err, "pagination loop")
+}
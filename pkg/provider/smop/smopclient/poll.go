@@ -0,0 +1,56 @@
+package smopclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// pollVersionPrefix marks a poll version holding the SMoP version of the KV.
+	pollVersionPrefix = "v"
+	// pollHashPrefix marks a poll version holding a hash of the value of the KV.
+	pollHashPrefix = "sha256:"
+)
+
+// PollForChange reports whether the KV `name` at `folderPath` changed since `lastVersion`, and returns
+// its current version to pass to the next poll. An empty `lastVersion` always reports a change.
+// Versions are opaque and only comparable to versions returned by PollForChange for the same KV.
+//
+// The version is read without the value of the KV, with GetSecretMetadata. When SMoP does not report
+// the version of the KV, or the KV is an alias whose target can change on its own, the KV is fetched
+// in full and its version is a hash of its value instead.
+func (c *SMOPClient) PollForChange(ctx context.Context, name string, folderPath *string, lastVersion string) (bool, string, error) {
+	metadata, err := c.GetSecretMetadata(ctx, name, folderPath)
+	if err != nil {
+		return false, "", err
+	}
+
+	var version string
+	if metadata.Version > 0 && !(c.followAliases && metadata.Type == kvTypeAlias) {
+		version = pollVersionPrefix + strconv.FormatInt(metadata.Version, 10)
+	} else {
+		if version, err = c.pollValueVersion(ctx, name, folderPath); err != nil {
+			return false, "", err
+		}
+	}
+	return version != lastVersion, version, nil
+}
+
+// pollValueVersion fetches the KV like GetSecret and returns a hash of its value.
+func (c *SMOPClient) pollValueVersion(ctx context.Context, name string, folderPath *string) (string, error) {
+	kv, _, err := c.getSecret(ctx, name, folderPath)
+	if err != nil {
+		return "", err
+	}
+	// maps are marshalled with sorted keys, so an unchanged value hashes the same
+	value, err := json.Marshal(kv.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash secret %q at %q: %w", name, getPathString(folderPath), err)
+	}
+	sum := sha256.Sum256(value)
+	return pollHashPrefix + hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,104 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollForChange(t *testing.T) {
+	var version atomic.Int64
+	version.Store(3)
+	var gets atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.URL.Path, "/metadata") {
+			gets.Add(1)
+		}
+		switch r.URL.Path {
+		case "/site/secrets/kv/db/metadata":
+			_, _ = w.Write([]byte(`{"path":"db","version":` + strconv.FormatInt(version.Load(), 10) + `}`))
+		case "/site/secrets/kv/link/metadata":
+			_, _ = w.Write([]byte(`{"path":"link","type":"alias","target":"db","version":1}`))
+		case "/site/secrets/kv/link":
+			_, _ = w.Write([]byte(`{"path":"link","type":"alias","target":"db","version":1,"secret":{}}`))
+		case "/site/secrets/kv/db":
+			_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t-` + strconv.FormatInt(version.Load(), 10) + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("version", func(t *testing.T) {
+		changed, first, err := c.PollForChange(ctx, "db", nil, "")
+		require.NoError(t, err)
+		assert.True(t, changed, "the first poll must report a change")
+
+		changed, second, err := c.PollForChange(ctx, "db", nil, first)
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, first, second)
+
+		version.Add(1)
+		changed, third, err := c.PollForChange(ctx, "db", nil, second)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.NotEqual(t, second, third)
+		assert.Zero(t, gets.Load(), "a reported version must not fetch the value")
+	})
+
+	t.Run("alias falls back to its value", func(t *testing.T) {
+		changed, first, err := c.PollForChange(ctx, "link", nil, "")
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.True(t, strings.HasPrefix(first, pollHashPrefix))
+
+		changed, _, err = c.PollForChange(ctx, "link", nil, first)
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		version.Add(1)
+		changed, _, err = c.PollForChange(ctx, "link", nil, first)
+		require.NoError(t, err)
+		assert.True(t, changed, "a changed alias target must be reported")
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, _, err := c.PollForChange(ctx, "gone", nil, "")
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	})
+}
+
+func TestPollForChangeWithoutVersion(t *testing.T) {
+	value := `{"path":"db","secret":{"password":"s3cr3t"}}`
+	c := newTestClient(t, map[string]string{"db": value})
+	ctx := context.Background()
+
+	changed, first, err := c.PollForChange(ctx, "db", nil, "")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, second, err := c.PollForChange(ctx, "db", nil, first)
+	require.NoError(t, err)
+	assert.False(t, changed, "an unchanged value must not be reported as changed")
+	assert.Equal(t, first, second)
+
+	changed, _, err = c.PollForChange(ctx, "db", nil, pollVersionPrefix+"1")
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
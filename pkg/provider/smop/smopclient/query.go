@@ -0,0 +1,79 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrQueryUnsupported is returned by QuerySecrets when the SMoP server does not offer the query endpoint.
+var ErrQueryUnsupported = errors.New("SMoP server does not offer the query endpoint")
+
+// Query selects KVs by their name and tags, see QuerySecrets.
+type Query struct {
+	// FolderPath is the folder searched, nil for the root folder.
+	FolderPath *string `json:"folderName,omitempty"`
+	// Recursive also searches the sub-folders of FolderPath.
+	Recursive bool `json:"recursive,omitempty"`
+	// NameRegexp only matches KVs whose name matches this RE2 expression.
+	NameRegexp string `json:"nameRegexp,omitempty"`
+	// Tags only matches KVs carrying all of these tags.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// QuerySecrets lists the KVs matching `query` with the `POST kv/query` endpoint,
+// letting the SMoP server apply filters too complex for the query parameters of a listing.
+// Sub-folder entries are skipped. ErrQueryUnsupported is returned when the SMoP server
+// does not offer the endpoint, in which case the KVs listed by WalkSecrets can be filtered instead.
+func (c *SMOPClient) QuerySecrets(ctx context.Context, query Query) ([]KVRef, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+	defer cancel()
+
+	path := getPathString(query.FolderPath)
+	resp, err := c.doRaw(ctx, http.MethodPost, nil, query, "kv", "query")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query secrets at %q: %w", path, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query secrets response at %q: %w", path, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, fmt.Errorf("failed to query secrets at %q: %w", path, ErrQueryUnsupported)
+	}
+
+	respContentType := resp.Header.Get("Content-Type")
+	isJSON := c.isJSONResponse(respContentType, respBytes)
+	if resp.StatusCode != http.StatusOK || !isJSON {
+		if isJSON {
+			if err := parseAPIErrorResponse(respBytes, "kv/query", resp.StatusCode); err != nil {
+				return nil, err
+			}
+		}
+		return nil, createAPIError(resp.StatusCode, respContentType, "kv/query")
+	}
+
+	var dest struct {
+		Data []struct {
+			batchItem
+			kvAttributes
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBytes, &dest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query secrets response at %q: %w", path, err)
+	}
+
+	refs := make([]KVRef, 0, len(dest.Data))
+	for _, item := range dest.Data {
+		if item.isFolder() {
+			continue
+		}
+		refs = append(refs, KVRef{Name: item.Path, FolderPath: item.FolderName, UpdatedAt: item.UpdatedAt, Tags: item.Tags})
+	}
+	return refs, nil
+}
@@ -0,0 +1,90 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuerySecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/site/secrets/kv/query", r.URL.Path)
+
+		var query Query
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		require.NotNil(t, query.FolderPath)
+		assert.Equal(t, "apps", *query.FolderPath)
+		assert.True(t, query.Recursive)
+		assert.Equal(t, "^db-", query.NameRegexp)
+		assert.Equal(t, map[string]string{"team": "payments"}, query.Tags)
+
+		_, _ = w.Write([]byte(`{"data":[
+			{"path":"db-main","folderName":"apps","updatedAt":"2026-01-02T03:04:05Z"},
+			{"path":"db-old","type":"folder","folderName":"apps"},
+			{"path":"db-replica","folderName":"apps/eu","tags":{"team":"payments"}}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	apps := "apps"
+	refs, err := c.QuerySecrets(context.Background(), Query{
+		FolderPath: &apps,
+		Recursive:  true,
+		NameRegexp: "^db-",
+		Tags:       map[string]string{"team": "payments"},
+	})
+	require.NoError(t, err)
+	require.Len(t, refs, 2)
+	assert.Equal(t, "apps/db-main", refs[0].String())
+	require.NotNil(t, refs[0].UpdatedAt)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), *refs[0].UpdatedAt)
+	assert.Equal(t, "apps/eu/db-replica", refs[1].String())
+	assert.Nil(t, refs[1].UpdatedAt)
+	assert.Equal(t, map[string]string{"team": "payments"}, refs[1].Tags)
+}
+
+func TestQuerySecretsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "unsupported", status: http.StatusNotFound, wantErr: ErrQueryUnsupported},
+		{name: "not implemented", status: http.StatusNotImplemented, wantErr: ErrQueryUnsupported},
+		{name: "invalid query", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"error":"invalid nameRegexp"}`))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+			require.NoError(t, err)
+
+			_, err = c.QuerySecrets(context.Background(), Query{NameRegexp: "("})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.NotErrorIs(t, err, ErrQueryUnsupported)
+		})
+	}
+}
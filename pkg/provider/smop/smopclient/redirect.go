@@ -0,0 +1,43 @@
+package smopclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// defaultMaxRedirects is the number of redirects followed for a single SMoP request.
+const defaultMaxRedirects = 5
+
+var (
+	// ErrCrossHostRedirect is returned when SMoP redirects a request to another host,
+	// or from HTTPS to HTTP, and cross-host redirects are not allowed.
+	ErrCrossHostRedirect = errors.New("SMoP redirect to another host refused")
+	// ErrTooManyRedirects is returned when a request is redirected more often than allowed.
+	ErrTooManyRedirects = errors.New("too many SMoP redirects")
+)
+
+// checkRedirect is the redirect policy of SMoP requests. Redirects to the host of the original
+// request are followed up to maxRedirects times, keeping the Authorization header. Redirects to
+// another host are refused, so the token is never sent to a host the client was not set up for,
+// unless allowCrossHostRedirects is set; net/http then only keeps the Authorization header for
+// the same domain and its subdomains. A maxRedirects of 0 does not follow redirects: the 3xx
+// response is handled as any other unexpected response.
+func (c *SMOPClient) checkRedirect(req *http.Request, via []*http.Request) error {
+	if c.maxRedirects == 0 {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > c.maxRedirects {
+		return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, c.maxRedirects)
+	}
+
+	original := via[0].URL
+	if c.allowCrossHostRedirects {
+		return nil
+	}
+	if req.URL.Host != original.Host || original.Scheme == "https" && req.URL.Scheme != "https" {
+		return fmt.Errorf("%w: from %s://%s to %s://%s", ErrCrossHostRedirect,
+			original.Scheme, original.Host, req.URL.Scheme, req.URL.Host)
+	}
+	return nil
+}
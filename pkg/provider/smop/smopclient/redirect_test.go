@@ -0,0 +1,96 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRedirectServer returns a server answering kv/db with a secret and redirecting kv/moved
+// to `target`, recording the Authorization header of each request it gets in `auth`.
+func newRedirectServer(t *testing.T, target func(srv *httptest.Server) string, auth *[]string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*auth = append(*auth, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/site/secrets/kv/db":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+		case "/site/secrets/kv/moved":
+			http.Redirect(w, r, target(srv), http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRedirectSameHost(t *testing.T) {
+	var auth []string
+	srv := newRedirectServer(t, func(srv *httptest.Server) string { return srv.URL + "/site/secrets/kv/db" }, &auth)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	kv, err := c.GetSecret(context.Background(), "moved", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", kv.Secret["password"])
+	require.Len(t, auth, 2)
+	assert.Equal(t, "Bearer "+testToken, auth[1], "the token must be kept on a same-host redirect")
+
+	t.Run("not followed", func(t *testing.T) {
+		c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithMaxRedirects(0))
+		require.NoError(t, err)
+		_, err = c.GetSecret(context.Background(), "moved", nil)
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr), "got %v", err)
+		assert.Equal(t, http.StatusFound, apiErr.StatusCode)
+	})
+}
+
+func TestRedirectLoop(t *testing.T) {
+	var auth []string
+	srv := newRedirectServer(t, func(srv *httptest.Server) string { return srv.URL + "/site/secrets/kv/moved" }, &auth)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithMaxRedirects(2))
+	require.NoError(t, err)
+
+	_, err = c.GetSecret(context.Background(), "moved", nil)
+	assert.ErrorIs(t, err, ErrTooManyRedirects)
+	assert.Len(t, auth, 3)
+}
+
+func TestRedirectCrossHost(t *testing.T) {
+	var otherAuth []string
+	other := newRedirectServer(t, func(srv *httptest.Server) string { return srv.URL }, &otherAuth)
+
+	var auth []string
+	srv := newRedirectServer(t, func(*httptest.Server) string { return other.URL + "/site/secrets/kv/db" }, &auth)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	_, err = c.GetSecret(context.Background(), "moved", nil)
+	assert.ErrorIs(t, err, ErrCrossHostRedirect)
+	assert.Empty(t, otherAuth, "a cross-host redirect must not be followed")
+
+	c, err = NewSMOPClient(srv.URL+"/site/secrets", testToken, WithCrossHostRedirects(true))
+	require.NoError(t, err)
+
+	kv, err := c.GetSecret(context.Background(), "moved", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", kv.Secret["password"])
+	assert.Len(t, otherAuth, 1)
+}
+
+func TestWithMaxRedirects(t *testing.T) {
+	_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithMaxRedirects(-1))
+	assert.ErrorContains(t, err, "invalid SMoP max redirects -1")
+}
@@ -0,0 +1,249 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRetryBaseDelay is the delay before the first retry, doubled for every further retry.
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	// defaultRetryMaxDelay caps the delay between two retries.
+	defaultRetryMaxDelay = 2 * time.Second
+)
+
+// ErrRetryBudgetExhausted is returned when a request failed and the retry budget
+// of its context does not allow another attempt.
+var ErrRetryBudgetExhausted = errors.New("SMoP retry budget exhausted")
+
+// RetryBudget bounds the retries of all requests sharing a context.
+// A zero field does not limit retries.
+type RetryBudget struct {
+	// MaxAttempts is the total number of retries.
+	MaxAttempts int
+	// MaxDuration is the total time spent retrying, including the delays between retries.
+	MaxDuration time.Duration
+}
+
+// BeforeRetryFunc is called before a failed request is retried, see WithBeforeRetry.
+// `attempt` is the number of the retry, starting at 1, `err` is the failure of the previous attempt
+// and `delay` is the wait before the retry is sent.
+type BeforeRetryFunc func(ctx context.Context, attempt int, err error, delay time.Duration)
+
+// retryBudgetKey is the context key of a retryBudget.
+type retryBudgetKey struct{}
+
+// retryBudget tracks the retries spent against a RetryBudget.
+type retryBudget struct {
+	limits RetryBudget
+
+	mu        sync.Mutex
+	attempts  int
+	spent     time.Duration
+	exhausted bool
+}
+
+// reserve takes one retry waiting `delay` from the budget.
+// It returns false, and marks the budget exhausted, if the retry does not fit.
+func (b *retryBudget) reserve(delay time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if (b.limits.MaxAttempts > 0 && b.attempts >= b.limits.MaxAttempts) ||
+		(b.limits.MaxDuration > 0 && b.spent+delay > b.limits.MaxDuration) {
+		b.exhausted = true
+		return false
+	}
+	b.attempts++
+	b.spent += delay
+	return true
+}
+
+// charge adds the duration of a retried attempt to the budget.
+func (b *retryBudget) charge(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent += d
+}
+
+// isExhausted reports whether a retry did not fit into the budget.
+func (b *retryBudget) isExhausted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exhausted
+}
+
+// ContextWithRetryBudget returns a copy of ctx in which all SMoP requests share the client's retry budget.
+// Once the budget is exhausted, requests made with the context fail on their first error instead of retrying.
+// Requests made with a context without a budget each get their own budget.
+// If ctx already carries a budget, it is returned unchanged.
+func (c *SMOPClient) ContextWithRetryBudget(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(retryBudgetKey{}).(*retryBudget); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{limits: c.retryBudget})
+}
+
+// retryTransport retries idempotent requests which failed with a transient error.
+type retryTransport struct {
+	base http.RoundTripper
+
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	budget     RetryBudget
+
+	beforeRetry BeforeRetryFunc
+	stats       *clientStats
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.maxRetries < 1 || !isIdempotent(req.Method) || req.Body != nil && req.GetBody == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	budget, ok := req.Context().Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		budget = &retryBudget{limits: t.budget}
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		start := time.Now()
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt > 0 {
+			budget.charge(time.Since(start))
+			t.stats.observeRetry()
+		}
+		if !shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+		// once a request spent the budget, the other requests sharing it fail on their first error
+		if budget.isExhausted() {
+			return nil, retryBudgetError(req, resp, err)
+		}
+		if attempt >= t.maxRetries {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if !budget.reserve(delay) {
+			log.Info("SMoP retry budget exhausted, not retrying request",
+				"method", req.Method, "path", req.URL.Path, "attempts", attempt+1)
+			return nil, retryBudgetError(req, resp, err)
+		}
+		if t.beforeRetry != nil {
+			t.beforeRetry(req.Context(), attempt+1, attemptError(req, resp, err), delay)
+		}
+		drainBody(resp)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before retry number `attempt`+1.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << attempt
+	if delay <= 0 || delay > t.maxDelay {
+		return t.maxDelay
+	}
+	return delay
+}
+
+// shouldRetry reports whether an attempt failed with a transient error.
+// Errors caused by the request context and TLS verification failures are never retried.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !isTLSVerificationError(err)
+	}
+	return isRetryableStatus(resp.StatusCode)
+}
+
+// isRetryableStatus reports whether a SMoP response status denotes a transient failure.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsRetryable reports whether err, returned by an SMOPClient method, is transient and the call may
+// succeed when retried later. It agrees with the retries configured by WithMaxRetries:
+// 429, 502, 503 and 504 responses and transport failures are retryable, while errors caused by
+// a canceled or expired context and TLS verification failures are not.
+// An exhausted retry budget does not change the classification of the last failure it wraps.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || isTLSVerificationError(err) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	// the HTTP client reports every transport failure as an *url.Error
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// retryBudgetError wraps the last failure of a request with ErrRetryBudgetExhausted.
+// A failed response is closed and reported as an *APIError.
+func retryBudgetError(req *http.Request, resp *http.Response, err error) error {
+	drainBody(resp)
+	return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, attemptError(req, resp, err))
+}
+
+// attemptError returns the failure of an attempt: its transport error, or an *APIError for its response.
+func attemptError(req *http.Request, resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		Path:       req.URL.Path,
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// drainBody reads and closes the body of a discarded response so its connection can be reused.
+func drainBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+}
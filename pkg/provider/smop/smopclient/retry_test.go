@@ -0,0 +1,316 @@
+package smopclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyTestClient starts a stub SMoP server which answers the first `failures`
+// requests with 503 and every further request with a KV.
+func newFlakyTestClient(t *testing.T, failures int64, opts ...ClientOption) (*SMOPClient, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	opts = append([]ClientOption{WithRetryBackoff(time.Millisecond, 5*time.Millisecond)}, opts...)
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, opts...)
+	require.NoError(t, err)
+	return c, &requests
+}
+
+func TestRetry(t *testing.T) {
+	tests := map[string]struct {
+		failures     int64
+		opts         []ClientOption
+		wantErr      bool
+		wantRequests int64
+	}{
+		"no retries by default": {
+			failures:     1,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		"succeeds after retries": {
+			failures:     2,
+			opts:         []ClientOption{WithMaxRetries(3)},
+			wantRequests: 3,
+		},
+		"fails once retries are used up": {
+			failures:     5,
+			opts:         []ClientOption{WithMaxRetries(2)},
+			wantErr:      true,
+			wantRequests: 3,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, requests := newFlakyTestClient(t, tc.failures, tc.opts...)
+
+			_, err := c.GetSecret(context.Background(), "db", nil)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, ErrRetryBudgetExhausted)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantRequests, requests.Load())
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	c, requests := newFlakyTestClient(t, 100, WithMaxRetries(5), WithRetryBudget(RetryBudget{MaxAttempts: 2}))
+	ctx := c.ContextWithRetryBudget(context.Background())
+	assert.Equal(t, ctx, c.ContextWithRetryBudget(ctx), "an existing budget must be kept")
+
+	_, err := c.GetSecret(ctx, "db", nil)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int64(3), requests.Load())
+
+	// further calls sharing the budget fail on their first error
+	_, err = c.GetSecret(ctx, "db", nil)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, int64(4), requests.Load())
+
+	// calls without the budget context get their own budget
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, int64(7), requests.Load())
+}
+
+func TestRetryBudgetDuration(t *testing.T) {
+	c, requests := newFlakyTestClient(t, 100,
+		WithMaxRetries(10),
+		WithRetryBackoff(20*time.Millisecond, 20*time.Millisecond),
+		WithRetryBudget(RetryBudget{MaxDuration: 50 * time.Millisecond}))
+
+	_, err := c.GetSecret(context.Background(), "db", nil)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.LessOrEqual(t, requests.Load(), int64(3))
+}
+
+func TestRetryBudgetExhaustedDuration(t *testing.T) {
+	c, requests := newFlakyTestClient(t, 100,
+		WithMaxRetries(10),
+		WithRetryBackoff(10*time.Millisecond, 40*time.Millisecond),
+		WithRetryBudget(RetryBudget{MaxDuration: 50 * time.Millisecond}))
+	ctx := c.ContextWithRetryBudget(context.Background())
+
+	_, err := c.GetSecret(ctx, "db", nil)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	sent := requests.Load()
+
+	// the first delay of the next call would still fit, but the budget is spent
+	_, err = c.GetSecret(ctx, "db", nil)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, sent+1, requests.Load())
+}
+
+func TestBeforeRetry(t *testing.T) {
+	type call struct {
+		attempt int
+		status  int
+		delay   time.Duration
+	}
+	tests := map[string]struct {
+		failures  int64
+		opts      []ClientOption
+		wantErr   bool
+		wantCalls []call
+	}{
+		"called before each retry": {
+			failures: 3,
+			opts:     []ClientOption{WithMaxRetries(5)},
+			wantCalls: []call{
+				{attempt: 1, status: http.StatusServiceUnavailable, delay: time.Millisecond},
+				{attempt: 2, status: http.StatusServiceUnavailable, delay: 2 * time.Millisecond},
+				{attempt: 3, status: http.StatusServiceUnavailable, delay: 4 * time.Millisecond},
+			},
+		},
+		"not called without a further retry": {
+			failures: 100,
+			opts:     []ClientOption{WithMaxRetries(2)},
+			wantErr:  true,
+			wantCalls: []call{
+				{attempt: 1, status: http.StatusServiceUnavailable, delay: time.Millisecond},
+				{attempt: 2, status: http.StatusServiceUnavailable, delay: 2 * time.Millisecond},
+			},
+		},
+		"not called once the budget is exhausted": {
+			failures: 100,
+			opts:     []ClientOption{WithMaxRetries(5), WithRetryBudget(RetryBudget{MaxAttempts: 1})},
+			wantErr:  true,
+			wantCalls: []call{
+				{attempt: 1, status: http.StatusServiceUnavailable, delay: time.Millisecond},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls []call
+			record := WithBeforeRetry(func(_ context.Context, attempt int, err error, delay time.Duration) {
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				calls = append(calls, call{attempt: attempt, status: apiErr.StatusCode, delay: delay})
+			})
+			c, requests := newFlakyTestClient(t, tc.failures, append(tc.opts, record)...)
+
+			_, err := c.GetSecret(context.Background(), "db", nil)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantCalls, calls)
+			assert.Equal(t, int64(len(calls)+1), requests.Load())
+		})
+	}
+}
+
+func TestRetryIdempotentOnly(t *testing.T) {
+	c, requests := newFlakyTestClient(t, 1, WithMaxRetries(3))
+
+	_, err := c.IssueCredential(context.Background(), "database/app", nil)
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestRetryContextCancel(t *testing.T) {
+	c, _ := newFlakyTestClient(t, 100, WithMaxRetries(3), WithRetryBackoff(time.Minute, time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetSecret(ctx, "db", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestWithRetryOptions(t *testing.T) {
+	for name, opt := range map[string]ClientOption{
+		"negative max retries":    WithMaxRetries(-1),
+		"zero base delay":         WithRetryBackoff(0, time.Second),
+		"max below base delay":    WithRetryBackoff(time.Second, time.Millisecond),
+		"negative budget":         WithRetryBudget(RetryBudget{MaxAttempts: -1}),
+		"negative budget timeout": WithRetryBudget(RetryBudget{MaxDuration: -time.Second}),
+		"negative dial retries":   WithDialRetries(-1, time.Millisecond),
+		"zero dial retry delay":   WithDialRetries(1, 0),
+		"nil before retry":        WithBeforeRetry(nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, opt)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil":               {err: nil},
+		"too many requests": {err: &APIError{StatusCode: http.StatusTooManyRequests}, want: true},
+		"bad gateway":       {err: &APIError{StatusCode: http.StatusBadGateway}, want: true},
+		"unavailable":       {err: fmt.Errorf("failed to fetch: %w", &APIError{StatusCode: http.StatusServiceUnavailable}), want: true},
+		"gateway timeout":   {err: &APIError{StatusCode: http.StatusGatewayTimeout}, want: true},
+		"internal error":    {err: &APIError{StatusCode: http.StatusInternalServerError}},
+		"not found":         {err: &APIError{StatusCode: http.StatusNotFound}},
+		"unauthorized":      {err: &APIError{StatusCode: http.StatusUnauthorized}},
+		"transport failure": {err: fmt.Errorf("failed to fetch: %w", &url.Error{Op: "Get", URL: "https://smop", Err: syscall.ECONNREFUSED}), want: true},
+		"network error":     {err: &net.OpError{Op: "dial", Err: syscall.ECONNRESET}, want: true},
+		"canceled":          {err: &url.Error{Op: "Get", URL: "https://smop", Err: context.Canceled}},
+		"tls verification":  {err: &url.Error{Op: "Get", URL: "https://smop", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}},
+		"deadline exceeded": {err: fmt.Errorf("failed to fetch: %w", context.DeadlineExceeded)},
+		"budget exhausted":  {err: fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, &APIError{StatusCode: http.StatusServiceUnavailable}), want: true},
+		"alias cycle":       {err: ErrAliasCycle},
+		"unmarshal failure": {err: errors.New("failed to unmarshal response")},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestIsRetryableMatchesRetries(t *testing.T) {
+	for code := 400; code < 600; code++ {
+		resp := &http.Response{StatusCode: code}
+		assert.Equal(t, shouldRetry(context.Background(), resp, nil), IsRetryable(&APIError{StatusCode: code}), code)
+	}
+}
+
+func TestRetryCancelDuringBackoff(t *testing.T) {
+	tests := map[string]func(c *SMOPClient, ctx context.Context) error{
+		"get": func(c *SMOPClient, ctx context.Context) error {
+			_, err := c.GetSecret(ctx, "db", nil)
+			return err
+		},
+		"walk": func(c *SMOPClient, ctx context.Context) error {
+			_, err := c.WalkSecrets(ctx, nil)
+			return err
+		},
+		"shared retry budget": func(c *SMOPClient, ctx context.Context) error {
+			_, err := c.GetSecret(c.ContextWithRetryBudget(ctx), "db", nil)
+			return err
+		},
+	}
+
+	for name, call := range tests {
+		t.Run(name, func(t *testing.T) {
+			firstAttempt := make(chan struct{})
+			var requests atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if requests.Add(1) == 1 {
+					close(firstAttempt)
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithMaxRetries(3), WithRetryBackoff(time.Minute, time.Minute))
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-firstAttempt
+				cancel()
+			}()
+
+			start := time.Now()
+			err = call(c, ctx)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), 5*time.Second, "the backoff must be aborted instead of slept out")
+			assert.Equal(t, int64(1), requests.Load(), "no attempt may be made after the cancellation")
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package smopclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// defaultRotationPollInterval is how often RotateSecret polls a rotation in progress.
+	defaultRotationPollInterval = 2 * time.Second
+	// defaultRotationTimeout bounds RotateSecret, including the wait for the rotation to complete.
+	defaultRotationTimeout = 2 * time.Minute
+)
+
+// rotation states reported by SMoP.
+const (
+	rotationInProgress = "inProgress"
+	rotationCompleted  = "completed"
+	rotationFailed     = "failed"
+)
+
+// ErrRotationFailed is returned by RotateSecret when SMoP reports that the rotation of a KV failed.
+var ErrRotationFailed = errors.New("SMoP secret rotation failed")
+
+// Rotation is a completed rotation of a KV returned by RotateSecret.
+type Rotation struct {
+	// Version is the version of the KV holding the rotated value, if reported.
+	Version int64 `json:"version,omitempty"`
+	// RotatedAt is when the rotation completed, if reported.
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+}
+
+// rotationStatus is the body of the responses of the rotate endpoint.
+type rotationStatus struct {
+	Rotation
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RotateSecret asks SMoP to rotate the KV `name` at the specified `folderPath` and waits for the rotation
+// to complete, so the KV read next holds the rotated value.
+//
+// The rotation is requested with `POST kv/{name}/rotate`. SMoP answers 202 while the rotation runs, and 409
+// while another rotation of the KV is in progress: RotateSecret then polls `GET kv/{name}/rotate` until the
+// rotation completes, see WithRotationPolling. A rotation SMoP reports as failed is returned as
+// ErrRotationFailed, a KV which cannot be rotated as the *APIError of the response.
+// The request is never retried, as each one rotates the KV again.
+func (c *SMOPClient) RotateSecret(ctx context.Context, name string, folderPath *string) (*Rotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.rotationTimeout)
+	defer cancel()
+
+	fullKvPath := joinKVPath(getPathString(folderPath), name)
+	status, err := c.rotationRequest(ctx, http.MethodPost, name, folderPath)
+	for err == nil && status.Status == rotationInProgress {
+		if err := sleepContext(ctx, c.rotationPollInterval); err != nil {
+			return nil, fmt.Errorf("failed to wait for the rotation of secret %q: %w", fullKvPath, err)
+		}
+		status, err = c.rotationRequest(ctx, http.MethodGet, name, folderPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch status.Status {
+	case rotationCompleted:
+		return &status.Rotation, nil
+	case rotationFailed:
+		if status.Error != "" {
+			return nil, fmt.Errorf("%w: %q: %s", ErrRotationFailed, fullKvPath, status.Error)
+		}
+		return nil, fmt.Errorf("%w: %q", ErrRotationFailed, fullKvPath)
+	}
+	return nil, fmt.Errorf("failed to rotate secret %q: unknown rotation status %q", fullKvPath, status.Status)
+}
+
+// rotationRequest requests a rotation with a POST, or the state of the rotation in progress with a GET.
+// A response without a status is completed, or in progress if it is a 202.
+func (c *SMOPClient) rotationRequest(ctx context.Context, method, name string, folderPath *string) (*rotationStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	var query url.Values
+	if folderPath != nil {
+		query = url.Values{"folderName": []string{*folderPath}}
+	}
+	var body any
+	if method == http.MethodPost {
+		body = map[string]any{}
+	}
+
+	path := getPathString(folderPath)
+	resp, err := c.doRaw(ctx, method, query, body, "kv", name, "rotate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate secret %q at %q: %w", name, path, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rotation response %q at %q: %w", name, path, err)
+	}
+
+	fullKvPath := joinKVPath(path, name)
+	respContentType := resp.Header.Get("Content-Type")
+	isJSON := c.isJSONResponse(respContentType, respBytes)
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		var status rotationStatus
+		if len(bytes.TrimSpace(respBytes)) > 0 {
+			if !isJSON {
+				return nil, createAPIError(resp.StatusCode, respContentType, fullKvPath)
+			}
+			if err := json.Unmarshal(respBytes, &status); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal rotation response %q at %q: %w", name, path, err)
+			}
+		}
+		if status.Status == "" {
+			status.Status = rotationCompleted
+			if resp.StatusCode == http.StatusAccepted {
+				status.Status = rotationInProgress
+			}
+		}
+		return &status, nil
+	case http.StatusConflict:
+		// another rotation of the KV is in progress, its completion is waited for
+		return &rotationStatus{Status: rotationInProgress}, nil
+	}
+
+	// Try to parse error response
+	if isJSON {
+		if err := parseAPIErrorResponse(respBytes, fullKvPath, resp.StatusCode); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fallback error if we can't parse the response
+	return nil, createAPIError(resp.StatusCode, respContentType, fullKvPath)
+}
@@ -0,0 +1,151 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotationResponse is a response of the stub rotate endpoint.
+type rotationResponse struct {
+	code int
+	body string
+}
+
+func TestRotateSecret(t *testing.T) {
+	completed := rotationResponse{http.StatusOK, `{"status":"completed","version":4,"rotatedAt":"2026-10-14T09:00:00Z"}`}
+	inProgress := rotationResponse{http.StatusOK, `{"status":"inProgress"}`}
+
+	tests := map[string]struct {
+		post        rotationResponse
+		polls       []rotationResponse
+		wantErr     error
+		wantMessage string
+		wantVersion int64
+	}{
+		"rotated right away": {
+			post:        completed,
+			wantVersion: 4,
+		},
+		"rotated without a body": {
+			post: rotationResponse{http.StatusOK, ""},
+		},
+		"rotation accepted": {
+			post:        rotationResponse{http.StatusAccepted, ""},
+			polls:       []rotationResponse{inProgress, inProgress, completed},
+			wantVersion: 4,
+		},
+		"another rotation in progress": {
+			post:        rotationResponse{http.StatusConflict, `{"error":"rotation in progress"}`},
+			polls:       []rotationResponse{completed},
+			wantVersion: 4,
+		},
+		"rotation failed": {
+			post:        rotationResponse{http.StatusAccepted, `{"status":"inProgress"}`},
+			polls:       []rotationResponse{{http.StatusOK, `{"status":"failed","error":"database unreachable"}`}},
+			wantErr:     ErrRotationFailed,
+			wantMessage: "database unreachable",
+		},
+		"not rotatable": {
+			post:        rotationResponse{http.StatusBadRequest, `{"error":"secret type does not support rotation"}`},
+			wantMessage: "does not support rotation",
+		},
+		"unknown status": {
+			post:        rotationResponse{http.StatusOK, `{"status":"paused"}`},
+			wantMessage: `unknown rotation status "paused"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var polls atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/site/secrets/kv/db/rotate", r.URL.Path)
+				assert.Equal(t, "apps", r.URL.Query().Get("folderName"))
+				resp := tc.post
+				if r.Method == http.MethodGet {
+					n := int(polls.Add(1))
+					require.LessOrEqual(t, n, len(tc.polls), "polled after the rotation completed")
+					resp = tc.polls[n-1]
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(resp.code)
+				_, _ = w.Write([]byte(resp.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithRotationPolling(time.Millisecond, time.Second))
+			require.NoError(t, err)
+
+			apps := "apps"
+			rotation, err := c.RotateSecret(context.Background(), "db", &apps)
+			assert.Equal(t, int64(len(tc.polls)), polls.Load())
+			if tc.wantErr != nil || tc.wantMessage != "" {
+				if tc.wantErr != nil {
+					assert.ErrorIs(t, err, tc.wantErr)
+				}
+				assert.ErrorContains(t, err, tc.wantMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantVersion, rotation.Version)
+		})
+	}
+}
+
+func TestRotateSecretTimeout(t *testing.T) {
+	var polls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			polls.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"inProgress"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithRotationPolling(10*time.Millisecond, 50*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.RotateSecret(context.Background(), "db", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, `failed to wait for the rotation of secret "/db"`)
+	assert.Positive(t, polls.Load())
+}
+
+func TestRotateSecretNotRetried(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken,
+		WithMaxRetries(2), WithRetryBackoff(time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.RotateSecret(context.Background(), "db", nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int64(1), requests.Load(), "requesting a rotation must not be retried")
+}
+
+func TestWithRotationPolling(t *testing.T) {
+	for name, opt := range map[string]ClientOption{
+		"zero interval":          WithRotationPolling(0, time.Minute),
+		"timeout below interval": WithRotationPolling(time.Minute, time.Second),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, opt)
+			assert.Error(t, err)
+		})
+	}
+}
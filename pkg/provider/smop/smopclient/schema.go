@@ -0,0 +1,134 @@
+package smopclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnexpectedSchema is returned when response schema validation is enabled
+// and a SMoP response does not have the expected shape.
+var ErrUnexpectedSchema = errors.New("unexpected response schema from SMoP")
+
+// jsonKind is the JSON type expected for a response field.
+type jsonKind string
+
+const (
+	jsonObject jsonKind = "object"
+	jsonArray  jsonKind = "array"
+	jsonString jsonKind = "string"
+)
+
+// schemaField describes a single top-level field of a response object.
+type schemaField struct {
+	name     string
+	kind     jsonKind
+	required bool
+}
+
+var (
+	kvSchema = []schemaField{
+		{name: "path", kind: jsonString, required: true},
+		{name: "secret", kind: jsonObject, required: true},
+		{name: "type", kind: jsonString},
+		{name: "target", kind: jsonString},
+	}
+	kvListSchema = []schemaField{
+		{name: "data", kind: jsonArray, required: true},
+	}
+	kvListItemSchema = []schemaField{
+		{name: "path", kind: jsonString, required: true},
+		{name: "type", kind: jsonString},
+	}
+)
+
+// validateKVSchema checks a KV response body against the expected KV schema.
+func validateKVSchema(body []byte) error {
+	return validateObjectSchema(body, kvSchema, "KV")
+}
+
+// validateKVListSchema checks a KV list response body against the expected list envelope and item schema.
+func validateKVListSchema(body []byte) error {
+	if err := validateObjectSchema(body, kvListSchema, "KV list"); err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("%w: KV list: %w", ErrUnexpectedSchema, err)
+	}
+	for i, item := range envelope.Data {
+		if err := validateObjectSchema(item, kvListItemSchema, fmt.Sprintf("KV list item %d", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateObjectSchema checks that `body` is a JSON object carrying every required field of `schema`
+// and that known fields have the expected type. Unknown fields are allowed, but are named when a
+// required field is missing so that renamed or wrapped (e.g. by a gateway) responses are easy to spot.
+func validateObjectSchema(body []byte, schema []schemaField, what string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return fmt.Errorf("%w: %s: expected a JSON object", ErrUnexpectedSchema, what)
+	}
+
+	for _, field := range schema {
+		raw, ok := fields[field.name]
+		if !ok || isJSONNull(raw) {
+			if field.required {
+				return fmt.Errorf("%w: %s: missing required field %q%s", ErrUnexpectedSchema, what, field.name, extraFields(fields, schema))
+			}
+			continue
+		}
+		if kind := kindOf(raw); kind != field.kind {
+			return fmt.Errorf("%w: %s: field %q is %s, expected %s", ErrUnexpectedSchema, what, field.name, kind, field.kind)
+		}
+	}
+	return nil
+}
+
+// extraFields describes the fields of `fields` which are not part of `schema`.
+func extraFields(fields map[string]json.RawMessage, schema []schemaField) string {
+	var extra []string
+	for name := range fields {
+		if !slices.ContainsFunc(schema, func(f schemaField) bool { return f.name == name }) {
+			extra = append(extra, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(extra) == 0 {
+		return ""
+	}
+	slices.Sort(extra)
+	return fmt.Sprintf(" (unexpected fields: %s)", strings.Join(extra, ", "))
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return string(raw) == "null"
+}
+
+// kindOf returns the JSON type of a raw JSON value.
+func kindOf(raw json.RawMessage) jsonKind {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" {
+		return "empty"
+	}
+	switch trimmed[0] {
+	case '{':
+		return jsonObject
+	case '[':
+		return jsonArray
+	case '"':
+		return jsonString
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
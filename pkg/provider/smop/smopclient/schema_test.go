@@ -0,0 +1,79 @@
+package smopclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateKVSchema(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		wantErr string
+	}{
+		"valid":          {body: `{"path":"db","secret":{"password":"x"},"createdAt":"2025-01-01"}`},
+		"valid alias":    {body: `{"path":"db","type":"alias","target":"apps/db","secret":{}}`},
+		"not an object":  {body: `["db"]`, wantErr: "KV: expected a JSON object"},
+		"missing secret": {body: `{"path":"db"}`, wantErr: `KV: missing required field "secret"`},
+		"null secret":    {body: `{"path":"db","secret":null}`, wantErr: `missing required field "secret"`},
+		"wrapped by gateway": {
+			body:    `{"result":{"path":"db","secret":{}},"status":"ok"}`,
+			wantErr: `missing required field "path" (unexpected fields: "result", "status")`,
+		},
+		"secret not an object": {body: `{"path":"db","secret":"x"}`, wantErr: `field "secret" is string, expected object`},
+		"type not a string":    {body: `{"path":"db","secret":{},"type":1}`, wantErr: `field "type" is number, expected string`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateKVSchema([]byte(tc.body))
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrUnexpectedSchema)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestValidateKVListSchema(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		wantErr string
+	}{
+		"valid":        {body: `{"data":[{"path":"a"},{"path":"sub","type":"folder"}]}`},
+		"empty":        {body: `{"data":[]}`},
+		"missing data": {body: `{"items":[]}`, wantErr: `KV list: missing required field "data" (unexpected fields: "items")`},
+		"data object":  {body: `{"data":{}}`, wantErr: `field "data" is object, expected array`},
+		"bad item":     {body: `{"data":[{"path":"a"},{"name":"b"}]}`, wantErr: `KV list item 1: missing required field "path"`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateKVListSchema([]byte(tc.body))
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrUnexpectedSchema)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestWithResponseSchemaValidation(t *testing.T) {
+	kvs := map[string]string{"db": `{"secret":{"password":"x"}}`}
+
+	// without validation the malformed KV is decoded as-is
+	c := newTestClient(t, kvs)
+	_, err := c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+
+	c = newTestClient(t, kvs, WithResponseSchemaValidation(true))
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	assert.ErrorIs(t, err, ErrUnexpectedSchema)
+	assert.ErrorContains(t, err, `missing required field "path"`)
+}
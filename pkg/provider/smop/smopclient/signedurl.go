@@ -0,0 +1,180 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+const (
+	// defaultSignedURLTimeout bounds fetching the content of a KV from its signed URL,
+	// within the timeout of fetching the KV itself.
+	defaultSignedURLTimeout = 20 * time.Second
+	// defaultSignedURLMaxSize caps the content fetched from a signed URL at the size limit of a Kubernetes Secret.
+	defaultSignedURLMaxSize = 1 << 20
+	// maxSignedURLRefreshes is how often a KV is fetched again for a fresh signed URL when its URL expired.
+	maxSignedURLRefreshes = 2
+	// signedContentDefaultKey is the key of the KV value signed content is returned under when SMoP names none.
+	signedContentDefaultKey = "value"
+)
+
+var (
+	// ErrSignedContentTooLarge is returned for signed content larger than the limit set with WithSignedURLMaxSize.
+	ErrSignedContentTooLarge = errors.New("SMoP signed content too large")
+
+	// errSignedURLExpired is returned by fetchSignedContent when the signed URL is no longer valid.
+	errSignedURLExpired = errors.New("SMoP signed URL expired")
+)
+
+// signedContent is how SMoP returns the value of a large binary KV: instead of the value,
+// the KV holds a short-lived pre-signed URL to fetch it from object storage.
+type signedContent struct {
+	URL string `json:"url"`
+	// ExpiresAt is when the URL stops being valid, if reported.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Key is the key of the KV value the content is returned under, signedContentDefaultKey if empty.
+	Key string `json:"key,omitempty"`
+}
+
+// getKV fetches a single KV without following aliases.
+// The content of a KV returned as a signed URL is fetched from it, see fetchSignedContent,
+// and the KV is fetched again for a fresh URL when the URL expired.
+// Extra request editors apply after the Authorization header.
+func (c *SMOPClient) getKV(ctx context.Context, name string, folderPath *string, editors ...cg.RequestEditorFn) (*cg.KV, kvAttributes, error) {
+	for refresh := 0; ; refresh++ {
+		kv, attrs, err := c.fetchKV(ctx, name, folderPath, editors...)
+		if err != nil || attrs.SignedContent == nil {
+			return kv, attrs, err
+		}
+
+		content, err := c.fetchSignedContent(ctx, attrs.SignedContent)
+		if errors.Is(err, errSignedURLExpired) && refresh < maxSignedURLRefreshes {
+			log.V(1).Info("SMoP signed URL expired, fetching the secret again", "name", name, "folder", getPathString(folderPath))
+			continue
+		}
+		if err != nil {
+			return nil, kvAttributes{}, fmt.Errorf("failed to fetch content of secret %q at %q: %w", name, getPathString(folderPath), err)
+		}
+		return withSignedContent(kv, attrs.SignedContent, content), attrs, nil
+	}
+}
+
+// fetchSignedContent fetches the content a signed URL points to, within the signed URL timeout and
+// size limit. The request is sent with the content client, without the SMoP token, see newContentClient.
+// A URL past its expiry, or rejected with 401 or 403, is reported as errSignedURLExpired.
+func (c *SMOPClient) fetchSignedContent(ctx context.Context, content *signedContent) ([]byte, error) {
+	if content.ExpiresAt != nil && !time.Now().Before(*content.ExpiresAt) {
+		return nil, errSignedURLExpired
+	}
+	if err := c.checkSignedURL(content.URL); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.signedURLTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, content.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.contentClient.Do(req)
+	if err != nil {
+		// the error of the HTTP client holds the URL, and so its signature
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to fetch signed content: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, errSignedURLExpired
+	case resp.StatusCode != http.StatusOK:
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to fetch signed content", Path: redactedURL(content.URL)}
+	case resp.ContentLength > c.signedURLMaxSize:
+		return nil, fmt.Errorf("%w: %d bytes, limit is %d", ErrSignedContentTooLarge, resp.ContentLength, c.signedURLMaxSize)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.signedURLMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signed content: %w", err)
+	}
+	if int64(len(body)) > c.signedURLMaxSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrSignedContentTooLarge, c.signedURLMaxSize)
+	}
+	return body, nil
+}
+
+// newContentClient returns the HTTP client signed content is fetched with. Signed URLs point to object
+// storage rather than to the SMoP server, so none of the connection settings of the SMoP server apply:
+// the storage is verified against the system roots, no client certificate is sent, and neither the
+// transport set with WithTransport nor the host override is used. Only the TLS policy and the handshake
+// timeout are shared. Redirects are followed up to defaultMaxRedirects times, to URLs allowed by checkSignedURL.
+func (c *SMOPClient) newContentClient() *http.Client {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		transport = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = c.applyTLSPolicy(nil)
+	transport.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	if c.tlsHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = c.tlsHandshakeTimeout
+	}
+	transport.MaxResponseHeaderBytes = defaultMaxResponseHeaderBytes
+	return &http.Client{Transport: transport, CheckRedirect: c.checkContentRedirect}
+}
+
+// checkContentRedirect is the redirect policy of signed content requests: object storage may redirect
+// to another host, e.g. a regional endpoint, but only to a URL a signed URL may point to.
+func (c *SMOPClient) checkContentRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > defaultMaxRedirects {
+		return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, defaultMaxRedirects)
+	}
+	return c.checkSignedURL(req.URL.String())
+}
+
+// checkSignedURL checks that a signed URL is absolute and uses HTTPS, or HTTP if the SMoP server does.
+func (c *SMOPClient) checkSignedURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid SMoP signed URL %q: must be an absolute URL", redactedURL(rawURL))
+	}
+	if u.Scheme == "https" || u.Scheme == "http" && c.serverScheme == "http" {
+		return nil
+	}
+	return fmt.Errorf("invalid SMoP signed URL %q: must use https", redactedURL(rawURL))
+}
+
+// redactedURL returns a signed URL without its query, which holds the signature.
+func redactedURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
+
+// withSignedContent returns kv with `content` as the value under the key of the signed content.
+func withSignedContent(kv *cg.KV, signed *signedContent, content []byte) *cg.KV {
+	key := signed.Key
+	if key == "" {
+		key = signedContentDefaultKey
+	}
+	secret := make(cg.RedactedMap, len(kv.Secret)+1)
+	for k, v := range kv.Secret {
+		secret[k] = v
+	}
+	secret[key] = content
+	return &cg.KV{Path: kv.Path, Secret: secret}
+}
@@ -0,0 +1,228 @@
+package smopclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSignedURLServers starts a stub object storage serving `content` to requests signed with
+// a valid signature, and a stub SMoP server returning envelopes pointing to it. The signature
+// of the n-th envelope is valid unless expired(n). It returns the client and the number of
+// SMoP requests.
+func newSignedURLServers(t *testing.T, content string, expired func(n int64) bool, opts ...ClientOption) (*SMOPClient, *atomic.Int64) {
+	t.Helper()
+
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"), "the SMoP token must not be sent to object storage")
+		if r.URL.Query().Get("sig") != "valid" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Request has expired</Message></Error>`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(storage.Close)
+
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := requests.Add(1)
+		sig := "valid"
+		if expired(n) {
+			sig = "stale"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"path":"blob","version":2,"secret":{"format":"pkcs12"},`+
+			`"signedContent":{"url":"%s/bucket/blob?sig=%s","key":"keystore.p12"}}`, storage.URL, sig)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, opts...)
+	require.NoError(t, err)
+	return c, &requests
+}
+
+func TestGetSecretSignedURL(t *testing.T) {
+	content := "\x30\x82\x01\x00binary\x00content"
+	tests := map[string]struct {
+		expired      func(n int64) bool
+		opts         []ClientOption
+		wantErr      string
+		wantRequests int64
+	}{
+		"signed content": {
+			expired:      func(int64) bool { return false },
+			wantRequests: 1,
+		},
+		"expired once": {
+			expired:      func(n int64) bool { return n == 1 },
+			wantRequests: 2,
+		},
+		"always expired": {
+			expired:      func(int64) bool { return true },
+			wantErr:      "SMoP signed URL expired",
+			wantRequests: 1 + maxSignedURLRefreshes,
+		},
+		"too large": {
+			expired:      func(int64) bool { return false },
+			opts:         []ClientOption{WithSignedURLMaxSize(8)},
+			wantErr:      "SMoP signed content too large",
+			wantRequests: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, requests := newSignedURLServers(t, content, tc.expired, tc.opts...)
+
+			kv, md, err := c.GetSecretWithMetadata(context.Background(), "blob", nil)
+			assert.Equal(t, tc.wantRequests, requests.Load())
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				assert.NotContains(t, err.Error(), "sig=", "the signature of the URL must not be reported")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte(content), kv.Secret["keystore.p12"])
+			assert.Equal(t, "pkcs12", kv.Secret["format"])
+			assert.Equal(t, int64(2), md.Version)
+		})
+	}
+}
+
+func TestFetchSignedContent(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+		case "/chunked":
+			// no Content-Length, the size limit applies while reading
+			for range 4 {
+				_, _ = w.Write([]byte(strings.Repeat("x", 4)))
+				w.(http.Flusher).Flush()
+			}
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(storage.Close)
+
+	c, err := NewSMOPClient("http://smop.example.com/site/secrets", testToken,
+		WithSignedURLTimeout(50*time.Millisecond), WithSignedURLMaxSize(10))
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	tests := map[string]struct {
+		content *signedContent
+		wantErr string
+	}{
+		"timeout":      {content: &signedContent{URL: storage.URL + "/slow"}, wantErr: "context deadline exceeded"},
+		"too large":    {content: &signedContent{URL: storage.URL + "/chunked"}, wantErr: "SMoP signed content too large"},
+		"not found":    {content: &signedContent{URL: storage.URL + "/missing?sig=secret"}, wantErr: "HTTP 404"},
+		"expired":      {content: &signedContent{URL: storage.URL + "/slow", ExpiresAt: &past}, wantErr: errSignedURLExpired.Error()},
+		"relative URL": {content: &signedContent{URL: "/bucket/blob?sig=secret"}, wantErr: "must be an absolute URL"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.fetchSignedContent(context.Background(), tc.content)
+			require.ErrorContains(t, err, tc.wantErr)
+			assert.NotContains(t, err.Error(), "secret")
+		})
+	}
+
+	https, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken)
+	require.NoError(t, err)
+	_, err = https.fetchSignedContent(context.Background(), &signedContent{URL: storage.URL + "/blob"})
+	assert.ErrorContains(t, err, "must use https")
+}
+
+func TestContentClient(t *testing.T) {
+	var apiRequests atomic.Int64
+	withCount := WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		apiRequests.Add(1)
+		return http.DefaultTransport.RoundTrip(req)
+	}))
+	c, _ := newSignedURLServers(t, "content", func(int64) bool { return false }, withCount)
+
+	// signed content is not fetched with the transport of the SMoP server
+	kv, err := c.GetSecret(context.Background(), "blob", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("content"), kv.Secret["keystore.p12"])
+	assert.Equal(t, int64(1), apiRequests.Load())
+
+	// nor with its client certificate, CA or host override
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := tls.Certificate{Certificate: [][]byte{{0x30}}, PrivateKey: key}
+	withTLS, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+		WithTLSConfig(&tls.Config{RootCAs: x509.NewCertPool(), Certificates: []tls.Certificate{cert}}), WithHostOverride("10.0.0.12"))
+	require.NoError(t, err)
+	transport, ok := withTLS.contentClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Nil(t, transport.TLSClientConfig.RootCAs)
+	assert.Empty(t, transport.TLSClientConfig.Certificates)
+	assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+}
+
+func TestSignedContentRedirects(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, "/loop?sig=secret", http.StatusFound)
+		case "/moved":
+			http.Redirect(w, r, "/blob?sig=secret", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("content"))
+		}
+	}))
+	t.Cleanup(storage.Close)
+
+	c, err := NewSMOPClient("http://smop.example.com/site/secrets", testToken)
+	require.NoError(t, err)
+
+	content, err := c.fetchSignedContent(context.Background(), &signedContent{URL: storage.URL + "/moved"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("content"), content)
+
+	_, err = c.fetchSignedContent(context.Background(), &signedContent{URL: storage.URL + "/loop"})
+	require.ErrorIs(t, err, ErrTooManyRedirects)
+	assert.NotContains(t, err.Error(), "secret")
+
+	// a redirect from https may not leave it
+	https, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken)
+	require.NoError(t, err)
+	via := []*http.Request{httptest.NewRequest(http.MethodGet, "https://storage.example.com/blob", nil)}
+	assert.NoError(t, https.checkContentRedirect(httptest.NewRequest(http.MethodGet, "https://eu.storage.example.com/blob", nil), via))
+	err = https.checkContentRedirect(httptest.NewRequest(http.MethodGet, "http://storage.example.com/blob?sig=secret", nil), via)
+	assert.ErrorContains(t, err, "must use https")
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestWithSignedURLOptions(t *testing.T) {
+	for name, opt := range map[string]ClientOption{
+		"zero timeout":  WithSignedURLTimeout(0),
+		"zero max size": WithSignedURLMaxSize(0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, opt)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,26 +1,163 @@
 package smopclient
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/BeyondTrust/platform-secrets-manager/apiclient"
 	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 // SMOPClient represents a client for interacting with SMoP's API.
 type SMOPClient struct {
 	client *cg.ClientWithResponses
+	// raw is used for SMoP endpoints the generated client does not cover.
+	raw *cg.Client
 
 	baseURL   *url.URL
 	smopToken string
+	// tokenSource, if set, supplies the token instead of smopToken.
+	tokenSource TokenSource
+	// expectJWTToken makes NewSMOPClient check that the static token is shaped like a JWT.
+	expectJWTToken bool
+
+	impersonationSubject string
+	// requestEditors are applied to every request after the Authorization header is set.
+	requestEditors []cg.RequestEditorFn
+
+	clientOpts       []cg.ClientOption
+	pathPrefix       string
+	apiVersion       string
+	strictAPIVersion bool
+
+	// requestAPIVersion is the API version sent with every request.
+	requestAPIVersion        string
+	failOnAPIVersionMismatch bool
+	// serverAPIVersion is the API version last reported by the SMoP server.
+	serverAPIVersion atomic.Pointer[string]
+
+	followAliases bool
+	maxAliasDepth int
+
+	requestTimeout  time.Duration
+	defaultDeadline time.Duration
+	getTimeout      time.Duration
+	listTimeout     time.Duration
+
+	emptyListStatusCodes  map[int]struct{}
+	requireExistingFolder bool
+
+	deleteConcurrency int
+	fetchConcurrency  int
+	recursiveDelete   bool
+	rejectExpired     bool
+
+	// batchUnsupported is set once the SMoP server turned out not to offer the batch endpoint.
+	batchUnsupported atomic.Bool
+	// metadataUnsupported is set once the SMoP server turned out not to offer the metadata endpoint.
+	metadataUnsupported atomic.Bool
+	// folderMetadataUnsupported is set once the SMoP server turned out not to offer the folder metadata endpoint.
+	folderMetadataUnsupported atomic.Bool
+	// fieldSelectionUnsupported is set once the SMoP server turned out not to select fields of KVs.
+	fieldSelectionUnsupported atomic.Bool
+
+	walkConcurrency int
+	walkLevelDelay  time.Duration
+	walkBackoffBase time.Duration
+	walkBackoffMax  time.Duration
+	walkMaxDepth    int
+	walkMaxItems    int
+
+	validateSchema  bool
+	disableSniffing bool
+	// defaultContentType is the Content-Type assumed for responses without one, if not empty.
+	defaultContentType string
+
+	// ownedTransport is the *http.Transport built by baseTransport, nil if the client sends requests with a
+	// transport it does not own, i.e. http.DefaultTransport or a RoundTripper set with WithTransport used as is.
+	ownedTransport *http.Transport
+
+	transport           http.RoundTripper
+	tlsConfig           *tls.Config
+	tlsMinVersion       uint16
+	tlsCipherSuites     []uint16
+	allowInsecureTLS    bool
+	insecureSkipVerify  bool
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
+	tlsHandshakeTimeout time.Duration
+	// maxResponseHeaderBytes is left 0 unless set explicitly, so a transport set with WithTransport keeps its own limit.
+	maxResponseHeaderBytes int64
+	hostOverride           *hostOverride
+	// serverHost is the host of the server URL, which hostOverride applies to.
+	serverHost string
+	// serverScheme is the scheme of the server URL, which signed URLs must match unless they use HTTPS.
+	serverScheme string
+	// maxRedirects and allowCrossHostRedirects are the redirect policy of SMoP API requests, see checkRedirect.
+	maxRedirects            int
+	allowCrossHostRedirects bool
+
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	retryBudget    RetryBudget
+	beforeRetry    BeforeRetryFunc
+	dialRetries    int
+	dialRetryDelay time.Duration
+	// requestLimiter caps the requests in flight across every client sharing it.
+	requestLimiter *RequestLimiter
+
+	signedURLTimeout time.Duration
+	signedURLMaxSize int64
+	// contentClient fetches signed content from object storage, see newContentClient.
+	contentClient *http.Client
+
+	rotationPollInterval time.Duration
+	rotationTimeout      time.Duration
+
+	approvalPollInterval time.Duration
+	// approvalWait is how long a KV pending approval is waited for, 0 to not wait.
+	approvalWait time.Duration
+
+	stats *clientStats
 }
 
-// APIError represents an error response from the SMOP API
+// defaultAPIVersion is the SMoP API version sent when the version cannot be
+// determined from the API client library.
+const defaultAPIVersion = "1.0"
+
+var (
+	log = ctrl.Log.WithName("provider").WithName("smop").WithName("client")
+
+	// libraryAPIVersion returns the SMoP API version targeted by the API client library.
+	libraryAPIVersion = apiclient.APIVersion
+)
+
+var (
+	// ErrAliasCycle is returned when following an alias KV leads back to a KV already visited.
+	ErrAliasCycle = errors.New("SMoP alias cycle detected")
+	// ErrAliasDepthExceeded is returned when an alias chain is longer than the configured max depth.
+	ErrAliasDepthExceeded = errors.New("SMoP alias chain exceeds max depth")
+	// ErrBrokenAlias is returned when an alias KV points to a KV that does not exist.
+	ErrBrokenAlias = errors.New("SMoP alias points to a missing secret")
+	// ErrTruncatedResponse is returned when a list response is cut off before its end, even when listed again.
+	ErrTruncatedResponse = errors.New("SMoP response truncated")
+)
+
+// APIError represents an error response from the SMOP API.
+// Errors of the client wrap it, so callers can recover it with errors.As to handle status codes.
 type APIError struct {
 	StatusCode int
 	Message    string
@@ -31,31 +168,117 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("SMoP API error (HTTP %d): %s at path %q", e.StatusCode, e.Message, e.Path)
 }
 
-func NewSMOPClient(server, token string, opts ...cg.ClientOption) (*SMOPClient, error) {
+func NewSMOPClient(server, token string, opts ...ClientOption) (*SMOPClient, error) {
 	// validate server URL
 	if err := validateSmopServerURL(server); err != nil {
 		return nil, err
 	}
 
-	// get API version header option
-	apiVersion, err := apiclient.APIVersion()
+	c := &SMOPClient{
+		smopToken:     token,
+		followAliases: true,
+		maxAliasDepth: defaultMaxAliasDepth,
+
+		defaultDeadline: defaultDeadline,
+
+		deleteConcurrency: defaultDeleteConcurrency,
+		fetchConcurrency:  defaultFetchConcurrency,
+
+		walkConcurrency: defaultWalkConcurrency,
+		walkBackoffBase: defaultWalkBackoffBase,
+		walkBackoffMax:  defaultWalkBackoffMax,
+		walkMaxDepth:    defaultWalkMaxDepth,
+		walkMaxItems:    defaultWalkMaxItems,
+
+		retryBaseDelay: defaultRetryBaseDelay,
+		retryMaxDelay:  defaultRetryMaxDelay,
+		dialRetries:    defaultDialRetries,
+		dialRetryDelay: defaultDialRetryDelay,
+		maxRedirects:   defaultMaxRedirects,
+
+		signedURLTimeout: defaultSignedURLTimeout,
+		signedURLMaxSize: defaultSignedURLMaxSize,
+
+		rotationPollInterval: defaultRotationPollInterval,
+		rotationTimeout:      defaultRotationTimeout,
+		approvalPollInterval: defaultApprovalPollInterval,
+
+		defaultContentType: defaultResponseContentType,
+
+		stats: &clientStats{},
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.validateTLSPolicy(); err != nil {
+		return nil, err
+	}
+	if err := c.validateTLSConfig(); err != nil {
+		return nil, err
+	}
+
+	// a token source replaces the static token, which is then only used if set
+	if c.tokenSource == nil || token != "" {
+		if err := validateToken(token, c.expectJWTToken); err != nil {
+			return nil, err
+		}
+	}
+
+	server, err := prefixServerURLPath(server, c.pathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// get API version header option, preferring the explicit override
+	apiVersion := c.apiVersion
+	if apiVersion == "" {
+		apiVersion, err = libraryAPIVersion()
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get API version for SMOP client: %w", err)
+		if c.strictAPIVersion {
+			return nil, fmt.Errorf("failed to get API version for SMOP client: %w", err)
+		}
+		log.Info("failed to get API version for SMOP client, falling back to the default API version",
+			"apiVersion", defaultAPIVersion, "error", err.Error())
+		apiVersion = defaultAPIVersion
 	}
+	c.requestAPIVersion = apiVersion
 
-	allOpts := make([]cg.ClientOption, 0, len(opts)+1)
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SMOP server URL %q: %w", server, err)
+	}
+	c.serverHost = serverURL.Hostname()
+	c.serverScheme = serverURL.Scheme
+
+	transport, err := c.newTransport()
+	if err != nil {
+		return nil, err
+	}
+	c.contentClient = c.newContentClient()
+
+	// the instrumented transport comes first so an explicit WithHTTPClient still takes precedence
+	allOpts := make([]cg.ClientOption, 0, len(c.clientOpts)+2)
+	allOpts = append(allOpts, cg.WithHTTPClient(&http.Client{Transport: transport, CheckRedirect: c.checkRedirect}))
 	allOpts = append(allOpts, apiclient.WithAPIVersionHeader(apiVersion))
-	allOpts = append(allOpts, opts...)
+	allOpts = append(allOpts, c.clientOpts...)
 
 	client, err := cg.NewClientWithResponses(server, allOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SMOP API client: %w", err)
 	}
 
-	return &SMOPClient{
-		client:    client,
-		smopToken: token,
-	}, nil
+	raw, err := cg.NewClient(server, allOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SMOP API client: %w", err)
+	}
+
+	c.client = client
+	c.raw = raw
+	return c, nil
 }
 
 // BaseURL returns the base URL of the Doppler API.
@@ -64,6 +287,15 @@ func (c *SMOPClient) BaseURL() *url.URL {
 	return &u
 }
 
+// KnownAPIVersions returns the SMoP API versions this client is known to work with.
+func KnownAPIVersions() []string {
+	versions := []string{defaultAPIVersion}
+	if v, err := libraryAPIVersion(); err == nil && v != defaultAPIVersion {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
 // SetBaseURL sets the base URL for the Doppler API.
 func (c *SMOPClient) SetBaseURL(urlStr string) error {
 	baseURL, err := url.Parse(strings.TrimSuffix(urlStr, "/"))
@@ -80,113 +312,368 @@ func (c *SMOPClient) SetBaseURL(urlStr string) error {
 	return nil
 }
 
-// GetSecretByPath fetches the details for the specified secret
+// operationTimeout returns the timeout for an operation: the operation specific
+// timeout if set, else the request timeout if set, else the operation default.
+func (c *SMOPClient) operationTimeout(specific, def time.Duration) time.Duration {
+	if specific > 0 {
+		return specific
+	}
+	if c.requestTimeout > 0 {
+		return c.requestTimeout
+	}
+	return def
+}
+
+// withDefaultDeadline bounds a call spanning many requests when `ctx` has no deadline,
+// as a safety net against a misbehaving server stalling the caller forever.
+// A deadline of `ctx` is never shortened. Single requests are bounded by their operation timeout instead.
+func (c *SMOPClient) withDefaultDeadline(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.defaultDeadline <= 0 {
+		return ctx, func() {}
+	}
+	log.V(1).Info("applying default deadline to SMoP call without deadline", "operation", operation, "deadline", c.defaultDeadline)
+	return context.WithTimeout(ctx, c.defaultDeadline)
+}
+
+// GetSecret fetches the details for the specified secret.
+// Alias KVs are followed to their target unless alias following is disabled.
+// A KV past its expiry is rejected with ErrSecretExpired if configured, see WithRejectExpired.
 func (c *SMOPClient) GetSecret(ctx context.Context, name string, folderPath *string) (*cg.KV, error) {
+	kv, metadata, err := c.GetSecretWithMetadata(ctx, name, folderPath)
+	if err != nil {
+		return nil, err
+	}
+	if c.rejectExpired && metadata.Expired(time.Now()) {
+		return nil, metadata.expiredError()
+	}
+	return kv, nil
+}
+
+// GetSecretWithMetadata fetches the specified secret like GetSecret, together with its metadata.
+// For an alias KV the metadata of the alias target is returned.
+// Unlike GetSecret, a KV past its expiry is always returned, see KVMetadata.Expired.
+// The username and account of a privileged-account KV are returned in the metadata, see KVMetadata.AccountFields.
+func (c *SMOPClient) GetSecretWithMetadata(ctx context.Context, name string, folderPath *string) (*cg.KV, *KVMetadata, error) {
+	kv, attrs, err := c.getSecret(ctx, name, folderPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kv, newKVMetadata(kv.Path, attrs), nil
+}
+
+// GetSecretType returns the SMoP type of the specified secret, e.g. "tls", or an empty string if it has none.
+// Alias KVs are followed like in GetSecret, so the type of the alias target is returned.
+func (c *SMOPClient) GetSecretType(ctx context.Context, name string, folderPath *string) (string, error) {
+	_, attrs, err := c.getSecret(ctx, name, folderPath)
+	return attrs.Type, err
+}
+
+// getSecret fetches a secret and its attributes, following alias KVs unless alias following is disabled.
+// A secret pending approval is waited for, see awaitApproval.
+func (c *SMOPClient) getSecret(ctx context.Context, name string, folderPath *string) (*cg.KV, kvAttributes, error) {
+	return c.awaitApproval(ctx, func(ctx context.Context) (*cg.KV, kvAttributes, error) {
+		return c.resolveSecret(ctx, name, folderPath)
+	})
+}
+
+// resolveSecret fetches a secret and its attributes like getSecret, without waiting for approvals.
+func (c *SMOPClient) resolveSecret(ctx context.Context, name string, folderPath *string) (*cg.KV, kvAttributes, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	kv, attrs, err := c.getKV(ctx, name, folderPath)
+	if err != nil || !c.followAliases {
+		return kv, attrs, err
+	}
+
+	visited := map[string]struct{}{
+		joinKVPath(getPathString(folderPath), name): {},
+	}
+	for depth := 0; attrs.isAlias(); depth++ {
+		if depth >= c.maxAliasDepth {
+			return nil, kvAttributes{}, fmt.Errorf("%w (%d) resolving %q", ErrAliasDepthExceeded, c.maxAliasDepth, name)
+		}
+
+		targetFolder, targetName := splitKVPath(attrs.Target)
+		targetPath := joinKVPath(getPathString(targetFolder), targetName)
+		if _, ok := visited[targetPath]; ok {
+			return nil, kvAttributes{}, fmt.Errorf("%w resolving %q at %q", ErrAliasCycle, name, targetPath)
+		}
+		visited[targetPath] = struct{}{}
+
+		kv, attrs, err = c.getKV(ctx, targetName, targetFolder)
+		if err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				return nil, kvAttributes{}, fmt.Errorf("%w: %q -> %q: %w", ErrBrokenAlias, name, targetPath, err)
+			}
+			return nil, kvAttributes{}, err
+		}
+	}
+
+	return kv, attrs, nil
+}
+
+// fetchKV fetches a single KV as returned by SMoP, see getKV.
+func (c *SMOPClient) fetchKV(ctx context.Context, name string, folderPath *string, editors ...cg.RequestEditorFn) (*cg.KV, kvAttributes, error) {
+	if _, err := escapePathSegments([]string{name}); err != nil {
+		return nil, kvAttributes{}, err
+	}
+
 	params := &cg.GetKvByPathParams{
 		FolderName: folderPath,
 	}
 
-	// Build a per-request RequestEditorFn that injects Authorization header
-	reqEditor, err := getRequestEditor(c.smopToken)
+	// Build the per-request RequestEditorFn chain, starting with the Authorization header
+	reqEditor, err := c.requestEditor()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request editor: %w", err)
+		return nil, kvAttributes{}, err
 	}
 
 	// fetch secret
-	resp, err := c.client.GetKvByPath(ctx, name, params, reqEditor)
+	resp, err := c.client.GetKvByPath(ctx, name, params, append([]cg.RequestEditorFn{reqEditor}, editors...)...)
 	if err != nil {
 		path := getPathString(folderPath)
-		return nil, fmt.Errorf("failed to fetch secret %q at %q: %w", name, path, err)
+		return nil, kvAttributes{}, fmt.Errorf("failed to fetch secret %q at %q: %w", name, path, err)
 	}
 
 	// read secret
 	secretBytes, err := readResponseBody(resp)
 	if err != nil {
 		path := getPathString(folderPath)
-		return nil, fmt.Errorf("failed to read fetch secret response %q at %q: %w", name, path, err)
+		return nil, kvAttributes{}, fmt.Errorf("failed to read fetch secret response %q at %q: %w", name, path, err)
 	}
 
 	// handle secret response
 	path := getPathString(folderPath)
 	respContentType := resp.Header.Get("Content-Type")
-	isJSON := strings.Contains(respContentType, "json")
+	isJSON := c.isJSONResponse(respContentType, secretBytes)
 
 	if resp.StatusCode == http.StatusOK && isJSON {
+		if c.validateSchema {
+			if err := validateKVSchema(secretBytes); err != nil {
+				return nil, kvAttributes{}, fmt.Errorf("failed to fetch %q at %q: %w", name, path, err)
+			}
+		}
+
 		var kv cg.KV
+		var attrs kvAttributes
 
-		if err = json.Unmarshal(secretBytes, &kv); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal response from fetch %q at %q: %w", name, path, err)
+		if err = UnmarshalUseNumber(secretBytes, &kv); err != nil {
+			return nil, kvAttributes{}, fmt.Errorf("failed to unmarshal response from fetch %q at %q: %w", name, path, err)
+		}
+		if err = json.Unmarshal(secretBytes, &attrs); err != nil {
+			return nil, kvAttributes{}, fmt.Errorf("failed to unmarshal response from fetch %q at %q: %w", name, path, err)
 		}
 
-		return &kv, nil
+		return &kv, attrs, nil
 	}
 
-	fullKvPath := fmt.Sprintf("%s/%s", path, name)
+	fullKvPath := joinKVPath(path, name)
 	// Try to parse error response
 	if isJSON {
 		if err := parseAPIErrorResponse(secretBytes, fullKvPath, resp.StatusCode); err != nil {
-			return nil, err
+			return nil, kvAttributes{}, err
 		}
 	}
 
 	// Fallback error if we can't parse the response
-	return nil, createAPIError(resp.StatusCode, respContentType, fullKvPath)
+	return nil, kvAttributes{}, createAPIError(resp.StatusCode, respContentType, fullKvPath)
 }
 
-// GetSecrets fetches secrets at the specified `folderPath`
+// GetSecrets fetches secrets at the specified `folderPath`.
+// Paginated responses are followed through their `Link: <...>; rel="next"` header until the last page.
+// A nonexistent folder is listed as empty, unless WithRequireFolder is set.
 func (c *SMOPClient) GetSecrets(ctx context.Context, folderPath *string) ([]cg.KVListItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+	defer cancel()
+
+	items, _, err := c.listKVs(ctx, folderPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireFolder(ctx, folderPath, len(items) == 0); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// listKVs fetches every page of the KV list at `folderPath`.
+// The returned attributes are index-aligned with the returned items.
+func (c *SMOPClient) listKVs(ctx context.Context, folderPath *string) ([]cg.KVListItem, []kvAttributes, error) {
+	items := []cg.KVListItem{}
+	attrs := []kvAttributes{}
+	visited := map[string]struct{}{}
+
+	var next *url.URL
+	for {
+		page, pageAttrs, nextURL, err := c.getKVPage(ctx, folderPath, next)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, page...)
+		attrs = append(attrs, pageAttrs...)
+
+		if nextURL == nil {
+			return items, attrs, nil
+		}
+		if _, ok := visited[nextURL.String()]; ok {
+			return nil, nil, fmt.Errorf("failed to list secrets at %q: pagination loop at %q", getPathString(folderPath), nextURL.Redacted())
+		}
+		visited[nextURL.String()] = struct{}{}
+		next = nextURL
+	}
+}
+
+// getKVPage fetches a single page of the KV list at `folderPath`.
+// The first page is requested when `pageURL` is nil. The returned URL is the next page, if any.
+// The returned attributes are index-aligned with the returned items.
+// A page cut off mid-stream, e.g. by a gateway dropping the connection, is fetched once more
+// before it is reported as ErrTruncatedResponse.
+func (c *SMOPClient) getKVPage(ctx context.Context, folderPath *string, pageURL *url.URL) ([]cg.KVListItem, []kvAttributes, *url.URL, error) {
+	items, attrs, nextURL, err := c.fetchKVPage(ctx, folderPath, pageURL)
+	if errors.Is(err, ErrTruncatedResponse) && ctx.Err() == nil {
+		log.V(1).Info("SMoP list response truncated, listing again", "path", getPathString(folderPath), "error", err.Error())
+		items, attrs, nextURL, err = c.fetchKVPage(ctx, folderPath, pageURL)
+	}
+	return items, attrs, nextURL, err
+}
+
+// fetchKVPage fetches a single page of the KV list as returned by SMoP, see getKVPage.
+func (c *SMOPClient) fetchKVPage(ctx context.Context, folderPath *string, pageURL *url.URL) ([]cg.KVListItem, []kvAttributes, *url.URL, error) {
 	params := &cg.GetKvsParams{
 		Path: folderPath,
 	}
 
-	// Build a per-request RequestEditorFn that injects Authorization header
-	reqEditor, err := getRequestEditor(c.smopToken)
+	// Build the per-request RequestEditorFn chain, starting with the Authorization header
+	reqEditor, err := c.requestEditor()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request editor: %w", err)
+		return nil, nil, nil, err
+	}
+	reqEditors := []cg.RequestEditorFn{reqEditor}
+	if pageURL != nil {
+		reqEditors = append(reqEditors, setRequestURL(pageURL))
 	}
 
 	// fetch kv list
-	resp, err := c.client.GetKvs(ctx, params, reqEditor)
+	resp, err := c.client.GetKvs(ctx, params, reqEditors...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch secrets: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch secrets: %w", err)
 	}
 
 	// read kv list
 	listBytes, err := readResponseBody(resp)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, nil, fmt.Errorf("%w: failed to read list secrets response at %q: %w", ErrTruncatedResponse, getPathString(folderPath), err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read list secrets response: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to read list secrets response: %w", err)
+	}
+
+	// some gateways report an empty folder with a dedicated status code
+	if _, ok := c.emptyListStatusCodes[resp.StatusCode]; ok {
+		return []cg.KVListItem{}, []kvAttributes{}, nil, nil
 	}
 
 	// handle list response
 	path := getPathString(folderPath)
 	respContentType := resp.Header.Get("Content-Type")
-	isJSON := strings.Contains(respContentType, "json")
+	isJSON := c.isJSONResponse(respContentType, listBytes)
 
 	if resp.StatusCode == http.StatusOK && isJSON {
+		if isTruncatedJSON(listBytes) {
+			return nil, nil, nil, fmt.Errorf("%w: list secrets response at %q ends after %d bytes", ErrTruncatedResponse, path, len(listBytes))
+		}
+		if c.validateSchema {
+			if err := validateKVListSchema(listBytes); err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to list secrets at %q: %w", path, err)
+			}
+		}
+
 		var dest struct {
 			Data  []cg.KVListItem `json:"data"`
 			Error string          `json:"error,omitempty"`
 		}
+		var destAttrs struct {
+			Data []kvAttributes `json:"data"`
+		}
 		if err = json.Unmarshal(listBytes, &dest); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal response from list secrets at %q: %w", path, err)
+			return nil, nil, nil, fmt.Errorf("failed to unmarshal response from list secrets at %q: %w", path, err)
+		}
+		if err = json.Unmarshal(listBytes, &destAttrs); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to unmarshal response from list secrets at %q: %w", path, err)
 		}
 
-		// Empty folder is valid - return empty list
-		if len(dest.Data) == 0 {
-			return []cg.KVListItem{}, nil
+		nextURL, err := nextPageURL(resp)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to paginate list secrets at %q: %w", path, err)
 		}
 
-		return dest.Data, nil
+		return dest.Data, destAttrs.Data, nextURL, nil
 	}
 
 	// Try to parse error response
 	if isJSON {
 		if err := parseAPIErrorResponse(listBytes, path, resp.StatusCode); err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
 	// Fallback error if we can't parse the response
-	return nil, createAPIError(resp.StatusCode, respContentType, path)
+	return nil, nil, nil, createAPIError(resp.StatusCode, respContentType, path)
+}
+
+// doRaw sends a request for an endpoint the generated client does not cover.
+// The request goes through the same HTTP client and request editors as the generated client.
+// `query` and `body` are optional; `body` is sent as JSON.
+// Each path segment is escaped, so names with characters such as spaces, '+', '%' or '/'
+// address exactly one segment instead of a different path.
+func (c *SMOPClient) doRaw(ctx context.Context, method string, query url.Values, body any, pathSegments ...string) (*http.Response, error) {
+	serverURL, err := url.Parse(c.raw.Server)
+	if err != nil {
+		return nil, err
+	}
+	escaped, err := escapePathSegments(pathSegments)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := serverURL.JoinPath(escaped...)
+	if len(query) > 0 {
+		reqURL.RawQuery = query.Encode()
+	}
+
+	var reqBody io.Reader
+	var bodyBytes []byte
+	if body != nil {
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Build the per-request RequestEditorFn chain, starting with the Authorization header
+	reqEditor, err := c.requestEditor()
+	if err != nil {
+		return nil, err
+	}
+	for _, edit := range c.raw.RequestEditors {
+		if err := edit(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	if err := reqEditor(ctx, req); err != nil {
+		return nil, err
+	}
+	logWriteRequest(req, len(bodyBytes))
 
+	return c.raw.Client.Do(req)
 }
@@ -0,0 +1,790 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+const testToken = "test-token"
+
+// newTestClient starts a stub SMoP server which answers each KV name with
+// the matching JSON body, and returns a client pointing at it.
+func newTestClient(t testing.TB, kvs map[string]string, opts ...ClientOption) *SMOPClient {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, ok := kvs[path.Base(r.URL.Path)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func BenchmarkGetSecret(b *testing.B) {
+	c := newTestClient(b, map[string]string{
+		"db": `{"path":"db","secret":{"user":"app","password":"s3cr3t","port":5432}}`,
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		kv, err := c.GetSecret(context.Background(), "db", nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if kv.Secret["password"] != "s3cr3t" {
+			b.Fatalf("got secret %v", kv.Secret)
+		}
+	}
+}
+
+func BenchmarkGetSecrets(b *testing.B) {
+	const size = 100
+	items := make([]string, 0, size)
+	for i := range size {
+		items = append(items, fmt.Sprintf(`{"path":"kv%d","updatedAt":"2026-10-14T09:00:00Z"}`, i))
+	}
+	// the list endpoint is /kv, the stub answers it like a KV named "kv"
+	c := newTestClient(b, map[string]string{"kv": fmt.Sprintf(`{"data":[%s]}`, strings.Join(items, ","))})
+	apps := "apps"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		list, err := c.GetSecrets(context.Background(), &apps)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(list) != size {
+			b.Fatalf("listed %d KVs, want %d", len(list), size)
+		}
+	}
+}
+
+func TestGetSecretAlias(t *testing.T) {
+	kvs := map[string]string{
+		"db":      `{"path":"db","secret":{"password":"s3cr3t"}}`,
+		"db-link": `{"path":"db-link","type":"alias","target":"apps/db","secret":{}}`,
+		"hop-1":   `{"path":"hop-1","type":"alias","target":"apps/db-link","secret":{}}`,
+		"cycle-a": `{"path":"cycle-a","type":"alias","target":"apps/cycle-b","secret":{}}`,
+		"cycle-b": `{"path":"cycle-b","type":"alias","target":"apps/cycle-a","secret":{}}`,
+		"broken":  `{"path":"broken","type":"alias","target":"apps/missing","secret":{}}`,
+	}
+	folder := "apps"
+
+	tests := map[string]struct {
+		name     string
+		opts     []ClientOption
+		wantErr  error
+		wantData map[string]any
+	}{
+		"plain secret": {
+			name:     "db",
+			wantData: map[string]any{"password": "s3cr3t"},
+		},
+		"follows alias": {
+			name:     "db-link",
+			wantData: map[string]any{"password": "s3cr3t"},
+		},
+		"follows alias chain": {
+			name:     "hop-1",
+			wantData: map[string]any{"password": "s3cr3t"},
+		},
+		"alias following disabled": {
+			name:     "db-link",
+			opts:     []ClientOption{WithFollowAliases(false)},
+			wantData: map[string]any{},
+		},
+		"alias chain exceeds max depth": {
+			name:    "hop-1",
+			opts:    []ClientOption{WithMaxAliasDepth(1)},
+			wantErr: ErrAliasDepthExceeded,
+		},
+		"alias cycle": {
+			name:    "cycle-a",
+			wantErr: ErrAliasCycle,
+		},
+		"broken alias": {
+			name:    "broken",
+			wantErr: ErrBrokenAlias,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, kvs, tc.opts...)
+			kv, err := c.GetSecret(context.Background(), tc.name, &folder)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantData, map[string]any(kv.Secret))
+		})
+	}
+}
+
+func TestGetSecretAccountFields(t *testing.T) {
+	kvs := map[string]string{
+		"db-admin":   `{"path":"db-admin","type":"account","username":"sa","account":"sa@db.internal","secret":{"password":"s3cr3t"}}`,
+		"overridden": `{"path":"overridden","username":"sa","secret":{"username":"dba","password":"s3cr3t"}}`,
+		"alias":      `{"path":"alias","type":"alias","target":"apps/db-admin","secret":{}}`,
+		"plain":      `{"path":"plain","secret":{"password":"s3cr3t"}}`,
+	}
+	c := newTestClient(t, kvs)
+	folder := "apps"
+
+	tests := map[string]struct {
+		wantSecret cg.RedactedMap
+		wantFields map[string]string
+	}{
+		"db-admin": {
+			wantSecret: cg.RedactedMap{"password": "s3cr3t"},
+			wantFields: map[string]string{"username": "sa", "account": "sa@db.internal"},
+		},
+		"overridden": {
+			wantSecret: cg.RedactedMap{"username": "dba", "password": "s3cr3t"},
+			wantFields: map[string]string{"username": "sa"},
+		},
+		"alias": {
+			wantSecret: cg.RedactedMap{"password": "s3cr3t"},
+			wantFields: map[string]string{"username": "sa", "account": "sa@db.internal"},
+		},
+		"plain": {
+			wantSecret: cg.RedactedMap{"password": "s3cr3t"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the account is reported in the metadata, the secret is left as stored
+			kv, metadata, err := c.GetSecretWithMetadata(context.Background(), name, &folder)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantSecret, kv.Secret)
+			assert.Equal(t, tc.wantFields, metadata.AccountFields())
+		})
+	}
+}
+
+func TestGetSecretExpiry(t *testing.T) {
+	expired := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	nearExpiry := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	kvs := map[string]string{
+		"expired":     `{"path":"expired","expiresAt":"` + expired.Format(time.RFC3339) + `","secret":{"password":"s3cr3t"}}`,
+		"near-expiry": `{"path":"near-expiry","expiresAt":"` + nearExpiry.Format(time.RFC3339) + `","secret":{"password":"s3cr3t"}}`,
+		"alias":       `{"path":"alias","type":"alias","target":"apps/expired","secret":{}}`,
+		"plain":       `{"path":"plain","secret":{"password":"s3cr3t"}}`,
+	}
+	folder := "apps"
+
+	tests := map[string]struct {
+		rejectExpired bool
+		wantExpiresAt *time.Time
+		wantErr       error
+	}{
+		"expired":              {wantExpiresAt: &expired},
+		"expired/rejected":     {rejectExpired: true, wantExpiresAt: &expired, wantErr: ErrSecretExpired},
+		"near-expiry":          {wantExpiresAt: &nearExpiry},
+		"near-expiry/rejected": {rejectExpired: true, wantExpiresAt: &nearExpiry},
+		"alias/rejected":       {rejectExpired: true, wantExpiresAt: &expired, wantErr: ErrSecretExpired},
+		"plain/rejected":       {rejectExpired: true},
+		"alias":                {wantExpiresAt: &expired},
+		"plain":                {},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, kvs, WithRejectExpired(tc.rejectExpired))
+			key := strings.Split(name, "/")[0]
+			kv, err := c.GetSecret(context.Background(), key, &folder)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, kv)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "s3cr3t", kv.Secret["password"])
+			}
+
+			kv, metadata, err := c.GetSecretWithMetadata(context.Background(), key, &folder)
+			require.NoError(t, err, "the metadata variant must return expired secrets")
+			assert.Equal(t, "s3cr3t", kv.Secret["password"])
+			assert.Equal(t, tc.wantExpiresAt, metadata.ExpiresAt)
+		})
+	}
+}
+
+func TestWithBaseURLPathPrefix(t *testing.T) {
+	tests := map[string]struct {
+		server string
+		prefix string
+	}{
+		"plain prefix": {
+			server: "/site-id/secrets",
+			prefix: "/smop/v1",
+		},
+		"prefix and server with extra slashes": {
+			server: "/site-id/secrets/",
+			prefix: "smop/v1/",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotPath, gotFolder string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotFolder = r.URL.Query().Get("folderName")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+			}))
+			defer srv.Close()
+
+			c, err := NewSMOPClient(srv.URL+tc.server, testToken, WithBaseURLPathPrefix(tc.prefix))
+			require.NoError(t, err)
+
+			folder := "apps/prod"
+			_, err = c.GetSecret(context.Background(), "db", &folder)
+			require.NoError(t, err)
+
+			assert.True(t, strings.HasPrefix(gotPath, "/smop/v1/site-id/secrets/"), "unexpected request path %q", gotPath)
+			assert.True(t, strings.HasSuffix(gotPath, "/db"), "unexpected request path %q", gotPath)
+			assert.NotContains(t, gotPath, "//")
+			assert.Equal(t, folder, gotFolder)
+		})
+	}
+}
+
+func TestOperationTimeout(t *testing.T) {
+	tests := map[string]struct {
+		opts     []ClientOption
+		wantGet  time.Duration
+		wantList time.Duration
+	}{
+		"defaults": {
+			wantGet:  defaultGetTimeout,
+			wantList: defaultListTimeout,
+		},
+		"request timeout applies to all operations": {
+			opts:     []ClientOption{WithRequestTimeout(time.Minute)},
+			wantGet:  time.Minute,
+			wantList: time.Minute,
+		},
+		"operation timeout takes precedence over request timeout": {
+			opts:     []ClientOption{WithRequestTimeout(time.Minute), WithListTimeout(5 * time.Minute)},
+			wantGet:  time.Minute,
+			wantList: 5 * time.Minute,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, nil, tc.opts...)
+			assert.Equal(t, tc.wantGet, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+			assert.Equal(t, tc.wantList, c.operationTimeout(c.listTimeout, defaultListTimeout))
+		})
+	}
+}
+
+func TestGetSecretTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewSMOPClient(srv.URL, testToken, WithGetTimeout(10*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = c.GetSecrets(context.Background(), nil)
+	assert.NoError(t, err)
+}
+
+func TestDefaultDeadline(t *testing.T) {
+	tree := &syntheticTree{depth: 2, fanout: 1, kvs: 1}
+
+	t.Run("context without deadline", func(t *testing.T) {
+		c := newWalkTestClient(t, tree, WithDefaultDeadline(50*time.Millisecond), WithWalkLevelDelay(time.Minute))
+
+		start := time.Now()
+		_, err := c.WalkSecrets(context.Background(), nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("context deadline is not shortened", func(t *testing.T) {
+		c := newTestClient(t, nil, WithDefaultDeadline(time.Millisecond))
+		want := time.Now().Add(time.Hour)
+		ctx, cancel := context.WithDeadline(context.Background(), want)
+		defer cancel()
+
+		ctx, cancelDefault := c.withDefaultDeadline(ctx, "test")
+		defer cancelDefault()
+		got, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c := newTestClient(t, nil, WithDefaultDeadline(0))
+		ctx, cancel := c.withDefaultDeadline(context.Background(), "test")
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := NewSMOPClient("https://smop.example.com", testToken, WithDefaultDeadline(-time.Second))
+		assert.ErrorContains(t, err, "invalid SMoP default deadline")
+	})
+}
+
+func TestGetSecretsEmptyListStatusCodes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("path") {
+		case "empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"folder not found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewSMOPClient(srv.URL, testToken, WithEmptyListStatusCodes(http.StatusNoContent))
+	require.NoError(t, err)
+
+	empty := "empty"
+	items, err := c.GetSecrets(context.Background(), &empty)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	missing := "missing"
+	_, err = c.GetSecrets(context.Background(), &missing)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestGetSecretsTruncated(t *testing.T) {
+	const list = `{"data":[{"path":"db"},{"path":"token"}]}`
+	truncated := list[:20]
+
+	tests := map[string]struct {
+		// bodies are the bodies of the successive responses, the last one is repeated
+		bodies []string
+		// cut declares a longer Content-Length than sent, so the connection ends mid-body
+		cut          bool
+		wantErr      error
+		wantRequests int64
+	}{
+		"complete": {
+			bodies:       []string{list},
+			wantRequests: 1,
+		},
+		"truncated once": {
+			bodies:       []string{truncated, list},
+			wantRequests: 2,
+		},
+		"always truncated": {
+			bodies:       []string{truncated},
+			wantErr:      ErrTruncatedResponse,
+			wantRequests: 2,
+		},
+		"connection dropped mid-body": {
+			bodies:       []string{truncated},
+			cut:          true,
+			wantErr:      ErrTruncatedResponse,
+			wantRequests: 2,
+		},
+		"invalid JSON is not listed again": {
+			bodies:       []string{`{"data":[}`},
+			wantRequests: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := int(requests.Add(1))
+				body := tc.bodies[min(n, len(tc.bodies))-1]
+				w.Header().Set("Content-Type", "application/json")
+				if tc.cut {
+					w.Header().Set("Content-Length", strconv.Itoa(len(list)))
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+			require.NoError(t, err)
+
+			items, err := c.GetSecrets(context.Background(), nil)
+			assert.Equal(t, tc.wantRequests, requests.Load())
+			switch {
+			case tc.wantErr != nil:
+				require.ErrorIs(t, err, tc.wantErr)
+				assert.ErrorContains(t, err, "SMoP response truncated")
+			case tc.wantRequests == 1 && tc.bodies[0] != list:
+				require.Error(t, err)
+				assert.NotErrorIs(t, err, ErrTruncatedResponse)
+			default:
+				require.NoError(t, err)
+				assert.Len(t, items, 2)
+			}
+		})
+	}
+}
+
+func TestValidateEmptyListStatusCodes(t *testing.T) {
+	assert.NoError(t, ValidateEmptyListStatusCodes([]int{http.StatusNoContent, 209, http.StatusGone}))
+
+	for _, code := range []int{http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden,
+		http.StatusTooManyRequests, http.StatusMovedPermanently, http.StatusBadGateway} {
+		assert.Error(t, ValidateEmptyListStatusCodes([]int{code}), "status code %d", code)
+	}
+}
+
+func TestNewSMOPClientAPIVersionFallback(t *testing.T) {
+	orig := libraryAPIVersion
+	t.Cleanup(func() { libraryAPIVersion = orig })
+	libraryAPIVersion = func() (string, error) { return "", errors.New("version unavailable") }
+
+	var gotHeaders http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewSMOPClient(srv.URL, testToken, WithStrictAPIVersion(true))
+	assert.ErrorContains(t, err, "failed to get API version")
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	_, err = c.GetSecrets(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, headerValueSent(gotHeaders, defaultAPIVersion), "default API version header not sent: %v", gotHeaders)
+}
+
+// headerValueSent reports whether any request header carries the value.
+// The API version header name is owned by the API client library.
+func headerValueSent(headers http.Header, value string) bool {
+	for _, values := range headers {
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestWithAPIVersion(t *testing.T) {
+	var gotHeaders http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	// the override is used as-is, even when the library version is unavailable
+	orig := libraryAPIVersion
+	t.Cleanup(func() { libraryAPIVersion = orig })
+	libraryAPIVersion = func() (string, error) { return "", errors.New("version unavailable") }
+
+	c, err := NewSMOPClient(srv.URL, testToken, WithAPIVersion("2099-01-01"), WithStrictAPIVersion(true))
+	require.NoError(t, err)
+
+	_, err = c.GetSecrets(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, headerValueSent(gotHeaders, "2099-01-01"), "API version override header not sent: %v", gotHeaders)
+}
+
+func TestContextWithTokenOverride(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		ctx      context.Context
+		wantAuth string
+	}{
+		"client token": {
+			ctx:      context.Background(),
+			wantAuth: "Bearer " + testToken,
+		},
+		"override": {
+			ctx:      ContextWithTokenOverride(context.Background(), "other-token"),
+			wantAuth: "Bearer other-token",
+		},
+		"empty override": {
+			ctx:      ContextWithTokenOverride(context.Background(), ""),
+			wantAuth: "Bearer " + testToken,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.GetSecret(tc.ctx, "db", nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAuth, gotAuth)
+		})
+	}
+}
+
+func TestGetSecretType(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"cert":      `{"path":"cert","type":"tls","secret":{}}`,
+		"cert-link": `{"path":"cert-link","type":"alias","target":"apps/cert","secret":{}}`,
+		"plain":     `{"path":"plain","secret":{}}`,
+	})
+	folder := "apps"
+
+	for name, want := range map[string]string{"cert": "tls", "cert-link": "tls", "plain": ""} {
+		got, err := c.GetSecretType(context.Background(), name, &folder)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, name)
+	}
+}
+
+func TestGetSecretContentTypeSniffing(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		body        string
+		opts        []ClientOption
+		wantErr     bool
+	}{
+		"JSON content type": {
+			contentType: "application/json; charset=utf-8",
+			body:        `{"path":"db","secret":{"password":"s3cr3t"}}`,
+		},
+		"missing content type with JSON body": {
+			body: `{"path":"db","secret":{"password":"s3cr3t"}}`,
+		},
+		"generic content type with JSON body": {
+			contentType: "application/octet-stream",
+			body:        ` {"path":"db","secret":{"password":"s3cr3t"}}`,
+		},
+		"missing content type with non-JSON body": {
+			body:    `password=s3cr3t`,
+			wantErr: true,
+		},
+		"generic content type with truncated JSON body": {
+			contentType: "application/octet-stream",
+			body:        `{"path":"db","secret":{`,
+			wantErr:     true,
+		},
+		"other content type with JSON body": {
+			contentType: "text/html",
+			body:        `{"path":"db","secret":{"password":"s3cr3t"}}`,
+			wantErr:     true,
+		},
+		"missing content type with default content type and sniffing disabled": {
+			body: `{"path":"db","secret":{"password":"s3cr3t"}}`,
+			opts: []ClientOption{WithContentTypeSniffing(false)},
+		},
+		"missing content type without default content type": {
+			body: `{"path":"db","secret":{"password":"s3cr3t"}}`,
+			opts: []ClientOption{WithDefaultResponseContentType("")},
+		},
+		"missing content type with non-JSON default content type": {
+			body:    `{"path":"db","secret":{"password":"s3cr3t"}}`,
+			opts:    []ClientOption{WithDefaultResponseContentType("text/plain"), WithContentTypeSniffing(false)},
+			wantErr: true,
+		},
+		"strict": {
+			body:    `{"path":"db","secret":{"password":"s3cr3t"}}`,
+			opts:    []ClientOption{WithDefaultResponseContentType(""), WithContentTypeSniffing(false)},
+			wantErr: true,
+		},
+		"generic content type with sniffing disabled": {
+			contentType: "application/octet-stream",
+			body:        `{"path":"db","secret":{"password":"s3cr3t"}}`,
+			opts:        []ClientOption{WithContentTypeSniffing(false)},
+			wantErr:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				// keep net/http from detecting a Content-Type itself
+				w.Header()["Content-Type"] = nil
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, tc.opts...)
+			require.NoError(t, err)
+
+			kv, err := c.GetSecret(context.Background(), "db", nil)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "s3cr3t", kv.Secret["password"])
+		})
+	}
+}
+
+func TestWithDefaultResponseContentType(t *testing.T) {
+	c, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", c.defaultContentType)
+
+	c, err = NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithDefaultResponseContentType("application/vnd.smop+json"))
+	require.NoError(t, err)
+	assert.Equal(t, "application/vnd.smop+json", c.defaultContentType)
+
+	_, err = NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithDefaultResponseContentType("application/"))
+	assert.ErrorContains(t, err, "invalid SMoP default response content type")
+}
+
+func TestAPIErrorAs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path.Base(r.URL.Path) {
+		case "denied", "kv":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"access denied"}`))
+		case "gateway":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`<html>bad gateway</html>`))
+		case "link":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"path":"link","type":"alias","target":"apps/missing","secret":{}}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithMaxRetries(0))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		call       func() error
+		wantStatus int
+		wantIs     error
+	}{
+		"error response": {
+			call: func() error {
+				_, err := c.GetSecret(ctx, "denied", nil)
+				return err
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		"unexpected response": {
+			call: func() error {
+				_, err := c.GetSecret(ctx, "gateway", nil)
+				return err
+			},
+			wantStatus: http.StatusBadGateway,
+		},
+		"broken alias": {
+			call: func() error {
+				_, err := c.GetSecret(ctx, "link", nil)
+				return err
+			},
+			wantStatus: http.StatusNotFound,
+			wantIs:     ErrBrokenAlias,
+		},
+		"with metadata": {
+			call: func() error {
+				_, _, err := c.GetSecretWithMetadata(ctx, "missing", nil)
+				return err
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		"list": {
+			call: func() error {
+				_, err := c.GetSecrets(ctx, nil)
+				return err
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		"wrapped by the caller": {
+			call: func() error {
+				_, err := c.GetSecret(ctx, "denied", nil)
+				return fmt.Errorf("failed to get secret: %w", err)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.call()
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tc.wantStatus, apiErr.StatusCode)
+			if tc.wantIs != nil {
+				assert.ErrorIs(t, err, tc.wantIs)
+			}
+		})
+	}
+}
+
+func TestGetSecretNumbers(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"db":      `{"path":"db","secret":{"id":12345678901234567891,"rate":0.10000000000000000555}}`,
+		"trailer": `{"path":"trailer","secret":{}}}`,
+	})
+
+	kv, err := c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("12345678901234567891"), kv.Secret["id"])
+	assert.Equal(t, json.Number("0.10000000000000000555"), kv.Secret["rate"])
+
+	_, err = c.GetSecret(context.Background(), "trailer", nil)
+	assert.ErrorContains(t, err, "failed to unmarshal")
+}
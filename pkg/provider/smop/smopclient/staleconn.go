@@ -0,0 +1,48 @@
+package smopclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// defaultIdleConnTimeout is how long an idle connection to the SMoP server is kept open.
+// It stays below the idle timeout of common load balancers and NAT gateways (60s and more),
+// so connections are closed by the client before an intermediary silently drops them.
+const defaultIdleConnTimeout = 50 * time.Second
+
+// staleConnTransport retries a GET or HEAD request once, right away, when it failed on a reused
+// connection which was already dropped by the server or an intermediary, e.g. with "connection reset by peer".
+// Unlike the retries of retryTransport it applies to every client, without backoff, and never to writes.
+type staleConnTransport struct {
+	base http.RoundTripper
+}
+
+func (t *staleConnTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead || req.Body != nil && req.Body != http.NoBody {
+		return t.base.RoundTrip(req)
+	}
+
+	var reused atomic.Bool
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { reused.Store(info.Reused) },
+	}
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err == nil || !reused.Load() || !isStaleConnError(err) || req.Context().Err() != nil {
+		return resp, err
+	}
+
+	log.V(1).Info("retrying SMoP request on a new connection, the reused one was dropped",
+		"method", req.Method, "path", req.URL.Path, "error", err.Error())
+	return t.base.RoundTrip(req)
+}
+
+// isStaleConnError reports whether err is caused by a connection closed by the peer.
+func isStaleConnError(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,150 @@
+package smopclient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResetOnReuseServer serves every request with a keep-alive response, but resets a connection
+// on its second request, like a load balancer which dropped the connection while it was idle.
+// It returns the server URL and the number of requests received, including the reset ones.
+func newResetOnReuseServer(t *testing.T) (string, *atomic.Int64) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	var requests atomic.Int64
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				br := bufio.NewReader(conn)
+				for served := 0; ; served++ {
+					req, err := http.ReadRequest(br)
+					if err != nil {
+						return
+					}
+					_, _ = io.Copy(io.Discard, req.Body)
+					requests.Add(1)
+					if served == 1 {
+						_ = conn.(*net.TCPConn).SetLinger(0)
+						return
+					}
+					body := `{"path":"db","secret":{"password":"s3cr3t"}}`
+					_, _ = fmt.Fprintf(conn, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+				}
+			}()
+		}
+	}()
+	return "http://" + ln.Addr().String(), &requests
+}
+
+func TestStaleConnectionRecovery(t *testing.T) {
+	t.Run("get", func(t *testing.T) {
+		serverURL, requests := newResetOnReuseServer(t)
+		c, err := NewSMOPClient(serverURL, testToken)
+		require.NoError(t, err)
+
+		for range 3 {
+			kv, err := c.GetSecret(context.Background(), "db", nil)
+			require.NoError(t, err, "a GET on a dropped connection must be retried on a new one")
+			assert.Equal(t, "s3cr3t", kv.Secret["password"])
+		}
+		assert.Equal(t, int64(5), requests.Load(), "every request after the first reused a dropped connection")
+	})
+
+	t.Run("write", func(t *testing.T) {
+		serverURL, requests := newResetOnReuseServer(t)
+		c, err := NewSMOPClient(serverURL, testToken)
+		require.NoError(t, err)
+
+		_, err = c.GetSecret(context.Background(), "db", nil)
+		require.NoError(t, err)
+		err = c.SetSecret(context.Background(), "db", nil, map[string]any{"password": "s3cr3t"}, nil)
+		assert.Error(t, err, "a write must not be replayed")
+		assert.Equal(t, int64(2), requests.Load())
+	})
+}
+
+func TestStaleConnTransport(t *testing.T) {
+	tests := map[string]struct {
+		method       string
+		reused       bool
+		err          error
+		wantAttempts int
+	}{
+		"reset reused connection": {
+			method:       http.MethodGet,
+			reused:       true,
+			err:          syscall.ECONNRESET,
+			wantAttempts: 2,
+		},
+		"closed reused connection": {
+			method:       http.MethodHead,
+			reused:       true,
+			err:          io.EOF,
+			wantAttempts: 2,
+		},
+		"new connection": {
+			method:       http.MethodGet,
+			err:          syscall.ECONNRESET,
+			wantAttempts: 1,
+		},
+		"other error": {
+			method:       http.MethodGet,
+			reused:       true,
+			err:          syscall.ECONNREFUSED,
+			wantAttempts: 1,
+		},
+		"write": {
+			method:       http.MethodDelete,
+			reused:       true,
+			err:          syscall.ECONNRESET,
+			wantAttempts: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts int
+			transport := &staleConnTransport{base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				if attempts > 1 {
+					return httptest.NewRecorder().Result(), nil
+				}
+				if trace := httptrace.ContextClientTrace(req.Context()); trace != nil && trace.GotConn != nil {
+					trace.GotConn(httptrace.GotConnInfo{Reused: tc.reused})
+				}
+				return nil, &net.OpError{Op: "read", Net: "tcp", Err: tc.err}
+			})}
+
+			req, err := http.NewRequest(tc.method, "https://smop.example.com/kv/db", http.NoBody)
+			require.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			assert.Equal(t, tc.wantAttempts, attempts)
+			assert.Equal(t, tc.wantAttempts == 1, err != nil && strings.Contains(err.Error(), tc.err.Error()))
+		})
+	}
+}
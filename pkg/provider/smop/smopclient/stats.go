@@ -0,0 +1,56 @@
+package smopclient
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Stats are counters of the requests a SMOPClient sent to the SMoP server, for debugging and tests.
+// Requests sent with an HTTP client set through WithClientGenOptions are not counted.
+// They are cumulative over the lifetime of the client and never reset; compare two snapshots
+// returned by SMOPClient.Stats to count the requests in between.
+type Stats struct {
+	// Requests is the number of HTTP requests sent, including retries.
+	Requests int64
+	// Retries is the number of requests which retried a failed request.
+	Retries int64
+	// Errors is the number of requests which failed, or were answered with a 4xx or 5xx status.
+	Errors int64
+}
+
+// clientStats collects the Stats of a client. A nil *clientStats collects nothing.
+type clientStats struct {
+	requests atomic.Int64
+	retries  atomic.Int64
+	errors   atomic.Int64
+}
+
+// observe counts a single request and its outcome.
+func (s *clientStats) observe(resp *http.Response, err error) {
+	if s == nil {
+		return
+	}
+	s.requests.Add(1)
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		s.errors.Add(1)
+	}
+}
+
+// observeRetry counts a retried request.
+func (s *clientStats) observeRetry() {
+	if s == nil {
+		return
+	}
+	s.retries.Add(1)
+}
+
+// Stats returns a snapshot of the request counters of the client.
+// The counters are updated atomically, but not as a group: a snapshot taken while
+// requests are in flight may count a request, but not yet its outcome.
+func (c *SMOPClient) Stats() Stats {
+	return Stats{
+		Requests: c.stats.requests.Load(),
+		Retries:  c.stats.retries.Load(),
+		Errors:   c.stats.errors.Load(),
+	}
+}
@@ -0,0 +1,44 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	var dbRequests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if path.Base(r.URL.Path) != "db" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		if dbRequests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, testToken, WithMaxRetries(2), WithRetryBackoff(time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, c.Stats())
+
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Requests: 2, Retries: 1, Errors: 1}, c.Stats())
+
+	_, err = c.GetSecret(context.Background(), "gone", nil)
+	require.Error(t, err)
+	assert.Equal(t, Stats{Requests: 3, Retries: 1, Errors: 2}, c.Stats(), "stats are cumulative")
+}
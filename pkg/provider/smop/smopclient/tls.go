@@ -0,0 +1,92 @@
+package smopclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// defaultTLSMinVersion is the minimum TLS version accepted from the SMoP server by default.
+	defaultTLSMinVersion = tls.VersionTLS12
+	// defaultTLSHandshakeTimeout bounds the TLS handshake with the SMoP server, so a stalled handshake
+	// fails well before the request timeout.
+	defaultTLSHandshakeTimeout = 5 * time.Second
+)
+
+// isKnownTLSVersion reports whether version is a TLS version supported by crypto/tls.
+func isKnownTLSVersion(version uint16) bool {
+	switch version {
+	case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+		return true
+	}
+	return false
+}
+
+// cipherSuite returns the crypto/tls cipher suite with the given ID, or nil if it is unknown.
+func cipherSuite(id uint16) *tls.CipherSuite {
+	for _, suite := range slices.Concat(tls.CipherSuites(), tls.InsecureCipherSuites()) {
+		if suite.ID == id {
+			return suite
+		}
+	}
+	return nil
+}
+
+// validateTLSPolicy refuses a TLS min version below the default and insecure cipher suites,
+// unless WithAllowInsecureTLS is set. It runs once all options are applied, so the order of
+// the options does not matter.
+func (c *SMOPClient) validateTLSPolicy() error {
+	if c.allowInsecureTLS {
+		return nil
+	}
+	if c.tlsMinVersion != 0 && c.tlsMinVersion < defaultTLSMinVersion {
+		return fmt.Errorf("invalid SMoP TLS min version %s: must be at least %s unless insecure TLS is allowed",
+			tls.VersionName(c.tlsMinVersion), tls.VersionName(defaultTLSMinVersion))
+	}
+	for _, id := range c.tlsCipherSuites {
+		if suite := cipherSuite(id); suite.Insecure {
+			return fmt.Errorf("invalid SMoP TLS cipher suite %s: insecure cipher suites require insecure TLS to be allowed", suite.Name)
+		}
+	}
+	return nil
+}
+
+// validateTLSConfig refuses a WithTLSConfig client certificate missing its certificate chain or its private key,
+// which would otherwise only fail once the SMoP server asks for the certificate during the handshake.
+func (c *SMOPClient) validateTLSConfig() error {
+	if c.tlsConfig == nil {
+		return nil
+	}
+	for i, cert := range c.tlsConfig.Certificates {
+		switch {
+		case len(cert.Certificate) == 0:
+			return fmt.Errorf("invalid SMoP TLS client certificate %d: the certificate is missing", i)
+		case cert.PrivateKey == nil:
+			return fmt.Errorf("invalid SMoP TLS client certificate %d: the private key is missing", i)
+		}
+	}
+	return nil
+}
+
+// applyTLSPolicy applies the TLS min version and cipher suites to cfg, which may be nil,
+// and returns the resulting configuration. A stricter MinVersion of cfg is kept.
+func (c *SMOPClient) applyTLSPolicy(cfg *tls.Config) *tls.Config {
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+
+	minVersion := c.tlsMinVersion
+	if minVersion == 0 {
+		minVersion = max(cfg.MinVersion, defaultTLSMinVersion)
+	} else {
+		minVersion = max(cfg.MinVersion, minVersion)
+	}
+	cfg.MinVersion = minVersion //nolint:gosec // below TLS 1.2 only with WithAllowInsecureTLS, see validateTLSPolicy
+
+	if len(c.tlsCipherSuites) > 0 {
+		cfg.CipherSuites = slices.Clone(c.tlsCipherSuites)
+	}
+	return cfg
+}
@@ -0,0 +1,168 @@
+package smopclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// baseTLSConfig returns the TLS configuration of the transport built for c.
+func baseTLSConfig(t *testing.T, c *SMOPClient) *tls.Config {
+	t.Helper()
+
+	return httpTransport(t, c).TLSClientConfig
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	tests := map[string]struct {
+		opts []ClientOption
+		want uint16
+	}{
+		"defaults to TLS 1.2": {want: tls.VersionTLS12},
+		"TLS 1.3":             {opts: []ClientOption{WithTLSMinVersion(tls.VersionTLS13)}, want: tls.VersionTLS13},
+		"raises a weaker TLS config": {
+			opts: []ClientOption{WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS10})}, //nolint:gosec // raised by the client
+			want: tls.VersionTLS12,
+		},
+		"keeps a stricter TLS config": {
+			opts: []ClientOption{WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13}), WithTLSMinVersion(tls.VersionTLS12)},
+			want: tls.VersionTLS13,
+		},
+		"below the default with override": {
+			opts: []ClientOption{WithTLSMinVersion(tls.VersionTLS11), WithAllowInsecureTLS(true)},
+			want: tls.VersionTLS11,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, tc.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, baseTLSConfig(t, c).MinVersion)
+		})
+	}
+}
+
+func TestTLSMinVersionHandshake(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12} //nolint:gosec // a server without TLS 1.3
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithTLSConfig(&tls.Config{RootCAs: roots})) //nolint:gosec // MinVersion is set by the client
+	require.NoError(t, err)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+
+	c, err = NewSMOPClient(srv.URL+"/site/secrets", testToken,
+		WithTLSConfig(&tls.Config{RootCAs: roots}), WithTLSMinVersion(tls.VersionTLS13)) //nolint:gosec // MinVersion is set by the client
+	require.NoError(t, err)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	assert.Error(t, err, "a server without TLS 1.3 must be refused")
+}
+
+func TestTLSHandshakeTimeout(t *testing.T) {
+	// a server which accepts connections but never answers the TLS handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	c, err := NewSMOPClient("https://"+ln.Addr().String()+"/site/secrets", testToken,
+		WithTLSHandshakeTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	assert.ErrorContains(t, err, "TLS handshake timeout")
+	assert.Less(t, time.Since(start), 2*time.Second, "a stalled handshake must fail fast")
+}
+
+func TestTLSCipherSuites(t *testing.T) {
+	suites := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}
+	c, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithTLSCipherSuites(suites))
+	require.NoError(t, err)
+	assert.Equal(t, suites, baseTLSConfig(t, c).CipherSuites)
+
+	_, err = NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+		WithTLSCipherSuites([]uint16{tls.TLS_RSA_WITH_RC4_128_SHA}), WithAllowInsecureTLS(true))
+	assert.NoError(t, err)
+}
+
+func TestTLSPolicyRejected(t *testing.T) {
+	tests := map[string][]ClientOption{
+		"below the default":         {WithTLSMinVersion(tls.VersionTLS10)},
+		"unknown version":           {WithTLSMinVersion(0x0200)},
+		"insecure cipher suite":     {WithTLSCipherSuites([]uint16{tls.TLS_RSA_WITH_RC4_128_SHA})},
+		"unknown cipher suite":      {WithTLSCipherSuites([]uint16{0xffff})},
+		"empty cipher suites":       {WithTLSCipherSuites(nil)},
+		"override disabled":         {WithAllowInsecureTLS(false), WithTLSMinVersion(tls.VersionTLS11)},
+		"non http.Transport in use": {WithTLSMinVersion(tls.VersionTLS13), WithTransport(roundTripperFunc(nil))},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, opts...)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTLSConfigClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		cert    tls.Certificate
+		wantErr string
+	}{
+		"certificate and key": {
+			cert: tls.Certificate{Certificate: [][]byte{{0x30}}, PrivateKey: key},
+		},
+		"certificate without key": {
+			cert:    tls.Certificate{Certificate: [][]byte{{0x30}}},
+			wantErr: "the private key is missing",
+		},
+		"key without certificate": {
+			cert:    tls.Certificate{PrivateKey: key},
+			wantErr: "the certificate is missing",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+				WithTLSConfig(&tls.Config{Certificates: []tls.Certificate{tc.cert}}))
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
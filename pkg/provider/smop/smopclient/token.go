@@ -0,0 +1,46 @@
+package smopclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidToken is returned by NewSMOPClient for a SMoP token which cannot be valid.
+var ErrInvalidToken = errors.New("invalid SMoP token")
+
+// validateToken checks the static token passed to NewSMOPClient, so an unusable token fails
+// at construction instead of with a 401 on the first request. It is never included in the error.
+// With expectJWT the token must also be shaped like a JWT: three dot separated base64url
+// segments, the first two holding JSON objects.
+func validateToken(token string, expectJWT bool) error {
+	if strings.TrimSpace(token) == "" {
+		return fmt.Errorf("%w: must not be empty", ErrInvalidToken)
+	}
+	if i := strings.IndexFunc(token, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }); i >= 0 {
+		return fmt.Errorf("%w: must not contain whitespace or control characters (found at offset %d), "+
+			"check the Secret holding it for a trailing newline", ErrInvalidToken, i)
+	}
+	if !expectJWT {
+		return nil
+	}
+
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return fmt.Errorf("%w: expected a JWT with 3 segments, got %d", ErrInvalidToken, len(segments))
+	}
+	for i, name := range []string{"header", "payload"} {
+		decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segments[i], "="))
+		if err != nil {
+			return fmt.Errorf("%w: JWT %s is not base64url encoded", ErrInvalidToken, name)
+		}
+		var object map[string]any
+		if err := json.Unmarshal(decoded, &object); err != nil {
+			return fmt.Errorf("%w: JWT %s is not a JSON object", ErrInvalidToken, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// tokenExchangeGrantType is the OAuth 2.0 token exchange grant type (RFC 8693).
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// jwtTokenType is the token type of a Kubernetes ServiceAccount token.
+	jwtTokenType = "urn:ietf:params:oauth:token-type:jwt"
+
+	// defaultExchangedTokenLifetime is assumed for exchanged tokens reported without expires_in.
+	defaultExchangedTokenLifetime = 5 * time.Minute
+	// tokenRefreshSkew is how long before its expiry an exchanged token is refreshed.
+	tokenRefreshSkew = time.Minute
+	// defaultTokenExchangeTimeout bounds a single token exchange.
+	defaultTokenExchangeTimeout = 30 * time.Second
+)
+
+// ErrTokenExchange is returned when a workload identity token cannot be exchanged for a SMoP token.
+var ErrTokenExchange = errors.New("SMoP token exchange failed")
+
+// TokenSource supplies the SMoP token requests authenticate with,
+// e.g. a short-lived token which is refreshed on expiry.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// SubjectTokenFunc returns the workload identity token traded for a SMoP token,
+// e.g. a projected Kubernetes ServiceAccount token.
+type SubjectTokenFunc func(ctx context.Context) (string, error)
+
+// TokenExchangeSource is a TokenSource trading a workload identity token for a SMoP token
+// with an OAuth 2.0 token exchange (RFC 8693). The SMoP token is cached and exchanged
+// again shortly before it expires. It is safe for concurrent use.
+type TokenExchangeSource struct {
+	exchangeURL  string
+	audience     string
+	subjectToken SubjectTokenFunc
+	httpClient   *http.Client
+
+	// lock serializes exchanges; unlike a mutex, waiting for it is aborted with the context.
+	lock      chan struct{}
+	token     string
+	refreshAt time.Time
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewTokenExchangeSource returns a TokenSource exchanging the token returned by subjectToken at exchangeURL.
+// `audience` is requested as the audience of the SMoP token, if set. A nil httpClient uses http.DefaultClient.
+func NewTokenExchangeSource(exchangeURL, audience string, subjectToken SubjectTokenFunc, httpClient *http.Client) (*TokenExchangeSource, error) {
+	if err := validateSmopServerURL(exchangeURL); err != nil {
+		return nil, fmt.Errorf("invalid SMoP token exchange URL: %w", err)
+	}
+	if subjectToken == nil {
+		return nil, errors.New("invalid SMoP token exchange: a subject token is required")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &TokenExchangeSource{
+		exchangeURL:  exchangeURL,
+		audience:     audience,
+		subjectToken: subjectToken,
+		httpClient:   httpClient,
+		lock:         make(chan struct{}, 1),
+		now:          time.Now,
+	}, nil
+}
+
+// Token returns the cached SMoP token, exchanging a new one if it is about to expire.
+// A call waiting for the exchange of another call returns once its context is done.
+func (s *TokenExchangeSource) Token(ctx context.Context) (string, error) {
+	select {
+	case s.lock <- struct{}{}:
+	case <-ctx.Done():
+		return "", fmt.Errorf("%w: %w", ErrTokenExchange, ctx.Err())
+	}
+	defer func() { <-s.lock }()
+
+	if s.token != "" && s.now().Before(s.refreshAt) {
+		return s.token, nil
+	}
+
+	token, lifetime, err := s.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	s.refreshAt = s.now().Add(lifetime - min(tokenRefreshSkew, lifetime/2))
+	return s.token, nil
+}
+
+// exchangeResponse is the successful response of a token exchange (RFC 8693 section 2.2.1).
+type exchangeResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// exchangeErrorResponse is the error response of a token exchange (RFC 6749 section 5.2).
+type exchangeErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchange trades a fresh subject token for a SMoP token and returns it with its lifetime.
+func (s *TokenExchangeSource) exchange(ctx context.Context) (string, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTokenExchangeTimeout)
+	defer cancel()
+
+	subjectToken, err := s.subjectToken(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: failed to get subject token: %w", ErrTokenExchange, err)
+	}
+
+	form := url.Values{
+		"grant_type":         []string{tokenExchangeGrantType},
+		"subject_token":      []string{subjectToken},
+		"subject_token_type": []string{jwtTokenType},
+	}
+	if s.audience != "" {
+		form.Set("audience", s.audience)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.exchangeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: failed to read response: %w", ErrTokenExchange, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp exchangeErrorResponse
+		if json.Unmarshal(respBytes, &errResp) == nil && errResp.Error != "" {
+			return "", 0, fmt.Errorf("%w: HTTP %d: %s %s", ErrTokenExchange, resp.StatusCode, errResp.Error, errResp.ErrorDescription)
+		}
+		return "", 0, fmt.Errorf("%w: HTTP %d", ErrTokenExchange, resp.StatusCode)
+	}
+
+	var exchanged exchangeResponse
+	if err := json.Unmarshal(respBytes, &exchanged); err != nil {
+		return "", 0, fmt.Errorf("%w: failed to unmarshal response: %w", ErrTokenExchange, err)
+	}
+	if exchanged.AccessToken == "" {
+		return "", 0, fmt.Errorf("%w: response has no access_token", ErrTokenExchange)
+	}
+	if exchanged.TokenType != "" && !strings.EqualFold(exchanged.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("%w: unsupported token type %q", ErrTokenExchange, exchanged.TokenType)
+	}
+
+	lifetime := defaultExchangedTokenLifetime
+	if exchanged.ExpiresIn > 0 {
+		lifetime = time.Duration(exchanged.ExpiresIn) * time.Second
+	}
+	return exchanged.AccessToken, lifetime, nil
+}
@@ -0,0 +1,179 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenExchangeSource(t *testing.T) {
+	var exchanges atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, tokenExchangeGrantType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, jwtTokenType, r.PostForm.Get("subject_token_type"))
+		assert.Equal(t, "sa-token", r.PostForm.Get("subject_token"))
+		assert.Equal(t, "smop", r.PostForm.Get("audience"))
+
+		n := exchanges.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("smop-token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   600,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	subjectToken := func(context.Context) (string, error) { return "sa-token", nil }
+	source, err := NewTokenExchangeSource(srv.URL+"/auth/token", "smop", subjectToken, nil)
+	require.NoError(t, err)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "smop-token-1", token)
+
+	now = now.Add(8 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "smop-token-1", token, "the token must be cached until shortly before it expires")
+
+	now = now.Add(90 * time.Second)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "smop-token-2", token, "the token must be refreshed before it expires")
+	assert.Equal(t, int64(2), exchanges.Load())
+}
+
+func TestTokenExchangeSourceErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"token audience mismatch"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	source, err := NewTokenExchangeSource(srv.URL, "", func(context.Context) (string, error) { return "sa-token", nil }, nil)
+	require.NoError(t, err)
+	_, err = source.Token(context.Background())
+	assert.ErrorIs(t, err, ErrTokenExchange)
+	assert.ErrorContains(t, err, "token audience mismatch")
+
+	failing, err := NewTokenExchangeSource(srv.URL, "", func(context.Context) (string, error) { return "", errors.New("no token") }, nil)
+	require.NoError(t, err)
+	_, err = failing.Token(context.Background())
+	assert.ErrorIs(t, err, ErrTokenExchange)
+	assert.ErrorContains(t, err, "no token")
+
+	_, err = NewTokenExchangeSource("", "", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestWithTokenSource(t *testing.T) {
+	var tokens atomic.Int64
+	source := tokenSourceFunc(func(context.Context) (string, error) {
+		tokens.Add(1)
+		return "rotated", nil
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer rotated", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, "", WithTokenSource(source))
+	require.NoError(t, err)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tokens.Load())
+}
+
+// tokenSourceFunc adapts a function to a TokenSource.
+type tokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f tokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+func TestTokenExchangeSourceCancel(t *testing.T) {
+	release := make(chan struct{})
+	exchanging := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(exchanging)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	subjectToken := func(context.Context) (string, error) { return "sa-token", nil }
+	source, err := NewTokenExchangeSource(srv.URL+"/auth/token", "", subjectToken, nil)
+	require.NoError(t, err)
+
+	// the first call hangs in the exchange until its context is canceled
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := source.Token(firstCtx)
+		firstErr <- err
+	}()
+	<-exchanging
+
+	// a second call waits for the first exchange, it must give up with its own context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = source.Token(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, ErrTokenExchange)
+	assert.Less(t, time.Since(start), 5*time.Second, "waiting for another exchange must be aborted")
+
+	start = time.Now()
+	cancelFirst()
+	select {
+	case err := <-firstErr:
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a canceled exchange must return promptly")
+	}
+}
+
+func TestWithTokenSourceCancel(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(srv.Close)
+
+	// a token source stuck refreshing, e.g. backing off from a failing identity provider
+	source := tokenSourceFunc(func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	c, err := NewSMOPClient(srv.URL, "", WithTokenSource(source), WithMaxRetries(3), WithRetryBackoff(time.Minute, time.Minute))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.GetSecret(ctx, "db", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Zero(t, requests.Load(), "no request may be sent without a token")
+}
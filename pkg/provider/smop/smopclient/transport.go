@@ -0,0 +1,258 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// saturationWaitThreshold is how long a request may wait for a connection before it counts as a slow wait.
+	saturationWaitThreshold = 250 * time.Millisecond
+	// saturationWarnAfter is the number of consecutive slow waits after which the pool is reported as saturated.
+	saturationWarnAfter = 10
+	// saturationWarnInterval rate limits the saturation warning.
+	saturationWarnInterval = time.Minute
+
+	// defaultMaxResponseHeaderBytes bounds the response headers read from the SMoP server.
+	// SMoP headers are small, the limit leaves room for cookies and tracing headers added by gateways
+	// while a broken or malicious gateway can't make the client buffer megabytes of headers.
+	defaultMaxResponseHeaderBytes = 64 << 10
+)
+
+// newTransport builds the HTTP transport used for the SMoP API from the client's tuning options.
+// The transport retries transient failures and requests failing on a stale connection,
+// and is instrumented to report connection pool usage.
+// A RoundTripper set with WithTransport is used as the innermost layer, below retries and
+// instrumentation; the TLS and connection pool options only apply if it is an *http.Transport.
+func (c *SMOPClient) newTransport() (http.RoundTripper, error) {
+	base, err := c.baseTransport()
+	if err != nil {
+		return nil, err
+	}
+	if owned, ok := base.(*http.Transport); ok && base != http.DefaultTransport {
+		c.ownedTransport = owned
+	}
+
+	var attempt http.RoundTripper = &staleConnTransport{
+		base: &instrumentedTransport{
+			base:                base,
+			maxIdleConnsPerHost: c.maxIdleConnsPerHost,
+			maxConnsPerHost:     c.maxConnsPerHost,
+			stats:               c.stats,
+		},
+	}
+	// each attempt takes a slot of the shared limiter, which is free while a retry backs off
+	if c.requestLimiter != nil {
+		attempt = &limitedTransport{base: attempt, limiter: c.requestLimiter}
+	}
+
+	return &retryTransport{
+		base:       attempt,
+		maxRetries: c.maxRetries,
+		baseDelay:  c.retryBaseDelay,
+		maxDelay:   c.retryMaxDelay,
+		budget:     c.retryBudget,
+
+		beforeRetry: c.beforeRetry,
+		stats:       c.stats,
+	}, nil
+}
+
+// CloseIdleConnections closes the idle connections of the transports the client built,
+// e.g. once the client is no longer used. Transports the client does not own are left untouched.
+func (c *SMOPClient) CloseIdleConnections() {
+	if c.ownedTransport != nil {
+		c.ownedTransport.CloseIdleConnections()
+	}
+	if c.contentClient != nil {
+		c.contentClient.CloseIdleConnections()
+	}
+}
+
+// baseTransport returns the transport requests are sent with: a clone of the *http.Transport
+// set with WithTransport or of http.DefaultTransport, with the client's tuning options applied.
+// Any other RoundTripper set with WithTransport is used as is.
+func (c *SMOPClient) baseTransport() (http.RoundTripper, error) {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if c.transport != nil {
+		base, ok = c.transport.(*http.Transport)
+		if !ok {
+			if c.tlsConfig != nil || c.tlsMinVersion != 0 || len(c.tlsCipherSuites) > 0 || c.insecureSkipVerify ||
+				c.maxIdleConnsPerHost > 0 || c.maxConnsPerHost > 0 || c.idleConnTimeout > 0 || c.maxResponseHeaderBytes > 0 ||
+				c.tlsHandshakeTimeout > 0 || c.hostOverride != nil {
+				return nil, fmt.Errorf("invalid SMoP transport %T: TLS, connection pool and host override options require an *http.Transport", c.transport)
+			}
+			return c.transport, nil
+		}
+	}
+	if !ok {
+		return http.DefaultTransport, nil
+	}
+	base = base.Clone()
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	dial := dialer.DialContext
+	if base.DialContext != nil {
+		dial = base.DialContext
+	}
+	if c.hostOverride != nil {
+		dial = c.hostOverride.dial(dial, c.serverHost)
+	}
+	if c.dialRetries > 0 {
+		dial = retryDial(dial, c.dialRetries, c.dialRetryDelay)
+	}
+	base.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return newTrackedConn(conn), nil
+	}
+	if c.tlsConfig != nil {
+		base.TLSClientConfig = c.tlsConfig
+	}
+	base.TLSClientConfig = c.applyTLSPolicy(base.TLSClientConfig.Clone())
+	if c.insecureSkipVerify {
+		base.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // explicitly enabled, see WithInsecureSkipVerify
+	}
+	if c.maxIdleConnsPerHost > 0 {
+		base.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+	}
+	if c.maxConnsPerHost > 0 {
+		base.MaxConnsPerHost = c.maxConnsPerHost
+	}
+	// a transport set with WithTransport keeps its own idle timeout unless one is set explicitly
+	if c.idleConnTimeout > 0 {
+		base.IdleConnTimeout = c.idleConnTimeout
+	} else if c.transport == nil {
+		base.IdleConnTimeout = defaultIdleConnTimeout
+	}
+	if c.tlsHandshakeTimeout > 0 {
+		base.TLSHandshakeTimeout = c.tlsHandshakeTimeout
+	} else if c.transport == nil {
+		base.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	}
+	if c.maxResponseHeaderBytes > 0 {
+		base.MaxResponseHeaderBytes = c.maxResponseHeaderBytes
+	} else if c.transport == nil {
+		base.MaxResponseHeaderBytes = defaultMaxResponseHeaderBytes
+	}
+	return base, nil
+}
+
+// instrumentedTransport reports connection pool usage of the wrapped transport and
+// warns when requests consistently wait for a connection.
+type instrumentedTransport struct {
+	base http.RoundTripper
+
+	// maxIdleConnsPerHost and maxConnsPerHost are reported in the saturation warning.
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+
+	stats *clientStats
+
+	slowWaits   atomic.Int64
+	lastWarning atomic.Int64
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		getConn time.Time
+		gotConn atomic.Bool
+	)
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) {
+			getConn = time.Now()
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			// http.Transport gets another connection when it retries a request on a dropped one by itself,
+			// the request holds a single connection all the same
+			if gotConn.CompareAndSwap(false, true) {
+				connsInUse.Add(1)
+			}
+			t.observeWait(time.Since(getConn))
+		},
+	}
+
+	var releaseOnce sync.Once
+	release := func() {
+		releaseOnce.Do(func() {
+			if gotConn.Load() {
+				connsInUse.Add(-1)
+			}
+		})
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	t.stats.observe(resp, err)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// the connection goes back to the pool once the body is closed
+	resp.Body = &releaseOnCloseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// observeWait records how long a request waited for a connection and warns
+// when requests consistently wait longer than saturationWaitThreshold.
+func (t *instrumentedTransport) observeWait(wait time.Duration) {
+	connWaitSeconds.Observe(wait.Seconds())
+
+	if wait < saturationWaitThreshold {
+		t.slowWaits.Store(0)
+		return
+	}
+	if t.slowWaits.Add(1) < saturationWarnAfter {
+		return
+	}
+
+	now := time.Now()
+	last := t.lastWarning.Load()
+	if now.Sub(time.Unix(0, last)) < saturationWarnInterval || !t.lastWarning.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	log.Info("SMoP connection pool is saturated, requests are waiting for a connection; consider raising maxIdleConnsPerHost or maxConnsPerHost",
+		"wait", wait.String(),
+		"consecutiveSlowWaits", t.slowWaits.Load(),
+		"maxIdleConnsPerHost", t.maxIdleConnsPerHost,
+		"maxConnsPerHost", t.maxConnsPerHost)
+}
+
+// releaseOnCloseBody calls release once the response body is closed.
+type releaseOnCloseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnCloseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// trackedConn counts open connections to the SMoP server.
+type trackedConn struct {
+	net.Conn
+	closeOnce sync.Once
+}
+
+func newTrackedConn(conn net.Conn) net.Conn {
+	connsOpen.Add(1)
+	return &trackedConn{Conn: conn}
+}
+
+func (c *trackedConn) Close() error {
+	c.closeOnce.Do(func() { connsOpen.Add(-1) })
+	return c.Conn.Close()
+}
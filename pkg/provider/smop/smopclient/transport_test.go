@@ -0,0 +1,201 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func connWaitObservations(t *testing.T) uint64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, connWaitSeconds.Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestTransportConnectionMetrics(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"db": `{"path":"db","secret":{"password":"s3cr3t"}}`,
+	}, WithMaxIdleConnsPerHost(4), WithMaxConnsPerHost(8))
+
+	before := connWaitObservations(t)
+	_, err := c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, before+1, connWaitObservations(t))
+	assert.Zero(t, activeConns(), "connection must be released once the response is read")
+	assert.GreaterOrEqual(t, idleConns(), float64(1), "connection must be kept idle for reuse")
+}
+
+func TestTransportLimits(t *testing.T) {
+	c, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+		WithMaxIdleConnsPerHost(4), WithMaxConnsPerHost(8), WithIdleConnTimeout(20*time.Second),
+		WithMaxResponseHeaderBytes(8<<10), WithTLSHandshakeTimeout(2*time.Second))
+	require.NoError(t, err)
+
+	base := httpTransport(t, c)
+	assert.Equal(t, 4, base.MaxIdleConnsPerHost)
+	assert.Equal(t, 8, base.MaxConnsPerHost)
+	assert.Equal(t, 20*time.Second, base.IdleConnTimeout)
+	assert.Equal(t, int64(8<<10), base.MaxResponseHeaderBytes)
+	assert.Equal(t, 2*time.Second, base.TLSHandshakeTimeout)
+
+	c, err = NewSMOPClient("https://smop.example.com/site/secrets", testToken)
+	require.NoError(t, err)
+	assert.Equal(t, defaultIdleConnTimeout, httpTransport(t, c).IdleConnTimeout)
+	assert.Equal(t, int64(defaultMaxResponseHeaderBytes), httpTransport(t, c).MaxResponseHeaderBytes)
+	assert.Equal(t, defaultTLSHandshakeTimeout, httpTransport(t, c).TLSHandshakeTimeout)
+
+	for name, opt := range map[string]ClientOption{
+		"max idle conns":    WithMaxIdleConnsPerHost(0),
+		"max conns":         WithMaxConnsPerHost(0),
+		"idle conn timeout": WithIdleConnTimeout(0),
+		"max header bytes":  WithMaxResponseHeaderBytes(0),
+		"handshake timeout": WithTLSHandshakeTimeout(0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, opt)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMaxResponseHeaderBytes(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("X-Gateway-Trace", strings.Repeat("x", 4<<10))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, WithMaxResponseHeaderBytes(1<<10))
+	require.NoError(t, err)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	require.ErrorContains(t, err, "server response headers exceeded 1024 bytes")
+	assert.True(t, IsRetryable(err), "an oversized response fails like any transport error")
+	assert.Equal(t, 1, requests)
+
+	c, err = NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	kv, err := c.GetSecret(context.Background(), "db", nil)
+	require.NoError(t, err, "the default limit leaves room for large gateway headers")
+	assert.Equal(t, "s3cr3t", kv.Secret["password"])
+}
+
+// httpTransport returns the *http.Transport at the bottom of the transport of c.
+func httpTransport(t *testing.T, c *SMOPClient) *http.Transport {
+	t.Helper()
+	rt, err := c.newTransport()
+	require.NoError(t, err)
+	return rt.(*retryTransport).base.(*staleConnTransport).base.(*instrumentedTransport).base.(*http.Transport)
+}
+
+func TestTransportSaturationWarning(t *testing.T) {
+	transport := &instrumentedTransport{base: &http.Transport{}}
+
+	for range saturationWarnAfter - 1 {
+		transport.observeWait(saturationWaitThreshold)
+	}
+	assert.Zero(t, transport.lastWarning.Load(), "must not warn before enough consecutive slow waits")
+
+	transport.observeWait(time.Millisecond)
+	assert.Zero(t, transport.slowWaits.Load(), "a fast wait must reset the slow wait streak")
+
+	for range saturationWarnAfter {
+		transport.observeWait(saturationWaitThreshold)
+	}
+	warned := transport.lastWarning.Load()
+	assert.NotZero(t, warned)
+
+	transport.observeWait(saturationWaitThreshold)
+	assert.Equal(t, warned, transport.lastWarning.Load(), "warning must be rate limited")
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken)
+	require.NoError(t, err)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	assert.ErrorContains(t, err, "certificate")
+
+	c, err = NewSMOPClient(srv.URL+"/site/secrets", testToken, WithInsecureSkipVerify(true))
+	require.NoError(t, err)
+	_, err = c.GetSecret(context.Background(), "db", nil)
+	assert.NoError(t, err)
+}
+
+// roundTripperFunc adapts a function to an http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	t.Run("custom round tripper sees every retried attempt", func(t *testing.T) {
+		var attempts int
+		rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			assert.Equal(t, "Bearer "+testToken, req.Header.Get("Authorization"))
+			rec := httptest.NewRecorder()
+			if attempts == 1 {
+				rec.WriteHeader(http.StatusServiceUnavailable)
+				return rec.Result(), nil
+			}
+			rec.Header().Set("Content-Type", "application/json")
+			_, _ = rec.WriteString(`{"path":"db","secret":{"password":"s3cr3t"}}`)
+			return rec.Result(), nil
+		})
+
+		c, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+			WithTransport(rt), WithMaxRetries(1), WithRetryBackoff(time.Millisecond, time.Millisecond))
+		require.NoError(t, err)
+
+		kv, err := c.GetSecret(context.Background(), "db", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", kv.Secret["password"])
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("http.Transport gets the tuning options applied to a clone", func(t *testing.T) {
+		custom := &http.Transport{MaxIdleConns: 3, TLSHandshakeTimeout: time.Minute}
+		c, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+			WithTransport(custom), WithMaxConnsPerHost(8))
+		require.NoError(t, err)
+
+		base := httpTransport(t, c)
+		assert.NotSame(t, custom, base)
+		assert.Equal(t, 3, base.MaxIdleConns)
+		assert.Equal(t, 8, base.MaxConnsPerHost)
+		assert.Zero(t, custom.MaxConnsPerHost, "the caller's transport must not be modified")
+		assert.Zero(t, base.IdleConnTimeout, "the idle timeout of the caller's transport is kept")
+		assert.Zero(t, base.MaxResponseHeaderBytes, "the header limit of the caller's transport is kept")
+		assert.Equal(t, time.Minute, base.TLSHandshakeTimeout, "the handshake timeout of the caller's transport is kept")
+	})
+
+	t.Run("tuning options require an http.Transport", func(t *testing.T) {
+		rt := roundTripperFunc(http.DefaultTransport.RoundTrip)
+		_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken,
+			WithTransport(rt), WithMaxConnsPerHost(8))
+		assert.ErrorContains(t, err, "require an *http.Transport")
+	})
+
+	t.Run("nil transport", func(t *testing.T) {
+		_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, WithTransport(nil))
+		assert.Error(t, err)
+	})
+}
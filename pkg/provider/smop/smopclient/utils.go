@@ -1,13 +1,18 @@
 package smopclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
+	"time"
 
 	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
 	sp "github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
@@ -27,6 +32,39 @@ func validateSmopServerURL(server string) error {
 	return nil
 }
 
+// ErrInvalidPathSegment is returned for a KV name or path segment which cannot be addressed in a URL path.
+var ErrInvalidPathSegment = errors.New("invalid SMoP path segment")
+
+// escapePathSegments escapes each segment so it is sent as exactly one URL path segment.
+// Empty and dot segments are rejected, as they would address a different path once the URL is resolved.
+func escapePathSegments(segments []string) ([]string, error) {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return nil, fmt.Errorf("%w %q", ErrInvalidPathSegment, segment)
+		}
+		escaped[i] = url.PathEscape(segment)
+	}
+	return escaped, nil
+}
+
+// prefixServerURLPath prepends the given path prefix to the path of the server URL,
+// collapsing any duplicate slashes between the segments.
+func prefixServerURLPath(server, prefix string) (string, error) {
+	if strings.Trim(prefix, "/") == "" {
+		return server, nil
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid smop server URL %q: %w", server, err)
+	}
+
+	serverURL.Path = path.Join("/", prefix, serverURL.Path)
+	serverURL.RawPath = ""
+	return serverURL.String(), nil
+}
+
 // getPathString returns the string value of the given path pointer.
 func getPathString(pathPtr *string) string {
 	if pathPtr == nil {
@@ -36,21 +74,115 @@ func getPathString(pathPtr *string) string {
 	return *pathPtr
 }
 
-// getRequestEditor creates a RequestEditorFn that adds the Bearer token to the request.
-func getRequestEditor(token string) (cg.RequestEditorFn, error) {
+// joinKVPath joins a folder path and a KV name into a single path.
+func joinKVPath(folder, name string) string {
+	return strings.TrimSuffix(folder, "/") + "/" + name
+}
+
+// splitKVPath splits a full KV path into its folder path and KV name.
+// The folder path is nil if the KV lives in the root folder.
+func splitKVPath(fullPath string) (*string, string) {
+	fullPath = strings.Trim(fullPath, "/")
+
+	i := strings.LastIndex(fullPath, "/")
+	if i < 0 {
+		return nil, fullPath
+	}
+
+	folder := fullPath[:i]
+	return &folder, fullPath[i+1:]
+}
+
+// tokenOverrideKey is the context key of a per-request SMoP token override.
+type tokenOverrideKey struct{}
+
+// ContextWithTokenOverride returns a copy of ctx in which SMoP requests authenticate
+// with `token` instead of the client's token. An empty token leaves the client's token in use.
+//
+// This is meant for advanced use such as impersonation and tests; the token is never logged.
+func ContextWithTokenOverride(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenOverrideKey{}, token)
+}
+
+// tokenOverrideFromContext returns the per-request token override set on ctx, if any.
+func tokenOverrideFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenOverrideKey{}).(string)
+	return token, ok && token != ""
+}
+
+// getRequestEditor creates a RequestEditorFn that adds the Bearer token, and the impersonation subject and group, if any, to the request.
+// A token override on the request context (see ContextWithTokenOverride) is preferred over `token`,
+// and a token from `tokens` is preferred over `token` if `tokens` is set.
+func getRequestEditor(token string, tokens TokenSource, impersonationSubject string) (cg.RequestEditorFn, error) {
 	bearer, err := sp.NewSecurityProviderBearerToken(token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolved SMoP bearer token: %w", err)
+		return nil, fmt.Errorf("failed to resolve SMoP bearer token: %w", err)
 	}
 
 	reqEditor := cg.RequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		if err := setImpersonationHeader(ctx, req, impersonationSubject); err != nil {
+			return err
+		}
+		if err := setGroupQueryParam(ctx, req); err != nil {
+			return err
+		}
+		override, ok := tokenOverrideFromContext(ctx)
+		if !ok && tokens != nil {
+			if override, err = tokens.Token(ctx); err != nil {
+				return fmt.Errorf("failed to get SMoP token: %w", err)
+			}
+			ok = true
+		}
+		if ok {
+			overrideBearer, err := sp.NewSecurityProviderBearerToken(override)
+			if err != nil {
+				return fmt.Errorf("failed to resolve SMoP bearer token override: %w", err)
+			}
+			return overrideBearer.Intercept(ctx, req)
+		}
 		return bearer.Intercept(ctx, req)
 	})
 
 	return reqEditor, nil
 }
 
+// isJSONResponse reports whether a response body is JSON. A missing Content-Type is replaced
+// by the default response content type, if any (see WithDefaultResponseContentType).
+// A Content-Type which is still missing or generic (application/octet-stream) falls back to sniffing
+// the body for a JSON object or array, unless content type sniffing is disabled (see WithContentTypeSniffing).
+func (c *SMOPClient) isJSONResponse(contentType string, body []byte) bool {
+	if contentType == "" {
+		contentType = c.defaultContentType
+	}
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+	if c.disableSniffing {
+		return false
+	}
+
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || mediaType != "application/octet-stream" {
+			return false
+		}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed)
+}
+
+// isTruncatedJSON reports whether body is the beginning of a JSON value which is cut off before its end.
+// Such a body is valid JSON up to where it stops, unlike a body which is not JSON at all.
+func isTruncatedJSON(body []byte) bool {
+	var value json.RawMessage
+	return errors.Is(json.NewDecoder(bytes.NewReader(body)).Decode(&value), io.ErrUnexpectedEOF)
+}
+
 // readResponseBody reads and returns the body of the given HTTP response.
+// The body is read until EOF and never sized by Content-Length, so responses streamed
+// by gateways with chunked transfer encoding are read in full. A stream cut off before
+// its terminating chunk is reported as io.ErrUnexpectedEOF instead of a truncated body.
 func readResponseBody(resp *http.Response) ([]byte, error) {
 	defer func() { _ = resp.Body.Close() }()
 
@@ -72,7 +204,16 @@ func createAPIError(statusCode int, contentType string, path string) error {
 }
 
 // parseAPIErrorResponse attempts to parse the error response body and extract the error message.
+// A response sent while SMoP is down for maintenance is returned as a *MaintenanceError,
+// a response to a request awaiting approval as an *ApprovalPendingError.
 func parseAPIErrorResponse(secretBytes []byte, path string, statusCode int) error {
+	if err := parseMaintenanceResponse(secretBytes, path, statusCode, time.Now()); err != nil {
+		return err
+	}
+	if err := parseApprovalPendingResponse(secretBytes, path, statusCode, time.Now()); err != nil {
+		return err
+	}
+
 	var errResp struct {
 		Error string `json:"error"`
 	}
@@ -85,4 +226,4 @@ func parseAPIErrorResponse(secretBytes []byte, path string, statusCode int) erro
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,131 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecretsChunkedResponse(t *testing.T) {
+	const items = 5000
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		flusher := w.(http.Flusher)
+		_, _ = io.WriteString(w, `{"data":[`)
+		for i := range items {
+			if i > 0 {
+				_, _ = io.WriteString(w, ",")
+			}
+			_, _ = fmt.Fprintf(w, `{"path":"kv-%d"}`, i)
+			if i%100 == 0 {
+				flusher.Flush()
+			}
+		}
+		_, _ = io.WriteString(w, `]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	var chunked bool
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil {
+			chunked = resp.ContentLength < 0 && len(resp.TransferEncoding) > 0 && resp.TransferEncoding[0] == "chunked"
+		}
+		return resp, err
+	})
+
+	c, err := NewSMOPClient(srv.URL, testToken, WithTransport(rt))
+	require.NoError(t, err)
+
+	list, err := c.GetSecrets(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, chunked, "the listing must be served without Content-Length")
+	require.Len(t, list, items)
+	assert.Equal(t, "kv-0", list[0].Path)
+	assert.Equal(t, fmt.Sprintf("kv-%d", items-1), list[items-1].Path)
+}
+
+func TestGetSecretsTruncatedChunkedResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		body := `{"data":[{"path":"a"},{"path":"b"}]}`
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nTransfer-Encoding: chunked\r\n\r\n")
+		// the stream is cut off after the first chunk, without the terminating zero-length chunk
+		_, _ = fmt.Fprintf(buf, "%x\r\n%s\r\n", len(body), body)
+		_ = buf.Flush()
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	_, err = c.GetSecrets(context.Background(), nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.ErrorIs(t, err, ErrTruncatedResponse)
+}
+
+func TestPathEscaping(t *testing.T) {
+	const folder = "team a+b/100%"
+	names := []string{"my secret", "a+b", "100%", "%41", "a/b"}
+
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/kv/"), "/metadata")
+		_, _ = fmt.Fprintf(w, `{"path":%q,"secret":{"k":"v"}}`, name)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	folderPath := folder
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			requests = nil
+			ctx := context.Background()
+			_, err := c.GetSecret(ctx, name, &folderPath)
+			require.NoError(t, err)
+			_, err = c.GetSecretMetadata(ctx, name, &folderPath)
+			require.NoError(t, err)
+			require.NoError(t, c.SetSecret(ctx, name, &folderPath, map[string]any{"k": "v"}, nil))
+			require.NoError(t, c.DeleteSecret(ctx, name, &folderPath))
+
+			require.Len(t, requests, 4)
+			for _, r := range requests {
+				assert.Equal(t, folder, r.URL.Query().Get("folderName"), r.Method)
+				assert.Equal(t, "/kv/"+name, strings.TrimSuffix(r.URL.Path, "/metadata"), r.Method)
+				segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+				assert.Equal(t, "kv", segments[0], r.Method)
+				assert.Equal(t, url.PathEscape(name), segments[1], "%s: the name must be exactly one path segment", r.Method)
+			}
+		})
+	}
+
+	t.Run("listing", func(t *testing.T) {
+		requests = nil
+		_, err := c.GetSecrets(context.Background(), &folderPath)
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, folder, requests[0].URL.Query().Get("path"))
+	})
+
+	for _, name := range []string{"", ".", ".."} {
+		_, err := c.GetSecret(context.Background(), name, &folderPath)
+		assert.ErrorIs(t, err, ErrInvalidPathSegment, name)
+		assert.ErrorIs(t, c.DeleteSecret(context.Background(), name, &folderPath), ErrInvalidPathSegment, name)
+	}
+}
@@ -0,0 +1,96 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// serverAPIVersionHeader is the response header SMoP reports its own API version in.
+const serverAPIVersionHeader = "X-Api-Version"
+
+var (
+	// ErrServerAPIVersionUnknown is returned when the SMoP server does not report its API version.
+	ErrServerAPIVersionUnknown = errors.New("SMoP server API version unknown")
+	// ErrAPIVersionMismatch is returned by CheckAPIVersion when the SMoP server reports a different
+	// API version than the client sends and WithFailOnAPIVersionMismatch is set.
+	ErrAPIVersionMismatch = errors.New("SMoP server API version does not match the client API version")
+)
+
+// versionResponse is the body of the `GET version` endpoint.
+type versionResponse struct {
+	APIVersion string `json:"apiVersion"`
+}
+
+// APIVersion returns the SMoP API version the client sends with every request.
+func (c *SMOPClient) APIVersion() string {
+	return c.requestAPIVersion
+}
+
+// DetectedAPIVersion returns the API version last reported by the SMoP server,
+// or an empty string if it has not been queried yet.
+func (c *SMOPClient) DetectedAPIVersion() string {
+	if v := c.serverAPIVersion.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// ServerAPIVersion queries the API version advertised by the SMoP server.
+// The version is read from the `GET version` endpoint, falling back to the
+// X-Api-Version header of its response if the body does not report it.
+func (c *SMOPClient) ServerAPIVersion(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	resp, err := c.doRaw(ctx, http.MethodGet, nil, nil, "version")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch SMoP server API version: %w", err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SMoP server API version response: %w", err)
+	}
+
+	var version string
+	if resp.StatusCode == http.StatusOK && c.isJSONResponse(resp.Header.Get("Content-Type"), respBytes) {
+		var body versionResponse
+		if err := json.Unmarshal(respBytes, &body); err != nil {
+			return "", fmt.Errorf("failed to unmarshal SMoP server API version: %w", err)
+		}
+		version = strings.TrimSpace(body.APIVersion)
+	}
+	if version == "" {
+		version = strings.TrimSpace(resp.Header.Get(serverAPIVersionHeader))
+	}
+	if version == "" {
+		return "", fmt.Errorf("%w: HTTP %d from the version endpoint", ErrServerAPIVersionUnknown, resp.StatusCode)
+	}
+
+	c.serverAPIVersion.Store(&version)
+	return version, nil
+}
+
+// CheckAPIVersion queries the API version advertised by the SMoP server and compares it
+// to the API version the client sends. A mismatch is logged as a warning and only
+// reported as ErrAPIVersionMismatch if WithFailOnAPIVersionMismatch is set.
+func (c *SMOPClient) CheckAPIVersion(ctx context.Context) (string, error) {
+	version, err := c.ServerAPIVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	if version == c.requestAPIVersion {
+		return version, nil
+	}
+
+	if c.failOnAPIVersionMismatch {
+		return version, fmt.Errorf("%w: server reports %q, client sends %q", ErrAPIVersionMismatch, version, c.requestAPIVersion)
+	}
+	log.Info("WARNING: SMoP server API version does not match the client API version, some features may not work",
+		"serverAPIVersion", version, "clientAPIVersion", c.requestAPIVersion)
+	return version, nil
+}
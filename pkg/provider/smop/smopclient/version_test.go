@@ -0,0 +1,82 @@
+package smopclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAPIVersion(t *testing.T) {
+	tests := map[string]struct {
+		handler     http.HandlerFunc
+		opts        []ClientOption
+		wantVersion string
+		wantErr     error
+	}{
+		"matching version": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"apiVersion":"2099-01-01"}`))
+			},
+			wantVersion: "2099-01-01",
+		},
+		"mismatching version only warns": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"apiVersion":"2100-06-01"}`))
+			},
+			wantVersion: "2100-06-01",
+		},
+		"mismatching version fails when requested": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"apiVersion":"2100-06-01"}`))
+			},
+			opts:        []ClientOption{WithFailOnAPIVersionMismatch(true)},
+			wantVersion: "2100-06-01",
+			wantErr:     ErrAPIVersionMismatch,
+		},
+		"version from response header": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set(serverAPIVersionHeader, "2099-01-01")
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantVersion: "2099-01-01",
+		},
+		"version not reported": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantErr: ErrServerAPIVersionUnknown,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/site/secrets/version", r.URL.Path)
+				tc.handler(w, r)
+			}))
+			t.Cleanup(srv.Close)
+
+			opts := append([]ClientOption{WithAPIVersion("2099-01-01")}, tc.opts...)
+			c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, opts...)
+			require.NoError(t, err)
+			assert.Empty(t, c.DetectedAPIVersion())
+
+			version, err := c.CheckAPIVersion(context.Background())
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantVersion, version)
+			assert.Equal(t, tc.wantVersion, c.DetectedAPIVersion())
+			assert.Equal(t, "2099-01-01", c.APIVersion())
+		})
+	}
+}
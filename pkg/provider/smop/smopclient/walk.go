@@ -0,0 +1,255 @@
+package smopclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+)
+
+const (
+	// defaultWalkConcurrency is the number of folders WalkSecrets lists in parallel.
+	defaultWalkConcurrency = 4
+	// defaultWalkBackoffBase is the listing delay WalkSecrets starts with once it is rate limited.
+	defaultWalkBackoffBase = 250 * time.Millisecond
+	// defaultWalkBackoffMax caps the listing delay of WalkSecrets.
+	defaultWalkBackoffMax = 5 * time.Second
+	// walkMaxRateLimitRetries is how often WalkSecrets lists a rate limited folder again before failing.
+	walkMaxRateLimitRetries = 5
+	// defaultWalkMaxDepth is how many levels of sub-folders a recursive walk descends at most.
+	defaultWalkMaxDepth = 32
+	// defaultWalkMaxItems is how many KVs and folders a recursive walk visits at most.
+	defaultWalkMaxItems = 100000
+)
+
+// ErrWalkLimitExceeded is returned when a recursive walk descends deeper or visits more entries
+// than allowed, e.g. because it was started at the wrong folder.
+var ErrWalkLimitExceeded = errors.New("SMoP walk limit exceeded")
+
+// KVRef identifies a KV by its name and folder.
+type KVRef struct {
+	Name string
+	// FolderPath is nil for KVs in the root folder.
+	FolderPath *string
+	// UpdatedAt is the time the KV was last changed, nil if the listing does not report it.
+	UpdatedAt *time.Time
+	// Tags are the tags of the KV, nil if the listing does not report them.
+	Tags map[string]string
+}
+
+// WalkSecrets lists the KVs at the specified `folderPath` and in all its sub-folders, one level at a time.
+// Folders of a level are listed in parallel (see WithWalkConcurrency), with a pause between levels
+// (see WithWalkLevelDelay). When SMoP rate limits a listing, the walk slows down all further listings
+// and lists the folder again (see WithWalkBackoff); it speeds up again as listings succeed.
+// KVs are returned level by level, in listing order.
+// The walk fails with ErrWalkLimitExceeded on a tree deeper or larger than allowed
+// (see WithWalkMaxDepth and WithWalkMaxItems).
+// A nonexistent folder is walked as empty, unless WithRequireFolder is set.
+func (c *SMOPClient) WalkSecrets(ctx context.Context, folderPath *string) ([]KVRef, error) {
+	ctx, cancel := c.withDefaultDeadline(ctx, "WalkSecrets")
+	defer cancel()
+
+	refs, err := c.walk(ctx, folderPath, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireFolder(ctx, folderPath, len(refs) == 0); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// ListSecrets lists the KVs at the specified `folderPath`, without its sub-folders.
+// Unlike GetSecrets, sub-folder entries are skipped and the time each KV was last changed
+// is returned, if the SMoP server reports it.
+// A nonexistent folder is listed as empty, unless WithRequireFolder is set.
+func (c *SMOPClient) ListSecrets(ctx context.Context, folderPath *string) ([]KVRef, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+	defer cancel()
+
+	refs, err := c.walk(ctx, folderPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireFolder(ctx, folderPath, len(refs) == 0); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// walk lists the KVs at `folderPath`, descending into sub-folders when `recursive` is set.
+// It stops before listing a level beyond the maximum depth, or once more entries than the maximum are listed.
+func (c *SMOPClient) walk(ctx context.Context, folderPath *string, recursive bool) ([]KVRef, error) {
+	pacer := &walkPacer{base: c.walkBackoffBase, max: c.walkBackoffMax}
+
+	var refs []KVRef
+	visited := 0
+	level := []*string{folderPath}
+	for depth := 0; len(level) > 0; depth++ {
+		if depth > c.walkMaxDepth {
+			return nil, fmt.Errorf("%w: %q has more than %d levels of sub-folders", ErrWalkLimitExceeded, getPathString(folderPath), c.walkMaxDepth)
+		}
+		if depth > 0 {
+			if err := sleepContext(ctx, c.walkLevelDelay); err != nil {
+				return nil, err
+			}
+		}
+
+		listings, err := c.listFolders(ctx, level, pacer)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []*string
+		for i, listing := range listings {
+			if visited += len(listing.items); visited > c.walkMaxItems {
+				return nil, fmt.Errorf("%w: %q holds more than %d KVs and folders", ErrWalkLimitExceeded, getPathString(folderPath), c.walkMaxItems)
+			}
+			for j, item := range listing.items {
+				if attrs := listing.attrs[j]; !attrs.isFolder() {
+					refs = append(refs, KVRef{Name: item.Path, FolderPath: level[i], UpdatedAt: attrs.UpdatedAt, Tags: attrs.Tags})
+					continue
+				}
+				if recursive {
+					subFolder := joinKVPath(getPathString(level[i]), item.Path)
+					next = append(next, &subFolder)
+				}
+			}
+		}
+		level = next
+	}
+	return refs, nil
+}
+
+// folderListing is the listing of a single folder.
+type folderListing struct {
+	items []cg.KVListItem
+	attrs []kvAttributes
+}
+
+// listFolders lists the given folders in parallel, stopping at the first failure.
+func (c *SMOPClient) listFolders(ctx context.Context, folders []*string, pacer *walkPacer) ([]folderListing, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	listings := make([]folderListing, len(folders))
+	sem := make(chan struct{}, c.walkConcurrency)
+	for i, folder := range folders {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			items, attrs, err := c.listFolderPaced(ctx, folder, pacer)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			listings[i] = folderListing{items: items, attrs: attrs}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// the parent context was cancelled before every folder was listed
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// listFolderPaced lists a single folder, waiting for the pacer first
+// and listing the folder again when it is rate limited.
+func (c *SMOPClient) listFolderPaced(ctx context.Context, folderPath *string, pacer *walkPacer) ([]cg.KVListItem, []kvAttributes, error) {
+	for attempt := 0; ; attempt++ {
+		if err := pacer.wait(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		listCtx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.listTimeout, defaultListTimeout))
+		items, attrs, err := c.listKVs(listCtx, folderPath)
+		cancel()
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests && attempt < walkMaxRateLimitRetries {
+			pacer.slowDown()
+			continue
+		}
+		if err == nil {
+			pacer.speedUp()
+		}
+		return items, attrs, err
+	}
+}
+
+// walkPacer spaces out the listings of a walk, backing off while SMoP rate limits it.
+type walkPacer struct {
+	base time.Duration
+	max  time.Duration
+
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+// wait blocks for the current delay or until ctx is done.
+func (p *walkPacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	delay := p.delay
+	p.mu.Unlock()
+	return sleepContext(ctx, delay)
+}
+
+// slowDown doubles the delay, starting at base and capped at max.
+func (p *walkPacer) slowDown() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.delay = min(max(p.delay*2, p.base), p.max)
+}
+
+// speedUp halves the delay, dropping it once it falls below base.
+func (p *walkPacer) speedUp() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.delay /= 2; p.delay < p.base {
+		p.delay = 0
+	}
+}
+
+// sleepContext sleeps for `d`, returning early with the context error when ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,229 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticTree serves a folder tree under "/root" which is `depth` levels deep.
+// Every folder holds `kvs` KVs and, above the last level, `fanout` sub-folders.
+// The first `rateLimited` listings are answered with 429.
+type syntheticTree struct {
+	depth, fanout, kvs int
+	rateLimited        int64
+
+	listings atomic.Int64
+}
+
+func (tree *syntheticTree) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if tree.listings.Add(1) <= tree.rateLimited {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":"rate limited"}`))
+		return
+	}
+
+	level := strings.Count(strings.TrimPrefix(r.URL.Query().Get("path"), "/root"), "/")
+	items := make([]string, 0, tree.kvs+tree.fanout)
+	for i := range tree.kvs {
+		items = append(items, fmt.Sprintf(`{"path":"kv%d"}`, i))
+	}
+	if level < tree.depth {
+		for i := range tree.fanout {
+			items = append(items, fmt.Sprintf(`{"path":"f%d","type":"folder"}`, i))
+		}
+	}
+	_, _ = fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(items, ","))
+}
+
+// size returns the number of KVs in the tree.
+func (tree *syntheticTree) size() int {
+	folders, level := 0, 1
+	for range tree.depth + 1 {
+		folders += level
+		level *= tree.fanout
+	}
+	return folders * tree.kvs
+}
+
+func newWalkTestClient(tb testing.TB, tree *syntheticTree, opts ...ClientOption) *SMOPClient {
+	tb.Helper()
+
+	srv := httptest.NewServer(tree)
+	tb.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL+"/site/secrets", testToken, opts...)
+	require.NoError(tb, err)
+	return c
+}
+
+func TestWalkSecrets(t *testing.T) {
+	tree := &syntheticTree{depth: 2, fanout: 2, kvs: 1}
+	c := newWalkTestClient(t, tree, WithWalkConcurrency(2))
+
+	root := "/root"
+	refs, err := c.WalkSecrets(context.Background(), &root)
+	require.NoError(t, err)
+
+	got := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		got = append(got, joinKVPath(*ref.FolderPath, ref.Name))
+	}
+	assert.Equal(t, []string{
+		"/root/kv0",
+		"/root/f0/kv0", "/root/f1/kv0",
+		"/root/f0/f0/kv0", "/root/f0/f1/kv0", "/root/f1/f0/kv0", "/root/f1/f1/kv0",
+	}, got)
+}
+
+func TestWalkSecretsRateLimited(t *testing.T) {
+	tree := &syntheticTree{depth: 1, fanout: 2, kvs: 1, rateLimited: 2}
+	c := newWalkTestClient(t, tree, WithWalkBackoff(time.Millisecond, 5*time.Millisecond))
+
+	root := "/root"
+	refs, err := c.WalkSecrets(context.Background(), &root)
+	require.NoError(t, err)
+	assert.Len(t, refs, tree.size())
+	assert.Equal(t, int64(5), tree.listings.Load())
+}
+
+func TestWalkSecretsRateLimitedFails(t *testing.T) {
+	tree := &syntheticTree{depth: 1, fanout: 2, kvs: 1, rateLimited: 100}
+	c := newWalkTestClient(t, tree, WithWalkBackoff(time.Millisecond, time.Millisecond))
+
+	root := "/root"
+	_, err := c.WalkSecrets(context.Background(), &root)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, int64(walkMaxRateLimitRetries+1), tree.listings.Load())
+}
+
+func TestWalkSecretsLimits(t *testing.T) {
+	// 3 entries in /root, 3 in each of its 2 sub-folders and 1 in each of their 4 sub-folders
+	tests := []struct {
+		name               string
+		maxDepth, maxItems int
+		wantErr            string
+	}{
+		{name: "within limits", maxDepth: 2, maxItems: 13},
+		{name: "too deep", maxDepth: 1, maxItems: 13, wantErr: `"/root" has more than 1 levels of sub-folders`},
+		{name: "too many items", maxDepth: 2, maxItems: 12, wantErr: `"/root" holds more than 12 KVs and folders`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := &syntheticTree{depth: 2, fanout: 2, kvs: 1}
+			c := newWalkTestClient(t, tree, WithWalkMaxDepth(tt.maxDepth), WithWalkMaxItems(tt.maxItems))
+
+			root := "/root"
+			refs, err := c.WalkSecrets(context.Background(), &root)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Len(t, refs, tree.size())
+				return
+			}
+			require.ErrorIs(t, err, ErrWalkLimitExceeded)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDeleteSecretsWalkLimit(t *testing.T) {
+	tree := &syntheticTree{depth: 2, fanout: 2, kvs: 1}
+	c := newWalkTestClient(t, tree, WithWalkMaxDepth(1))
+
+	root := "/root"
+	err := c.DeleteSecrets(context.Background(), &root, true, true)
+	require.ErrorIs(t, err, ErrWalkLimitExceeded)
+	// only /root and its sub-folders were listed, nothing was deleted
+	assert.Equal(t, int64(3), tree.listings.Load())
+}
+
+func TestWithWalkLimits(t *testing.T) {
+	for _, opt := range []ClientOption{WithWalkMaxDepth(0), WithWalkMaxItems(0), WithWalkMaxDepth(-1)} {
+		_, err := NewSMOPClient("https://example.com/site/secrets", testToken, opt)
+		assert.ErrorContains(t, err, "must be at least 1")
+	}
+}
+
+func TestWalkSecretsCancel(t *testing.T) {
+	tree := &syntheticTree{depth: 3, fanout: 2, kvs: 1}
+	c := newWalkTestClient(t, tree, WithWalkLevelDelay(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	root := "/root"
+	_, err := c.WalkSecrets(ctx, &root)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int64(1), tree.listings.Load(), "the walk must stop before the next level")
+}
+
+func TestWithWalkOptions(t *testing.T) {
+	for name, opt := range map[string]ClientOption{
+		"zero concurrency":     WithWalkConcurrency(0),
+		"negative level delay": WithWalkLevelDelay(-time.Second),
+		"zero backoff":         WithWalkBackoff(0, time.Second),
+		"max below base":       WithWalkBackoff(time.Second, time.Millisecond),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSMOPClient("https://smop.example.com/site/secrets", testToken, opt)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func BenchmarkWalkSecrets(b *testing.B) {
+	tree := &syntheticTree{depth: 4, fanout: 3, kvs: 5}
+	c := newWalkTestClient(b, tree, WithWalkConcurrency(8))
+	root := "/root"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		refs, err := c.WalkSecrets(context.Background(), &root)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(refs) != tree.size() {
+			b.Fatalf("walked %d KVs, want %d", len(refs), tree.size())
+		}
+	}
+}
+
+func TestListSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.Equal(t, "apps", r.URL.Query().Get("path"))
+		_, _ = w.Write([]byte(`{"data":[
+			{"path":"db","updatedAt":"2026-10-14T09:00:00Z"},
+			{"path":"legacy"},
+			{"path":"sub","type":"folder"}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	apps := "apps"
+	refs, err := c.ListSecrets(context.Background(), &apps)
+	require.NoError(t, err)
+	require.Len(t, refs, 2, "sub-folders must not be listed")
+	assert.Equal(t, "db", refs[0].Name)
+	require.NotNil(t, refs[0].UpdatedAt)
+	assert.Equal(t, time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), refs[0].UpdatedAt.UTC())
+	assert.Equal(t, "legacy", refs[1].Name)
+	assert.Nil(t, refs[1].UpdatedAt)
+}
@@ -0,0 +1,52 @@
+package smopclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SetSecret creates the KV `name` at the specified `folderPath`, or replaces its value with `secret`.
+// The KV tags are replaced with `tags`, unless `tags` is empty.
+func (c *SMOPClient) SetSecret(ctx context.Context, name string, folderPath *string, secret map[string]any, tags map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.operationTimeout(c.getTimeout, defaultGetTimeout))
+	defer cancel()
+
+	var query url.Values
+	if folderPath != nil {
+		query = url.Values{"folderName": []string{*folderPath}}
+	}
+
+	path := getPathString(folderPath)
+	body := map[string]any{"path": name, "secret": secret}
+	if len(tags) > 0 {
+		body["tags"] = tags
+	}
+	resp, err := c.doRaw(ctx, http.MethodPut, query, body, "kv", name)
+	if err != nil {
+		return fmt.Errorf("failed to write secret %q at %q: %w", name, path, err)
+	}
+
+	respBytes, err := readResponseBody(resp)
+	if err != nil {
+		return fmt.Errorf("failed to read write secret response %q at %q: %w", name, path, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+
+	fullKvPath := joinKVPath(path, name)
+	respContentType := resp.Header.Get("Content-Type")
+	// Try to parse error response
+	if c.isJSONResponse(respContentType, respBytes) {
+		if err := parseAPIErrorResponse(respBytes, fullKvPath, resp.StatusCode); err != nil {
+			return err
+		}
+	}
+
+	// Fallback error if we can't parse the response
+	return createAPIError(resp.StatusCode, respContentType, fullKvPath)
+}
@@ -0,0 +1,56 @@
+package smopclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/kv/db", r.URL.Path)
+		require.Equal(t, "apps", r.URL.Query().Get("folderName"))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body struct {
+			Path   string            `json:"path"`
+			Secret map[string]any    `json:"secret"`
+			Tags   map[string]string `json:"tags"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "db", body.Path)
+		assert.Equal(t, map[string]any{"password": "s3cr3t"}, body.Secret)
+		assert.Equal(t, map[string]string{"eso.managed-by": "external-secrets"}, body.Tags)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	apps := "apps"
+	require.NoError(t, c.SetSecret(context.Background(), "db", &apps, map[string]any{"password": "s3cr3t"}, map[string]string{"eso.managed-by": "external-secrets"}))
+}
+
+func TestSetSecretError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"read-only token"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSMOPClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	err = c.SetSecret(context.Background(), "db", nil, map[string]any{}, nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+}
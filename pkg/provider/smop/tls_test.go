@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+)
+
+// newTestKeyPair returns a PEM encoded self-signed certificate and its private key.
+func newTestKeyPair(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "smop-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestLoadTLSConfigFromSpec(t *testing.T) {
+	certPEM, keyPEM := newTestKeyPair(t)
+	_, otherKeyPEM := newTestKeyPair(t)
+
+	kube := clientfake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "smop-tls", Namespace: "default"},
+		Data: map[string][]byte{
+			"tls.crt":   certPEM,
+			"tls.key":   keyPEM,
+			"other.key": otherKeyPEM,
+		},
+	}).Build()
+	selector := func(key string) *esmeta.SecretKeySelector {
+		return &esmeta.SecretKeySelector{Name: "smop-tls", Key: key}
+	}
+
+	tests := map[string]struct {
+		tls     *esv1.SmopTLS
+		wantErr error
+	}{
+		"CA bundle and client certificate": {
+			tls: &esv1.SmopTLS{CABundle: certPEM, ClientCert: selector("tls.crt"), ClientKey: selector("tls.key")},
+		},
+		"CA bundle with trailing garbage": {
+			tls:     &esv1.SmopTLS{CABundle: append(append([]byte{}, certPEM...), "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"...)},
+			wantErr: ErrTLSInvalidCA,
+		},
+		"client certificate without key": {
+			tls:     &esv1.SmopTLS{ClientCert: selector("tls.crt")},
+			wantErr: ErrTLSClientCertKeyPair,
+		},
+		"client key without certificate": {
+			tls:     &esv1.SmopTLS{ClientKey: selector("tls.key")},
+			wantErr: ErrTLSClientCertKeyPair,
+		},
+		"client key of another certificate": {
+			tls:     &esv1.SmopTLS{ClientCert: selector("tls.crt"), ClientKey: selector("other.key")},
+			wantErr: ErrTLSInvalidClientCert,
+		},
+		"client key in place of the certificate": {
+			tls:     &esv1.SmopTLS{ClientCert: selector("tls.key"), ClientKey: selector("tls.key")},
+			wantErr: ErrTLSInvalidClientCert,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := makeValidSmopProvider()
+			spec.TLS = tc.tls
+
+			cfg, _, err := loadTLSConfigFromSpec(context.Background(), spec, kube, "default", esv1.SecretStoreKind)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, cfg.RootCAs)
+			assert.Len(t, cfg.Certificates, 1)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"sync"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+)
+
+var _ esv1.SecretVersionHinter = &Client{}
+
+// versions tracks the SMoP versions of the secrets read by a Client, keyed by their full SMoP path.
+type versions struct {
+	mu   sync.Mutex
+	read map[string]int64
+	// unknown is set once a secret without a version was read.
+	unknown bool
+}
+
+// observe records the version of the secret at `path`, zero if SMoP did not report one.
+func (v *versions) observe(path string, version int64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if version == 0 {
+		v.unknown = true
+		return
+	}
+	if v.read == nil {
+		v.read = map[string]int64{}
+	}
+	v.read[path] = version
+}
+
+// SecretVersion returns a hash of the versions of the secrets read with the Client so far,
+// so the controller can skip updating a Secret whose SMoP secrets did not change since the last sync.
+// It returns an empty string, and the Secret is always updated, if SMoP did not report the version
+// of a secret, e.g. of a checked out secret.
+func (c *Client) SecretVersion() string {
+	c.versions.mu.Lock()
+	defer c.versions.mu.Unlock()
+	if c.versions.unknown || len(c.versions.read) == 0 {
+		return ""
+	}
+	return esutils.ObjectHash(c.versions.read)
+}
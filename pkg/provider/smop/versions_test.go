@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cg "github.com/BeyondTrust/platform-secrets-manager/apiclient/clientgen"
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestSecretVersion(t *testing.T) {
+	read := func(secretVersions map[string]int64, keys ...string) string {
+		t.Helper()
+		c := &Client{
+			store: &esv1.SmopProvider{FolderPath: "apps"},
+			smopClient: &fake.SmopClient{
+				GetSecretWithMetadataFn: func(_ context.Context, name string, _ *string) (*cg.KV, *smopclient.KVMetadata, error) {
+					return &cg.KV{Path: name, Secret: cg.RedactedMap{"password": "s3cr3t"}},
+						&smopclient.KVMetadata{Path: name, Version: secretVersions[name]}, nil
+				},
+			},
+		}
+		for _, key := range keys {
+			_, err := c.GetSecret(context.Background(), esv1.ExternalSecretDataRemoteRef{Key: key, Property: "password"})
+			require.NoError(t, err)
+		}
+		return c.SecretVersion()
+	}
+
+	unchanged := read(map[string]int64{"db": 3, "api": 7}, "db", "api")
+	require.NotEmpty(t, unchanged)
+	assert.Equal(t, unchanged, read(map[string]int64{"db": 3, "api": 7}, "api", "db"), "unchanged versions must yield the same version")
+	assert.NotEqual(t, unchanged, read(map[string]int64{"db": 4, "api": 7}, "db", "api"), "a changed secret must change the version")
+	assert.Empty(t, read(map[string]int64{"db": 3}, "db", "api"), "a secret without a version must leave the version unknown")
+	assert.Empty(t, read(nil), "no version without secrets read")
+}
+
+func TestSecretVersionGetAllSecrets(t *testing.T) {
+	getAll := func(dbVersion int64) string {
+		t.Helper()
+		c := &Client{
+			store: &esv1.SmopProvider{FolderPath: "apps"},
+			smopClient: &fake.SmopClient{
+				ListSecretsFn: func(context.Context, *string) ([]smopclient.KVRef, error) {
+					return []smopclient.KVRef{{Name: "db"}, {Name: "api"}}, nil
+				},
+				BatchGetSecretsFn: func(_ context.Context, refs []smopclient.KVRef) (map[string]smopclient.BatchResult, error) {
+					kv := &cg.KV{Secret: cg.RedactedMap{"password": "s3cr3t"}}
+					return map[string]smopclient.BatchResult{
+						refs[0].String(): {KV: kv, Version: dbVersion},
+						refs[1].String(): {KV: kv, Version: 1},
+					}, nil
+				},
+			},
+		}
+		_, err := c.GetAllSecrets(context.Background(), esv1.ExternalSecretFind{})
+		require.NoError(t, err)
+		return c.SecretVersion()
+	}
+
+	assert.Equal(t, getAll(2), getAll(2))
+	assert.NotEqual(t, getAll(2), getAll(3))
+	assert.Empty(t, getAll(0))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	"github.com/external-secrets/external-secrets/pkg/esutils"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+const (
+	// defaultWorkloadIdentityAudience is the audience of ServiceAccount tokens exchanged for Smop tokens.
+	defaultWorkloadIdentityAudience = "smop"
+	// tokenExchangePath is the default token exchange endpoint, relative to the site API.
+	tokenExchangePath = "auth/token"
+	// serviceAccountTokenLifetime is the lifetime in seconds of requested ServiceAccount tokens,
+	// which are only used once for an exchange.
+	serviceAccountTokenLifetime int64 = 600
+)
+
+// ErrNoServiceAccountName is returned when the workload identity ServiceAccount has no name.
+var ErrNoServiceAccountName = errors.New("missing or invalid Smop workload identity ServiceAccount name in Smop SecretStore")
+
+// workloadIdentityKey identifies a cached workload identity token source.
+// It holds a hash of the TLS material of the store, as the exchange is sent over its TLS configuration.
+type workloadIdentityKey struct {
+	exchangeURL string
+	audience    string
+	namespace   string
+	name        string
+	audiences   string
+	tlsMaterial [sha256.Size]byte
+}
+
+// workloadIdentityTokens caches token sources across clients, so the Smop token is only exchanged
+// again when it is about to expire instead of on every reconcile.
+var workloadIdentityTokens = newLRUCache[workloadIdentityKey, *smopclient.TokenExchangeSource](maxCachedClients, nil)
+
+// validateWorkloadIdentity checks the workload identity auth of the store.
+func validateWorkloadIdentity(store esv1.GenericStore, spec *esv1.SmopWorkloadIdentityAuth) error {
+	if spec.ServiceAccountRef.Name == "" {
+		return ErrNoServiceAccountName
+	}
+	return esutils.ValidateServiceAccountSelector(store, spec.ServiceAccountRef)
+}
+
+// workloadIdentityTokenSource returns the token source exchanging the ServiceAccount token of `spec` for a Smop token.
+// A ServiceAccount of a SecretStore always lives in the store namespace.
+// tlsMaterial is the TLS material tlsConfig was built from, see loadTLSConfigFromSpec.
+func workloadIdentityTokenSource(spec *esv1.SmopWorkloadIdentityAuth, kube kclient.Client, namespace, storeKind, siteURL string, tlsConfig *tls.Config, tlsMaterial [][]byte) (*smopclient.TokenExchangeSource, error) {
+	exchangeURL := spec.TokenExchangeURL
+	if exchangeURL == "" {
+		exchangeURL = strings.TrimSuffix(siteURL, "/") + "/" + tokenExchangePath
+	}
+
+	saNamespace := namespace
+	if storeKind == esv1.ClusterSecretStoreKind && spec.ServiceAccountRef.Namespace != nil {
+		saNamespace = *spec.ServiceAccountRef.Namespace
+	}
+	audiences := spec.ServiceAccountRef.Audiences
+	if len(audiences) == 0 {
+		audiences = []string{defaultWorkloadIdentityAudience}
+	}
+
+	key := workloadIdentityKey{
+		exchangeURL: exchangeURL,
+		audience:    spec.Audience,
+		namespace:   saNamespace,
+		name:        spec.ServiceAccountRef.Name,
+		audiences:   strings.Join(audiences, ","),
+		tlsMaterial: hashParts(tlsMaterial),
+	}
+	if source, ok := workloadIdentityTokens.get(key); ok {
+		return source, nil
+	}
+
+	httpClient := http.DefaultClient
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig.Clone()
+		httpClient = &http.Client{Transport: transport}
+	}
+
+	subjectToken := func(ctx context.Context) (string, error) {
+		return serviceAccountToken(ctx, kube, saNamespace, spec.ServiceAccountRef.Name, audiences)
+	}
+	source, err := smopclient.NewTokenExchangeSource(exchangeURL, spec.Audience, subjectToken, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return workloadIdentityTokens.add(key, source), nil
+}
+
+// serviceAccountToken requests a token of the ServiceAccount `name` with the TokenRequest API.
+func serviceAccountToken(ctx context.Context, kube kclient.Client, namespace, name string, audiences []string) (string, error) {
+	expirationSeconds := serviceAccountTokenLifetime
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	if err := kube.SubResource("token").Create(ctx, sa, tokenRequest); err != nil {
+		return "", fmt.Errorf("failed to request token of ServiceAccount %s/%s: %w", namespace, name, err)
+	}
+	return tokenRequest.Status.Token, nil
+}
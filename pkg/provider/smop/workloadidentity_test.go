@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 ESO Maintainer Team
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smop
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	esv1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider/smop/smopclient"
+)
+
+func TestWorkloadIdentityAuth(t *testing.T) {
+	var exchanges atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/site/tenant/auth/token":
+			exchanges.Add(1)
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "fake-token", r.PostForm.Get("subject_token"))
+			assert.Equal(t, "smop-api", r.PostForm.Get("audience"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "exchanged", "token_type": "Bearer", "expires_in": 3600})
+		case "/site/tenant/secrets/kv/db":
+			assert.Equal(t, "Bearer exchanged", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"path":"db","secret":{"password":"s3cr3t"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	kube := clientfake.NewClientBuilder().WithObjects(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "smop-reader"},
+	}).Build()
+	spec := &esv1.SmopProvider{
+		Auth: &esv1.SmopAuth{WorkloadIdentity: &esv1.SmopWorkloadIdentityAuth{
+			ServiceAccountRef: esmeta.ServiceAccountSelector{Name: "smop-reader"},
+			Audience:          "smop-api",
+		}},
+		Server: &esv1.SmopServer{APIURL: srv.URL + "/site", SiteId: "tenant"},
+	}
+
+	for range 2 {
+		c, err := newSmopClient(context.Background(), spec, kube, "apps", storeRef{kind: esv1.SecretStoreKind})
+		require.NoError(t, err)
+		kv, err := c.GetSecret(context.Background(), "db", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", kv.Secret["password"])
+	}
+	assert.Equal(t, int64(1), exchanges.Load(), "the exchanged token must be reused across clients until it expires")
+}
+
+func TestWorkloadIdentityTokenSourceCached(t *testing.T) {
+	previous := workloadIdentityTokens
+	workloadIdentityTokens = newLRUCache[workloadIdentityKey, *smopclient.TokenExchangeSource](2, nil)
+	t.Cleanup(func() { workloadIdentityTokens = previous })
+
+	kube := clientfake.NewClientBuilder().Build()
+	spec := &esv1.SmopWorkloadIdentityAuth{ServiceAccountRef: esmeta.ServiceAccountSelector{Name: "smop-reader"}}
+	source := func(tlsMaterial ...[]byte) *smopclient.TokenExchangeSource {
+		t.Helper()
+		s, err := workloadIdentityTokenSource(spec, kube, "apps", esv1.SecretStoreKind, "https://smop.example.com/site", &tls.Config{MinVersion: tls.VersionTLS12}, tlsMaterial)
+		require.NoError(t, err)
+		return s
+	}
+
+	first := source([]byte("ca-1"))
+	assert.Same(t, first, source([]byte("ca-1")))
+	assert.NotSame(t, first, source([]byte("ca-2")), "a token source must not be reused with another TLS configuration")
+	assert.NotSame(t, first, source(), "a token source must not be reused without TLS")
+	assert.Equal(t, 2, workloadIdentityTokens.len(), "token sources must be evicted beyond the cache size")
+}